@@ -15,6 +15,8 @@ package v1alpha1
 import (
 	"context"
 	"fmt"
+	"strings"
+	"time"
 
 	cerrors "github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/errors"
 	"github.com/RedHatInsights/rhc-osdk-utils/utils"
@@ -23,6 +25,8 @@ import (
 	apps "k8s.io/api/apps/v1"
 	batch "k8s.io/api/batch/v1"
 	v1 "k8s.io/api/core/v1"
+	rbac "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
@@ -75,6 +79,205 @@ type DatabaseSpec struct {
 	// T-shirt size, one of small, medium, large
 	// +kubebuilder:validation:Enum={"small", "medium", "large"}
 	DBResourceSize string `json:"dbResourceSize,omitempty"`
+
+	// Overrides the database provider mode set in the ClowdEnvironment for this
+	// app only. Currently only (*_app-interface_*) is supported, and the
+	// environment must set allowAppModeOverride for the override to be honoured.
+	// +kubebuilder:validation:Enum={"app-interface"}
+	ModeOverride string `json:"modeOverride,omitempty"`
+
+	// Ephemeral storage request and limit for the database container in
+	// (*_local_*) mode. If unset, no ephemeral storage is requested.
+	EphemeralStorage *EphemeralStorageRequirements `json:"ephemeralStorage,omitempty"`
+
+	// Defines when changes that restart the database in (*_local_*) mode, such
+	// as an image or resource change, may be applied. Outside the window these
+	// changes are deferred and the app reports a PendingMaintenance condition.
+	// If unset, all changes are applied immediately.
+	MaintenanceWindow *MaintenanceWindow `json:"maintenanceWindow,omitempty"`
+
+	// Scales the app's deployments to zero before a new image is rolled out
+	// to the database in (*_local_*) mode, and back up once the database is
+	// ready again, so that the app's connections are closed rather than
+	// dropped by the restart. Requires a maintenanceWindow, within which the
+	// drain takes place. The step it is at is reported by the
+	// DatabaseDraining condition.
+	DrainOnUpgrade bool `json:"drainOnUpgrade,omitempty"`
+
+	// Overrides the pod selector of the database service in (*_local_*)
+	// mode, so that the service keeps routing to existing pods while a
+	// hand-managed database is migrated under Clowder. If unset, the service
+	// selects the database pods created by Clowder.
+	ServiceSelector map[string]string `json:"serviceSelector,omitempty"`
+
+	// Overrides the SQL statement the readiness probe runs against the
+	// database in (*_local_*) mode, e.g. to only mark the database ready once
+	// a bootstrap migration has created a table. The probe times out after
+	// two seconds, so the statement should be cheap. Defaults to SELECT 1.
+	// +kubebuilder:validation:MinLength=1
+	ReadinessQuery string `json:"readinessQuery,omitempty"`
+
+	// Sets max_connections of the database in (*_local_*) mode. The effective
+	// value is presented to the app as maxConnections in its database
+	// configuration, so connection pools can be sized to fit. Defaults to the
+	// image's default of 100.
+	// +kubebuilder:validation:Minimum=1
+	MaxConnections *int32 `json:"maxConnections,omitempty"`
+
+	// Tunes the thresholds of the liveness probe of the database in
+	// (*_local_*) mode, e.g. so that a database that is slow to recover
+	// tolerates more failures before it is restarted.
+	LivenessProbe *DatabaseProbeThresholds `json:"livenessProbe,omitempty"`
+
+	// Tunes the thresholds of the readiness probe of the database in
+	// (*_local_*) mode.
+	ReadinessProbe *DatabaseProbeThresholds `json:"readinessProbe,omitempty"`
+
+	// A list of pull secrets, in the same namespace as the ClowdApp, to use
+	// when pulling the database image in (*_local_*) mode. These are merged
+	// with the pull secrets set in the ClowdEnvironment.
+	ImagePullSecrets []v1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
+
+	// Sets the image pull policy of the database container in (*_local_*)
+	// mode independently of the app's own images, e.g. IfNotPresent for a
+	// pinned database image while app images are pulled Always. Defaults to
+	// the environment's deployment imagePullPolicy.
+	// +kubebuilder:validation:Enum={"Always", "IfNotPresent", "Never"}
+	ImagePullPolicy v1.PullPolicy `json:"imagePullPolicy,omitempty"`
+
+	// The format of the connection URL Clowder stores under db.url in the
+	// database secret in (*_local_*) and (*_shared_*) modes, either
+	// (*_postgres_*) for a postgres:// URL or (*_jdbc_*) for a
+	// jdbc:postgresql: URL. Defaults to (*_postgres_*).
+	// +kubebuilder:validation:Enum=postgres;jdbc
+	URLFormat string `json:"urlFormat,omitempty"`
+
+	// Runs SQL scripts against the database in (*_local_*) mode from a Job,
+	// each of them exactly once, so that scripts added later are run without
+	// running the earlier ones again. Failures are reported by the
+	// DatabaseMigrationFailed condition. If unset, no scripts are run.
+	Migrations *DatabaseMigrations `json:"migrations,omitempty"`
+}
+
+// DatabaseMigrations names the SQL scripts to run against an app's local
+// database.
+type DatabaseMigrations struct {
+	// The name of a ConfigMap in the app's namespace holding the scripts, one
+	// per key. The scripts are run in the order of their keys, each in a
+	// transaction that also records it in the clowder_migrations table of the
+	// database.
+	// +kubebuilder:validation:MinLength=1
+	ConfigMapName string `json:"configMapName"`
+}
+
+// DatabaseProbeThresholds sets how many consecutive probe results it takes
+// for a probe of the database to change state.
+type DatabaseProbeThresholds struct {
+	// Consecutive failures after which the probe fails. Defaults to 3.
+	// +kubebuilder:validation:Minimum=1
+	FailureThreshold *int32 `json:"failureThreshold,omitempty"`
+
+	// Consecutive successes after which a failing probe passes again.
+	// Defaults to 1, which is the only value allowed for the liveness probe.
+	// +kubebuilder:validation:Minimum=1
+	SuccessThreshold *int32 `json:"successThreshold,omitempty"`
+}
+
+// MaintenanceWindow defines a recurring window of time in UTC.
+type MaintenanceWindow struct {
+	// The days of the week the window opens on, e.g. ["Sat", "Sun"]. If unset,
+	// the window opens every day.
+	Days []string `json:"days,omitempty"`
+
+	// The time of day the window opens, in UTC, in HH:MM format.
+	// +kubebuilder:validation:Pattern=`^([01][0-9]|2[0-3]):[0-5][0-9]$`
+	Start string `json:"start"`
+
+	// How long the window stays open, e.g. 2h.
+	Duration metav1.Duration `json:"duration"`
+}
+
+var weekdays = map[string]time.Weekday{
+	"Sun": time.Sunday,
+	"Mon": time.Monday,
+	"Tue": time.Tuesday,
+	"Wed": time.Wednesday,
+	"Thu": time.Thursday,
+	"Fri": time.Friday,
+	"Sat": time.Saturday,
+}
+
+// Validate returns an error if the window's days, start or duration are invalid.
+func (m *MaintenanceWindow) Validate() error {
+	for _, day := range m.Days {
+		if _, ok := weekdays[day]; !ok {
+			return fmt.Errorf("unknown day %q, must be one of Sun, Mon, Tue, Wed, Thu, Fri, Sat", day)
+		}
+	}
+	if _, err := time.Parse("15:04", m.Start); err != nil {
+		return fmt.Errorf("invalid start %q: %w", m.Start, err)
+	}
+	if m.Duration.Duration <= 0 || m.Duration.Duration > 7*24*time.Hour {
+		return fmt.Errorf("duration must be between 0 and 168h")
+	}
+	return nil
+}
+
+func (m *MaintenanceWindow) opensOn(day time.Weekday) bool {
+	if len(m.Days) == 0 {
+		return true
+	}
+	for _, d := range m.Days {
+		if weekdays[d] == day {
+			return true
+		}
+	}
+	return false
+}
+
+// startOn returns the time the window would open on the day of t.
+func (m *MaintenanceWindow) startOn(t time.Time) time.Time {
+	start, _ := time.Parse("15:04", m.Start)
+	return time.Date(t.Year(), t.Month(), t.Day(), start.Hour(), start.Minute(), 0, 0, time.UTC)
+}
+
+// IsOpen returns true if t falls inside the window. An invalid window is never open.
+func (m *MaintenanceWindow) IsOpen(t time.Time) bool {
+	if m.Validate() != nil {
+		return false
+	}
+	t = t.UTC()
+	// A window may have opened on an earlier day and still be running
+	for days := 0; days <= 7; days++ {
+		start := m.startOn(t.AddDate(0, 0, -days))
+		if !start.After(t) && t.Before(start.Add(m.Duration.Duration)) && m.opensOn(start.Weekday()) {
+			return true
+		}
+	}
+	return false
+}
+
+// NextOpen returns the next time after t that the window opens.
+func (m *MaintenanceWindow) NextOpen(t time.Time) time.Time {
+	t = t.UTC()
+	for days := 0; days <= 7; days++ {
+		start := m.startOn(t.AddDate(0, 0, days))
+		if start.After(t) && m.opensOn(start.Weekday()) {
+			return start
+		}
+	}
+	return t.AddDate(0, 0, 7)
+}
+
+// EphemeralStorageRequirements defines the ephemeral-storage request and limit
+// for a container.
+type EphemeralStorageRequirements struct {
+	// The amount of ephemeral storage requested for the container
+	Request *resource.Quantity `json:"request,omitempty"`
+
+	// The maximum amount of ephemeral storage the container may use before its
+	// pod is evicted
+	Limit *resource.Quantity `json:"limit,omitempty"`
 }
 
 // Job defines a ClowdJob
@@ -130,6 +333,11 @@ type Job struct {
 	// The activeDeadlineSeconds for the Job or CronJob.
 	// More info: https://kubernetes.io/docs/concepts/workloads/controllers/job/
 	ActiveDeadlineSeconds *int64 `json:"activeDeadlineSeconds,omitempty"`
+
+	// The name of an existing ServiceAccount in the app's namespace that the
+	// job's pods run as, e.g. one carrying IAM annotations for the systems the
+	// job calls. Defaults to the app's ServiceAccount.
+	ServiceAccountName string `json:"serviceAccountName,omitempty"`
 }
 
 // WebDeprecated defines a boolean flag to help distinguish from the newer WebServices
@@ -179,10 +387,58 @@ type WebServices struct {
 	Metrics MetricsWebService `json:"metrics,omitempty"`
 }
 
+// DeploymentService is an additional Service for a deployment, exposing some
+// of its ports under a name of its own.
+type DeploymentService struct {
+	// The name of the Service. It must be a DNS-1035 label, and unique among
+	// the Services of the ClowdApp.
+	Name string `json:"name"`
+
+	// The ports of the deployment's Service to expose, any of public, private,
+	// metrics, auth, tls and tls-private.
+	// +kubebuilder:validation:MinItems=1
+	Ports []string `json:"ports"`
+
+	// The labels of the pods the Service selects, which are always among the
+	// pods of the ClowdApp. Defaults to the pods of the deployment.
+	Selector map[string]string `json:"selector,omitempty"`
+}
+
 // K8sAccessLevel defines the access level for the deployment, one of 'default', 'view' or 'edit'
 // +kubebuilder:validation:Enum={"default", "view", "", "edit"}
 type K8sAccessLevel string
 
+// DeploymentKind defines the kind of workload a deployment is run as, one of
+// 'Deployment' or 'DaemonSet'
+// +kubebuilder:validation:Enum={"Deployment", "DaemonSet"}
+type DeploymentKind string
+
+// ConfigReloadMode defines how a deployment takes in changes to its config, one
+// of 'restart' or 'inPlace'
+// +kubebuilder:validation:Enum={"restart", "inPlace"}
+type ConfigReloadMode string
+
+const (
+	// ConfigReloadRestart rolls the deployment's pods when its config changes
+	ConfigReloadRestart ConfigReloadMode = "restart"
+	// ConfigReloadInPlace leaves the pods running while the config mounted into them is updated
+	ConfigReloadInPlace ConfigReloadMode = "inPlace"
+)
+
+// PodAntiAffinityMode defines how strongly the pods of a deployment avoid each
+// other, one of 'preferred', 'required' or 'disabled'
+// +kubebuilder:validation:Enum={"preferred", "required", "disabled"}
+type PodAntiAffinityMode string
+
+const (
+	// PodAntiAffinityPreferred spreads the pods across zones and nodes where the scheduler can
+	PodAntiAffinityPreferred PodAntiAffinityMode = "preferred"
+	// PodAntiAffinityRequired never schedules two of the pods on the same node
+	PodAntiAffinityRequired PodAntiAffinityMode = "required"
+	// PodAntiAffinityDisabled adds no anti-affinity
+	PodAntiAffinityDisabled PodAntiAffinityMode = "disabled"
+)
+
 type DeploymentMetadata struct {
 	Annotations map[string]string `json:"annotations,omitempty"`
 }
@@ -226,6 +482,60 @@ type Deployment struct {
 	DeploymentStrategy *DeploymentStrategy `json:"deploymentStrategy,omitempty"`
 
 	Metadata DeploymentMetadata `json:"metadata,omitempty"`
+
+	// A list of pull secrets, in the same namespace as the ClowdApp, to use when
+	// pulling the deployment's images. These are merged with the pull secrets
+	// set in the ClowdEnvironment.
+	ImagePullSecrets []v1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
+
+	// The number of seconds a rollout of the deployment may take before it
+	// is reported as failed in the ClowdApp's status. Defaults to 600.
+	// +kubebuilder:validation:Minimum=1
+	ProgressDeadlineSeconds *int32 `json:"progressDeadlineSeconds,omitempty"`
+
+	// The kind of workload the deployment is run as. A DaemonSet runs one pod
+	// on every schedulable node and so cannot set replicas, an autoscaler, a
+	// deployment strategy or a progress deadline. Defaults to Deployment.
+	Kind DeploymentKind `json:"kind,omitempty"`
+
+	// How the deployment's pods take in changes to their config. With restart,
+	// the default, the pods are restarted whenever the app config or a
+	// ConfigMap or Secret it uses changes. With inPlace the pods are left
+	// running and the files mounted from the app config secret are updated in
+	// place, for apps that reload their config themselves.
+	ConfigReload ConfigReloadMode `json:"configReload,omitempty"`
+
+	// The number of seconds the deployment's container sleeps in a preStop
+	// hook before it is sent SIGTERM, so that load balancers stop routing to
+	// a terminating pod before it shuts down. The image must provide sleep.
+	// Defaults to 0, which adds no hook.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=25
+	DrainDelaySeconds *int32 `json:"drainDelaySeconds,omitempty"`
+
+	// The names of other deployments of the ClowdApp that must be rolled out
+	// and available before this one is. The rollout of this deployment is
+	// paused until they are, so it cannot be a DaemonSet. Deployments without
+	// dependencies are rolled out at the same time.
+	DependsOn []string `json:"dependsOn,omitempty"`
+
+	// A list of additional Services for the deployment, each exposing some of
+	// its ports under a name of its own, e.g. for service mesh routes keyed on
+	// the service name. The deployment's Service with all of its ports is
+	// always created.
+	Services []DeploymentService `json:"services,omitempty"`
+
+	// How the deployment's pods avoid being placed with each other. With
+	// preferred, the default, the scheduler spreads them across zones and
+	// nodes where it can. With required no two of them run on the same node,
+	// leaving replicas pending when there are too few nodes, and disabled
+	// places them wherever the scheduler likes.
+	PodAntiAffinity PodAntiAffinityMode `json:"podAntiAffinity,omitempty"`
+}
+
+// IsDaemonSet returns true if the deployment is run as a DaemonSet.
+func (d *Deployment) IsDaemonSet() bool {
+	return d.Kind == "DaemonSet"
 }
 
 func (d *Deployment) GetReplicaCount() *int32 {
@@ -253,6 +563,22 @@ type Sidecar struct {
 
 	// Defines if the sidecar is enabled, defaults to False
 	Enabled bool `json:"enabled"`
+
+	// Keeps the sidecar running for this many seconds after the pod starts
+	// terminating, so that it outlives the pod's container, defaults to 0
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=300
+	TerminationDelaySeconds int32 `json:"terminationDelaySeconds,omitempty"`
+}
+
+// SidecarVolume defines an emptyDir volume shared between a pod's container and
+// its injected sidecars.
+type SidecarVolume struct {
+	// The name of the volume, which must not match any of the pod's volumes
+	Name string `json:"name"`
+
+	// The path the volume is mounted at in the pod container and in each sidecar
+	MountPath string `json:"mountPath"`
 }
 
 // Metadata for applying annotations etc to PodSpec
@@ -297,15 +623,32 @@ type PodSpec struct {
 	// false.
 	ReadinessProbe *v1.Probe `json:"readinessProbe,omitempty"`
 
+	// Disables the readiness probe Clowder sets up when no readinessProbe is
+	// given. By default public web services get an HTTP probe on /healthz and
+	// private-only web services get a TCP probe on the privatePort.
+	DisableDefaultReadinessProbe bool `json:"disableDefaultReadinessProbe,omitempty"`
+
 	// A pass-through of a list of Volumes in standa k8s format.
 	Volumes []v1.Volume `json:"volumes,omitempty"`
 
 	// A pass-through of a list of VolumesMounts in standa k8s format.
 	VolumeMounts []v1.VolumeMount `json:"volumeMounts,omitempty"`
 
+	// A pass-through of a list of VolumeDevices in standard k8s format. Each
+	// attaches one of the pod's volumes to the pod container as a raw block
+	// device, which requires the volume's PersistentVolumeClaim to set
+	// volumeMode to Block. A volume cannot be both mounted and a device.
+	VolumeDevices []v1.VolumeDevice `json:"volumeDevices,omitempty"`
+
 	// Lists the expected side cars, will be validated in the validating webhook
 	Sidecars []Sidecar `json:"sidecars,omitempty"`
 
+	// Volumes shared between the pod container and its injected sidecars,
+	// e.g. a directory holding a unix socket. Each is rendered as an emptyDir
+	// mounted in every one of those containers, and only when at least one
+	// sidecar is injected.
+	SidecarVolumes []SidecarVolume `json:"sidecarVolumes,omitempty"`
+
 	// MachinePool allows the pod to be scheduled to a particular machine pool.
 	MachinePool string `json:"machinePool,omitempty"`
 }
@@ -397,10 +740,31 @@ type KafkaTopicSpec struct {
 	// +kubebuilder:validation:Maximum:=32767
 	Replicas int32 `json:"replicas,omitempty"`
 
+	// The minimum number of in-sync replicas that must acknowledge a write to
+	// this topic when producers use acks=all. It may not exceed the number of
+	// replicas. If unset, default is '1'
+	// +optional
+	// +kubebuilder:validation:Minimum:=1
+	// +kubebuilder:validation:Maximum:=32767
+	MinInSyncReplicas int32 `json:"minInSyncReplicas,omitempty"`
+
+	// The compression.type of this topic, the codec the brokers store its
+	// messages with. If unset, default is 'producer', which keeps the codec
+	// the producer used
+	// +optional
+	// +kubebuilder:validation:Enum={"producer", "uncompressed", "gzip", "snappy", "lz4", "zstd"}
+	CompressionType string `json:"compressionType,omitempty"`
+
+	// The tier of this topic, naming one of the environment's topicTiers. The
+	// tier's defaults apply to the partitions and config this topic does not
+	// set. If unset, default is 'standard'
+	// +optional
+	Tier string `json:"tier,omitempty"`
+
 	// The requested name for this topic.
 	// +kubebuilder:validation:MinLength:=1
 	// +kubebuilder:validation:MaxLength:=249
-	// +kubebuilder:validation:Pattern:="[a-zA-Z0-9\\._\\-]"
+	// +kubebuilder:validation:Pattern:="^[a-zA-Z0-9\\._\\-]+$"
 	TopicName string `json:"topicName"`
 }
 
@@ -408,6 +772,46 @@ type TestingSpec struct {
 	IqePlugin string `json:"iqePlugin"`
 }
 
+// ObjectStoreScope defines a named set of object store credentials that are
+// limited to a subset of the app's buckets.
+type ObjectStoreScope struct {
+	// The name of the scope, used to look up its credentials in the
+	// cdappconfig.
+	Name string `json:"name"`
+
+	// The buckets the scope grants access to. Each bucket must also be
+	// listed in objectStore.
+	Buckets []string `json:"buckets"`
+
+	// If readOnly is set to true, the scope's credentials may only list and
+	// read objects in its buckets.
+	ReadOnly bool `json:"readOnly,omitempty"`
+}
+
+// ConfigDependency names a ConfigMap or Secret in the ClowdApp's namespace
+// whose changes should restart the app.
+type ConfigDependency struct {
+	// The kind of the object, either ConfigMap or Secret.
+	// +kubebuilder:validation:Enum=ConfigMap;Secret
+	Kind string `json:"kind"`
+
+	// The name of the object.
+	Name string `json:"name"`
+}
+
+// InMemoryDBSpec declares a named in-memory store of a ClowdApp.
+type InMemoryDBSpec struct {
+	// The name of the store, under which its configuration is passed to the app.
+	Name string `json:"name"`
+
+	// The index of the Redis logical database the store uses, from 0 to 15.
+	// It must be unique among the app's stores, but is not checked against
+	// other apps sharing the same server.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=15
+	DBIndex int32 `json:"dbIndex"`
+}
+
 // ClowdAppSpec is the main specification for a single Clowder Application
 // it defines n pods along with dependencies that are shared between them.
 type ClowdAppSpec struct {
@@ -419,13 +823,22 @@ type ClowdAppSpec struct {
 
 	// The name of the ClowdEnvironment resource that this ClowdApp will use as
 	// its base. This does not mean that the ClowdApp needs to be placed in the
-	// same directory as the targetNamespace of the ClowdEnvironment.
-	EnvName string `json:"envName"`
+	// same namespace as the targetNamespace of the ClowdEnvironment.
+	// ClowdEnvironments are cluster scoped, so they are referenced by name
+	// alone from ClowdApps in any namespace. When omitted, the default
+	// environment the operator is configured with is used.
+	EnvName string `json:"envName,omitempty"`
 
 	// A list of Kafka topics that will be created and made available to all
 	// the pods listed in the ClowdApp.
 	KafkaTopics []KafkaTopicSpec `json:"kafkaTopics,omitempty"`
 
+	// Keeps the app's Kafka topics when the ClowdApp is deleted, defaults to
+	// true. When false, the topics created for the app in (*_operator_*) mode
+	// are deleted along with it, except those another app in the environment
+	// also requests.
+	RetainTopics *bool `json:"retainTopics,omitempty"`
+
 	// The database specification defines a single database, the configuration
 	// of which will be made available to all the pods in the ClowdApp.
 	Database DatabaseSpec `json:"database,omitempty"`
@@ -434,11 +847,21 @@ type ClowdAppSpec struct {
 	// defined by the ClowdEnvironment, Clowder will create those buckets.
 	ObjectStore []string `json:"objectStore,omitempty"`
 
+	// A list of named credential scopes for the object store. Each scope is
+	// given its own access and secret key, which only grant access to the
+	// buckets it lists.
+	ObjectStoreScopes []ObjectStoreScope `json:"objectStoreScopes,omitempty"`
+
 	// If inMemoryDb is set to true, Clowder will pass configuration
 	// of an In Memory Database to the pods in the ClowdApp. This single
 	// instance will be shared between all apps.
 	InMemoryDB bool `json:"inMemoryDb,omitempty"`
 
+	// A list of named in-memory stores, for apps that keep e.g. their cache and
+	// session storage apart. Each gets its own logical database on the In Memory
+	// Database server, which inMemoryDb itself uses database 0 of.
+	InMemoryDBs []InMemoryDBSpec `json:"inMemoryDbs,omitempty"`
+
 	// If featureFlags is set to true, Clowder will pass configuration of a
 	// FeatureFlags instance to the pods in the ClowdApp. This single
 	// instance will be shared between all apps.
@@ -452,6 +875,33 @@ type ClowdAppSpec struct {
 	// will be added to the configuration when present.
 	OptionalDependencies []string `json:"optionalDependencies,omitempty"`
 
+	// If waitForDependencies is set to true, Clowder will add an init container
+	// to each of the ClowdApp's deployments that blocks the pod from starting
+	// until the services of its hard dependencies and its databases are reachable.
+	WaitForDependencies bool `json:"waitForDependencies,omitempty"`
+
+	// A list of the ClowdEnvironment providers this app requires, e.g.
+	// objectStore. The app fails to reconcile, with a ProvidersMissing
+	// condition, while its environment runs any of them in (*_none_*) mode.
+	RequiredProviders []ProviderName `json:"requiredProviders,omitempty"`
+
+	// A list of ConfigMaps and Secrets in the ClowdApp's namespace, typically
+	// managed outside of Clowder, whose contents are folded into the config
+	// hash. Changes to any of them restart the app's pods, whether or not they
+	// carry the restarter annotation.
+	ConfigDependencies []ConfigDependency `json:"configDependencies,omitempty"`
+
+	// The port that the app's deployments expose metrics on. It is kept separate
+	// from the public and private ports and is only used as the scrape target for
+	// Prometheus. If unset, the port from the ClowdEnvironment's metrics provider
+	// configuration is used.
+	MetricsPort int32 `json:"metricsPort,omitempty"`
+
+	// A list of rules for a Role that Clowder creates in the ClowdApp's namespace
+	// and binds to the service accounts of the app and each of its deployments,
+	// giving them access to the k8s API there. No Role is created if unset.
+	RBACRules []rbac.PolicyRule `json:"rbacRules,omitempty"`
+
 	// Iqe plugin and other specifics
 	Testing TestingSpec `json:"testing,omitempty"`
 
@@ -465,6 +915,11 @@ type ClowdAppSpec struct {
 
 	// Disabled turns off reconciliation for this ClowdApp
 	Disabled bool `json:"disabled,omitempty"`
+
+	// Settings presented to the app under globalConfig in its
+	// cdappconfig.json, overriding those of the same key in the
+	// ClowdEnvironment's globalConfig.
+	GlobalConfig map[string]string `json:"globalConfig,omitempty"`
 }
 
 const (
@@ -476,6 +931,36 @@ const (
 	ReconciliationFailed clusterv1.ConditionType = "ReconciliationFailed"
 	// JobInvocationComplete means all the Jobs have finished
 	JobInvocationComplete clusterv1.ConditionType = "JobInvocationComplete"
+	// VolumeUnbound means one or more of the PersistentVolumeClaims belonging to the app are not bound
+	VolumeUnbound clusterv1.ConditionType = "VolumeUnbound"
+	// PendingMaintenance means changes that would restart the app's database are waiting for its maintenance window
+	PendingMaintenance clusterv1.ConditionType = "PendingMaintenance"
+	// VolumeZoneMismatch means the app's database pod cannot be scheduled in the zone its volume lives in
+	VolumeZoneMismatch clusterv1.ConditionType = "VolumeZoneMismatch"
+	// CircuitOpen means reconciles of the app are being held back after it failed repeatedly
+	CircuitOpen clusterv1.ConditionType = "CircuitOpen"
+	// Failed means the app used up the reconcile retries its environment allows, and is not retried until it changes
+	Failed clusterv1.ConditionType = "Failed"
+	// RolloutFailed means a rollout of one of the app's deployments exceeded its progress deadline
+	RolloutFailed clusterv1.ConditionType = "RolloutFailed"
+	// CrashLooping means containers in the app's pods are being restarted in a CrashLoopBackOff
+	CrashLooping clusterv1.ConditionType = "CrashLooping"
+	// ProviderTimedOut means the last reconcile failed because a provider ran past its deadline
+	ProviderTimedOut clusterv1.ConditionType = "ProviderTimedOut"
+	// ProvidersMissing means the environment does not offer a provider the app requires
+	ProvidersMissing clusterv1.ConditionType = "ProvidersMissing"
+	// DatabaseImageMissing means there is no image to run the app's local database with
+	DatabaseImageMissing clusterv1.ConditionType = "DatabaseImageMissing"
+	// DatabaseDraining means the app's deployments are scaled down while its local database is upgraded
+	DatabaseDraining clusterv1.ConditionType = "DatabaseDraining"
+	// DatabaseMigrationFailed means the job running the migrations of the app's local database failed
+	DatabaseMigrationFailed clusterv1.ConditionType = "DatabaseMigrationFailed"
+	// KafkaTopicCreationFailed means a topic of the app could not be created on the Kafka cluster
+	KafkaTopicCreationFailed clusterv1.ConditionType = "KafkaTopicCreationFailed"
+	// MaintenanceMode means the app's config has maintenanceMode set while its database migrations run
+	MaintenanceMode clusterv1.ConditionType = "MaintenanceMode"
+	// EnvironmentReady means the shared infrastructure of a ClowdEnvironment has been provisioned
+	EnvironmentReady clusterv1.ConditionType = clusterv1.ReadyCondition
 )
 
 // ClowdAppStatus defines the observed state of ClowdApp
@@ -486,6 +971,93 @@ type ClowdAppStatus struct {
 	Deployments AppResourceStatus     `json:"deployments,omitempty"`
 	Ready       bool                  `json:"ready"`
 	Conditions  []clusterv1.Condition `json:"conditions,omitempty"`
+	// The hash of the app config that was last applied successfully, this
+	// matches the configHash annotation on the app's pods.
+	ConfigHash string `json:"configHash,omitempty"`
+	// Advisory right-sized resources for the app's deployments, based on their
+	// observed usage. Only reported when the ClowdEnvironment enables resource
+	// recommendations, and never applied by Clowder.
+	ResourceRecommendation *ResourceRecommendation `json:"resourceRecommendation,omitempty"`
+	// The rollout progress of each of the app's deployments. Only reported
+	// when any of them depends on another.
+	DeploymentRollouts []DeploymentRolloutStatus `json:"deploymentRollouts,omitempty"`
+	// The objects Clowder manages for the app, as of the last successful
+	// reconcile. Objects Clowder has deleted are pruned from it.
+	Inventory []ManagedObject `json:"inventory,omitempty"`
+}
+
+// ManagedObject identifies an object Clowder manages for a ClowdApp.
+type ManagedObject struct {
+	// The API version of the object, e.g. apps/v1.
+	APIVersion string `json:"apiVersion"`
+
+	// The kind of the object.
+	Kind string `json:"kind"`
+
+	// The name of the object.
+	Name string `json:"name"`
+
+	// The namespace of the object.
+	Namespace string `json:"namespace"`
+
+	// The UID of the object.
+	UID types.UID `json:"uid"`
+
+	// The resourceVersion of the object when the inventory last changed. A
+	// change that only moves the resourceVersions of the objects does not
+	// update the inventory.
+	ResourceVersion string `json:"resourceVersion"`
+}
+
+// RolloutPhase is the progress of a deployment's rollout, one of 'Waiting',
+// 'RollingOut' or 'Ready'
+type RolloutPhase string
+
+const (
+	// RolloutWaiting means the rollout is paused until the deployments it depends on are ready
+	RolloutWaiting RolloutPhase = "Waiting"
+	// RolloutRollingOut means the deployment's pods are being replaced or are not yet available
+	RolloutRollingOut RolloutPhase = "RollingOut"
+	// RolloutReady means all of the deployment's pods run its current spec and are available
+	RolloutReady RolloutPhase = "Ready"
+)
+
+// DeploymentRolloutStatus reports how far the rollout of a deployment has got.
+type DeploymentRolloutStatus struct {
+	// The name of the deployment, as given in the ClowdApp.
+	Name string `json:"name"`
+
+	// The phase of the rollout.
+	Phase RolloutPhase `json:"phase"`
+
+	// The deployments that are not yet ready, while the rollout is waiting.
+	WaitingOn []string `json:"waitingOn,omitempty"`
+}
+
+// ResourceRecommendation suggests resources for each of an app's deployments.
+type ResourceRecommendation struct {
+	// The time the recommendation was last computed.
+	LastUpdated metav1.Time `json:"lastUpdated"`
+
+	// A recommendation per deployment with usage data.
+	Deployments []DeploymentResourceRecommendation `json:"deployments,omitempty"`
+}
+
+// DeploymentResourceRecommendation compares the observed usage of a deployment
+// with the resources suggested for it.
+type DeploymentResourceRecommendation struct {
+	// The name of the deployment, as given in the ClowdApp.
+	Name string `json:"name"`
+
+	// The 95th percentile of CPU usage and the peak memory usage of the
+	// deployment's containers over the last day.
+	Observed v1.ResourceList `json:"observed,omitempty"`
+
+	// The suggested resource requests, the observed usage plus headroom.
+	Requests v1.ResourceList `json:"requests,omitempty"`
+
+	// The suggested resource limits, twice the suggested requests.
+	Limits v1.ResourceList `json:"limits,omitempty"`
 }
 
 type AppResourceStatus struct {
@@ -631,11 +1203,88 @@ func (i *ClowdApp) IsReady() bool {
 	return i.Status.Ready && conditionCheck
 }
 
+// RetainsTopics returns whether the app's Kafka topics are kept when it is deleted.
+func (i *ClowdApp) RetainsTopics() bool {
+	return i.Spec.RetainTopics == nil || *i.Spec.RetainTopics
+}
+
+// RequestsInMemoryDB returns whether the app uses the In Memory Database, directly or through
+// named stores.
+func (i *ClowdApp) RequestsInMemoryDB() bool {
+	return i.Spec.InMemoryDB || len(i.Spec.InMemoryDBs) > 0
+}
+
+// HasDeploymentOrder returns whether any of the app's deployments depend on another.
+func (i *ClowdApp) HasDeploymentOrder() bool {
+	for _, deployment := range i.Spec.Deployments {
+		if len(deployment.DependsOn) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// GetDeploymentRolloutOrder returns the app's deployments ordered so that each comes after the
+// deployments it depends on, and in the order of the spec otherwise. It fails if a deployment
+// depends on one the app does not have, or if the dependencies form a cycle.
+func (i *ClowdApp) GetDeploymentRolloutOrder() ([]*Deployment, error) {
+	names := map[string]bool{}
+	for _, deployment := range i.Spec.Deployments {
+		names[deployment.Name] = true
+	}
+	for _, deployment := range i.Spec.Deployments {
+		for _, dep := range deployment.DependsOn {
+			if !names[dep] {
+				return nil, fmt.Errorf("deployment %s depends on unknown deployment %s", deployment.Name, dep)
+			}
+		}
+	}
+
+	ordered := []*Deployment{}
+	placed := map[string]bool{}
+	for len(ordered) < len(i.Spec.Deployments) {
+		progressed := false
+		for idx := range i.Spec.Deployments {
+			deployment := &i.Spec.Deployments[idx]
+			if placed[deployment.Name] {
+				continue
+			}
+			depsPlaced := true
+			for _, dep := range deployment.DependsOn {
+				depsPlaced = depsPlaced && placed[dep]
+			}
+			if depsPlaced {
+				ordered = append(ordered, deployment)
+				placed[deployment.Name] = true
+				progressed = true
+			}
+		}
+		if !progressed {
+			remaining := []string{}
+			for _, deployment := range i.Spec.Deployments {
+				if !placed[deployment.Name] {
+					remaining = append(remaining, deployment.Name)
+				}
+			}
+			return nil, fmt.Errorf("deployments %s cannot be ordered, their dependencies form a cycle", strings.Join(remaining, ", "))
+		}
+	}
+	return ordered, nil
+}
+
 // GetClowdSAName returns the ServiceAccount Name for the App
 func (i *ClowdApp) GetClowdSAName() string {
 	return fmt.Sprintf("%s-app", i.GetClowdName())
 }
 
+// GetJobSAName returns the name of the ServiceAccount the given job's pods run as.
+func (i *ClowdApp) GetJobSAName(job *Job) string {
+	if job.ServiceAccountName != "" {
+		return job.ServiceAccountName
+	}
+	return i.GetClowdSAName()
+}
+
 // omfunc is a utility function that performs an operation on a metav1.Object.
 type omfunc func(o metav1.Object)
 
@@ -707,8 +1356,14 @@ func GetAppForDBInSameEnv(ctx context.Context, pClient client.Client, app *Clowd
 	return nil, fmt.Errorf("could not get app for db in env")
 }
 
+// GetEnvNamespacedName returns the key of the ClowdEnvironment the app targets. ClowdEnvironments
+// are cluster scoped, so the key never carries a namespace, whichever namespace the app is in.
+func (i *ClowdApp) GetEnvNamespacedName() types.NamespacedName {
+	return types.NamespacedName{Name: i.Spec.EnvName}
+}
+
 func (i *ClowdApp) GetOurEnv(ctx context.Context, pClient client.Client, env *ClowdEnvironment) error {
-	return pClient.Get(ctx, types.NamespacedName{Name: i.Spec.EnvName}, env)
+	return pClient.Get(ctx, i.GetEnvNamespacedName(), env)
 }
 
 // GetAppsInEnv populates the appList with a list of all apps in the ClowdEnvironment.
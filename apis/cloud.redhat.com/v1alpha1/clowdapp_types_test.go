@@ -0,0 +1,104 @@
+package v1alpha1
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestMaintenanceWindow(t *testing.T) {
+	// Opens Saturdays at 22:00 for four hours, running into Sunday
+	window := &MaintenanceWindow{
+		Days:     []string{"Sat"},
+		Start:    "22:00",
+		Duration: metav1.Duration{Duration: 4 * time.Hour},
+	}
+	assert.NoError(t, window.Validate())
+
+	// 2022-10-08 was a Saturday
+	sat := time.Date(2022, 10, 8, 0, 0, 0, 0, time.UTC)
+
+	assert.False(t, window.IsOpen(sat.Add(21*time.Hour)))
+	assert.True(t, window.IsOpen(sat.Add(22*time.Hour)))
+	assert.True(t, window.IsOpen(sat.Add(25*time.Hour)))
+	assert.False(t, window.IsOpen(sat.Add(26*time.Hour)))
+	assert.False(t, window.IsOpen(sat.Add(46*time.Hour)))
+
+	assert.Equal(t, sat.Add(22*time.Hour), window.NextOpen(sat.Add(21*time.Hour)))
+	assert.Equal(t, sat.Add((7*24+22)*time.Hour), window.NextOpen(sat.Add(23*time.Hour)))
+}
+
+func TestMaintenanceWindowEveryDay(t *testing.T) {
+	window := &MaintenanceWindow{
+		Start:    "02:30",
+		Duration: metav1.Duration{Duration: time.Hour},
+	}
+	now := time.Date(2022, 10, 11, 12, 0, 0, 0, time.UTC)
+
+	assert.False(t, window.IsOpen(now))
+	assert.Equal(t, time.Date(2022, 10, 12, 2, 30, 0, 0, time.UTC), window.NextOpen(now))
+}
+
+func TestMaintenanceWindowValidate(t *testing.T) {
+	tests := []MaintenanceWindow{
+		{Days: []string{"Saturday"}, Start: "22:00", Duration: metav1.Duration{Duration: time.Hour}},
+		{Start: "25:00", Duration: metav1.Duration{Duration: time.Hour}},
+		{Start: "22:00"},
+		{Start: "22:00", Duration: metav1.Duration{Duration: 8 * 24 * time.Hour}},
+	}
+	for _, window := range tests {
+		assert.Error(t, window.Validate())
+		assert.False(t, window.IsOpen(time.Now()))
+	}
+}
+
+func TestGetEnvNamespacedName(t *testing.T) {
+	env := &ClowdEnvironment{
+		ObjectMeta: metav1.ObjectMeta{Name: "env"},
+		Spec:       ClowdEnvironmentSpec{TargetNamespace: "env-ns"},
+	}
+
+	// The environment is found by name alone, whether the app lives in its target namespace or not
+	for _, namespace := range []string{"env-ns", "other-ns"} {
+		app := &ClowdApp{
+			ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: namespace},
+			Spec:       ClowdAppSpec{EnvName: "env"},
+		}
+		assert.Equal(t, types.NamespacedName{Name: env.Name}, app.GetEnvNamespacedName(), namespace)
+		assert.Empty(t, app.ValidateWithEnvironment(env), namespace)
+	}
+}
+
+func TestGetJobSAName(t *testing.T) {
+	app := &ClowdApp{ObjectMeta: metav1.ObjectMeta{Name: "app"}}
+
+	job := &Job{Name: "migrate"}
+	assert.Equal(t, "app-app", app.GetJobSAName(job))
+
+	job.ServiceAccountName = "app-migrate"
+	assert.Equal(t, "app-migrate", app.GetJobSAName(job))
+}
+
+func TestGetDeploymentRolloutOrder(t *testing.T) {
+	app := &ClowdApp{Spec: ClowdAppSpec{Deployments: []Deployment{
+		{Name: "api", DependsOn: []string{"worker", "migrate"}},
+		{Name: "worker", DependsOn: []string{"migrate"}},
+		{Name: "migrate"},
+		{Name: "frontend"},
+	}}}
+
+	order, err := app.GetDeploymentRolloutOrder()
+	assert.NoError(t, err)
+	names := []string{}
+	for _, deployment := range order {
+		names = append(names, deployment.Name)
+	}
+	assert.Equal(t, []string{"migrate", "frontend", "worker", "api"}, names)
+
+	app.Spec.Deployments[2].DependsOn = []string{"cache"}
+	_, err = app.GetDeploymentRolloutOrder()
+	assert.EqualError(t, err, "deployment migrate depends on unknown deployment cache")
+}
@@ -0,0 +1,137 @@
+package v1alpha1
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// maxDBNameLength is the longest identifier PostgreSQL will accept for a
+// database name.
+const maxDBNameLength = 63
+
+// maxKafkaTopicNameLength is the longest topic name Kafka will accept.
+const maxKafkaTopicNameLength = 249
+
+var kafkaTopicNameRegexp = regexp.MustCompile(`^[a-zA-Z0-9._-]+$`)
+
+// KafkaTopicNameErrors returns the Kafka topic naming rules that the given name
+// breaks, it is empty when the name is valid.
+func KafkaTopicNameErrors(name string) []string {
+	if name == "" {
+		return []string{"must not be empty"}
+	}
+
+	var msgs []string
+	if name == "." || name == ".." {
+		msgs = append(msgs, "must not be '.' or '..'")
+	}
+	if len(name) > maxKafkaTopicNameLength {
+		msgs = append(msgs, fmt.Sprintf("must be no more than %d characters", maxKafkaTopicNameLength))
+	}
+	if !kafkaTopicNameRegexp.MatchString(name) {
+		msgs = append(msgs, "must only contain alphanumerics, '.', '_' and '-'")
+	}
+	return msgs
+}
+
+// KafkaTopicCollisionName returns the name Kafka compares topics by when
+// checking for collisions. Kafka's metric names do not tell '.' and '_' apart,
+// so two topics whose names only differ in those characters cannot both exist.
+func KafkaTopicCollisionName(name string) string {
+	return strings.ReplaceAll(name, ".", "_")
+}
+
+// Validate runs the same semantic checks as the ClowdApp admission webhook and
+// returns every problem found. It needs no cluster access, so it can be used to
+// lint manifests offline.
+func (r *ClowdApp) Validate() field.ErrorList {
+	return runValidations(r, appValidations...)
+}
+
+// ValidateWithEnvironment runs Validate and additionally checks the app
+// against the ClowdEnvironment it targets, e.g. for port collisions with the
+// environment's web and metrics ports.
+func (r *ClowdApp) ValidateWithEnvironment(env *ClowdEnvironment) field.ErrorList {
+	allErrs := r.Validate()
+
+	if env.Name != r.Spec.EnvName {
+		allErrs = append(allErrs, field.Invalid(
+			field.NewPath("spec.EnvName"), r.Spec.EnvName,
+			fmt.Sprintf("does not match the ClowdEnvironment %s", env.Name)),
+		)
+	}
+
+	metricsPort := env.Spec.Providers.Metrics.Port
+	metricsPath := field.NewPath("spec.providers.metrics.port")
+	if r.Spec.MetricsPort != 0 {
+		metricsPort = r.Spec.MetricsPort
+		metricsPath = field.NewPath("spec.MetricsPort")
+	}
+
+	type webPort struct {
+		name string
+		port int32
+	}
+	webPorts := []webPort{
+		{"port", env.Spec.Providers.Web.Port},
+		{"privatePort", env.Spec.Providers.Web.PrivatePort},
+	}
+	if env.Spec.Providers.Web.TLS.Enabled {
+		webPorts = append(webPorts,
+			webPort{"tls.port", env.Spec.Providers.Web.TLS.Port},
+			webPort{"tls.privatePort", env.Spec.Providers.Web.TLS.PrivatePort},
+		)
+	}
+	for _, web := range webPorts {
+		if web.port != 0 && web.port == metricsPort {
+			allErrs = append(allErrs, field.Duplicate(
+				metricsPath,
+				fmt.Sprintf("%d collides with spec.providers.web.%s", metricsPort, web.name)),
+			)
+		}
+	}
+
+	allErrs = append(allErrs, validateEphemeralStorageWithEnvironment(r, env)...)
+
+	return allErrs
+}
+
+// validateEphemeralStorageWithEnvironment checks the ephemeral-storage request of each of the
+// app's pods against its limit, once the environment's resourceDefaults have filled in whichever
+// of the two the pod leaves out.
+func validateEphemeralStorageWithEnvironment(r *ClowdApp, env *ClowdEnvironment) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	effective := func(pod, defaults core.ResourceList) (resource.Quantity, bool) {
+		if quantity, ok := pod[core.ResourceEphemeralStorage]; ok && !quantity.IsZero() {
+			return quantity, true
+		}
+		quantity, ok := defaults[core.ResourceEphemeralStorage]
+		return quantity, ok && !quantity.IsZero()
+	}
+
+	check := func(path *field.Path, resources core.ResourceRequirements) {
+		request, hasRequest := effective(resources.Requests, env.Spec.ResourceDefaults.Requests)
+		limit, hasLimit := effective(resources.Limits, env.Spec.ResourceDefaults.Limits)
+		if hasRequest && hasLimit && request.Cmp(limit) > 0 {
+			allErrs = append(allErrs, field.Invalid(
+				path.Child("requests", string(core.ResourceEphemeralStorage)), request.String(),
+				fmt.Sprintf("must be less than or equal to the effective ephemeral-storage limit of %s", limit.String())),
+			)
+		}
+	}
+
+	for depIndex, deployment := range r.Spec.Deployments {
+		check(field.NewPath(fmt.Sprintf("spec.Deployments[%d].PodSpec.Resources", depIndex)), deployment.PodSpec.Resources)
+	}
+	for jobIndex, job := range r.Spec.Jobs {
+		check(field.NewPath(fmt.Sprintf("spec.Jobs[%d].PodSpec.Resources", jobIndex)), job.PodSpec.Resources)
+	}
+
+	return allErrs
+}
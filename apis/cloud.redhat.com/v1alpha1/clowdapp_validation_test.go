@@ -0,0 +1,485 @@
+package v1alpha1
+
+import (
+	"testing"
+	"time"
+
+	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/featuregates"
+	"github.com/stretchr/testify/assert"
+	core "k8s.io/api/core/v1"
+	rbac "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+func TestValidateResources(t *testing.T) {
+	app := &ClowdApp{Spec: ClowdAppSpec{Deployments: []Deployment{{
+		Name: "processor",
+		PodSpec: PodSpec{Resources: core.ResourceRequirements{
+			Requests: core.ResourceList{core.ResourceCPU: resource.MustParse("2")},
+			Limits:   core.ResourceList{core.ResourceCPU: resource.MustParse("1")},
+		}},
+	}}}}
+
+	errs := app.Validate()
+	assert.Len(t, errs, 1)
+	assert.Equal(t, field.ErrorTypeInvalid, errs[0].Type)
+	assert.Equal(t, "spec.Deployments[0].PodSpec.Resources.requests.cpu", errs[0].Field)
+}
+
+func TestValidateEphemeralStorageWithEnvironment(t *testing.T) {
+	env := &ClowdEnvironment{ObjectMeta: metav1.ObjectMeta{Name: "env"}}
+	env.Spec.ResourceDefaults.Limits = core.ResourceList{core.ResourceEphemeralStorage: resource.MustParse("1Gi")}
+
+	app := &ClowdApp{Spec: ClowdAppSpec{EnvName: "env", Deployments: []Deployment{{
+		Name: "processor",
+		PodSpec: PodSpec{Resources: core.ResourceRequirements{
+			Requests: core.ResourceList{core.ResourceEphemeralStorage: resource.MustParse("512Mi")},
+		}},
+	}}}}
+	assert.Empty(t, app.ValidateWithEnvironment(env))
+
+	// A request above the environment's default limit is rejected
+	app.Spec.Deployments[0].PodSpec.Resources.Requests[core.ResourceEphemeralStorage] = resource.MustParse("2Gi")
+	errs := app.ValidateWithEnvironment(env)
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "spec.Deployments[0].PodSpec.Resources.requests.ephemeral-storage", errs[0].Field)
+
+	// Unless the pod sets a limit of its own
+	app.Spec.Deployments[0].PodSpec.Resources.Limits = core.ResourceList{core.ResourceEphemeralStorage: resource.MustParse("4Gi")}
+	assert.Empty(t, app.ValidateWithEnvironment(env))
+
+	// The environment's own defaults are checked too
+	env.Spec.ResourceDefaults.Requests = core.ResourceList{core.ResourceEphemeralStorage: resource.MustParse("2Gi")}
+	errs = env.Validate()
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "spec.resourceDefaults.requests.ephemeral-storage", errs[0].Field)
+}
+
+func TestValidateQuantities(t *testing.T) {
+	app := &ClowdApp{Spec: ClowdAppSpec{Deployments: []Deployment{{
+		Name: "processor",
+		AutoScalerSimple: &AutoScalerSimple{
+			RAM: SimpleAutoScalerMetric{ScaleAtValue: "2GB"},
+			CPU: SimpleAutoScalerMetric{ScaleAtValue: "500m"},
+		},
+	}}}}
+
+	errs := app.Validate()
+	assert.Len(t, errs, 1)
+	assert.Equal(t, field.ErrorTypeInvalid, errs[0].Type)
+	assert.Equal(t, "spec.Deployments[0].AutoScalerSimple.RAM.ScaleAtValue", errs[0].Field)
+	assert.Equal(t, "2GB", errs[0].BadValue)
+
+	env := &ClowdEnvironment{}
+	assert.Empty(t, env.Validate())
+
+	env.Spec.Providers.Kafka.Cluster.StorageSize = "10 Gi"
+	errs = env.Validate()
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "spec.providers.kafka.cluster.storageSize", errs[0].Field)
+}
+
+func TestValidateWithEnvironment(t *testing.T) {
+	env := &ClowdEnvironment{
+		ObjectMeta: metav1.ObjectMeta{Name: "env"},
+		Spec: ClowdEnvironmentSpec{Providers: ProvidersConfig{
+			Web:     WebConfig{Port: 8000, PrivatePort: 10000},
+			Metrics: MetricsConfig{Port: 9000},
+		}},
+	}
+
+	app := &ClowdApp{Spec: ClowdAppSpec{EnvName: "env"}}
+	assert.Empty(t, app.ValidateWithEnvironment(env))
+
+	app.Spec.MetricsPort = 10000
+	errs := app.ValidateWithEnvironment(env)
+	assert.Len(t, errs, 1)
+	assert.Equal(t, field.ErrorTypeDuplicate, errs[0].Type)
+
+	app.Spec.MetricsPort = 8800
+	env.Spec.Providers.Web.TLS = TLS{Enabled: true, Port: 8800, PrivatePort: 10800}
+	errs = app.ValidateWithEnvironment(env)
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "8800 collides with spec.providers.web.tls.port", errs[0].BadValue)
+
+	app.Spec.MetricsPort = 0
+	app.Spec.EnvName = "other"
+	errs = app.ValidateWithEnvironment(env)
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "spec.EnvName", errs[0].Field)
+}
+
+func TestValidateDatabaseServiceSelector(t *testing.T) {
+	app := &ClowdApp{Spec: ClowdAppSpec{Database: DatabaseSpec{
+		Name:            "inventory",
+		ServiceSelector: map[string]string{"name": "legacy postgres"},
+	}}}
+
+	errs := app.Validate()
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "spec.Database.ServiceSelector", errs[0].Field)
+
+	app.Spec.Database.ServiceSelector = map[string]string{"name": "legacy-postgres"}
+	assert.Empty(t, app.Validate())
+}
+
+func TestValidateDatabaseReadinessQuery(t *testing.T) {
+	app := &ClowdApp{Spec: ClowdAppSpec{Database: DatabaseSpec{
+		Name:           "inventory",
+		ReadinessQuery: "  ",
+	}}}
+
+	errs := app.Validate()
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "spec.Database.ReadinessQuery", errs[0].Field)
+
+	app.Spec.Database.ReadinessQuery = "SELECT 1 FROM hosts LIMIT 1"
+	assert.Empty(t, app.Validate())
+}
+
+func TestValidateDatabaseDrainOnUpgrade(t *testing.T) {
+	app := &ClowdApp{Spec: ClowdAppSpec{Database: DatabaseSpec{
+		Name:           "inventory",
+		DrainOnUpgrade: true,
+	}}}
+
+	errs := app.Validate()
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "spec.Database.DrainOnUpgrade", errs[0].Field)
+
+	app.Spec.Database.MaintenanceWindow = &MaintenanceWindow{Start: "02:00", Duration: metav1.Duration{Duration: time.Hour}}
+	assert.Empty(t, app.Validate())
+}
+
+func TestValidateDatabaseLivenessProbe(t *testing.T) {
+	successThreshold := int32(2)
+	app := &ClowdApp{Spec: ClowdAppSpec{Database: DatabaseSpec{
+		Name:           "inventory",
+		LivenessProbe:  &DatabaseProbeThresholds{SuccessThreshold: &successThreshold},
+		ReadinessProbe: &DatabaseProbeThresholds{SuccessThreshold: &successThreshold},
+	}}}
+
+	errs := app.Validate()
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "spec.Database.LivenessProbe.SuccessThreshold", errs[0].Field)
+
+	app.Spec.Database.LivenessProbe.SuccessThreshold = nil
+	assert.Empty(t, app.Validate())
+}
+
+func TestValidateSidecarVolumes(t *testing.T) {
+	app := &ClowdApp{Spec: ClowdAppSpec{Deployments: []Deployment{{
+		Name: "processor",
+		PodSpec: PodSpec{
+			Volumes:      []core.Volume{{Name: "config"}},
+			VolumeMounts: []core.VolumeMount{{Name: "config", MountPath: "/config"}},
+			SidecarVolumes: []SidecarVolume{
+				{Name: "config", MountPath: "/sockets"},
+				{Name: "Sockets", MountPath: "/config"},
+				{Name: "tmp"},
+			},
+		},
+	}}}}
+
+	errs := app.Validate()
+	assert.Len(t, errs, 4)
+	assert.Equal(t, field.ErrorTypeDuplicate, errs[0].Type)
+	assert.Equal(t, "spec.Deployment[0].SidecarVolumes[0].Name", errs[0].Field)
+	assert.Equal(t, field.ErrorTypeInvalid, errs[1].Type)
+	assert.Equal(t, "spec.Deployment[0].SidecarVolumes[1].Name", errs[1].Field)
+	assert.Equal(t, field.ErrorTypeDuplicate, errs[2].Type)
+	assert.Equal(t, "spec.Deployment[0].SidecarVolumes[1].MountPath", errs[2].Field)
+	assert.Equal(t, field.ErrorTypeRequired, errs[3].Type)
+	assert.Equal(t, "spec.Deployment[0].SidecarVolumes[2].MountPath", errs[3].Field)
+}
+
+func TestValidateEnvName(t *testing.T) {
+	app := &ClowdApp{Spec: ClowdAppSpec{EnvName: "env"}}
+	assert.Empty(t, app.Validate())
+
+	app.Spec.EnvName = "env-ns/env"
+	errs := app.Validate()
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "spec.EnvName", errs[0].Field)
+	assert.Contains(t, errs[0].Detail, "cluster scoped")
+
+	app.Spec.EnvName = "Env"
+	errs = app.Validate()
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "spec.EnvName", errs[0].Field)
+}
+
+func TestValidateObjectStoreScopes(t *testing.T) {
+	app := &ClowdApp{Spec: ClowdAppSpec{
+		ObjectStore: []string{"reports", "data-lake"},
+		ObjectStoreScopes: []ObjectStoreScope{
+			{Name: "writer", Buckets: []string{"reports"}},
+			{Name: "writer", Buckets: []string{"archive"}},
+			{Name: "lake"},
+		},
+	}}
+
+	errs := app.Validate()
+	assert.Len(t, errs, 3)
+	assert.Equal(t, field.ErrorTypeDuplicate, errs[0].Type)
+	assert.Equal(t, "spec.ObjectStoreScopes[1].Name", errs[0].Field)
+	assert.Equal(t, field.ErrorTypeNotFound, errs[1].Type)
+	assert.Equal(t, "spec.ObjectStoreScopes[1].Buckets[0]", errs[1].Field)
+	assert.Equal(t, field.ErrorTypeRequired, errs[2].Type)
+	assert.Equal(t, "spec.ObjectStoreScopes[2].Buckets", errs[2].Field)
+
+	app.Spec.ObjectStoreScopes = []ObjectStoreScope{
+		{Name: "writer", Buckets: []string{"reports"}},
+		{Name: "lake", Buckets: []string{"data-lake"}, ReadOnly: true},
+	}
+	assert.Empty(t, app.Validate())
+}
+
+func TestValidateKafkaTopicMinInSyncReplicas(t *testing.T) {
+	app := &ClowdApp{Spec: ClowdAppSpec{KafkaTopics: []KafkaTopicSpec{
+		{TopicName: "events", Replicas: 2, MinInSyncReplicas: 3},
+		{TopicName: "audit", MinInSyncReplicas: 4},
+	}}}
+
+	errs := app.Validate()
+	assert.Len(t, errs, 2)
+	assert.Equal(t, "spec.KafkaTopics[0].MinInSyncReplicas", errs[0].Field)
+	assert.Equal(t, "spec.KafkaTopics[1].MinInSyncReplicas", errs[1].Field)
+
+	app.Spec.KafkaTopics[0].MinInSyncReplicas = 2
+	app.Spec.KafkaTopics[1].MinInSyncReplicas = 3
+	assert.Empty(t, app.Validate())
+}
+
+func TestValidateKafkaTopicCompressionType(t *testing.T) {
+	app := &ClowdApp{Spec: ClowdAppSpec{KafkaTopics: []KafkaTopicSpec{
+		{TopicName: "events", Config: map[string]string{"compression.type": "brotli"}},
+		{TopicName: "audit", CompressionType: "zstd", Config: map[string]string{"compression.type": "lz4"}},
+	}}}
+
+	errs := app.Validate()
+	assert.Len(t, errs, 2)
+	assert.Equal(t, field.ErrorTypeNotSupported, errs[0].Type)
+	assert.Equal(t, "spec.KafkaTopics[0].Config", errs[0].Field)
+	assert.Equal(t, "spec.KafkaTopics[1].Config", errs[1].Field)
+
+	app.Spec.KafkaTopics[0].Config["compression.type"] = "gzip"
+	app.Spec.KafkaTopics[1].Config = nil
+	assert.Empty(t, app.Validate())
+}
+
+func TestValidateKafkaTopicNames(t *testing.T) {
+	app := &ClowdApp{Spec: ClowdAppSpec{KafkaTopics: []KafkaTopicSpec{
+		{TopicName: "platform.inventory.events"},
+		{TopicName: "platform.inventory.events"},
+	}}}
+	assert.Empty(t, app.Validate())
+
+	app.Spec.KafkaTopics = append(app.Spec.KafkaTopics,
+		KafkaTopicSpec{TopicName: "platform_inventory.events"},
+		KafkaTopicSpec{TopicName: ".."},
+	)
+	errs := app.Validate()
+	assert.Len(t, errs, 2)
+	assert.Equal(t, "spec.KafkaTopics[2].TopicName", errs[0].Field)
+	assert.Contains(t, errs[0].Detail, "collides with topic platform.inventory.events")
+	assert.Equal(t, "spec.KafkaTopics[3].TopicName", errs[1].Field)
+	assert.Equal(t, "must not be '.' or '..'", errs[1].Detail)
+}
+
+func TestValidateDaemonSets(t *testing.T) {
+	replicas := int32(2)
+	app := &ClowdApp{Spec: ClowdAppSpec{Deployments: []Deployment{
+		{Name: "agent", Kind: "DaemonSet"},
+		{Name: "api", Replicas: &replicas},
+	}}}
+	assert.Empty(t, app.Validate())
+
+	app.Spec.Deployments[0].Replicas = &replicas
+	app.Spec.Deployments[0].AutoScalerSimple = &AutoScalerSimple{}
+	errs := app.Validate()
+	assert.Len(t, errs, 2)
+	assert.Equal(t, field.ErrorTypeForbidden, errs[0].Type)
+	assert.Equal(t, "spec.Deployments[0].Replicas", errs[0].Field)
+	assert.Equal(t, "spec.Deployments[0].AutoScalerSimple", errs[1].Field)
+}
+
+func TestValidateRBACRules(t *testing.T) {
+	app := &ClowdApp{Spec: ClowdAppSpec{RBACRules: []rbac.PolicyRule{
+		{APIGroups: []string{""}, Resources: []string{"configmaps"}, Verbs: []string{"get", "watch"}},
+	}}}
+	assert.Empty(t, app.Validate())
+
+	app.Spec.RBACRules = append(app.Spec.RBACRules,
+		rbac.PolicyRule{Resources: []string{"roles"}, Verbs: []string{"escalate"}},
+		rbac.PolicyRule{NonResourceURLs: []string{"/metrics"}, Verbs: []string{"get"}},
+	)
+	errs := app.Validate()
+	assert.Len(t, errs, 3)
+	assert.Equal(t, "spec.RBACRules[1].Verbs", errs[0].Field)
+	assert.Equal(t, field.ErrorTypeForbidden, errs[0].Type)
+	assert.Equal(t, "spec.RBACRules[1].APIGroups", errs[1].Field)
+	assert.Equal(t, "spec.RBACRules[2].NonResourceURLs", errs[2].Field)
+
+	// Wildcard verbs, and writes to RBAC policy, would let the app widen its own access
+	app.Spec.RBACRules = []rbac.PolicyRule{
+		{APIGroups: []string{""}, Resources: []string{"configmaps"}, Verbs: []string{"*"}},
+		{APIGroups: []string{"rbac.authorization.k8s.io"}, Resources: []string{"roles", "rolebindings"}, Verbs: []string{"get", "patch"}},
+		{APIGroups: []string{"*"}, Resources: []string{"*"}, Verbs: []string{"create"}},
+		{APIGroups: []string{"*"}, Resources: []string{"*"}, Verbs: []string{"list"}},
+	}
+	errs = app.Validate()
+	assert.Len(t, errs, 4)
+	assert.Equal(t, "spec.RBACRules[0].Verbs", errs[0].Field)
+	assert.Equal(t, "verb * is not allowed", errs[0].Detail)
+	assert.Equal(t, "spec.RBACRules[1].Resources", errs[1].Field)
+	assert.Equal(t, "resource roles cannot be written", errs[1].Detail)
+	assert.Equal(t, "resource rolebindings cannot be written", errs[2].Detail)
+	assert.Equal(t, "spec.RBACRules[2].Resources", errs[3].Field)
+}
+
+func TestValidateInMemoryDBs(t *testing.T) {
+	app := &ClowdApp{Spec: ClowdAppSpec{InMemoryDBs: []InMemoryDBSpec{
+		{Name: "cache", DBIndex: 0},
+		{Name: "sessions", DBIndex: 1},
+	}}}
+	assert.Empty(t, app.Validate())
+
+	app.Spec.InMemoryDB = true
+	app.Spec.InMemoryDBs = append(app.Spec.InMemoryDBs,
+		InMemoryDBSpec{Name: "sessions", DBIndex: 16},
+	)
+	errs := app.Validate()
+	assert.Len(t, errs, 3)
+	assert.Equal(t, "spec.InMemoryDBs[0].DBIndex", errs[0].Field)
+	assert.Equal(t, "already used by inMemoryDb", errs[0].Detail)
+	assert.Equal(t, field.ErrorTypeDuplicate, errs[1].Type)
+	assert.Equal(t, "spec.InMemoryDBs[2].DBIndex", errs[2].Field)
+	assert.Equal(t, "must be between 0 and 15", errs[2].Detail)
+}
+
+func TestValidateDeploymentOrder(t *testing.T) {
+	app := &ClowdApp{Spec: ClowdAppSpec{Deployments: []Deployment{
+		{Name: "migrate"},
+		{Name: "api", DependsOn: []string{"migrate"}},
+	}}}
+	assert.Empty(t, app.Validate())
+
+	app.Spec.Deployments = append(app.Spec.Deployments,
+		Deployment{Name: "agent", Kind: "DaemonSet", DependsOn: []string{"agent", "worker"}},
+	)
+	errs := app.Validate()
+	assert.Len(t, errs, 3)
+	assert.Equal(t, field.ErrorTypeForbidden, errs[0].Type)
+	assert.Equal(t, "spec.Deployments[2].DependsOn", errs[0].Field)
+	assert.Equal(t, "a deployment cannot depend on itself", errs[1].Detail)
+	assert.Equal(t, field.ErrorTypeNotFound, errs[2].Type)
+
+	app.Spec.Deployments[0].DependsOn = []string{"api"}
+	app.Spec.Deployments = app.Spec.Deployments[:2]
+	errs = app.Validate()
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "spec.Deployments", errs[0].Field)
+	assert.Contains(t, errs[0].Detail, "deployments migrate, api cannot be ordered")
+}
+
+func TestValidateDeploymentServices(t *testing.T) {
+	app := &ClowdApp{ObjectMeta: metav1.ObjectMeta{Name: "puptoo"}, Spec: ClowdAppSpec{Deployments: []Deployment{
+		{Name: "api", Services: []DeploymentService{
+			{Name: "puptoo-api-public", Ports: []string{"public"}},
+			{Name: "puptoo-api-metrics", Ports: []string{"metrics"}, Selector: map[string]string{"tier": "api"}},
+		}},
+		{Name: "processor"},
+	}}}
+	assert.Empty(t, app.Validate())
+
+	app.Spec.Deployments[1].Services = []DeploymentService{
+		{Name: "puptoo-api", Ports: []string{"private", "private"}},
+		{Name: "Processor", Ports: []string{"grpc"}},
+	}
+	errs := app.Validate()
+	assert.Len(t, errs, 4)
+	assert.Equal(t, field.ErrorTypeDuplicate, errs[0].Type)
+	assert.Equal(t, "spec.Deployments[1].Services[0].Name", errs[0].Field)
+	assert.Equal(t, "spec.Deployments[1].Services[0].Ports[1]", errs[1].Field)
+	assert.Equal(t, "spec.Deployments[1].Services[1].Name", errs[2].Field)
+	assert.Equal(t, field.ErrorTypeNotSupported, errs[3].Type)
+}
+
+func TestValidateFeatureGates(t *testing.T) {
+	app := &ClowdApp{Spec: ClowdAppSpec{Deployments: []Deployment{
+		{Name: "migrate"},
+		{Name: "api", DependsOn: []string{"migrate"}, Services: []DeploymentService{{Name: "puptoo-api-public", Ports: []string{"public"}}}},
+	}}}
+
+	// Both gates are alpha, and so off by default
+	errs := validateFeatureGates(app)
+	assert.Len(t, errs, 2)
+	assert.Equal(t, "spec.Deployments[1].DependsOn", errs[0].Field)
+	assert.Equal(t, "spec.Deployments[1].Services", errs[1].Field)
+
+	assert.NoError(t, featuregates.Gates.Set("DeploymentRolloutOrder=true,NamedServices=true"))
+	defer func() {
+		assert.NoError(t, featuregates.Gates.Set("DeploymentRolloutOrder=false,NamedServices=false"))
+	}()
+	assert.Empty(t, validateFeatureGates(app))
+}
+
+func TestValidateVolumeDevices(t *testing.T) {
+	block := core.PersistentVolumeBlock
+	filesystem := core.PersistentVolumeFilesystem
+	app := &ClowdApp{Spec: ClowdAppSpec{Deployments: []Deployment{{
+		Name: "processor",
+		PodSpec: PodSpec{
+			Volumes: []core.Volume{
+				{Name: "raw", VolumeSource: core.VolumeSource{PersistentVolumeClaim: &core.PersistentVolumeClaimVolumeSource{ClaimName: "raw"}}},
+				{Name: "scratch", VolumeSource: core.VolumeSource{Ephemeral: &core.EphemeralVolumeSource{
+					VolumeClaimTemplate: &core.PersistentVolumeClaimTemplate{Spec: core.PersistentVolumeClaimSpec{
+						VolumeMode:  &block,
+						AccessModes: []core.PersistentVolumeAccessMode{core.ReadWriteOnce},
+					}},
+				}}},
+				{Name: "files", VolumeSource: core.VolumeSource{Ephemeral: &core.EphemeralVolumeSource{
+					VolumeClaimTemplate: &core.PersistentVolumeClaimTemplate{Spec: core.PersistentVolumeClaimSpec{VolumeMode: &filesystem}},
+				}}},
+				{Name: "config", VolumeSource: core.VolumeSource{ConfigMap: &core.ConfigMapVolumeSource{}}},
+			},
+			VolumeMounts: []core.VolumeMount{{Name: "config", MountPath: "/config"}},
+			VolumeDevices: []core.VolumeDevice{
+				{Name: "raw", DevicePath: "/dev/xvda"},
+				{Name: "scratch", DevicePath: "/dev/xvdb"},
+			},
+		},
+	}}}}
+	assert.Len(t, app.Validate(), 0)
+
+	app.Spec.Deployments[0].PodSpec.VolumeDevices = []core.VolumeDevice{
+		{Name: "missing", DevicePath: "/dev/xvda"},
+		{Name: "files", DevicePath: "/dev/xvdb"},
+		{Name: "config", DevicePath: "/config"},
+	}
+
+	errs := app.Validate()
+	assert.Len(t, errs, 5)
+	assert.Equal(t, field.ErrorTypeNotFound, errs[0].Type)
+	assert.Equal(t, "spec.Deployment[0].VolumeDevices[0].Name", errs[0].Field)
+	assert.Contains(t, errs[1].Detail, "volumeMode to Block")
+	assert.Contains(t, errs[2].Detail, "only persistentVolumeClaim and ephemeral volumes")
+	assert.Contains(t, errs[3].Detail, "both mounted and attached")
+	assert.Equal(t, field.ErrorTypeDuplicate, errs[4].Type)
+	assert.Equal(t, "spec.Deployment[0].VolumeDevices[2].DevicePath", errs[4].Field)
+
+	// A block-mode claim template must be writable and can't be mounted
+	scratch := app.Spec.Deployments[0].PodSpec.Volumes[1].Ephemeral.VolumeClaimTemplate
+	scratch.Spec.AccessModes = []core.PersistentVolumeAccessMode{core.ReadOnlyMany}
+	app.Spec.Deployments[0].PodSpec.VolumeDevices = nil
+	app.Spec.Deployments[0].PodSpec.VolumeMounts = []core.VolumeMount{{Name: "scratch", MountPath: "/scratch"}}
+
+	errs = app.Validate()
+	assert.Len(t, errs, 2)
+	assert.Equal(t, "spec.Deployment[0].Volumes[1]", errs[0].Field)
+	assert.Contains(t, errs[0].Detail, "ReadWrite access mode")
+	assert.Contains(t, errs[1].Detail, "not mounted")
+}
@@ -18,11 +18,18 @@ package v1alpha1
 
 import (
 	"fmt"
+	"strings"
+
+	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/featuregates"
 
 	apps "k8s.io/api/apps/v1"
+	core "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	ctrl "sigs.k8s.io/controller-runtime"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
@@ -47,24 +54,14 @@ var _ webhook.Validator = &ClowdApp{}
 func (r *ClowdApp) ValidateCreate() error {
 	clowdapplog.Info("validate create", "name", r.Name)
 
-	return r.processValidations(r,
-		validateDatabase,
-		validateSidecars,
-		validateInit,
-		validateDeploymentStrategy,
-	)
+	return r.processValidations(r, webhookAppValidations...)
 }
 
 // ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
 func (r *ClowdApp) ValidateUpdate(_ runtime.Object) error {
 	clowdapplog.Info("validate update", "name", r.Name)
 
-	return r.processValidations(r,
-		validateDatabase,
-		validateSidecars,
-		validateInit,
-		validateDeploymentStrategy,
-	)
+	return r.processValidations(r, webhookAppValidations...)
 }
 
 // ValidateDelete implements webhook.Validator so a webhook will be registered for the type
@@ -75,7 +72,52 @@ func (r *ClowdApp) ValidateDelete() error {
 
 type appValidationFunc func(*ClowdApp) field.ErrorList
 
-func (r *ClowdApp) processValidations(o *ClowdApp, vfns ...appValidationFunc) error {
+// appValidations are the semantic checks run by the admission webhook and by
+// ClowdApp.Validate.
+var appValidations = []appValidationFunc{
+	validateEnvName,
+	validateDatabase,
+	validateKafkaTopics,
+	validateObjectStoreScopes,
+	validateSidecars,
+	validateVolumeDevices,
+	validateInit,
+	validateDeploymentStrategy,
+	validateDaemonSets,
+	validateResources,
+	validateRBACRules,
+	validateInMemoryDBs,
+	validateDeploymentOrder,
+	validateDeploymentServices,
+}
+
+// webhookAppValidations are the checks run by the admission webhook, those of Validate along with
+// the ones that depend on the running operator.
+var webhookAppValidations = append(appValidations[:len(appValidations):len(appValidations)], validateFeatureGates)
+
+// validateFeatureGates rejects fields whose feature gate is disabled in the running operator, as
+// they would otherwise be silently ignored. It is left out of Validate, which has no operator to
+// ask.
+func validateFeatureGates(r *ClowdApp) field.ErrorList {
+	allErrs := field.ErrorList{}
+	for depIndex, deployment := range r.Spec.Deployments {
+		if len(deployment.DependsOn) > 0 && !featuregates.Gates.Enabled(featuregates.DeploymentRolloutOrder) {
+			allErrs = append(allErrs, field.Forbidden(
+				field.NewPath(fmt.Sprintf("spec.Deployments[%d].DependsOn", depIndex)),
+				"requires the DeploymentRolloutOrder feature gate"),
+			)
+		}
+		if len(deployment.Services) > 0 && !featuregates.Gates.Enabled(featuregates.NamedServices) {
+			allErrs = append(allErrs, field.Forbidden(
+				field.NewPath(fmt.Sprintf("spec.Deployments[%d].Services", depIndex)),
+				"requires the NamedServices feature gate"),
+			)
+		}
+	}
+	return allErrs
+}
+
+func runValidations(o *ClowdApp, vfns ...appValidationFunc) field.ErrorList {
 	var allErrs field.ErrorList
 
 	for _, validation := range vfns {
@@ -85,6 +127,12 @@ func (r *ClowdApp) processValidations(o *ClowdApp, vfns ...appValidationFunc) er
 		}
 	}
 
+	return allErrs
+}
+
+func (r *ClowdApp) processValidations(o *ClowdApp, vfns ...appValidationFunc) error {
+	allErrs := runValidations(o, vfns...)
+
 	if len(allErrs) == 0 {
 		return nil
 	}
@@ -110,6 +158,164 @@ func validateDatabase(r *ClowdApp) field.ErrorList {
 		)
 	}
 
+	if r.Spec.Database.ModeOverride != "" && r.Spec.Database.Name == "" {
+		allErrs = append(allErrs, field.Forbidden(
+			field.NewPath("spec.Database.ModeOverride", "spec.Database.Name"), "cannot override the db mode without a db name"),
+		)
+	}
+
+	if window := r.Spec.Database.MaintenanceWindow; window != nil {
+		if err := window.Validate(); err != nil {
+			allErrs = append(allErrs, field.Invalid(
+				field.NewPath("spec.Database.MaintenanceWindow"), window, err.Error()),
+			)
+		}
+	}
+
+	if r.Spec.Database.DrainOnUpgrade && r.Spec.Database.MaintenanceWindow == nil {
+		allErrs = append(allErrs, field.Forbidden(
+			field.NewPath("spec.Database.DrainOnUpgrade"), "cannot drain the app for database upgrades without a maintenance window"),
+		)
+	}
+
+	if q := r.Spec.Database.ReadinessQuery; q != "" && strings.TrimSpace(q) == "" {
+		allErrs = append(allErrs, field.Invalid(
+			field.NewPath("spec.Database.ReadinessQuery"), q, "readiness query cannot be blank"),
+		)
+	}
+
+	if probe := r.Spec.Database.LivenessProbe; probe != nil && probe.SuccessThreshold != nil && *probe.SuccessThreshold != 1 {
+		allErrs = append(allErrs, field.Invalid(
+			field.NewPath("spec.Database.LivenessProbe.SuccessThreshold"), *probe.SuccessThreshold, "must be 1 for a liveness probe"),
+		)
+	}
+
+	allErrs = append(allErrs, metav1validation.ValidateLabels(
+		r.Spec.Database.ServiceSelector, field.NewPath("spec.Database.ServiceSelector"))...,
+	)
+
+	if len(r.Spec.Database.Name) > maxDBNameLength {
+		allErrs = append(allErrs, field.TooLong(
+			field.NewPath("spec.Database.Name"), r.Spec.Database.Name, maxDBNameLength),
+		)
+	}
+
+	return allErrs
+}
+
+func validateEnvName(r *ClowdApp) field.ErrorList {
+	allErrs := field.ErrorList{}
+	path := field.NewPath("spec.EnvName")
+
+	if r.Spec.EnvName == "" {
+		return allErrs
+	}
+
+	if strings.Contains(r.Spec.EnvName, "/") {
+		return append(allErrs, field.Invalid(path, r.Spec.EnvName,
+			"ClowdEnvironments are cluster scoped and are referenced by name only"),
+		)
+	}
+
+	for _, msg := range validation.IsDNS1123Subdomain(r.Spec.EnvName) {
+		allErrs = append(allErrs, field.Invalid(path, r.Spec.EnvName, msg))
+	}
+
+	return allErrs
+}
+
+// KafkaCompressionTypes are the values Kafka accepts for the compression.type of a topic.
+var KafkaCompressionTypes = []string{"producer", "uncompressed", "gzip", "snappy", "lz4", "zstd"}
+
+func isKafkaCompressionType(compression string) bool {
+	for _, compressionType := range KafkaCompressionTypes {
+		if compression == compressionType {
+			return true
+		}
+	}
+	return false
+}
+
+func validateKafkaTopics(r *ClowdApp) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	path := field.NewPath("spec.KafkaTopics")
+
+	collisions := map[string]string{}
+	for idx, topic := range r.Spec.KafkaTopics {
+		for _, msg := range KafkaTopicNameErrors(topic.TopicName) {
+			allErrs = append(allErrs, field.Invalid(path.Index(idx).Child("TopicName"), topic.TopicName, msg))
+		}
+
+		collisionName := KafkaTopicCollisionName(topic.TopicName)
+		if other, ok := collisions[collisionName]; ok && other != topic.TopicName {
+			allErrs = append(allErrs, field.Invalid(
+				path.Index(idx).Child("TopicName"), topic.TopicName,
+				fmt.Sprintf("collides with topic %s as Kafka treats '.' and '_' alike", other),
+			))
+		} else if !ok {
+			collisions[collisionName] = topic.TopicName
+		}
+
+		// Providers default an unset replica count to 3
+		replicas := topic.Replicas
+		if replicas < 1 {
+			replicas = 3
+		}
+		if topic.MinInSyncReplicas > replicas {
+			allErrs = append(allErrs, field.Invalid(
+				path.Index(idx).Child("MinInSyncReplicas"),
+				topic.MinInSyncReplicas,
+				fmt.Sprintf("cannot exceed the topic's %d replicas", replicas),
+			))
+		}
+
+		if compression, ok := topic.Config["compression.type"]; ok {
+			if topic.CompressionType != "" {
+				allErrs = append(allErrs, field.Invalid(
+					path.Index(idx).Child("Config"), compression,
+					"compression.type cannot be set along with compressionType",
+				))
+			} else if !isKafkaCompressionType(compression) {
+				allErrs = append(allErrs, field.NotSupported(
+					path.Index(idx).Child("Config"), compression, KafkaCompressionTypes,
+				))
+			}
+		}
+	}
+
+	return allErrs
+}
+
+func validateObjectStoreScopes(r *ClowdApp) field.ErrorList {
+	allErrs := field.ErrorList{}
+	path := field.NewPath("spec.ObjectStoreScopes")
+
+	buckets := map[string]bool{}
+	for _, bucket := range r.Spec.ObjectStore {
+		buckets[bucket] = true
+	}
+
+	names := map[string]bool{}
+	for idx, scope := range r.Spec.ObjectStoreScopes {
+		for _, msg := range validation.IsDNS1123Label(scope.Name) {
+			allErrs = append(allErrs, field.Invalid(path.Index(idx).Child("Name"), scope.Name, msg))
+		}
+		if names[scope.Name] {
+			allErrs = append(allErrs, field.Duplicate(path.Index(idx).Child("Name"), scope.Name))
+		}
+		names[scope.Name] = true
+
+		if len(scope.Buckets) == 0 {
+			allErrs = append(allErrs, field.Required(path.Index(idx).Child("Buckets"), "scope must grant access to at least one bucket"))
+		}
+		for bucketIdx, bucket := range scope.Buckets {
+			if !buckets[bucket] {
+				allErrs = append(allErrs, field.NotFound(path.Index(idx).Child("Buckets").Index(bucketIdx), bucket))
+			}
+		}
+	}
+
 	return allErrs
 }
 
@@ -136,6 +342,9 @@ func validateInit(r *ClowdApp) field.ErrorList {
 func validateSidecars(r *ClowdApp) field.ErrorList {
 	allErrs := field.ErrorList{}
 	for depIndx, deployment := range r.Spec.Deployments {
+		allErrs = append(allErrs, validateSidecarVolumes(
+			field.NewPath(fmt.Sprintf("spec.Deployment[%d].SidecarVolumes", depIndx)), &deployment.PodSpec)...,
+		)
 		for carIndx, sidecar := range deployment.PodSpec.Sidecars {
 			if sidecar.Name != "token-refresher" {
 				allErrs = append(
@@ -152,6 +361,9 @@ func validateSidecars(r *ClowdApp) field.ErrorList {
 		if job.Schedule == "" {
 			continue
 		}
+		allErrs = append(allErrs, validateSidecarVolumes(
+			field.NewPath(fmt.Sprintf("spec.Jobs[%d].SidecarVolumes", jobIndx)), &job.PodSpec)...,
+		)
 		for carIndx, sidecar := range job.PodSpec.Sidecars {
 			if sidecar.Name != "token-refresher" {
 				allErrs = append(
@@ -167,6 +379,127 @@ func validateSidecars(r *ClowdApp) field.ErrorList {
 	return allErrs
 }
 
+func validateSidecarVolumes(path *field.Path, pod *PodSpec) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	names := map[string]bool{}
+	for _, vol := range pod.Volumes {
+		names[vol.Name] = true
+	}
+	mountPaths := map[string]bool{}
+	for _, mount := range pod.VolumeMounts {
+		mountPaths[mount.MountPath] = true
+	}
+
+	for idx, vol := range pod.SidecarVolumes {
+		for _, msg := range validation.IsDNS1123Label(vol.Name) {
+			allErrs = append(allErrs, field.Invalid(path.Index(idx).Child("Name"), vol.Name, msg))
+		}
+		if names[vol.Name] {
+			allErrs = append(allErrs, field.Duplicate(path.Index(idx).Child("Name"), vol.Name))
+		}
+		if vol.MountPath == "" {
+			allErrs = append(allErrs, field.Required(path.Index(idx).Child("MountPath"), "sidecar volumes must set a mountPath"))
+		} else if mountPaths[vol.MountPath] {
+			allErrs = append(allErrs, field.Duplicate(path.Index(idx).Child("MountPath"), vol.MountPath))
+		}
+		names[vol.Name] = true
+		mountPaths[vol.MountPath] = true
+	}
+
+	return allErrs
+}
+
+func validateVolumeDevices(r *ClowdApp) field.ErrorList {
+	allErrs := field.ErrorList{}
+	for depIndx, deployment := range r.Spec.Deployments {
+		allErrs = append(allErrs, validatePodVolumeDevices(
+			field.NewPath(fmt.Sprintf("spec.Deployment[%d]", depIndx)), &deployment.PodSpec)...,
+		)
+	}
+	for jobIndx, job := range r.Spec.Jobs {
+		allErrs = append(allErrs, validatePodVolumeDevices(
+			field.NewPath(fmt.Sprintf("spec.Jobs[%d]", jobIndx)), &job.PodSpec)...,
+		)
+	}
+	return allErrs
+}
+
+// validatePodVolumeDevices checks that every device refers to a claim of the
+// pod that can be attached as a raw block device, that no volume or path is
+// used both as a device and as a mount, and that block-mode claim templates
+// are neither mounted nor read-only. Devices are always attached read-write.
+func validatePodVolumeDevices(path *field.Path, pod *PodSpec) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	volumes := map[string]core.Volume{}
+	for _, vol := range pod.Volumes {
+		volumes[vol.Name] = vol
+	}
+	mountNames := map[string]bool{}
+	mountPaths := map[string]bool{}
+	for _, mount := range pod.VolumeMounts {
+		mountNames[mount.Name] = true
+		mountPaths[mount.MountPath] = true
+	}
+	deviceNames := map[string]bool{}
+	for _, device := range pod.VolumeDevices {
+		deviceNames[device.Name] = true
+	}
+
+	for idx, vol := range pod.Volumes {
+		if vol.Ephemeral == nil || !isBlockClaimTemplate(vol.Ephemeral.VolumeClaimTemplate) {
+			continue
+		}
+		if !hasWritableAccessMode(vol.Ephemeral.VolumeClaimTemplate.Spec.AccessModes) {
+			allErrs = append(allErrs, field.Invalid(path.Child("Volumes").Index(idx), vol.Name,
+				"a block-mode claim template must request a ReadWrite access mode"))
+		}
+		if mountNames[vol.Name] && !deviceNames[vol.Name] {
+			allErrs = append(allErrs, field.Invalid(path.Child("Volumes").Index(idx), vol.Name,
+				"a block-mode volume must be attached with volumeDevices, not mounted"))
+		}
+	}
+
+	path = path.Child("VolumeDevices")
+
+	for idx, device := range pod.VolumeDevices {
+		vol, ok := volumes[device.Name]
+		switch {
+		case !ok:
+			allErrs = append(allErrs, field.NotFound(path.Index(idx).Child("Name"), device.Name))
+		case vol.PersistentVolumeClaim == nil && vol.Ephemeral == nil:
+			allErrs = append(allErrs, field.Invalid(path.Index(idx).Child("Name"), device.Name,
+				"only persistentVolumeClaim and ephemeral volumes can be attached as devices"))
+		case vol.Ephemeral != nil && !isBlockClaimTemplate(vol.Ephemeral.VolumeClaimTemplate):
+			allErrs = append(allErrs, field.Invalid(path.Index(idx).Child("Name"), device.Name,
+				"the volume's claim template must set volumeMode to Block"))
+		}
+		if mountNames[device.Name] {
+			allErrs = append(allErrs, field.Invalid(path.Index(idx).Child("Name"), device.Name,
+				"a volume cannot be both mounted and attached as a device"))
+		}
+		if mountPaths[device.DevicePath] {
+			allErrs = append(allErrs, field.Duplicate(path.Index(idx).Child("DevicePath"), device.DevicePath))
+		}
+	}
+
+	return allErrs
+}
+
+func isBlockClaimTemplate(template *core.PersistentVolumeClaimTemplate) bool {
+	return template != nil && template.Spec.VolumeMode != nil && *template.Spec.VolumeMode == core.PersistentVolumeBlock
+}
+
+func hasWritableAccessMode(modes []core.PersistentVolumeAccessMode) bool {
+	for _, mode := range modes {
+		if mode != core.ReadOnlyMany {
+			return true
+		}
+	}
+	return false
+}
+
 func validateDeploymentStrategy(r *ClowdApp) field.ErrorList {
 	allErrs := field.ErrorList{}
 	for depIndex, deployment := range r.Spec.Deployments {
@@ -182,3 +515,247 @@ func validateDeploymentStrategy(r *ClowdApp) field.ErrorList {
 	}
 	return allErrs
 }
+
+func validateDaemonSets(r *ClowdApp) field.ErrorList {
+	allErrs := field.ErrorList{}
+	for depIndex, deployment := range r.Spec.Deployments {
+		if !deployment.IsDaemonSet() {
+			continue
+		}
+		path := field.NewPath(fmt.Sprintf("spec.Deployments[%d]", depIndex))
+		incompatible := []struct {
+			name string
+			set  bool
+		}{
+			{"MinReplicas", deployment.MinReplicas != nil},
+			{"Replicas", deployment.Replicas != nil},
+			{"AutoScaler", deployment.AutoScaler != nil},
+			{"AutoScalerSimple", deployment.AutoScalerSimple != nil},
+			{"DeploymentStrategy", deployment.DeploymentStrategy != nil},
+			{"ProgressDeadlineSeconds", deployment.ProgressDeadlineSeconds != nil},
+		}
+		for _, f := range incompatible {
+			if f.set {
+				allErrs = append(allErrs, field.Forbidden(path.Child(f.name), "cannot be set for a DaemonSet"))
+			}
+		}
+	}
+	return allErrs
+}
+
+func validateDeploymentOrder(r *ClowdApp) field.ErrorList {
+	allErrs := field.ErrorList{}
+	names := map[string]bool{}
+	for _, deployment := range r.Spec.Deployments {
+		names[deployment.Name] = true
+	}
+	for depIndex, deployment := range r.Spec.Deployments {
+		path := field.NewPath(fmt.Sprintf("spec.Deployments[%d].DependsOn", depIndex))
+		if len(deployment.DependsOn) > 0 && deployment.IsDaemonSet() {
+			allErrs = append(allErrs, field.Forbidden(path, "cannot be set for a DaemonSet"))
+		}
+		for _, dep := range deployment.DependsOn {
+			if dep == deployment.Name {
+				allErrs = append(allErrs, field.Invalid(path, dep, "a deployment cannot depend on itself"))
+			} else if !names[dep] {
+				allErrs = append(allErrs, field.NotFound(path, dep))
+			}
+		}
+	}
+	if len(allErrs) > 0 {
+		return allErrs
+	}
+
+	if _, err := r.GetDeploymentRolloutOrder(); err != nil {
+		allErrs = append(allErrs, field.Forbidden(field.NewPath("spec.Deployments"), err.Error()))
+	}
+	return allErrs
+}
+
+// deploymentServicePorts are the names of the ports a deployment's Service may have.
+var deploymentServicePorts = []string{"public", "private", "metrics", "auth", "tls", "tls-private"}
+
+func validateDeploymentServices(r *ClowdApp) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	names := map[string]bool{}
+	for i := range r.Spec.Deployments {
+		names[r.GetDeploymentNamespacedName(&r.Spec.Deployments[i]).Name] = true
+	}
+
+	for depIndex, deployment := range r.Spec.Deployments {
+		path := field.NewPath(fmt.Sprintf("spec.Deployments[%d].Services", depIndex))
+		for idx, svc := range deployment.Services {
+			for _, msg := range validation.IsDNS1035Label(svc.Name) {
+				allErrs = append(allErrs, field.Invalid(path.Index(idx).Child("Name"), svc.Name, msg))
+			}
+			if names[svc.Name] {
+				allErrs = append(allErrs, field.Duplicate(path.Index(idx).Child("Name"), svc.Name))
+			}
+			names[svc.Name] = true
+
+			if len(svc.Ports) == 0 {
+				allErrs = append(allErrs, field.Required(path.Index(idx).Child("Ports"), "service must expose at least one port"))
+			}
+			ports := map[string]bool{}
+			for portIdx, port := range svc.Ports {
+				known := false
+				for _, name := range deploymentServicePorts {
+					known = known || name == port
+				}
+				if !known {
+					allErrs = append(allErrs, field.NotSupported(path.Index(idx).Child("Ports").Index(portIdx), port, deploymentServicePorts))
+				} else if ports[port] {
+					allErrs = append(allErrs, field.Duplicate(path.Index(idx).Child("Ports").Index(portIdx), port))
+				}
+				ports[port] = true
+			}
+
+			allErrs = append(allErrs, metav1validation.ValidateLabels(svc.Selector, path.Index(idx).Child("Selector"))...)
+		}
+	}
+	return allErrs
+}
+
+func validateResources(r *ClowdApp) field.ErrorList {
+	allErrs := field.ErrorList{}
+	for depIndex, deployment := range r.Spec.Deployments {
+		path := field.NewPath(fmt.Sprintf("spec.Deployments[%d].PodSpec.Resources", depIndex))
+		allErrs = append(allErrs, validateRequestsWithinLimits(path, deployment.PodSpec.Resources)...)
+
+		if scaler := deployment.AutoScalerSimple; scaler != nil {
+			path := field.NewPath(fmt.Sprintf("spec.Deployments[%d].AutoScalerSimple", depIndex))
+			allErrs = append(allErrs, validateQuantity(path.Child("RAM", "ScaleAtValue"), scaler.RAM.ScaleAtValue)...)
+			allErrs = append(allErrs, validateQuantity(path.Child("CPU", "ScaleAtValue"), scaler.CPU.ScaleAtValue)...)
+		}
+	}
+	for jobIndex, job := range r.Spec.Jobs {
+		path := field.NewPath(fmt.Sprintf("spec.Jobs[%d].PodSpec.Resources", jobIndex))
+		allErrs = append(allErrs, validateRequestsWithinLimits(path, job.PodSpec.Resources)...)
+	}
+	if storage := r.Spec.Database.EphemeralStorage; storage != nil && storage.Request != nil && storage.Limit != nil {
+		if storage.Request.Cmp(*storage.Limit) > 0 {
+			allErrs = append(allErrs, field.Invalid(
+				field.NewPath("spec.Database.EphemeralStorage.Request"), storage.Request.String(),
+				fmt.Sprintf("must be less than or equal to the limit of %s", storage.Limit.String())),
+			)
+		}
+	}
+	return allErrs
+}
+
+func validateRequestsWithinLimits(path *field.Path, resources core.ResourceRequirements) field.ErrorList {
+	allErrs := field.ErrorList{}
+	for name, request := range resources.Requests {
+		limit, ok := resources.Limits[name]
+		if ok && request.Cmp(limit) > 0 {
+			allErrs = append(allErrs, field.Invalid(
+				path.Child("requests", string(name)), request.String(),
+				fmt.Sprintf("must be less than or equal to the %s limit of %s", name, limit.String())),
+			)
+		}
+	}
+	return allErrs
+}
+
+// maxInMemoryDBIndex is the highest logical database index a Redis server offers by default.
+const maxInMemoryDBIndex = 15
+
+// validateInMemoryDBs checks the index of each named store. Indices are only unique among the
+// app's own stores, as in (*_redis_*) mode every app runs its own server.
+func validateInMemoryDBs(r *ClowdApp) field.ErrorList {
+	allErrs := field.ErrorList{}
+	names := map[string]bool{}
+	indices := map[int32]string{}
+	if r.Spec.InMemoryDB {
+		indices[0] = "inMemoryDb"
+	}
+	for storeIndex, store := range r.Spec.InMemoryDBs {
+		path := field.NewPath(fmt.Sprintf("spec.InMemoryDBs[%d]", storeIndex))
+		if store.Name == "" {
+			allErrs = append(allErrs, field.Required(path.Child("Name"), "stores must be named"))
+		} else if names[store.Name] {
+			allErrs = append(allErrs, field.Duplicate(path.Child("Name"), store.Name))
+		}
+		names[store.Name] = true
+
+		if store.DBIndex < 0 || store.DBIndex > maxInMemoryDBIndex {
+			allErrs = append(allErrs, field.Invalid(path.Child("DBIndex"), store.DBIndex, fmt.Sprintf("must be between 0 and %d", maxInMemoryDBIndex)))
+			continue
+		}
+		if other, ok := indices[store.DBIndex]; ok {
+			allErrs = append(allErrs, field.Invalid(path.Child("DBIndex"), store.DBIndex, fmt.Sprintf("already used by %s", other)))
+			continue
+		}
+		indices[store.DBIndex] = store.Name
+	}
+	return allErrs
+}
+
+// escalatingVerbs are the verbs that would let an app grant itself, or act with, more access
+// than its Role holds. The wildcard verb includes all of them.
+var escalatingVerbs = map[string]bool{"*": true, "bind": true, "escalate": true, "impersonate": true}
+
+// writeVerbs are the verbs that modify resources.
+var writeVerbs = map[string]bool{"create": true, "update": true, "patch": true, "delete": true, "deletecollection": true}
+
+// rbacResources are the resources that hold RBAC policy, and so would let an app that can write
+// them grant itself more access. The wildcard resource includes all of them.
+var rbacResources = map[string]bool{"*": true, "roles": true, "rolebindings": true, "clusterroles": true, "clusterrolebindings": true}
+
+func validateRBACRules(r *ClowdApp) field.ErrorList {
+	allErrs := field.ErrorList{}
+	for ruleIndex, rule := range r.Spec.RBACRules {
+		path := field.NewPath(fmt.Sprintf("spec.RBACRules[%d]", ruleIndex))
+		if len(rule.Verbs) == 0 {
+			allErrs = append(allErrs, field.Required(path.Child("Verbs"), "verbs must contain at least one value"))
+		}
+		for _, verb := range rule.Verbs {
+			if escalatingVerbs[verb] {
+				allErrs = append(allErrs, field.Forbidden(path.Child("Verbs"), fmt.Sprintf("verb %s is not allowed", verb)))
+			}
+		}
+		if len(rule.NonResourceURLs) > 0 {
+			allErrs = append(allErrs, field.Forbidden(path.Child("NonResourceURLs"), "namespaced rules cannot apply to non-resource URLs"))
+			continue
+		}
+		if len(rule.APIGroups) == 0 {
+			allErrs = append(allErrs, field.Required(path.Child("APIGroups"), "resource rules must supply at least one api group"))
+		}
+		if len(rule.Resources) == 0 {
+			allErrs = append(allErrs, field.Required(path.Child("Resources"), "resource rules must supply at least one resource"))
+		}
+		if !hasWriteVerb(rule.Verbs) {
+			continue
+		}
+		for _, res := range rule.Resources {
+			if rbacResources[res] {
+				allErrs = append(allErrs, field.Forbidden(path.Child("Resources"), fmt.Sprintf("resource %s cannot be written", res)))
+			}
+		}
+	}
+	return allErrs
+}
+
+func hasWriteVerb(verbs []string) bool {
+	for _, verb := range verbs {
+		if writeVerbs[verb] {
+			return true
+		}
+	}
+	return false
+}
+
+// validateQuantity checks that a resource quantity held in a string field can
+// be parsed, as unlike the typed quantity fields the API server does not check
+// it and it would otherwise first be parsed during reconciliation. Empty values
+// are left to their defaults.
+func validateQuantity(path *field.Path, value string) field.ErrorList {
+	if value == "" {
+		return nil
+	}
+	if _, err := resource.ParseQuantity(value); err != nil {
+		return field.ErrorList{field.Invalid(path, value, "must be a resource quantity, e.g. 2Gi or 500m")}
+	}
+	return nil
+}
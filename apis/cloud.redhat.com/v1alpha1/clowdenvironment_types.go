@@ -124,6 +124,10 @@ type MetricsConfig struct {
 
 	// Prometheus specific configuration
 	Prometheus PrometheusConfig `json:"prometheus,omitempty"`
+
+	// Reports advisory resource requests and limits in the status of each
+	// ClowdApp, based on the usage Prometheus has observed.
+	ResourceRecommendations bool `json:"resourceRecommendations,omitempty"`
 }
 
 // KafkaMode details the mode of operation of the Clowder Kafka Provider
@@ -153,6 +157,11 @@ type KafkaClusterConfig struct {
 	// Only applies when KafkaConfig.PVC is set to 'true'
 	DeleteClaim bool `json:"deleteClaim,omitempty"`
 
+	// Default broker log retention in hours. If unset, default is '24'
+	// Ignored when a log.retention option is given in Config
+	// +kubebuilder:validation:Minimum:=1
+	RetentionHours int32 `json:"retentionHours,omitempty"`
+
 	// Version. If unset, default is '2.5.0'
 	Version string `json:"version,omitempty"`
 
@@ -166,6 +175,39 @@ type KafkaClusterConfig struct {
 	Resources strimzi.KafkaSpecKafkaResources `json:"resources,omitempty"`
 }
 
+// KafkaTopicTier defines the defaults of the topics in a tier. A topic's own
+// partitions and config take precedence over those of its tier.
+type KafkaTopicTier struct {
+	// The default number of partitions of topics in this tier.
+	// +kubebuilder:validation:Minimum:=1
+	// +kubebuilder:validation:Maximum:=200000
+	Partitions int32 `json:"partitions,omitempty"`
+
+	// The default retention.ms of topics in this tier.
+	// +kubebuilder:validation:Minimum:=1
+	RetentionMs int64 `json:"retentionMs,omitempty"`
+
+	// The default compression.type of topics in this tier.
+	// +kubebuilder:validation:Enum={"uncompressed", "zstd", "lz4", "snappy", "gzip", "producer"}
+	CompressionType string `json:"compressionType,omitempty"`
+}
+
+// KafkaTopicCreationConfig defines how the creation of a topic is retried when
+// the Kafka cluster is briefly unavailable.
+type KafkaTopicCreationConfig struct {
+	// How many times the creation of a topic is retried, across reconciles,
+	// before the reconcile fails. If unset, default is '3', '0' disables retries.
+	// +kubebuilder:validation:Minimum:=0
+	// +kubebuilder:validation:Maximum:=10
+	Retries *int32 `json:"retries,omitempty"`
+
+	// How long to wait before the first retry, in milliseconds, doubling with
+	// each retry after it. If unset, default is '500'.
+	// +kubebuilder:validation:Minimum:=1
+	// +kubebuilder:validation:Maximum:=60000
+	BackoffMs int32 `json:"backoffMs,omitempty"`
+}
+
 // KafkaConnectClusterConfig defines options related to the Kafka Connect cluster managed/monitored by Clowder
 type KafkaConnectClusterConfig struct {
 	// Defines the kafka connect cluster name (default: <kafka cluster's name>)
@@ -219,6 +261,21 @@ type KafkaConfig struct {
 	// Defines options related to the Kafka cluster for this environment. Ignored for (*_local_*) mode.
 	Cluster KafkaClusterConfig `json:"cluster,omitempty"`
 
+	// Rejects topics whose replicas or min.insync.replicas exceed the number of
+	// brokers in the cluster. Defaults to true; if set to false they are lowered
+	// to fit instead. Only used in (*_operator_*) and (*_managed-ephem_*) modes.
+	EnforceTopicReplicas *bool `json:"enforceTopicReplicas,omitempty"`
+
+	// Default settings of the topics in each tier, keyed by the tier name that
+	// topics request in their tier field. Topics without a tier are in the
+	// 'standard' tier, which has no defaults unless it is configured here. Only
+	// used in (*_operator_*) and (*_managed-ephem_*) modes.
+	TopicTiers map[string]KafkaTopicTier `json:"topicTiers,omitempty"`
+
+	// Defines how the creation of topics is retried when the Kafka cluster is
+	// briefly unavailable. Only used in (*_managed-ephem_*) mode.
+	TopicCreation KafkaTopicCreationConfig `json:"topicCreation,omitempty"`
+
 	// Defines options related to the Kafka Connect cluster for this environment. Ignored for (*_local_*) mode.
 	Connect KafkaConnectClusterConfig `json:"connect,omitempty"`
 
@@ -275,6 +332,91 @@ type DatabaseConfig struct {
 	// If using the (*_local_*) mode and PVC is set to true, this instructs the local
 	// Database instance to use a PVC instead of emptyDir for its volumes.
 	PVC bool `json:"pvc,omitempty"`
+
+	// A set of annotations to apply to the database service in (*_local_*) and
+	// (*_shared_*) modes, e.g. to request an internal load balancer from the cloud provider.
+	ServiceAnnotations map[string]string `json:"serviceAnnotations,omitempty"`
+
+	// Allows ClowdApps in this environment to override the database provider
+	// mode using modeOverride. An app using (*_app-interface_*) mode is handed the
+	// credentials from any matching secret in its namespace, so this should only
+	// be enabled where namespace access is already trusted.
+	AllowAppModeOverride bool `json:"allowAppModeOverride,omitempty"`
+
+	// If using the (*_local_*) mode with PVC set to true, this pins each
+	// database pod to the zone its volume was provisioned in, so that it is
+	// never scheduled where the volume cannot attach. This works best with a
+	// storage class using the WaitForFirstConsumer volume binding mode.
+	ZoneAwareScheduling bool `json:"zoneAwareScheduling,omitempty"`
+
+	// The names of the environment variables the credentials are handed to
+	// the database container under in (*_local_*) and (*_shared_*) modes.
+	// Defaults to those of the RHEL postgres image.
+	EnvVarNames DatabaseEnvVarNames `json:"envVarNames,omitempty"`
+
+	// The session affinity of the database service in (*_local_*) and
+	// (*_shared_*) modes, so that connection pooling clients keep talking to
+	// the same endpoint once the database has several. Defaults to None.
+	SessionAffinity *DatabaseSessionAffinity `json:"sessionAffinity,omitempty"`
+
+	// Sets max_connections of the shared databases in (*_shared_*) mode,
+	// which every app on a database shares. The effective value is presented
+	// to apps as maxConnections in their database configuration. Defaults to
+	// the image's default of 100.
+	// +kubebuilder:validation:Minimum=1
+	MaxConnections *int32 `json:"maxConnections,omitempty"`
+
+	// Overrides the images Clowder runs databases with in (*_local_*) and
+	// (*_shared_*) modes, keyed by PostgreSQL version, e.g. "15". Versions
+	// that are not listed use the image built into Clowder.
+	Images map[string]string `json:"images,omitempty"`
+
+	// Runs a postgres_exporter sidecar next to the databases in (*_local_*)
+	// and (*_shared_*) modes, and exposes its metrics on port 9187 of the
+	// database service as the metrics port.
+	MetricsExporter bool `json:"metricsExporter,omitempty"`
+}
+
+// DatabaseSessionAffinity configures how the database service routes the
+// connections of a client.
+type DatabaseSessionAffinity struct {
+	// Either None, to route each connection to any endpoint, or ClientIP, to
+	// route all connections from a client to the same endpoint.
+	// +kubebuilder:validation:Enum=None;ClientIP
+	Type core.ServiceAffinity `json:"type"`
+
+	// How long a ClientIP affinity sticks to an endpoint once the client
+	// goes idle, from 1 to 86400 seconds. Defaults to 10800.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=86400
+	TimeoutSeconds *int32 `json:"timeoutSeconds,omitempty"`
+}
+
+// DatabaseEnvVarNames maps the credentials of a local database onto the
+// environment variables its image reads them from. Names that are set
+// override those of the preset.
+type DatabaseEnvVarNames struct {
+	// The image the names default to, either (*_rhel_*) or (*_upstream_*)
+	// for the postgres image from Docker Hub. Defaults to (*_rhel_*).
+	// +kubebuilder:validation:Enum=rhel;upstream
+	Preset string `json:"preset,omitempty"`
+
+	// The variable holding the username of the database user.
+	User string `json:"user,omitempty"`
+
+	// The variable holding the password of the database user.
+	Password string `json:"password,omitempty"`
+
+	// The variable holding the name of the database to create.
+	Database string `json:"database,omitempty"`
+
+	// The variable holding the username of the admin user. Unset in the
+	// (*_upstream_*) preset, whose image has no admin user.
+	AdminUser string `json:"adminUser,omitempty"`
+
+	// The variable holding the password of the admin user. Unset in the
+	// (*_upstream_*) preset, whose image has no admin user.
+	AdminPassword string `json:"adminPassword,omitempty"`
 }
 
 // LoggingMode details the mode of operation of the Clowder Logging Provider
@@ -300,6 +442,28 @@ type ServiceMeshConfig struct {
 	Mode ServiceMeshMode `json:"mode,omitempty"`
 }
 
+// TracingMode details the mode of operation of the Clowder Tracing Provider
+// +kubebuilder:validation:Enum=local;managed;none
+type TracingMode string
+
+// TracingConfig configures the Clowder provider controlling OpenTelemetry
+// tracing.
+type TracingConfig struct {
+	// The mode of operation of the Clowder Tracing Provider. Valid options are:
+	// (*_local_*) where an OpenTelemetry collector sidecar is added to each of
+	// the app's pods, (*_managed_*) where apps are pointed at the shared
+	// collector given by endpoint, and (*_none_*) where no tracing will be
+	// configured.
+	Mode TracingMode `json:"mode,omitempty"`
+
+	// The OTLP gRPC endpoint of the shared collector used in (*_managed_*)
+	// mode, e.g. http://otel-collector.observability.svc:4317.
+	Endpoint string `json:"endpoint,omitempty"`
+
+	// Overrides the image of the collector sidecar used in (*_local_*) mode.
+	Image string `json:"image,omitempty"`
+}
+
 // ObjectStoreMode details the mode of operation of the Clowder ObjectStore
 // Provider
 // +kubebuilder:validation:Enum=minio;app-interface;none
@@ -320,6 +484,21 @@ type ObjectStoreConfig struct {
 	// If using the (*_local_*) mode and PVC is set to true, this instructs the local
 	// Database instance to use a PVC instead of emptyDir for its volumes.
 	PVC bool `json:"pvc,omitempty"`
+
+	// Tells clients to address buckets in the path of their requests rather
+	// than in the hostname, as MinIO and some S3 compatible stores require. If
+	// unset, default is 'true' in (*_minio_*) mode and 'false' in
+	// (*_app-interface_*) mode.
+	ForcePathStyle *bool `json:"forcePathStyle,omitempty"`
+
+	// Tells clients to connect to the object store over TLS. If unset, default
+	// is 'false' in (*_minio_*) mode and 'true' in (*_app-interface_*) mode.
+	TLSEnabled *bool `json:"tlsEnabled,omitempty"`
+
+	// Refers to the PEM encoded CA certificates clients should trust when
+	// connecting to the object store over TLS, for stores whose certificates
+	// are signed by a private CA. Nothing is passed by default.
+	CABundle *TrustedCABundleConfig `json:"caBundle,omitempty"`
 }
 
 // FeatureFlagsMode details the mode of operation of the Clowder FeatureFlags
@@ -436,6 +615,11 @@ type IqeUISeleniumConfig struct {
 type ServiceConfig struct {
 	// +kubebuilder:validation:Enum=ClusterIP;NodePort;""
 	Type string `json:"type"`
+
+	// The DNS suffix appended to service hostnames in the generated configuration,
+	// defaults to (*_svc_*). Set this to (*_svc.cluster.local_*) or similar when the
+	// fully qualified cluster domain is required for resolution.
+	DNSSuffix string `json:"dnsSuffix,omitempty"`
 }
 
 // ClowdEnvironmentSpec defines the desired state of ClowdEnvironment.
@@ -456,6 +640,133 @@ type ClowdEnvironmentSpec struct {
 
 	// Disabled turns off reconciliation for this ClowdEnv
 	Disabled bool `json:"disabled,omitempty"`
+
+	// Defines whether the generated configuration of each ClowdApp should also be
+	// exported to a ConfigMap for inspection.
+	ConfigExport ConfigExportConfig `json:"configExport,omitempty"`
+
+	// Defines the length and complexity of the usernames and passwords the
+	// providers generate, defaults to 16 alphanumeric characters.
+	CredentialPolicy *CredentialPolicy `json:"credentialPolicy,omitempty"`
+
+	// The number of consecutive failed reconciles after which a ClowdApp in
+	// this environment is marked Failed, and no longer retried until the app
+	// or the environment changes. Unset or 0 retries indefinitely.
+	// +kubebuilder:validation:Minimum=0
+	MaxReconcileRetries int32 `json:"maxReconcileRetries,omitempty"`
+
+	// Settings shared by every app in this environment, e.g. the endpoint of a
+	// common tracing collector. They are presented to each app under
+	// globalConfig in its cdappconfig.json, merged with the app's own
+	// globalConfig, which wins where both set a key.
+	GlobalConfig map[string]string `json:"globalConfig,omitempty"`
+}
+
+// CredentialClass is a class of characters a generated password must contain.
+// +kubebuilder:validation:Enum={"upper", "lower", "digit", "symbol"}
+type CredentialClass string
+
+const (
+	CredentialClassUpper  CredentialClass = "upper"
+	CredentialClassLower  CredentialClass = "lower"
+	CredentialClassDigit  CredentialClass = "digit"
+	CredentialClassSymbol CredentialClass = "symbol"
+)
+
+// DefaultCredentialLength is the length of generated credentials when no
+// policy sets one.
+const DefaultCredentialLength = 16
+
+// DefaultCredentialCharset is the set of characters generated credentials are
+// drawn from when no policy sets one.
+const DefaultCredentialCharset = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789"
+
+// CredentialPolicy defines the usernames and passwords generated for the
+// services Clowder runs, e.g. local databases.
+type CredentialPolicy struct {
+	// The length of generated usernames and passwords, defaults to 16.
+	// +kubebuilder:validation:Minimum=14
+	// +kubebuilder:validation:Maximum=63
+	Length int32 `json:"length,omitempty"`
+
+	// The characters generated passwords are drawn from, defaults to upper and
+	// lower case letters and digits. It may not contain $, which is expanded in
+	// the environment variables passwords are handed over in. Usernames are
+	// always alphanumeric.
+	Charset string `json:"charset,omitempty"`
+
+	// The classes of characters every generated password contains at least
+	// one of. Each class must be present in the charset.
+	RequiredClasses []CredentialClass `json:"requiredClasses,omitempty"`
+}
+
+// GetLength returns the length of generated credentials.
+func (p *CredentialPolicy) GetLength() int {
+	if p == nil || p.Length == 0 {
+		return DefaultCredentialLength
+	}
+	return int(p.Length)
+}
+
+// GetCharset returns the characters generated passwords are drawn from.
+func (p *CredentialPolicy) GetCharset() string {
+	if p == nil || p.Charset == "" {
+		return DefaultCredentialCharset
+	}
+	return p.Charset
+}
+
+// Matches returns whether the character belongs to the class.
+func (c CredentialClass) Matches(r rune) bool {
+	switch c {
+	case CredentialClassUpper:
+		return r >= 'A' && r <= 'Z'
+	case CredentialClassLower:
+		return r >= 'a' && r <= 'z'
+	case CredentialClassDigit:
+		return r >= '0' && r <= '9'
+	case CredentialClassSymbol:
+		return !CredentialClassUpper.Matches(r) && !CredentialClassLower.Matches(r) && !CredentialClassDigit.Matches(r)
+	}
+	return false
+}
+
+// Validate checks that passwords following the policy can be generated.
+func (p *CredentialPolicy) Validate() error {
+	if p == nil {
+		return nil
+	}
+	if length := p.GetLength(); length < 14 || length > 63 {
+		return fmt.Errorf("length %d must be between 14 and 63", length)
+	}
+	for _, r := range p.GetCharset() {
+		if r < '!' || r > '~' {
+			return fmt.Errorf("charset may only contain printable ASCII characters, found %q", r)
+		}
+		if r == '$' {
+			return fmt.Errorf("charset may not contain %q, which is expanded in environment variables", r)
+		}
+	}
+	for _, class := range p.RequiredClasses {
+		found := false
+		for _, r := range p.GetCharset() {
+			if class.Matches(r) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("required class %q has no characters in the charset", class)
+		}
+	}
+	return nil
+}
+
+// ConfigExportConfig provides options for exporting generated app configuration
+type ConfigExportConfig struct {
+	// Enables writing the non-secret portion of each ClowdApp's configuration to a
+	// ConfigMap named after the ClowdApp, credentials remain only in the Secret.
+	Enabled bool `json:"enabled,omitempty"`
 }
 
 type TokenRefresherConfig struct {
@@ -470,6 +781,53 @@ type Sidecars struct {
 
 type DeploymentConfig struct {
 	OmitPullPolicy bool `json:"omitPullPolicy,omitempty"`
+
+	// Sets the image pull policy of every container Clowder creates in this
+	// environment, taking precedence over omitPullPolicy.
+	// +kubebuilder:validation:Enum={"Always", "IfNotPresent", "Never"}
+	ImagePullPolicy core.PullPolicy `json:"imagePullPolicy,omitempty"`
+
+	// Replaces the registry of every image Clowder deploys in this
+	// environment, e.g. mirror.example.com. Images without a registry are
+	// prefixed with the mirror.
+	RegistryMirror string `json:"registryMirror,omitempty"`
+
+	// Sets the file mode of the cdappconfig.json file mounted into app
+	// containers, e.g. 256 (0400) for libraries that refuse to read
+	// credentials readable by others. Defaults to 420 (0644).
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=511
+	ConfigSecretMode *int32 `json:"configSecretMode,omitempty"`
+
+	// Sets the format the app config is mounted into app containers in,
+	// either (*_json_*) as cdappconfig.json or (*_yaml_*) as
+	// cdappconfig.yaml. ACG_CONFIG points at whichever file is mounted.
+	// Defaults to (*_json_*).
+	// +kubebuilder:validation:Enum=json;yaml
+	ConfigFormat string `json:"configFormat,omitempty"`
+
+	// Mounts a bundle of CA certificates into every app container, for apps
+	// calling services whose certificates are signed by a private CA. Nothing
+	// is mounted by default.
+	TrustedCABundle *TrustedCABundleConfig `json:"trustedCABundle,omitempty"`
+}
+
+// TrustedCABundleConfig refers to the PEM encoded CA certificates app containers
+// should trust for outbound TLS, held in a ConfigMap or a Secret.
+type TrustedCABundleConfig struct {
+	// The kind of object holding the bundle, either ConfigMap or Secret.
+	// Defaults to ConfigMap.
+	// +kubebuilder:validation:Enum={"ConfigMap", "Secret"}
+	Kind string `json:"kind,omitempty"`
+
+	// The name of the object holding the bundle.
+	Name string `json:"name"`
+
+	// The namespace of the object holding the bundle.
+	Namespace string `json:"namespace"`
+
+	// The key the bundle is stored under, defaults to ca-bundle.crt.
+	Key string `json:"key,omitempty"`
 }
 
 // ProvidersConfig defines a group of providers configuration for a ClowdEnvironment.
@@ -501,6 +859,9 @@ type ProvidersConfig struct {
 	// Defines the Configuration for the Clowder ServiceMesh Provider.
 	ServiceMesh ServiceMeshConfig `json:"serviceMesh,omitempty"`
 
+	// Defines the Configuration for the Clowder Tracing Provider.
+	Tracing TracingConfig `json:"tracing,omitempty"`
+
 	// Defines the pull secret to use for the service accounts.
 	PullSecrets []NamespacedName `json:"pullSecrets,omitempty"`
 
@@ -541,6 +902,9 @@ type ClowdEnvironmentStatus struct {
 	Generation      int64                 `json:"generation,omitempty"`
 	Hostname        string                `json:"hostname,omitempty"`
 	Prometheus      PrometheusStatus      `json:"prometheus,omitempty"`
+	// The Kafka topics requested by the apps in the environment, along with
+	// the apps requesting each of them.
+	Topics []TopicInfo `json:"topics,omitempty"`
 }
 
 type EnvResourceStatus struct {
@@ -561,6 +925,19 @@ type AppInfo struct {
 	Deployments []DeploymentInfo `json:"deployments"`
 }
 
+// TopicInfo lists the apps that requested a Kafka topic.
+type TopicInfo struct {
+	// The name of the topic as requested by the apps.
+	Name string `json:"name"`
+
+	// The apps requesting the topic as namespace/name, sorted.
+	Apps []string `json:"apps"`
+
+	// The compression.type the topic is created with, 'producer' unless the
+	// apps or the topic's tier set one.
+	CompressionType string `json:"compressionType,omitempty"`
+}
+
 // DeploymentInfo defailts information about a specific deployment.
 type DeploymentInfo struct {
 	Name     string `json:"name"`
@@ -663,6 +1040,43 @@ func (i *ClowdEnvironment) GenerateTargetNamespace() string {
 	return fmt.Sprintf("clowdenv-%s-%s", i.Name, utils.RandStringLower(6))
 }
 
+// ProviderName names a provider of a ClowdEnvironment, as in its providers
+// configuration.
+// +kubebuilder:validation:Enum={"db", "inMemoryDb", "kafka", "logging", "metrics", "objectStore", "web", "featureFlags", "serviceMesh", "tracing", "autoScaler"}
+type ProviderName string
+
+// ProviderMode returns the mode the environment runs the named provider in.
+func (i *ClowdEnvironment) ProviderMode(name ProviderName) string {
+	p := i.Spec.Providers
+	modes := map[ProviderName]string{
+		"db":           string(p.Database.Mode),
+		"inMemoryDb":   string(p.InMemoryDB.Mode),
+		"kafka":        string(p.Kafka.Mode),
+		"logging":      string(p.Logging.Mode),
+		"metrics":      string(p.Metrics.Mode),
+		"objectStore":  string(p.ObjectStore.Mode),
+		"web":          string(p.Web.Mode),
+		"featureFlags": string(p.FeatureFlags.Mode),
+		"serviceMesh":  string(p.ServiceMesh.Mode),
+		"tracing":      string(p.Tracing.Mode),
+		"autoScaler":   string(p.AutoScaler.Mode),
+	}
+	return modes[name]
+}
+
+// MissingProviders returns those of the named providers that the environment
+// does not offer, because they are unset or in a mode that disables them.
+func (i *ClowdEnvironment) MissingProviders(names []ProviderName) []ProviderName {
+	missing := []ProviderName{}
+	for _, name := range names {
+		switch i.ProviderMode(name) {
+		case "", "none", "null", "disabled":
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
+
 // IsReady returns true when all deployments are ready and the reconciliation is successful
 func (i *ClowdEnvironment) IsReady() bool {
 	conditionCheck := false
@@ -745,6 +1159,25 @@ func (i *ClowdEnvironment) IsNodePort() bool {
 	return i.Spec.ServiceConfig.Type == "NodePort"
 }
 
+// GetDNSSuffix returns the DNS suffix used for service hostnames in the environment
+func (i *ClowdEnvironment) GetDNSSuffix() string {
+	if i.Spec.ServiceConfig.DNSSuffix != "" {
+		return i.Spec.ServiceConfig.DNSSuffix
+	}
+	return "svc"
+}
+
+// ServiceHostname returns the in-cluster DNS name of a service, which always
+// includes the namespace so that it resolves from pods in any namespace.
+func ServiceHostname(name string, namespace string, dnsSuffix string) string {
+	return fmt.Sprintf("%s.%s.%s", name, namespace, dnsSuffix)
+}
+
+// GetServiceHostname returns the hostname of a service in the given namespace
+func (i *ClowdEnvironment) GetServiceHostname(name string, namespace string) string {
+	return ServiceHostname(name, namespace, i.GetDNSSuffix())
+}
+
 // GetClowdHostname gets the hostname for a particular environment
 func (i *ClowdEnvironment) GenerateHostname(ctx context.Context, pClient client.Client, log logr.Logger, random bool) string {
 	nn := types.NamespacedName{
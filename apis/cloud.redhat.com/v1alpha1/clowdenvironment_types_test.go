@@ -0,0 +1,37 @@
+package v1alpha1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMissingProviders(t *testing.T) {
+	env := &ClowdEnvironment{}
+	env.Spec.Providers.ObjectStore.Mode = "none"
+	env.Spec.Providers.Kafka.Mode = "operator"
+	env.Spec.Providers.Logging.Mode = "null"
+	env.Spec.Providers.ServiceMesh.Mode = "enabled"
+
+	assert.Equal(t, "operator", env.ProviderMode("kafka"))
+	assert.Equal(t, []ProviderName{}, env.MissingProviders([]ProviderName{"kafka", "serviceMesh"}))
+	assert.Equal(t,
+		[]ProviderName{"objectStore", "logging", "db"},
+		env.MissingProviders([]ProviderName{"objectStore", "kafka", "logging", "db"}),
+	)
+}
+
+func TestValidateEnvCredentialPolicy(t *testing.T) {
+	env := &ClowdEnvironment{}
+	assert.Empty(t, env.Validate())
+
+	env.Spec.CredentialPolicy = &CredentialPolicy{Charset: DefaultCredentialCharset + "!#%"}
+	assert.Empty(t, env.Validate())
+
+	// Passwords are handed over in environment variables, where $ is expanded
+	env.Spec.CredentialPolicy.Charset = DefaultCredentialCharset + "$"
+	errs := env.Validate()
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "spec.credentialPolicy", errs[0].Field)
+	assert.Contains(t, errs[0].Detail, "may not contain '$'")
+}
@@ -0,0 +1,97 @@
+package v1alpha1
+
+import (
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+// log is for logging in this package.
+var clowdenvironmentlog = logf.Log.WithName("clowdenvironment-resource")
+
+func (r *ClowdEnvironment) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/validate-cloud-redhat-com-v1alpha1-clowdenvironment,mutating=false,failurePolicy=fail,sideEffects=None,groups=cloud.redhat.com,resources=clowdenvironments,verbs=create;update,versions=v1alpha1,name=vclowdenvironment.kb.io,admissionReviewVersions={v1}
+
+var _ webhook.Validator = &ClowdEnvironment{}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (r *ClowdEnvironment) ValidateCreate() error {
+	clowdenvironmentlog.Info("validate create", "name", r.Name)
+
+	return r.processValidations()
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+func (r *ClowdEnvironment) ValidateUpdate(_ runtime.Object) error {
+	clowdenvironmentlog.Info("validate update", "name", r.Name)
+
+	return r.processValidations()
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (r *ClowdEnvironment) ValidateDelete() error {
+	clowdenvironmentlog.Info("validate delete", "name", r.Name)
+	return nil
+}
+
+type envValidationFunc func(*ClowdEnvironment) field.ErrorList
+
+// envValidations are the semantic checks run by the admission webhook and by
+// ClowdEnvironment.Validate.
+var envValidations = []envValidationFunc{
+	validateEnvQuantities,
+	validateEnvResourceDefaults,
+	validateEnvCredentialPolicy,
+}
+
+// Validate runs the same semantic checks as the ClowdEnvironment admission
+// webhook and returns every problem found, without needing cluster access.
+func (r *ClowdEnvironment) Validate() field.ErrorList {
+	var allErrs field.ErrorList
+
+	for _, validation := range envValidations {
+		allErrs = append(allErrs, validation(r)...)
+	}
+
+	return allErrs
+}
+
+func (r *ClowdEnvironment) processValidations() error {
+	allErrs := r.Validate()
+
+	if len(allErrs) == 0 {
+		return nil
+	}
+
+	return apierrors.NewInvalid(
+		schema.GroupKind{Group: "cloud.redhat.com", Kind: "ClowdEnvironment"},
+		r.Name, allErrs,
+	)
+}
+
+func validateEnvResourceDefaults(r *ClowdEnvironment) field.ErrorList {
+	return validateRequestsWithinLimits(field.NewPath("spec.resourceDefaults"), r.Spec.ResourceDefaults)
+}
+
+func validateEnvCredentialPolicy(r *ClowdEnvironment) field.ErrorList {
+	if err := r.Spec.CredentialPolicy.Validate(); err != nil {
+		return field.ErrorList{field.Invalid(field.NewPath("spec.credentialPolicy"), r.Spec.CredentialPolicy, err.Error())}
+	}
+	return field.ErrorList{}
+}
+
+func validateEnvQuantities(r *ClowdEnvironment) field.ErrorList {
+	return validateQuantity(
+		field.NewPath("spec.providers.kafka.cluster.storageSize"),
+		r.Spec.Providers.Kafka.Cluster.StorageSize,
+	)
+}
@@ -100,6 +100,9 @@ var _ = ginkgo.BeforeSuite(func() {
 	err = (&ClowdApp{}).SetupWebhookWithManager(mgr)
 	Expect(err).NotTo(HaveOccurred())
 
+	err = (&ClowdEnvironment{}).SetupWebhookWithManager(mgr)
+	Expect(err).NotTo(HaveOccurred())
+
 	//+kubebuilder:scaffold:webhook
 
 	go func() {
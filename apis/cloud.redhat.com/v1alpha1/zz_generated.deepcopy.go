@@ -24,6 +24,7 @@ package v1alpha1
 import (
 	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
 	"k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/cluster-api/api/v1beta1"
 )
@@ -231,12 +232,29 @@ func (in *ClowdAppSpec) DeepCopyInto(out *ClowdAppSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.RetainTopics != nil {
+		in, out := &in.RetainTopics, &out.RetainTopics
+		*out = new(bool)
+		**out = **in
+	}
 	in.Database.DeepCopyInto(&out.Database)
 	if in.ObjectStore != nil {
 		in, out := &in.ObjectStore, &out.ObjectStore
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.ObjectStoreScopes != nil {
+		in, out := &in.ObjectStoreScopes, &out.ObjectStoreScopes
+		*out = make([]ObjectStoreScope, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.InMemoryDBs != nil {
+		in, out := &in.InMemoryDBs, &out.InMemoryDBs
+		*out = make([]InMemoryDBSpec, len(*in))
+		copy(*out, *in)
+	}
 	if in.Dependencies != nil {
 		in, out := &in.Dependencies, &out.Dependencies
 		*out = make([]string, len(*in))
@@ -247,8 +265,32 @@ func (in *ClowdAppSpec) DeepCopyInto(out *ClowdAppSpec) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.RequiredProviders != nil {
+		in, out := &in.RequiredProviders, &out.RequiredProviders
+		*out = make([]ProviderName, len(*in))
+		copy(*out, *in)
+	}
+	if in.ConfigDependencies != nil {
+		in, out := &in.ConfigDependencies, &out.ConfigDependencies
+		*out = make([]ConfigDependency, len(*in))
+		copy(*out, *in)
+	}
+	if in.RBACRules != nil {
+		in, out := &in.RBACRules, &out.RBACRules
+		*out = make([]rbacv1.PolicyRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	out.Testing = in.Testing
 	out.Cyndi = in.Cyndi
+	if in.GlobalConfig != nil {
+		in, out := &in.GlobalConfig, &out.GlobalConfig
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClowdAppSpec.
@@ -272,6 +314,23 @@ func (in *ClowdAppStatus) DeepCopyInto(out *ClowdAppStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.ResourceRecommendation != nil {
+		in, out := &in.ResourceRecommendation, &out.ResourceRecommendation
+		*out = new(ResourceRecommendation)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DeploymentRollouts != nil {
+		in, out := &in.DeploymentRollouts, &out.DeploymentRollouts
+		*out = make([]DeploymentRolloutStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Inventory != nil {
+		in, out := &in.Inventory, &out.Inventory
+		*out = make([]ManagedObject, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClowdAppStatus.
@@ -349,6 +408,19 @@ func (in *ClowdEnvironmentSpec) DeepCopyInto(out *ClowdEnvironmentSpec) {
 	in.Providers.DeepCopyInto(&out.Providers)
 	in.ResourceDefaults.DeepCopyInto(&out.ResourceDefaults)
 	out.ServiceConfig = in.ServiceConfig
+	out.ConfigExport = in.ConfigExport
+	if in.CredentialPolicy != nil {
+		in, out := &in.CredentialPolicy, &out.CredentialPolicy
+		*out = new(CredentialPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.GlobalConfig != nil {
+		in, out := &in.GlobalConfig, &out.GlobalConfig
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClowdEnvironmentSpec.
@@ -380,6 +452,13 @@ func (in *ClowdEnvironmentStatus) DeepCopyInto(out *ClowdEnvironmentStatus) {
 		}
 	}
 	out.Prometheus = in.Prometheus
+	if in.Topics != nil {
+		in, out := &in.Topics, &out.Topics
+		*out = make([]TopicInfo, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClowdEnvironmentStatus.
@@ -506,6 +585,56 @@ func (in *ClowdJobInvocationStatus) DeepCopy() *ClowdJobInvocationStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConfigDependency) DeepCopyInto(out *ConfigDependency) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConfigDependency.
+func (in *ConfigDependency) DeepCopy() *ConfigDependency {
+	if in == nil {
+		return nil
+	}
+	out := new(ConfigDependency)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConfigExportConfig) DeepCopyInto(out *ConfigExportConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConfigExportConfig.
+func (in *ConfigExportConfig) DeepCopy() *ConfigExportConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ConfigExportConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CredentialPolicy) DeepCopyInto(out *CredentialPolicy) {
+	*out = *in
+	if in.RequiredClasses != nil {
+		in, out := &in.RequiredClasses, &out.RequiredClasses
+		*out = make([]CredentialClass, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CredentialPolicy.
+func (in *CredentialPolicy) DeepCopy() *CredentialPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(CredentialPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CyndiSpec) DeepCopyInto(out *CyndiSpec) {
 	*out = *in
@@ -524,6 +653,31 @@ func (in *CyndiSpec) DeepCopy() *CyndiSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DatabaseConfig) DeepCopyInto(out *DatabaseConfig) {
 	*out = *in
+	if in.ServiceAnnotations != nil {
+		in, out := &in.ServiceAnnotations, &out.ServiceAnnotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	out.EnvVarNames = in.EnvVarNames
+	if in.SessionAffinity != nil {
+		in, out := &in.SessionAffinity, &out.SessionAffinity
+		*out = new(DatabaseSessionAffinity)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MaxConnections != nil {
+		in, out := &in.MaxConnections, &out.MaxConnections
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Images != nil {
+		in, out := &in.Images, &out.Images
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatabaseConfig.
@@ -536,6 +690,81 @@ func (in *DatabaseConfig) DeepCopy() *DatabaseConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DatabaseEnvVarNames) DeepCopyInto(out *DatabaseEnvVarNames) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatabaseEnvVarNames.
+func (in *DatabaseEnvVarNames) DeepCopy() *DatabaseEnvVarNames {
+	if in == nil {
+		return nil
+	}
+	out := new(DatabaseEnvVarNames)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DatabaseMigrations) DeepCopyInto(out *DatabaseMigrations) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatabaseMigrations.
+func (in *DatabaseMigrations) DeepCopy() *DatabaseMigrations {
+	if in == nil {
+		return nil
+	}
+	out := new(DatabaseMigrations)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DatabaseProbeThresholds) DeepCopyInto(out *DatabaseProbeThresholds) {
+	*out = *in
+	if in.FailureThreshold != nil {
+		in, out := &in.FailureThreshold, &out.FailureThreshold
+		*out = new(int32)
+		**out = **in
+	}
+	if in.SuccessThreshold != nil {
+		in, out := &in.SuccessThreshold, &out.SuccessThreshold
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatabaseProbeThresholds.
+func (in *DatabaseProbeThresholds) DeepCopy() *DatabaseProbeThresholds {
+	if in == nil {
+		return nil
+	}
+	out := new(DatabaseProbeThresholds)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DatabaseSessionAffinity) DeepCopyInto(out *DatabaseSessionAffinity) {
+	*out = *in
+	if in.TimeoutSeconds != nil {
+		in, out := &in.TimeoutSeconds, &out.TimeoutSeconds
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatabaseSessionAffinity.
+func (in *DatabaseSessionAffinity) DeepCopy() *DatabaseSessionAffinity {
+	if in == nil {
+		return nil
+	}
+	out := new(DatabaseSessionAffinity)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DatabaseSpec) DeepCopyInto(out *DatabaseSpec) {
 	*out = *in
@@ -544,6 +773,48 @@ func (in *DatabaseSpec) DeepCopyInto(out *DatabaseSpec) {
 		*out = new(int32)
 		**out = **in
 	}
+	if in.EphemeralStorage != nil {
+		in, out := &in.EphemeralStorage, &out.EphemeralStorage
+		*out = new(EphemeralStorageRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MaintenanceWindow != nil {
+		in, out := &in.MaintenanceWindow, &out.MaintenanceWindow
+		*out = new(MaintenanceWindow)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ServiceSelector != nil {
+		in, out := &in.ServiceSelector, &out.ServiceSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.MaxConnections != nil {
+		in, out := &in.MaxConnections, &out.MaxConnections
+		*out = new(int32)
+		**out = **in
+	}
+	if in.LivenessProbe != nil {
+		in, out := &in.LivenessProbe, &out.LivenessProbe
+		*out = new(DatabaseProbeThresholds)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ReadinessProbe != nil {
+		in, out := &in.ReadinessProbe, &out.ReadinessProbe
+		*out = new(DatabaseProbeThresholds)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ImagePullSecrets != nil {
+		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
+		*out = make([]v1.LocalObjectReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.Migrations != nil {
+		in, out := &in.Migrations, &out.Migrations
+		*out = new(DatabaseMigrations)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatabaseSpec.
@@ -587,6 +858,33 @@ func (in *Deployment) DeepCopyInto(out *Deployment) {
 		**out = **in
 	}
 	in.Metadata.DeepCopyInto(&out.Metadata)
+	if in.ImagePullSecrets != nil {
+		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
+		*out = make([]v1.LocalObjectReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.ProgressDeadlineSeconds != nil {
+		in, out := &in.ProgressDeadlineSeconds, &out.ProgressDeadlineSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.DrainDelaySeconds != nil {
+		in, out := &in.DrainDelaySeconds, &out.DrainDelaySeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.DependsOn != nil {
+		in, out := &in.DependsOn, &out.DependsOn
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Services != nil {
+		in, out := &in.Services, &out.Services
+		*out = make([]DeploymentService, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Deployment.
@@ -602,6 +900,16 @@ func (in *Deployment) DeepCopy() *Deployment {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DeploymentConfig) DeepCopyInto(out *DeploymentConfig) {
 	*out = *in
+	if in.ConfigSecretMode != nil {
+		in, out := &in.ConfigSecretMode, &out.ConfigSecretMode
+		*out = new(int32)
+		**out = **in
+	}
+	if in.TrustedCABundle != nil {
+		in, out := &in.TrustedCABundle, &out.TrustedCABundle
+		*out = new(TrustedCABundleConfig)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeploymentConfig.
@@ -651,6 +959,89 @@ func (in *DeploymentMetadata) DeepCopy() *DeploymentMetadata {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeploymentResourceRecommendation) DeepCopyInto(out *DeploymentResourceRecommendation) {
+	*out = *in
+	if in.Observed != nil {
+		in, out := &in.Observed, &out.Observed
+		*out = make(v1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	if in.Requests != nil {
+		in, out := &in.Requests, &out.Requests
+		*out = make(v1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	if in.Limits != nil {
+		in, out := &in.Limits, &out.Limits
+		*out = make(v1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeploymentResourceRecommendation.
+func (in *DeploymentResourceRecommendation) DeepCopy() *DeploymentResourceRecommendation {
+	if in == nil {
+		return nil
+	}
+	out := new(DeploymentResourceRecommendation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeploymentRolloutStatus) DeepCopyInto(out *DeploymentRolloutStatus) {
+	*out = *in
+	if in.WaitingOn != nil {
+		in, out := &in.WaitingOn, &out.WaitingOn
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeploymentRolloutStatus.
+func (in *DeploymentRolloutStatus) DeepCopy() *DeploymentRolloutStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DeploymentRolloutStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeploymentService) DeepCopyInto(out *DeploymentService) {
+	*out = *in
+	if in.Ports != nil {
+		in, out := &in.Ports, &out.Ports
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeploymentService.
+func (in *DeploymentService) DeepCopy() *DeploymentService {
+	if in == nil {
+		return nil
+	}
+	out := new(DeploymentService)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DeploymentStrategy) DeepCopyInto(out *DeploymentStrategy) {
 	*out = *in
@@ -681,6 +1072,31 @@ func (in *EnvResourceStatus) DeepCopy() *EnvResourceStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EphemeralStorageRequirements) DeepCopyInto(out *EphemeralStorageRequirements) {
+	*out = *in
+	if in.Request != nil {
+		in, out := &in.Request, &out.Request
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.Limit != nil {
+		in, out := &in.Limit, &out.Limit
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EphemeralStorageRequirements.
+func (in *EphemeralStorageRequirements) DeepCopy() *EphemeralStorageRequirements {
+	if in == nil {
+		return nil
+	}
+	out := new(EphemeralStorageRequirements)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *FeatureFlagsConfig) DeepCopyInto(out *FeatureFlagsConfig) {
 	*out = *in
@@ -712,6 +1128,21 @@ func (in *InMemoryDBConfig) DeepCopy() *InMemoryDBConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InMemoryDBSpec) DeepCopyInto(out *InMemoryDBSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InMemoryDBSpec.
+func (in *InMemoryDBSpec) DeepCopy() *InMemoryDBSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(InMemoryDBSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *InitContainer) DeepCopyInto(out *InitContainer) {
 	*out = *in
@@ -967,6 +1398,19 @@ func (in *KafkaClusterConfig) DeepCopy() *KafkaClusterConfig {
 func (in *KafkaConfig) DeepCopyInto(out *KafkaConfig) {
 	*out = *in
 	in.Cluster.DeepCopyInto(&out.Cluster)
+	if in.EnforceTopicReplicas != nil {
+		in, out := &in.EnforceTopicReplicas, &out.EnforceTopicReplicas
+		*out = new(bool)
+		**out = **in
+	}
+	if in.TopicTiers != nil {
+		in, out := &in.TopicTiers, &out.TopicTiers
+		*out = make(map[string]KafkaTopicTier, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	in.TopicCreation.DeepCopyInto(&out.TopicCreation)
 	in.Connect.DeepCopyInto(&out.Connect)
 	out.ManagedSecretRef = in.ManagedSecretRef
 	out.EphemManagedSecretRef = in.EphemManagedSecretRef
@@ -998,6 +1442,26 @@ func (in *KafkaConnectClusterConfig) DeepCopy() *KafkaConnectClusterConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaTopicCreationConfig) DeepCopyInto(out *KafkaTopicCreationConfig) {
+	*out = *in
+	if in.Retries != nil {
+		in, out := &in.Retries, &out.Retries
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaTopicCreationConfig.
+func (in *KafkaTopicCreationConfig) DeepCopy() *KafkaTopicCreationConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaTopicCreationConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *KafkaTopicSpec) DeepCopyInto(out *KafkaTopicSpec) {
 	*out = *in
@@ -1020,6 +1484,21 @@ func (in *KafkaTopicSpec) DeepCopy() *KafkaTopicSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaTopicTier) DeepCopyInto(out *KafkaTopicTier) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaTopicTier.
+func (in *KafkaTopicTier) DeepCopy() *KafkaTopicTier {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaTopicTier)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *LoggingConfig) DeepCopyInto(out *LoggingConfig) {
 	*out = *in
@@ -1035,6 +1514,42 @@ func (in *LoggingConfig) DeepCopy() *LoggingConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MaintenanceWindow) DeepCopyInto(out *MaintenanceWindow) {
+	*out = *in
+	if in.Days != nil {
+		in, out := &in.Days, &out.Days
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	out.Duration = in.Duration
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MaintenanceWindow.
+func (in *MaintenanceWindow) DeepCopy() *MaintenanceWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(MaintenanceWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ManagedObject) DeepCopyInto(out *ManagedObject) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManagedObject.
+func (in *ManagedObject) DeepCopy() *ManagedObject {
+	if in == nil {
+		return nil
+	}
+	out := new(ManagedObject)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MetricsConfig) DeepCopyInto(out *MetricsConfig) {
 	*out = *in
@@ -1100,6 +1615,21 @@ func (in *NamespacedName) DeepCopy() *NamespacedName {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ObjectStoreConfig) DeepCopyInto(out *ObjectStoreConfig) {
 	*out = *in
+	if in.ForcePathStyle != nil {
+		in, out := &in.ForcePathStyle, &out.ForcePathStyle
+		*out = new(bool)
+		**out = **in
+	}
+	if in.TLSEnabled != nil {
+		in, out := &in.TLSEnabled, &out.TLSEnabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.CABundle != nil {
+		in, out := &in.CABundle, &out.CABundle
+		*out = new(TrustedCABundleConfig)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObjectStoreConfig.
@@ -1112,6 +1642,26 @@ func (in *ObjectStoreConfig) DeepCopy() *ObjectStoreConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ObjectStoreScope) DeepCopyInto(out *ObjectStoreScope) {
+	*out = *in
+	if in.Buckets != nil {
+		in, out := &in.Buckets, &out.Buckets
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObjectStoreScope.
+func (in *ObjectStoreScope) DeepCopy() *ObjectStoreScope {
+	if in == nil {
+		return nil
+	}
+	out := new(ObjectStoreScope)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PodSpec) DeepCopyInto(out *PodSpec) {
 	*out = *in
@@ -1165,11 +1715,21 @@ func (in *PodSpec) DeepCopyInto(out *PodSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.VolumeDevices != nil {
+		in, out := &in.VolumeDevices, &out.VolumeDevices
+		*out = make([]v1.VolumeDevice, len(*in))
+		copy(*out, *in)
+	}
 	if in.Sidecars != nil {
 		in, out := &in.Sidecars, &out.Sidecars
 		*out = make([]Sidecar, len(*in))
 		copy(*out, *in)
 	}
+	if in.SidecarVolumes != nil {
+		in, out := &in.SidecarVolumes, &out.SidecarVolumes
+		*out = make([]SidecarVolume, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodSpec.
@@ -1252,15 +1812,16 @@ func (in *PrometheusStatus) DeepCopy() *PrometheusStatus {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ProvidersConfig) DeepCopyInto(out *ProvidersConfig) {
 	*out = *in
-	out.Database = in.Database
+	in.Database.DeepCopyInto(&out.Database)
 	out.InMemoryDB = in.InMemoryDB
 	in.Kafka.DeepCopyInto(&out.Kafka)
 	out.Logging = in.Logging
 	out.Metrics = in.Metrics
-	out.ObjectStore = in.ObjectStore
+	in.ObjectStore.DeepCopyInto(&out.ObjectStore)
 	out.Web = in.Web
 	out.FeatureFlags = in.FeatureFlags
 	out.ServiceMesh = in.ServiceMesh
+	out.Tracing = in.Tracing
 	if in.PullSecrets != nil {
 		in, out := &in.PullSecrets, &out.PullSecrets
 		*out = make([]NamespacedName, len(*in))
@@ -1269,7 +1830,7 @@ func (in *ProvidersConfig) DeepCopyInto(out *ProvidersConfig) {
 	in.Testing.DeepCopyInto(&out.Testing)
 	out.Sidecars = in.Sidecars
 	out.AutoScaler = in.AutoScaler
-	out.Deployment = in.Deployment
+	in.Deployment.DeepCopyInto(&out.Deployment)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProvidersConfig.
@@ -1302,6 +1863,29 @@ func (in *PublicWebService) DeepCopy() *PublicWebService {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceRecommendation) DeepCopyInto(out *ResourceRecommendation) {
+	*out = *in
+	in.LastUpdated.DeepCopyInto(&out.LastUpdated)
+	if in.Deployments != nil {
+		in, out := &in.Deployments, &out.Deployments
+		*out = make([]DeploymentResourceRecommendation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceRecommendation.
+func (in *ResourceRecommendation) DeepCopy() *ResourceRecommendation {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceRecommendation)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ServiceConfig) DeepCopyInto(out *ServiceConfig) {
 	*out = *in
@@ -1347,6 +1931,21 @@ func (in *Sidecar) DeepCopy() *Sidecar {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SidecarVolume) DeepCopyInto(out *SidecarVolume) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SidecarVolume.
+func (in *SidecarVolume) DeepCopy() *SidecarVolume {
+	if in == nil {
+		return nil
+	}
+	out := new(SidecarVolume)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Sidecars) DeepCopyInto(out *Sidecars) {
 	*out = *in
@@ -1454,6 +2053,56 @@ func (in *TokenRefresherConfig) DeepCopy() *TokenRefresherConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TopicInfo) DeepCopyInto(out *TopicInfo) {
+	*out = *in
+	if in.Apps != nil {
+		in, out := &in.Apps, &out.Apps
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TopicInfo.
+func (in *TopicInfo) DeepCopy() *TopicInfo {
+	if in == nil {
+		return nil
+	}
+	out := new(TopicInfo)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TracingConfig) DeepCopyInto(out *TracingConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TracingConfig.
+func (in *TracingConfig) DeepCopy() *TracingConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(TracingConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TrustedCABundleConfig) DeepCopyInto(out *TrustedCABundleConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TrustedCABundleConfig.
+func (in *TrustedCABundleConfig) DeepCopy() *TrustedCABundleConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(TrustedCABundleConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *WebConfig) DeepCopyInto(out *WebConfig) {
 	*out = *in
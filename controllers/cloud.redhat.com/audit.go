@@ -0,0 +1,153 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/clowderconfig"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// auditEntry records a single write Clowder made to the cluster. Entries are
+// written one JSON object per line, so that they can be shipped to a SIEM.
+type auditEntry struct {
+	Timestamp  time.Time `json:"timestamp"`
+	Controller string    `json:"controller"`
+	Trigger    string    `json:"trigger"`
+	Operation  string    `json:"operation"`
+	Kind       string    `json:"kind"`
+	Namespace  string    `json:"namespace,omitempty"`
+	Name       string    `json:"name"`
+	Result     string    `json:"result"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// auditSink writes audit entries as JSON lines to an underlying writer.
+type auditSink struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+	now func() time.Time
+}
+
+func newAuditSink(w io.Writer) *auditSink {
+	return &auditSink{enc: json.NewEncoder(w), now: time.Now}
+}
+
+// auditLog is where the writes of all controllers are recorded, or nil if
+// audit logging is disabled.
+var auditLog *auditSink
+
+// openAuditLog returns the audit sink set up in the Clowder configuration,
+// which is "stdout", "file" to append to settings.auditLog.path, or empty to
+// disable audit logging.
+func openAuditLog() (*auditSink, error) {
+	settings := clowderconfig.LoadedConfig.Settings.AuditLog
+
+	switch settings.Sink {
+	case "":
+		return nil, nil
+	case "stdout":
+		return newAuditSink(os.Stdout), nil
+	case "file":
+		f, err := os.OpenFile(settings.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+		if err != nil {
+			return nil, err
+		}
+		return newAuditSink(f), nil
+	default:
+		return nil, fmt.Errorf("unknown audit log sink %q", settings.Sink)
+	}
+}
+
+func (s *auditSink) record(entry auditEntry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry.Timestamp = s.now().UTC()
+	if err := s.enc.Encode(entry); err != nil {
+		setupLog.Error(err, "could not write audit log entry")
+	}
+}
+
+// auditClient records every create, update, patch and delete made through it,
+// along with the controller and object whose reconcile made it.
+type auditClient struct {
+	client.Client
+	sink       *auditSink
+	controller string
+	trigger    string
+}
+
+// newAuditClient wraps the client so that its writes are audited as part of
+// the reconcile of the trigger object. If audit logging is disabled the client
+// is returned as is.
+func newAuditClient(c client.Client, sink *auditSink, controller string, trigger client.Object) client.Client {
+	if sink == nil {
+		return c
+	}
+
+	triggerName := trigger.GetName()
+	if ns := trigger.GetNamespace(); ns != "" {
+		triggerName = fmt.Sprintf("%s/%s", ns, triggerName)
+	}
+
+	return &auditClient{
+		Client:     c,
+		sink:       sink,
+		controller: controller,
+		trigger:    fmt.Sprintf("%s/%s", controller, triggerName),
+	}
+}
+
+func (a *auditClient) record(operation string, obj client.Object, err error) {
+	kind := obj.GetObjectKind().GroupVersionKind().Kind
+	if gvk, gvkErr := apiutil.GVKForObject(obj, a.Scheme()); kind == "" && gvkErr == nil {
+		kind = gvk.Kind
+	}
+
+	entry := auditEntry{
+		Controller: a.controller,
+		Trigger:    a.trigger,
+		Operation:  operation,
+		Kind:       kind,
+		Namespace:  obj.GetNamespace(),
+		Name:       obj.GetName(),
+		Result:     "success",
+	}
+	if err != nil {
+		entry.Result = "failure"
+		entry.Error = err.Error()
+	}
+
+	a.sink.record(entry)
+}
+
+func (a *auditClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	err := a.Client.Create(ctx, obj, opts...)
+	a.record("create", obj, err)
+	return err
+}
+
+func (a *auditClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	err := a.Client.Update(ctx, obj, opts...)
+	a.record("update", obj, err)
+	return err
+}
+
+func (a *auditClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	err := a.Client.Patch(ctx, obj, patch, opts...)
+	a.record("patch", obj, err)
+	return err
+}
+
+func (a *auditClient) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	err := a.Client.Delete(ctx, obj, opts...)
+	a.record("delete", obj, err)
+	return err
+}
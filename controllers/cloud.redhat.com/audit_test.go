@@ -0,0 +1,92 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	crd "github.com/RedHatInsights/clowder/apis/cloud.redhat.com/v1alpha1"
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	core "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestAuditClient(t *testing.T) {
+	ctx := context.Background()
+	buf := &bytes.Buffer{}
+	sink := newAuditSink(buf)
+	sink.now = func() time.Time { return time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC) }
+
+	app := &crd.ClowdApp{ObjectMeta: metav1.ObjectMeta{Name: "puptoo", Namespace: "test"}}
+	c := newAuditClient(fake.NewClientBuilder().WithScheme(Scheme).Build(), sink, "ClowdApp", app)
+
+	cm := &core.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "puptoo-config", Namespace: "test"}}
+	assert.NoError(t, c.Create(ctx, cm))
+	assert.Error(t, c.Create(ctx, &core.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "puptoo-config", Namespace: "test"}}))
+	assert.NoError(t, c.Delete(ctx, cm))
+
+	var entries []auditEntry
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		entry := auditEntry{}
+		assert.NoError(t, json.Unmarshal([]byte(line), &entry))
+		entries = append(entries, entry)
+	}
+
+	assert.Len(t, entries, 3)
+	assert.Equal(t, auditEntry{
+		Timestamp:  sink.now(),
+		Controller: "ClowdApp",
+		Trigger:    "ClowdApp/test/puptoo",
+		Operation:  "create",
+		Kind:       "ConfigMap",
+		Namespace:  "test",
+		Name:       "puptoo-config",
+		Result:     "success",
+	}, entries[0])
+	assert.Equal(t, "failure", entries[1].Result)
+	assert.Contains(t, entries[1].Error, "already exists")
+	assert.Equal(t, "delete", entries[2].Operation)
+	assert.Equal(t, "success", entries[2].Result)
+}
+
+func TestAuditClientDisabled(t *testing.T) {
+	c := fake.NewClientBuilder().Build()
+	assert.Equal(t, c, newAuditClient(c, nil, "ClowdApp", &crd.ClowdApp{}))
+}
+
+func TestReconciliationWritesAudited(t *testing.T) {
+	defer func(sink *auditSink) { auditLog = sink }(auditLog)
+	buf := &bytes.Buffer{}
+	auditLog = newAuditSink(buf)
+
+	ctx := context.Background()
+	log := logr.Discard()
+	app := &crd.ClowdApp{ObjectMeta: metav1.ObjectMeta{Name: "puptoo", Namespace: "test"}}
+	env := &crd.ClowdEnvironment{ObjectMeta: metav1.ObjectMeta{Name: "env"}}
+	c := fake.NewClientBuilder().WithScheme(Scheme).WithObjects(app, env).Build()
+
+	// The finalizers Clowder adds are recorded like any other write
+	appReconciliation := ClowdAppReconciliation{ctx: ctx, client: c, log: &log, app: app}
+	assert.NoError(t, appReconciliation.addFinalizerImplementation())
+	envReconciliation := ClowdEnvironmentReconciliation{ctx: ctx, client: c, log: &log, env: env}
+	assert.NoError(t, envReconciliation.addFinalizerImplementation())
+
+	var entries []auditEntry
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		entry := auditEntry{}
+		assert.NoError(t, json.Unmarshal([]byte(line), &entry))
+		entries = append(entries, entry)
+	}
+
+	assert.Len(t, entries, 2)
+	assert.Equal(t, "ClowdApp/test/puptoo", entries[0].Trigger)
+	assert.Equal(t, "update", entries[0].Operation)
+	assert.Equal(t, "ClowdApp", entries[0].Kind)
+	assert.Equal(t, "ClowdEnvironment/env", entries[1].Trigger)
+	assert.Equal(t, "ClowdEnvironment", entries[1].Kind)
+}
@@ -0,0 +1,109 @@
+package controllers
+
+import (
+	"sync"
+	"time"
+
+	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/clowderconfig"
+)
+
+// appCircuit tracks the consecutive failed reconciles of a single app at a
+// given generation.
+type appCircuit struct {
+	failures   int
+	generation int64
+	openedAt   time.Time
+}
+
+// circuitBreaker holds back reconciles of apps that keep failing to provision.
+// Once an app has failed threshold times in a row its circuit opens, and
+// reconciles are skipped until the cooldown elapses or the app's spec changes.
+// The first reconcile after the cooldown is let through, and a further failure
+// opens the circuit again straight away.
+type circuitBreaker struct {
+	mu        sync.Mutex
+	circuits  map[string]*appCircuit
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+}
+
+func newCircuitBreaker(threshold int, cooldown time.Duration) *circuitBreaker {
+	return &circuitBreaker{
+		circuits:  map[string]*appCircuit{},
+		threshold: threshold,
+		cooldown:  cooldown,
+		now:       time.Now,
+	}
+}
+
+var appCircuitBreaker = newCircuitBreaker(
+	clowderconfig.LoadedConfig.Settings.CircuitBreakerThreshold,
+	time.Duration(clowderconfig.LoadedConfig.Settings.CircuitBreakerCooldownMinutes)*time.Minute,
+)
+
+// isOpen reports whether reconciles of the app should be held back, and for
+// how much longer. A threshold of zero disables the circuit breaker.
+func (cb *circuitBreaker) isOpen(ident string, generation int64) (bool, time.Duration) {
+	if cb.threshold <= 0 {
+		return false, 0
+	}
+
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	c, ok := cb.circuits[ident]
+	if !ok {
+		return false, 0
+	}
+
+	if c.generation != generation {
+		delete(cb.circuits, ident)
+		return false, 0
+	}
+
+	if c.failures < cb.threshold {
+		return false, 0
+	}
+
+	remaining := c.openedAt.Add(cb.cooldown).Sub(cb.now())
+	if remaining <= 0 {
+		return false, 0
+	}
+
+	return true, remaining
+}
+
+// recordFailure counts a failed reconcile of the app and reports whether that
+// opened its circuit.
+func (cb *circuitBreaker) recordFailure(ident string, generation int64) bool {
+	if cb.threshold <= 0 {
+		return false
+	}
+
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	c, ok := cb.circuits[ident]
+	if !ok || c.generation != generation {
+		c = &appCircuit{generation: generation}
+		cb.circuits[ident] = c
+	}
+
+	c.failures++
+	if c.failures >= cb.threshold {
+		c.openedAt = cb.now()
+		return true
+	}
+
+	return false
+}
+
+// reset forgets the failures of the app, after it reconciles successfully or
+// is deleted.
+func (cb *circuitBreaker) reset(ident string) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	delete(cb.circuits, ident)
+}
@@ -0,0 +1,51 @@
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	now := time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC)
+	cb := newCircuitBreaker(3, 30*time.Minute)
+	cb.now = func() time.Time { return now }
+
+	assert.False(t, cb.recordFailure("ns/app", 1))
+	assert.False(t, cb.recordFailure("ns/app", 1))
+	open, _ := cb.isOpen("ns/app", 1)
+	assert.False(t, open)
+
+	assert.True(t, cb.recordFailure("ns/app", 1))
+	open, remaining := cb.isOpen("ns/app", 1)
+	assert.True(t, open)
+	assert.Equal(t, 30*time.Minute, remaining)
+
+	// After the cooldown one attempt is let through, and failing it reopens the circuit
+	now = now.Add(31 * time.Minute)
+	open, _ = cb.isOpen("ns/app", 1)
+	assert.False(t, open)
+	assert.True(t, cb.recordFailure("ns/app", 1))
+	open, _ = cb.isOpen("ns/app", 1)
+	assert.True(t, open)
+
+	// A spec change closes the circuit and starts counting again
+	open, _ = cb.isOpen("ns/app", 2)
+	assert.False(t, open)
+	assert.False(t, cb.recordFailure("ns/app", 2))
+
+	cb.reset("ns/app")
+	open, _ = cb.isOpen("ns/app", 2)
+	assert.False(t, open)
+}
+
+func TestCircuitBreakerDisabled(t *testing.T) {
+	cb := newCircuitBreaker(0, 30*time.Minute)
+
+	for i := 0; i < 10; i++ {
+		assert.False(t, cb.recordFailure("ns/app", 1))
+	}
+	open, _ := cb.isOpen("ns/app", 1)
+	assert.False(t, open)
+}
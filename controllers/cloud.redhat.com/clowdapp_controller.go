@@ -21,12 +21,13 @@ import (
 	"github.com/go-logr/logr"
 	"github.com/prometheus/client_golang/prometheus"
 	apps "k8s.io/api/apps/v1"
+	batch "k8s.io/api/batch/v1"
 	core "k8s.io/api/core/v1"
 	k8serr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
-	"k8s.io/client-go/util/workqueue"
 
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
@@ -46,7 +47,7 @@ import (
 	_ "github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers/autoscaler"
 	_ "github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers/confighash"
 	_ "github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers/cronjob"
-	_ "github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers/database"
+	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers/database"
 	_ "github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers/dependencies"
 	_ "github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers/deployment"
 	_ "github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers/featureflags"
@@ -55,12 +56,17 @@ import (
 	_ "github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers/kafka"
 	_ "github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers/logging"
 	_ "github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers/metrics"
+	_ "github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers/namedservice"
 	_ "github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers/namespace"
 	_ "github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers/objectstore"
 	_ "github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers/pullsecrets"
+	_ "github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers/rollout"
 	_ "github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers/serviceaccount"
 	_ "github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers/servicemesh"
 	_ "github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers/sidecar"
+	_ "github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers/tracing"
+	_ "github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers/trustedca"
+	_ "github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers/waitfordeps"
 	_ "github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers/web"
 
 	crd "github.com/RedHatInsights/clowder/apis/cloud.redhat.com/v1alpha1"
@@ -103,7 +109,7 @@ func (rm *ReconciliationMetrics) stop() {
 // +kubebuilder:rbac:groups=cloud.redhat.com,resources=clowdapps/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups="",resources=serviceaccounts;configmaps;services;persistentvolumeclaims;secrets;events;namespaces,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=batch,resources=cronjobs;jobs,verbs=get;list;create;update;watch;patch;delete
-// +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=apps,resources=daemonsets;deployments,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=kafka.strimzi.io,resources=kafkatopics,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=kafka.strimzi.io,resources=kafkas,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=kafka.strimzi.io,resources=kafkausers,verbs=get;list;watch;create;update;patch;delete
@@ -114,7 +120,7 @@ func (rm *ReconciliationMetrics) stop() {
 // +kubebuilder:rbac:groups=monitoring.coreos.com,resources=prometheuses;servicemonitors,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=operators.coreos.com,resources=subscriptions,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=kafka.strimzi.io,resources=kafkaconnectors,verbs=get;list;watch
-// +kubebuilder:rbac:groups="",resources=endpoints;pods,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=endpoints;persistentvolumes;pods,verbs=get;list;watch
 // +kubebuilder:rbac:groups=networking.k8s.io,resources=ingresses;networkpolicies,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=config.openshift.io,resources=ingresses,verbs=get;list
 
@@ -160,7 +166,7 @@ func (r *ClowdAppReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 		return res, err
 	}
 
-	return ctrl.Result{}, nil
+	return res, nil
 }
 
 // SetupWithManager sets up with Manager
@@ -185,11 +191,17 @@ func (r *ClowdAppReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		builder.WithPredicates(environmentPredicate(r.Log, "app")),
 	)
 	ctrlr.Watches(&source.Kind{Type: &apps.Deployment{}}, createNewHandler(deploymentFilter, r.Log, "app", &crd.ClowdApp{}, r.HashCache))
+	ctrlr.Watches(
+		&source.Kind{Type: &apps.Deployment{}},
+		handler.EnqueueRequestsFromMapFunc(r.appsToEnqueueUponDatabaseDrain),
+	)
+	ctrlr.Watches(&source.Kind{Type: &apps.DaemonSet{}}, createNewHandler(daemonSetFilter, r.Log, "app", &crd.ClowdApp{}, r.HashCache))
+	ctrlr.Watches(&source.Kind{Type: &batch.Job{}}, createNewHandler(jobFilter, r.Log, "app", &crd.ClowdApp{}, r.HashCache))
 	ctrlr.Watches(&source.Kind{Type: &core.Service{}}, createNewHandler(generationOnlyFilter, r.Log, "app", &crd.ClowdApp{}, r.HashCache))
 	ctrlr.Watches(&source.Kind{Type: &core.ConfigMap{}}, createNewHandler(generationOnlyFilter, r.Log, "app", &crd.ClowdApp{}, r.HashCache))
 	ctrlr.Watches(&source.Kind{Type: &core.Secret{}}, createNewHandler(alwaysFilter, r.Log, "app", &crd.ClowdApp{}, r.HashCache))
 	ctrlr.WithOptions(controller.Options{
-		RateLimiter: workqueue.NewItemExponentialFailureRateLimiter(time.Duration(500*time.Millisecond), time.Duration(60*time.Second)),
+		RateLimiter: RateLimiter.newRateLimiter(),
 	})
 	return ctrlr.Complete(r)
 }
@@ -240,6 +252,48 @@ func (r *ClowdAppReconciler) appsToEnqueueUponEnvUpdate(a client.Object) []recon
 	return reqs
 }
 
+// appsToEnqueueUponDatabaseDrain enqueues the apps sharing a local database through
+// sharedDbAppName when its deployment is being drained for an upgrade, so that they scale their
+// deployments down without waiting for their next reconcile.
+func (r *ClowdAppReconciler) appsToEnqueueUponDatabaseDrain(a client.Object) []reconcile.Request {
+	if _, draining := a.GetAnnotations()[database.DrainAnnotation]; !draining {
+		return nil
+	}
+
+	owner := metav1.GetControllerOf(a)
+	if owner == nil || owner.Kind != "ClowdApp" {
+		return nil
+	}
+
+	ctx := context.Background()
+	app := crd.ClowdApp{}
+	if err := r.Client.Get(ctx, types.NamespacedName{Name: owner.Name, Namespace: a.GetNamespace()}, &app); err != nil {
+		if !k8serr.IsNotFound(err) {
+			r.Log.Error(err, "Failed to fetch ClowdApp")
+		}
+		return nil
+	}
+
+	appList := crd.ClowdAppList{}
+	if err := crd.GetAppInSameEnv(ctx, r.Client, &app, &appList); err != nil {
+		r.Log.Error(err, "Failed to fetch ClowdApps")
+		return nil
+	}
+
+	reqs := []reconcile.Request{}
+	for _, iapp := range appList.Items {
+		if iapp.Spec.Database.SharedDBAppName == app.Name {
+			reqs = append(reqs, reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Name:      iapp.Name,
+					Namespace: iapp.Namespace,
+				},
+			})
+		}
+	}
+	return reqs
+}
+
 func contains(list []string, s string) bool {
 	for _, v := range list {
 		if v == s {
@@ -264,3 +318,20 @@ func updateMetadata(app *crd.ClowdApp, appConfig *config.AppConfig) {
 	appConfig.Metadata.Name = &app.Name
 	appConfig.Metadata.EnvName = &app.Spec.EnvName
 }
+
+// updateGlobalConfig merges the environment's globalConfig with the app's, the app's settings
+// overriding the environment's.
+func updateGlobalConfig(env *crd.ClowdEnvironment, app *crd.ClowdApp, appConfig *config.AppConfig) {
+	if len(env.Spec.GlobalConfig) == 0 && len(app.Spec.GlobalConfig) == 0 {
+		return
+	}
+
+	globalConfig := map[string]string{}
+	for key, value := range env.Spec.GlobalConfig {
+		globalConfig[key] = value
+	}
+	for key, value := range app.Spec.GlobalConfig {
+		globalConfig[key] = value
+	}
+	appConfig.GlobalConfig = globalConfig
+}
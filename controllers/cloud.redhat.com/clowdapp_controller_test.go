@@ -3,7 +3,9 @@ package controllers
 import (
 	"testing"
 
+	crd "github.com/RedHatInsights/clowder/apis/cloud.redhat.com/v1alpha1"
 	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/clowderconfig"
+	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/config"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -37,3 +39,25 @@ func TestReconcileMetricsEndEnabled(_ *testing.T) {
 	reconciler.start()
 	reconciler.stop()
 }
+
+func TestUpdateGlobalConfig(t *testing.T) {
+	env := &crd.ClowdEnvironment{Spec: crd.ClowdEnvironmentSpec{GlobalConfig: map[string]string{
+		"tracingEndpoint":  "collector.tracing.svc:4317",
+		"featureFlagsHost": "unleash.flags.svc",
+	}}}
+	app := &crd.ClowdApp{Spec: crd.ClowdAppSpec{GlobalConfig: map[string]string{
+		"featureFlagsHost": "unleash-next.flags.svc",
+	}}}
+
+	appConfig := &config.AppConfig{}
+	updateGlobalConfig(env, app, appConfig)
+	assert.Equal(t, map[string]string{
+		"tracingEndpoint":  "collector.tracing.svc:4317",
+		"featureFlagsHost": "unleash-next.flags.svc",
+	}, appConfig.GlobalConfig)
+	assert.Equal(t, "unleash.flags.svc", env.Spec.GlobalConfig["featureFlagsHost"])
+
+	appConfig = &config.AppConfig{}
+	updateGlobalConfig(&crd.ClowdEnvironment{}, &crd.ClowdApp{}, appConfig)
+	assert.Nil(t, appConfig.GlobalConfig)
+}
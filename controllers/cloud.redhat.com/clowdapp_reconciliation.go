@@ -2,15 +2,20 @@ package controllers
 
 import (
 	"context"
+	errlib "errors"
 	"fmt"
+	"strings"
 	"time"
 
 	crd "github.com/RedHatInsights/clowder/apis/cloud.redhat.com/v1alpha1"
 	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/clowderconfig"
 	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/config"
 	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/errors"
+	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/featuregates"
 	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/hashcache"
 	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers"
+	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers/confighash"
+	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers/kafka"
 	provutils "github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers/utils"
 	rc "github.com/RedHatInsights/rhc-osdk-utils/resourceCache"
 	"github.com/go-logr/logr"
@@ -19,6 +24,7 @@ import (
 	k8serr "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
+	cond "sigs.k8s.io/cluster-api/util/conditions"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
@@ -37,6 +43,8 @@ type ClowdAppReconciliation struct {
 	config                *config.AppConfig
 	oldStatus             *crd.ClowdAppStatus
 	hashCache             *hashcache.HashCache
+	requeue               providers.Requeue
+	possibleGVKs          rc.GVKMap
 }
 
 func (r *ClowdAppReconciliation) steps() []func() (ctrl.Result, error) {
@@ -48,18 +56,28 @@ func (r *ClowdAppReconciliation) steps() []func() (ctrl.Result, error) {
 		r.addFinalizer,
 		r.isEnvLocked,
 		r.isAppDisabled,
+		r.isCircuitOpen,
 		r.isAppNamespaceDeleted,
 		r.getClowdEnv,
+		r.isRetryLimitReached,
 		r.isEnvNamespaceDeleted,
 		r.isClowdEnvReconciled,
 		r.isEnvReady,
+		r.hasRequiredProviders,
 		r.createCache,
 		r.runProviders,
 		r.applyCache,
 		r.setAppResourceStatus,
 		r.deletedUnusedResources,
+		r.setInventory,
+		r.setResourceRecommendation,
 		r.setReconciliationSuccessful,
+		r.reportMaintenanceMode,
 		r.stopMetrics,
+		r.isVolumeZoneMismatch,
+		r.isVolumeUnbound,
+		r.isDatabaseDraining,
+		r.isMaintenancePending,
 	}
 }
 
@@ -70,7 +88,7 @@ func (r *ClowdAppReconciliation) Reconcile() (ctrl.Result, error) {
 			return result, err
 		}
 	}
-	return ctrl.Result{}, nil
+	return ctrl.Result{RequeueAfter: r.requeue.After}, nil
 }
 
 func (r *ClowdAppReconciliation) startMetrics() (ctrl.Result, error) {
@@ -108,6 +126,9 @@ func (r *ClowdAppReconciliation) getApp() (ctrl.Result, error) {
 		r.log.Info("App not found", "env", r.app.Spec.EnvName, "app", r.app.GetIdent(), "err", getAppErr)
 		return ctrl.Result{}, getAppErr
 	}
+	if err := r.applyDefaultEnv(); err != nil {
+		return ctrl.Result{Requeue: true}, err
+	}
 	// This is kinda side-effecty but I couldn't think of a better place
 	// to put it.
 	logWithEnv := r.log.WithValues("env", r.app.Spec.EnvName)
@@ -118,6 +139,35 @@ func (r *ClowdAppReconciliation) getApp() (ctrl.Result, error) {
 	return ctrl.Result{}, nil
 }
 
+// auditClient returns the client that the writes made while reconciling the app go through, so
+// that they are recorded in the audit log.
+func (r *ClowdAppReconciliation) auditClient() client.Client {
+	return newAuditClient(r.client, auditLog, "ClowdApp", r.app)
+}
+
+// applyDefaultEnv sets the operator's default ClowdEnvironment on an app that does not name one.
+// The default is written back to the app, so that it is found by the environment it now targets.
+func (r *ClowdAppReconciliation) applyDefaultEnv() error {
+	if r.app.Spec.EnvName != "" || DefaultEnvName == "" {
+		return nil
+	}
+
+	env := &crd.ClowdEnvironment{}
+	if err := r.client.Get(r.ctx, types.NamespacedName{Name: DefaultEnvName}, env); err != nil {
+		r.recorder.Eventf(r.app, "Warning", "ClowdEnvMissing", "Default Clowder Environment [%s] is missing", DefaultEnvName)
+		return errors.Wrap(fmt.Sprintf("default environment %s could not be found", DefaultEnvName), err)
+	}
+
+	r.app.Spec.EnvName = DefaultEnvName
+	if err := r.auditClient().Update(r.ctx, r.app); err != nil {
+		return errors.Wrap("could not apply default environment", err)
+	}
+
+	r.log.Info("Applied default ClowdEnvironment to app", "env", DefaultEnvName, "app", r.app.GetIdent())
+	r.recorder.Eventf(r.app, "Normal", "ClowdEnvDefaulted", "Clowdapp assigned the default Clowder Environment [%s]", DefaultEnvName)
+	return nil
+}
+
 func (r *ClowdAppReconciliation) isAppMarkedForDeletion() (ctrl.Result, error) {
 	isAppMarkedForDeletion := r.app.GetDeletionTimestamp() != nil
 	if isAppMarkedForDeletion {
@@ -128,7 +178,7 @@ func (r *ClowdAppReconciliation) isAppMarkedForDeletion() (ctrl.Result, error) {
 			}
 
 			controllerutil.RemoveFinalizer(r.app, appFinalizer)
-			removeFinalizeErr := r.client.Update(r.ctx, r.app)
+			removeFinalizeErr := r.auditClient().Update(r.ctx, r.app)
 			if removeFinalizeErr != nil {
 				r.log.Info("Cloud not remove finalizer", "err", removeFinalizeErr)
 				return ctrl.Result{}, removeFinalizeErr
@@ -148,6 +198,20 @@ func (r *ClowdAppReconciliation) finalizeApp() error {
 	delete(presentApps, r.app.GetIdent())
 	presentAppsMetric.Set(float64(len(presentApps)))
 
+	appCircuitBreaker.reset(r.app.GetIdent())
+	appRetryLimiter.reset(r.app.GetIdent())
+	appWriteCache.reset(r.app.GetIdent())
+
+	// The app's topics are owned by its environment, and go with it if it is already gone
+	env := &crd.ClowdEnvironment{}
+	if err := r.client.Get(r.ctx, r.app.GetEnvNamespacedName(), env); err != nil {
+		if !k8serr.IsNotFound(err) {
+			return err
+		}
+	} else if err := kafka.FinalizeAppTopics(r.ctx, r.auditClient(), *r.log, env, r.app); err != nil {
+		return err
+	}
+
 	r.log.Info("Successfully finalized ClowdApp")
 	return nil
 }
@@ -167,7 +231,7 @@ func (r *ClowdAppReconciliation) addFinalizerImplementation() error {
 	controllerutil.AddFinalizer(r.app, appFinalizer)
 
 	// Update CR
-	err := r.client.Update(r.ctx, r.app)
+	err := r.auditClient().Update(r.ctx, r.app)
 	if err != nil {
 		r.log.Error(err, "Failed to update ClowdApp with finalizer")
 		return err
@@ -190,12 +254,56 @@ func (r *ClowdAppReconciliation) isAppDisabled() (ctrl.Result, error) {
 	return ctrl.Result{}, nil
 }
 
+func (r *ClowdAppReconciliation) isCircuitOpen() (ctrl.Result, error) {
+	if open, remaining := appCircuitBreaker.isOpen(r.app.GetIdent(), r.app.Generation); open {
+		return ctrl.Result{RequeueAfter: remaining}, NewSkippedError("app circuit is open after repeated failures")
+	}
+	return ctrl.Result{}, nil
+}
+
+func (r *ClowdAppReconciliation) isRetryLimitReached() (ctrl.Result, error) {
+	if appRetryLimiter.isExhausted(r.app.GetIdent(), r.app.Generation, r.env.Generation) {
+		return ctrl.Result{}, NewSkippedError("app failed after using up its reconcile retries")
+	}
+	return ctrl.Result{}, nil
+}
+
+// recordFailure counts a failed provisioning attempt against both the circuit
+// breaker and the app's retry limit, and reports whether that opened the
+// app's circuit and whether it used up its retries.
+func (r *ClowdAppReconciliation) recordFailure() (bool, bool) {
+	opened := appCircuitBreaker.recordFailure(r.app.GetIdent(), r.app.Generation)
+	exhausted := appRetryLimiter.recordFailure(r.app.GetIdent(), r.app.Generation, r.env.Generation, r.env.Spec.MaxReconcileRetries)
+	return opened, exhausted
+}
+
+// failedReconcile returns the result of a failed provisioning attempt. Once the
+// app has used up its retries it is no longer requeued, and once its circuit
+// has opened, it is requeued after the cooldown instead of on the usual backoff.
+func (r *ClowdAppReconciliation) failedReconcile(opened bool, exhausted bool, err error) (ctrl.Result, error) {
+	if exhausted {
+		r.recorder.Eventf(r.app, "Warning", "RetriesExhausted", "Clowdapp marked failed after %d failed reconciles [%s]", r.env.Spec.MaxReconcileRetries, r.app.GetClowdName())
+		return ctrl.Result{}, NewSkippedError(fmt.Sprintf("app failed: %s", err.Error()))
+	}
+	if opened {
+		r.recorder.Eventf(r.app, "Warning", "CircuitOpen", "Clowdapp reconciles held back after repeated failures [%s]", r.app.GetClowdName())
+		return ctrl.Result{RequeueAfter: appCircuitBreaker.cooldown}, NewSkippedError(fmt.Sprintf("app circuit opened: %s", err.Error()))
+	}
+	// A topic creation with retries left is retried once its backoff is over, rather than
+	// straight away
+	var topicErr *errors.KafkaTopicCreationFailed
+	if errlib.As(err, &topicErr) && topicErr.RetryAfter > 0 {
+		return ctrl.Result{RequeueAfter: topicErr.RetryAfter}, NewSkippedError(fmt.Sprintf("app requeued: %s", err.Error()))
+	}
+	return ctrl.Result{Requeue: true}, err
+}
+
 func (r *ClowdAppReconciliation) getClowdEnv() (ctrl.Result, error) {
 	updatedContext := context.WithValue(r.ctx, errors.ClowdKey("obj"), r.app)
 	r.ctx = updatedContext
 	r.env = &crd.ClowdEnvironment{}
 
-	if getEnvErr := r.client.Get(r.ctx, types.NamespacedName{Name: r.app.Spec.EnvName}, r.env); getEnvErr != nil {
+	if getEnvErr := r.client.Get(r.ctx, r.app.GetEnvNamespacedName(), r.env); getEnvErr != nil {
 		r.log.Info("ClowdEnv missing", "err", getEnvErr)
 		r.recorder.Eventf(r.app, "Warning", "ClowdEnvMissing", "Clowder Environment [%s] is missing", r.app.Spec.EnvName)
 		if setClowdStatusErr := SetClowdAppConditions(r.ctx, r.client, r.app, crd.ReconciliationFailed, r.oldStatus, getEnvErr); setClowdStatusErr != nil {
@@ -246,12 +354,46 @@ func (r *ClowdAppReconciliation) isEnvReady() (ctrl.Result, error) {
 		}
 		return ctrl.Result{Requeue: true}, NewSkippedError("env isn't ready")
 	}
+	if !cond.IsTrue(r.env, crd.EnvironmentReady) {
+		r.recorder.Eventf(r.app, "Warning", "ClowdEnvNotReady", "Clowder Environment [%s] shared resources are not ready", r.app.Spec.EnvName)
+		if setClowdStatusErr := SetClowdAppConditions(r.ctx, r.client, r.app, crd.ReconciliationFailed, r.oldStatus, fmt.Errorf("clowd env shared resources not ready")); setClowdStatusErr != nil {
+			return ctrl.Result{Requeue: true}, setClowdStatusErr
+		}
+		return ctrl.Result{Requeue: true}, NewSkippedError("env shared resources aren't ready")
+	}
 	return ctrl.Result{}, nil
 }
 
+func (r *ClowdAppReconciliation) hasRequiredProviders() (ctrl.Result, error) {
+	missing := r.env.MissingProviders(r.app.Spec.RequiredProviders)
+	if len(missing) == 0 {
+		return ctrl.Result{}, nil
+	}
+
+	missingErr := &errors.MissingProviders{Env: r.env.Name}
+	for _, name := range missing {
+		mode := r.env.ProviderMode(name)
+		if mode == "" {
+			mode = "unset"
+		}
+		missingErr.Providers = append(missingErr.Providers, fmt.Sprintf("%s (%s)", name, mode))
+	}
+
+	r.recorder.Eventf(r.app, "Warning", "ProvidersMissing", "Clowder Environment [%s] does not offer required providers [%s]", r.app.Spec.EnvName, strings.Join(missingErr.Providers, ", "))
+	if setClowdStatusErr := SetClowdAppConditions(r.ctx, r.client, r.app, crd.ReconciliationFailed, r.oldStatus, missingErr); setClowdStatusErr != nil {
+		return ctrl.Result{Requeue: true}, setClowdStatusErr
+	}
+	return ctrl.Result{}, NewSkippedError(missingErr.Error())
+}
+
 func (r *ClowdAppReconciliation) createCache() (ctrl.Result, error) {
-	cacheConfig := rc.NewCacheConfig(Scheme, nil, ProtectedGVKs, rc.Options{StrictGVK: true, DebugOptions: DebugOptions})
-	cache := rc.NewObjectCache(r.ctx, r.client, r.log, cacheConfig)
+	// The cache registers every kind the providers may create in possibleGVKs, which is kept to
+	// build the app's inventory from
+	r.possibleGVKs = rc.GVKMap{}
+	cacheConfig := rc.NewCacheConfig(Scheme, r.possibleGVKs, ProtectedGVKs, rc.Options{StrictGVK: true, DebugOptions: DebugOptions})
+	cacheClient := newWriteCacheClient(r.auditClient(), appWriteCache, r.app, r.env)
+	cacheClient = newExplainClient(cacheClient, r.recorder, r.app)
+	cache := rc.NewObjectCache(r.ctx, cacheClient, r.log, cacheConfig)
 	r.cache = &cache
 	return ctrl.Result{}, nil
 }
@@ -261,23 +403,31 @@ func (r *ClowdAppReconciliation) runProviders() (ctrl.Result, error) {
 	r.hashCache.RemoveClowdObjectFromObjects(r.app)
 
 	provider := providers.Provider{
-		Client:    r.client,
+		Client:    r.auditClient(),
 		Ctx:       r.ctx,
 		Env:       r.env,
 		Cache:     r.cache,
 		Log:       *r.log,
 		Config:    r.config,
 		HashCache: r.hashCache,
+		Requeue:   &r.requeue,
 	}
 
 	if provErr := r.runProvidersImplementation(&provider); provErr != nil {
+		// A topic creation waiting out its backoff has retries left, so it is not counted as a
+		// failed reconcile of the app
+		opened, exhausted := false, false
+		var topicErr *errors.KafkaTopicCreationFailed
+		if !errlib.As(provErr, &topicErr) || topicErr.RetryAfter == 0 {
+			opened, exhausted = r.recordFailure()
+		}
 		r.recorder.Eventf(r.app, "Warning", "FailedReconciliation", "Clowdapp requeued [%s]", r.app.GetClowdName())
 		if setClowdStatusErr := SetClowdAppConditions(r.ctx, r.client, r.app, crd.ReconciliationFailed, r.oldStatus, provErr); setClowdStatusErr != nil {
 			r.log.Info("Set status error", "err", setClowdStatusErr)
 			return ctrl.Result{Requeue: true}, setClowdStatusErr
 		}
 		r.log.Info("Provider error", "err", provErr)
-		return ctrl.Result{Requeue: true}, provErr
+		return r.failedReconcile(opened, exhausted, provErr)
 	}
 	return ctrl.Result{}, nil
 }
@@ -285,21 +435,35 @@ func (r *ClowdAppReconciliation) runProviders() (ctrl.Result, error) {
 func (r *ClowdAppReconciliation) runProvidersImplementation(provider *providers.Provider) error {
 	// Update app metadata
 	updateMetadata(r.app, r.config)
+	updateGlobalConfig(r.env, r.app, r.config)
+
+	timeout := time.Duration(clowderconfig.LoadedConfig.Settings.ProviderTimeoutSeconds) * time.Second
 
 	for _, provAcc := range providers.ProvidersRegistration.Registry {
-		provutils.DebugLog(*r.log, "running provider:", "name", provAcc.Name, "order", provAcc.Order)
-		prov, err := provAcc.SetupProvider(provider)
-		if err != nil {
-			return errors.Wrap(fmt.Sprintf("getprov: %s", provAcc.Name), err)
+		if !featuregates.Gates.ProviderEnabled(provAcc.Name) {
+			provutils.DebugLog(*r.log, "skipping provider behind disabled feature gate:", "name", provAcc.Name)
+			recordProviderRun(provAcc.Name, "clowdapp", providerRunSkipped)
+			continue
 		}
+		provutils.DebugLog(*r.log, "running provider:", "name", provAcc.Name, "order", provAcc.Order)
 		start := time.Now()
-		err = prov.Provide(r.app)
+		err := providers.RunWithTimeout(provider, provAcc.Name, timeout, func(p *providers.Provider) error {
+			prov, err := provAcc.SetupProvider(p)
+			if err != nil {
+				return errors.Wrap(fmt.Sprintf("getprov: %s", provAcc.Name), err)
+			}
+			if err := prov.Provide(r.app); err != nil {
+				reterr := errors.Wrap(fmt.Sprintf("runapp: %s", provAcc.Name), err)
+				reterr.Requeue = true
+				return reterr
+			}
+			return nil
+		})
 		elapsed := time.Since(start).Seconds()
 		providerMetrics.With(prometheus.Labels{"provider": provAcc.Name, "source": "clowdapp"}).Observe(elapsed)
+		recordProviderRun(provAcc.Name, "clowdapp", providerRunOutcome(err))
 		if err != nil {
-			reterr := errors.Wrap(fmt.Sprintf("runapp: %s", provAcc.Name), err)
-			reterr.Requeue = true
-			return reterr
+			return err
 		}
 		provutils.DebugLog(*r.log, "running provider: complete", "name", provAcc.Name, "order", provAcc.Order, "elapsed", fmt.Sprintf("%f", elapsed))
 	}
@@ -312,13 +476,14 @@ func (r *ClowdAppReconciliation) applyCache() (ctrl.Result, error) {
 	cacheErr := r.cache.ApplyAll()
 
 	if cacheErr != nil {
+		opened, exhausted := r.recordFailure()
 		r.recorder.Eventf(r.app, "Warning", "FailedReconciliation", "Clowdapp requeued [%s]", r.app.GetClowdName())
 		if setClowdStatusErr := SetClowdAppConditions(r.ctx, r.client, r.app, crd.ReconciliationFailed, r.oldStatus, cacheErr); setClowdStatusErr != nil {
 			r.log.Info("Set status error", "err", setClowdStatusErr)
 			return ctrl.Result{Requeue: true}, setClowdStatusErr
 		}
 		r.log.Info("Cache error", "err", cacheErr)
-		return ctrl.Result{Requeue: true}, cacheErr
+		return r.failedReconcile(opened, exhausted, cacheErr)
 	}
 
 	return ctrl.Result{}, nil
@@ -347,7 +512,61 @@ func (r *ClowdAppReconciliation) deletedUnusedResources() (ctrl.Result, error) {
 	return ctrl.Result{}, nil
 }
 
+// setInventory records the objects the app owns once the cache has deleted those it no longer
+// needs. The status is written with the rest when the reconcile succeeds. An inventory that only
+// differs in resourceVersions is kept as it is.
+func (r *ClowdAppReconciliation) setInventory() (ctrl.Result, error) {
+	inventory, err := GetAppInventory(r.ctx, r.client, r.app, r.possibleGVKs)
+	if err != nil {
+		r.log.Info("Inventory error", "err", err)
+		return ctrl.Result{Requeue: true}, err
+	}
+	if sameInventory(r.app.Status.Inventory, inventory) {
+		return ctrl.Result{}, nil
+	}
+	r.app.Status.Inventory = inventory
+	return ctrl.Result{}, nil
+}
+
+func (r *ClowdAppReconciliation) setResourceRecommendation() (ctrl.Result, error) {
+	if !r.env.Spec.Providers.Metrics.ResourceRecommendations {
+		r.app.Status.ResourceRecommendation = nil
+		return ctrl.Result{}, nil
+	}
+
+	now := time.Now()
+	if last := r.app.Status.ResourceRecommendation; last != nil && now.Sub(last.LastUpdated.Time) < recommendationInterval {
+		return ctrl.Result{}, nil
+	}
+
+	// Recommendations are advisory, so failing to compute one never fails the reconcile
+	prom, err := newPromQuerier(r.env.Status.Prometheus.Hostname)
+	if err != nil {
+		r.log.Info("Could not compute resource recommendation", "err", err)
+		return ctrl.Result{}, nil
+	}
+
+	recommendation, err := getResourceRecommendation(r.ctx, prom, r.app, now)
+	if err != nil {
+		r.log.Info("Could not compute resource recommendation", "err", err)
+		return ctrl.Result{}, nil
+	}
+	r.app.Status.ResourceRecommendation = recommendation
+
+	return ctrl.Result{}, nil
+}
+
 func (r *ClowdAppReconciliation) setReconciliationSuccessful() (ctrl.Result, error) {
+	appCircuitBreaker.reset(r.app.GetIdent())
+	appRetryLimiter.reset(r.app.GetIdent())
+
+	// The config has been applied, so the hash reported in the status can move on to it
+	_, configHash, err := confighash.HashConfig(r.config)
+	if err != nil {
+		return ctrl.Result{Requeue: true}, err
+	}
+	r.app.Status.ConfigHash = configHash
+
 	if setClowdStatusErr := SetClowdAppConditions(r.ctx, r.client, r.app, crd.ReconciliationSuccessful, r.oldStatus, nil); setClowdStatusErr != nil {
 		r.log.Info("Set status error", "err", setClowdStatusErr)
 		return ctrl.Result{Requeue: true}, setClowdStatusErr
@@ -358,3 +577,59 @@ func (r *ClowdAppReconciliation) setReconciliationSuccessful() (ctrl.Result, err
 	r.log.Info("Reconciliation successful")
 	return ctrl.Result{}, nil
 }
+
+// reportMaintenanceMode records an event whenever the app goes into or comes out of maintenance
+// mode, so that the window in which it held off its writes can be traced.
+func (r *ClowdAppReconciliation) reportMaintenanceMode() (ctrl.Result, error) {
+	was := false
+	for _, condition := range r.oldStatus.Conditions {
+		if condition.Type == crd.MaintenanceMode && condition.Status == core.ConditionTrue {
+			was = true
+		}
+	}
+
+	switch is := cond.IsTrue(r.app, crd.MaintenanceMode); {
+	case is && !was:
+		r.recorder.Eventf(r.app, "Normal", "MaintenanceModeEntered", "Clowdapp in maintenance mode [%s]", r.app.GetClowdName())
+	case was && !is:
+		r.recorder.Eventf(r.app, "Normal", "MaintenanceModeExited", "Clowdapp out of maintenance mode [%s]", r.app.GetClowdName())
+	}
+	return ctrl.Result{}, nil
+}
+
+// databaseDrainInterval is how often an app is reconciled while it is drained for an upgrade of
+// its database, to move the drain on to its next step.
+const databaseDrainInterval = 10 * time.Second
+
+func (r *ClowdAppReconciliation) isDatabaseDraining() (ctrl.Result, error) {
+	if cond.IsTrue(r.app, crd.DatabaseDraining) {
+		r.recorder.Eventf(r.app, "Normal", "DatabaseDraining", "Clowdapp drained for a database upgrade [%s]", cond.GetMessage(r.app, crd.DatabaseDraining))
+		return ctrl.Result{RequeueAfter: databaseDrainInterval}, NewSkippedError("app is drained for a database upgrade")
+	}
+	return ctrl.Result{}, nil
+}
+
+func (r *ClowdAppReconciliation) isMaintenancePending() (ctrl.Result, error) {
+	if cond.IsTrue(r.app, crd.PendingMaintenance) && r.app.Spec.Database.MaintenanceWindow != nil {
+		now := time.Now()
+		next := r.app.Spec.Database.MaintenanceWindow.NextOpen(now)
+		r.recorder.Eventf(r.app, "Normal", "PendingMaintenance", "Clowdapp database changes deferred until [%s]", next.Format(time.RFC3339))
+		return ctrl.Result{RequeueAfter: next.Sub(now)}, NewSkippedError("app has database changes pending maintenance")
+	}
+	return ctrl.Result{}, nil
+}
+
+func (r *ClowdAppReconciliation) isVolumeZoneMismatch() (ctrl.Result, error) {
+	if cond.IsTrue(r.app, crd.VolumeZoneMismatch) {
+		r.recorder.Eventf(r.app, "Warning", "VolumeZoneMismatch", "Clowdapp database cannot be scheduled with its volume [%s]", cond.GetMessage(r.app, crd.VolumeZoneMismatch))
+	}
+	return ctrl.Result{}, nil
+}
+
+func (r *ClowdAppReconciliation) isVolumeUnbound() (ctrl.Result, error) {
+	if cond.IsTrue(r.app, crd.VolumeUnbound) {
+		r.recorder.Eventf(r.app, "Warning", "VolumeUnbound", "Clowdapp has unbound volumes [%s]", cond.GetMessage(r.app, crd.VolumeUnbound))
+		return ctrl.Result{Requeue: true}, NewSkippedError("app has unbound volumes")
+	}
+	return ctrl.Result{}, nil
+}
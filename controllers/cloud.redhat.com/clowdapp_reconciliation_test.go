@@ -0,0 +1,61 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	crd "github.com/RedHatInsights/clowder/apis/cloud.redhat.com/v1alpha1"
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestApplyDefaultEnv(t *testing.T) {
+	defer func(name string) { DefaultEnvName = name }(DefaultEnvName)
+
+	ctx := context.Background()
+	env := &crd.ClowdEnvironment{ObjectMeta: metav1.ObjectMeta{Name: "default-env"}}
+	apps := []*crd.ClowdApp{
+		{ObjectMeta: metav1.ObjectMeta{Name: "puptoo", Namespace: "test"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "ingress", Namespace: "test"}, Spec: crd.ClowdAppSpec{EnvName: "other-env"}},
+	}
+	c := fake.NewClientBuilder().WithScheme(Scheme).WithObjects(env, apps[0], apps[1]).Build()
+	log := logr.Discard()
+
+	recorder := record.NewFakeRecorder(10)
+
+	reconcile := func(app *crd.ClowdApp) error {
+		r := ClowdAppReconciliation{
+			ctx:      ctx,
+			client:   c,
+			log:      &log,
+			recorder: recorder,
+			app:      app,
+		}
+		return r.applyDefaultEnv()
+	}
+
+	// Without a default, an app without an envName is left alone
+	DefaultEnvName = ""
+	assert.NoError(t, reconcile(apps[0].DeepCopy()))
+
+	// The default environment must exist
+	DefaultEnvName = "missing-env"
+	assert.Error(t, reconcile(apps[0].DeepCopy()))
+	assert.Contains(t, <-recorder.Events, "ClowdEnvMissing")
+
+	DefaultEnvName = "default-env"
+	assert.NoError(t, reconcile(apps[0].DeepCopy()))
+	assert.Equal(t, "Normal ClowdEnvDefaulted Clowdapp assigned the default Clowder Environment [default-env]", <-recorder.Events)
+	assert.NoError(t, reconcile(apps[1].DeepCopy()))
+	assert.Empty(t, recorder.Events, "apps naming an environment should not be defaulted")
+
+	for app, envName := range map[string]string{"puptoo": "default-env", "ingress": "other-env"} {
+		got := &crd.ClowdApp{}
+		assert.NoError(t, c.Get(ctx, types.NamespacedName{Name: app, Namespace: "test"}, got))
+		assert.Equal(t, envName, got.Spec.EnvName)
+	}
+}
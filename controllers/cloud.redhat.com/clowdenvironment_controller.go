@@ -31,7 +31,6 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
-	"k8s.io/client-go/util/workqueue"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -53,17 +52,23 @@ import (
 	_ "github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers/kafka"
 	_ "github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers/logging"
 	_ "github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers/metrics"
+	_ "github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers/namedservice"
 	_ "github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers/namespace"
 	_ "github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers/objectstore"
 	_ "github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers/pullsecrets"
+	_ "github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers/rollout"
 	_ "github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers/serviceaccount"
 	_ "github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers/servicemesh"
 	_ "github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers/sidecar"
+	_ "github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers/tracing"
+	_ "github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers/trustedca"
+	_ "github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers/waitfordeps"
 	_ "github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers/web"
 
 	crd "github.com/RedHatInsights/clowder/apis/cloud.redhat.com/v1alpha1"
 	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/clowderconfig"
 	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/errors"
+	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/featuregates"
 	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers"
 	provutils "github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers/utils"
 	k8serr "k8s.io/apimachinery/pkg/api/errors"
@@ -150,7 +155,7 @@ func (r *ClowdEnvironmentReconciler) Reconcile(ctx context.Context, req ctrl.Req
 
 	ctx = context.WithValue(ctx, errors.ClowdKey("obj"), &env)
 	cacheConfig := rc.NewCacheConfig(Scheme, nil, ProtectedGVKs, rc.Options{StrictGVK: true, DebugOptions: DebugOptions})
-	cache := rc.NewObjectCache(ctx, r.Client, &log, cacheConfig)
+	cache := rc.NewObjectCache(ctx, newAuditClient(r.Client, auditLog, "ClowdEnvironment", &env), &log, cacheConfig)
 
 	r.initMetrics(env)
 
@@ -179,22 +184,35 @@ func (r *ClowdEnvironmentReconciler) Reconcile(ctx context.Context, req ctrl.Req
 	}
 	managedEnvironments[env.Name] = true
 
-	return ctrl.Result{}, nil
+	return result, nil
 }
 
 func runProvidersForEnv(log logr.Logger, provider providers.Provider) error {
+	timeout := time.Duration(clowderconfig.LoadedConfig.Settings.ProviderTimeoutSeconds) * time.Second
+
 	for _, provAcc := range providers.ProvidersRegistration.Registry {
+		if !featuregates.Gates.ProviderEnabled(provAcc.Name) {
+			provutils.DebugLog(log, "skipping provider behind disabled feature gate:", "name", provAcc.Name)
+			recordProviderRun(provAcc.Name, "clowdenv", providerRunSkipped)
+			continue
+		}
 		provutils.DebugLog(log, "running provider:", "name", provAcc.Name, "order", provAcc.Order)
 		start := time.Now()
-		prov, err := provAcc.SetupProvider(&provider)
-		if err != nil {
-			return errors.Wrap(fmt.Sprintf("getprov: %s", provAcc.Name), err)
-		}
-		err = prov.EnvProvide()
+		err := providers.RunWithTimeout(&provider, provAcc.Name, timeout, func(p *providers.Provider) error {
+			prov, err := provAcc.SetupProvider(p)
+			if err != nil {
+				return errors.Wrap(fmt.Sprintf("getprov: %s", provAcc.Name), err)
+			}
+			if err := prov.EnvProvide(); err != nil {
+				return errors.Wrap(fmt.Sprintf("runprov: %s", provAcc.Name), err)
+			}
+			return nil
+		})
 		elapsed := time.Since(start).Seconds()
 		providerMetrics.With(prometheus.Labels{"provider": provAcc.Name, "source": "clowdenv"}).Observe(elapsed)
+		recordProviderRun(provAcc.Name, "clowdenv", providerRunOutcome(err))
 		if err != nil {
-			return errors.Wrap(fmt.Sprintf("runprov: %s", provAcc.Name), err)
+			return err
 		}
 		provutils.DebugLog(log, "running provider: complete", "name", provAcc.Name, "order", provAcc.Order, "elapsed", fmt.Sprintf("%f", elapsed))
 	}
@@ -203,7 +221,7 @@ func runProvidersForEnv(log logr.Logger, provider providers.Provider) error {
 
 func runProvidersForEnvFinalize(log logr.Logger, provider providers.Provider) error {
 	for _, provAcc := range providers.ProvidersRegistration.Registry {
-		if provAcc.FinalizeProvider != nil {
+		if provAcc.FinalizeProvider != nil && featuregates.Gates.ProviderEnabled(provAcc.Name) {
 			provutils.DebugLog(log, "running provider finalize:", "name", provAcc.Name, "order", provAcc.Order)
 			err := provAcc.FinalizeProvider(&provider)
 			if err != nil {
@@ -238,7 +256,7 @@ func (r *ClowdEnvironmentReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	}
 
 	ctrlr.WithOptions(controller.Options{
-		RateLimiter: workqueue.NewItemExponentialFailureRateLimiter(time.Duration(500*time.Millisecond), time.Duration(60*time.Second)),
+		RateLimiter: RateLimiter.newRateLimiter(),
 	})
 	return ctrlr.Complete(r)
 }
@@ -267,8 +285,6 @@ func (r *ClowdEnvironmentReconciler) envToEnqueueUponAppUpdate(a client.Object)
 	logMessage(r.Log, "Reconciliation triggered", "ctrl", "env", "type", "update", "resType", "ClowdApp", "name", a.GetName(), "namespace", a.GetNamespace())
 
 	return []reconcile.Request{{
-		NamespacedName: types.NamespacedName{
-			Name: app.Spec.EnvName,
-		},
+		NamespacedName: app.GetEnvNamespacedName(),
 	}}
 }
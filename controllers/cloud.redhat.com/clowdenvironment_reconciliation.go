@@ -8,6 +8,7 @@ import (
 	crd "github.com/RedHatInsights/clowder/apis/cloud.redhat.com/v1alpha1"
 	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/clowderconfig"
 	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers"
+	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers/kafka"
 	rc "github.com/RedHatInsights/rhc-osdk-utils/resourceCache"
 	"github.com/go-logr/logr"
 	"github.com/prometheus/client_golang/prometheus"
@@ -57,6 +58,7 @@ type ClowdEnvironmentReconciliation struct {
 	env       *crd.ClowdEnvironment
 	log       *logr.Logger
 	oldStatus *crd.ClowdEnvironmentStatus
+	requeue   providers.Requeue
 }
 
 // Returns a list of step methods that should be run during reconciliation
@@ -96,7 +98,13 @@ func (r *ClowdEnvironmentReconciliation) Reconcile() (ctrl.Result, error) {
 		}
 	}
 
-	return ctrl.Result{}, nil
+	return ctrl.Result{RequeueAfter: r.requeue.After}, nil
+}
+
+// auditClient returns the client that the writes made while reconciling the environment go
+// through, so that they are recorded in the audit log.
+func (r *ClowdEnvironmentReconciliation) auditClient() client.Client {
+	return newAuditClient(r.client, auditLog, "ClowdEnvironment", r.env)
 }
 
 // Determine if app is marked for deletion, and if so finalize and end resonciliation
@@ -110,7 +118,7 @@ func (r *ClowdEnvironmentReconciliation) markedForDeletion() (ctrl.Result, error
 			}
 
 			controllerutil.RemoveFinalizer(r.env, envFinalizer)
-			removeFinalizeErr := r.client.Update(r.ctx, r.env)
+			removeFinalizeErr := r.auditClient().Update(r.ctx, r.env)
 			if removeFinalizeErr != nil {
 				r.log.Info("Cloud not remove finalizer", "err", removeFinalizeErr)
 				return ctrl.Result{}, removeFinalizeErr
@@ -129,7 +137,7 @@ func (r *ClowdEnvironmentReconciliation) finalizeEnvironmentImplementation() err
 
 	provider := providers.Provider{
 		Ctx:    r.ctx,
-		Client: r.client,
+		Client: r.auditClient(),
 		Env:    r.env,
 		Cache:  r.cache,
 		Log:    *r.log,
@@ -145,7 +153,7 @@ func (r *ClowdEnvironmentReconciliation) finalizeEnvironmentImplementation() err
 		namespace.SetName(r.env.Status.TargetNamespace)
 		r.log.Info(fmt.Sprintf("Removing auto-generated namespace for %s", r.env.Name))
 		r.recorder.Eventf(r.env, "Warning", "NamespaceDeletion", "Clowder Environment [%s] had no targetNamespace, deleting generated namespace", r.env.Name)
-		_ = r.client.Delete(context.TODO(), namespace)
+		_ = r.auditClient().Delete(context.TODO(), namespace)
 	}
 	delete(managedEnvironments, r.env.Name)
 	managedEnvsMetric.Set(float64(len(managedEnvironments)))
@@ -175,7 +183,7 @@ func (r *ClowdEnvironmentReconciliation) addFinalizerImplementation() error {
 	controllerutil.AddFinalizer(r.env, envFinalizer)
 
 	// Update CR
-	err := r.client.Update(context.TODO(), r.env)
+	err := r.auditClient().Update(context.TODO(), r.env)
 	if err != nil {
 		r.log.Error(err, "Failed to update ClowdEnvironment with finalizer")
 		return err
@@ -238,7 +246,7 @@ func (r *ClowdEnvironmentReconciliation) makeTargetNamespace() (ctrl.Result, err
 	r.env.Status.TargetNamespace = r.env.GenerateTargetNamespace()
 	namespace := &core.Namespace{}
 	namespace.SetName(r.env.Status.TargetNamespace)
-	if snErr := r.client.Create(r.ctx, namespace); snErr != nil {
+	if snErr := r.auditClient().Create(r.ctx, namespace); snErr != nil {
 		r.log.Info("Namespace create error", "err", snErr)
 		if setClowdStatusErr := SetClowdEnvConditions(r.ctx, r.client, r.env, crd.ReconciliationFailed, r.oldStatus, snErr); setClowdStatusErr != nil {
 			r.log.Info("Set status error", "err", setClowdStatusErr)
@@ -281,11 +289,12 @@ func (r *ClowdEnvironmentReconciliation) isTargetNamespaceMarkedForDeletion() (c
 
 func (r *ClowdEnvironmentReconciliation) runProviders() (ctrl.Result, error) {
 	provider := providers.Provider{
-		Ctx:    r.ctx,
-		Client: r.client,
-		Env:    r.env,
-		Cache:  r.cache,
-		Log:    *r.log,
+		Ctx:     r.ctx,
+		Client:  r.auditClient(),
+		Env:     r.env,
+		Cache:   r.cache,
+		Log:     *r.log,
+		Requeue: &r.requeue,
 	}
 	provErr := runProvidersForEnv(*r.log, provider)
 
@@ -382,7 +391,7 @@ func (r *ClowdEnvironmentReconciliation) setAppInfoImplementation() error {
 				Name: fmt.Sprintf("%s-%s", app.Name, pod.Name),
 			}
 			if bool(pod.Web) || pod.WebServices.Public.Enabled {
-				deploymentStatus.Hostname = fmt.Sprintf("%s.%s.svc", deploymentStatus.Name, app.Namespace)
+				deploymentStatus.Hostname = r.env.GetServiceHostname(deploymentStatus.Name, app.Namespace)
 				deploymentStatus.Port = r.env.Spec.Providers.Web.Port
 			}
 			appstatus.Deployments = append(appstatus.Deployments, deploymentStatus)
@@ -391,9 +400,47 @@ func (r *ClowdEnvironmentReconciliation) setAppInfoImplementation() error {
 	}
 
 	r.env.Status.Apps = apps
+	r.env.Status.Topics = getTopicInfo(r.env, appList.Items)
 	return nil
 }
 
+// getTopicInfo lists the Kafka topics requested by the given apps, sorted by name, along with
+// the apps requesting each of them and the compression type the topic is created with. Apps are
+// named by namespace and name, as apps in different namespaces may share a name. Apps being
+// deleted no longer own their topics.
+func getTopicInfo(env *crd.ClowdEnvironment, apps []crd.ClowdApp) []crd.TopicInfo {
+	owners := map[string][]string{}
+	requests := map[string][]crd.KafkaTopicSpec{}
+
+	for _, app := range apps {
+		if app.GetDeletionTimestamp() != nil {
+			continue
+		}
+		appName := fmt.Sprintf("%s/%s", app.Namespace, app.Name)
+		for _, topic := range app.Spec.KafkaTopics {
+			requests[topic.TopicName] = append(requests[topic.TopicName], topic)
+			appNames := owners[topic.TopicName]
+			if len(appNames) > 0 && appNames[len(appNames)-1] == appName {
+				continue
+			}
+			owners[topic.TopicName] = append(appNames, appName)
+		}
+	}
+
+	topics := []crd.TopicInfo{}
+	for name, appNames := range owners {
+		sort.Strings(appNames)
+		topics = append(topics, crd.TopicInfo{
+			Name:            name,
+			Apps:            appNames,
+			CompressionType: kafka.GetCompressionType(env, requests[name]),
+		})
+	}
+	sort.Slice(topics, func(i, j int) bool { return topics[i].Name < topics[j].Name })
+
+	return topics
+}
+
 func (r *ClowdEnvironmentReconciliation) setEnvResourceStatus() (ctrl.Result, error) {
 	if statusErr := SetEnvResourceStatus(r.ctx, r.client, r.env); statusErr != nil {
 		r.log.Info("SetEnvResourceStatus error", "err", statusErr)
@@ -0,0 +1,52 @@
+package controllers
+
+import (
+	"testing"
+
+	crd "github.com/RedHatInsights/clowder/apis/cloud.redhat.com/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGetTopicInfo(t *testing.T) {
+	withTopics := func(name string, topics ...string) crd.ClowdApp {
+		app := crd.ClowdApp{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test"}}
+		for _, topic := range topics {
+			app.Spec.KafkaTopics = append(app.Spec.KafkaTopics, crd.KafkaTopicSpec{TopicName: topic})
+		}
+		return app
+	}
+
+	deleted := withTopics("legacy", "platform.events")
+	now := metav1.Now()
+	deleted.SetDeletionTimestamp(&now)
+
+	compressed := withTopics("analytics", "platform.events")
+	compressed.Spec.KafkaTopics[0].CompressionType = "zstd"
+
+	topics := getTopicInfo(&crd.ClowdEnvironment{}, []crd.ClowdApp{
+		withTopics("inventory", "platform.inventory.events", "platform.events"),
+		withTopics("advisor", "platform.events", "platform.events"),
+		withTopics("web"),
+		compressed,
+		deleted,
+	})
+
+	assert.Equal(t, []crd.TopicInfo{
+		{Name: "platform.events", Apps: []string{"test/advisor", "test/analytics", "test/inventory"}, CompressionType: "zstd"},
+		{Name: "platform.inventory.events", Apps: []string{"test/inventory"}, CompressionType: "producer"},
+	}, topics)
+
+	// Apps with the same name in different namespaces are listed separately
+	staging := withTopics("inventory", "platform.inventory.events")
+	staging.Namespace = "staging"
+	topics = getTopicInfo(&crd.ClowdEnvironment{}, []crd.ClowdApp{
+		withTopics("inventory", "platform.inventory.events"),
+		staging,
+	})
+	assert.Equal(t, []crd.TopicInfo{
+		{Name: "platform.inventory.events", Apps: []string{"staging/inventory", "test/inventory"}, CompressionType: "producer"},
+	}, topics)
+
+	assert.Empty(t, getTopicInfo(&crd.ClowdEnvironment{}, nil))
+}
@@ -8,11 +8,12 @@ import (
 
 type ClowderConfig struct {
 	Images struct {
-		MBOP           string `json:"mbop"`
-		Caddy          string `json:"caddy"`
-		Keycloak       string `json:"Keycloak"`
-		Mocktitlements string `json:"mocktitlements"`
-		Envoy          string `json:"envoy"`
+		MBOP                string `json:"mbop"`
+		Caddy               string `json:"caddy"`
+		Keycloak            string `json:"Keycloak"`
+		Mocktitlements      string `json:"mocktitlements"`
+		Envoy               string `json:"envoy"`
+		WaitForDependencies string `json:"waitForDependencies"`
 	} `json:"images"`
 	DebugOptions struct {
 		Logging struct {
@@ -45,8 +46,15 @@ type ClowderConfig struct {
 		DisableRandomRoutes         bool `json:"disableRandomRoutes"`
 	} `json:"features"`
 	Settings struct {
-		ManagedKafkaEphemDeleteRegex string `json:"managedKafkaEphemDeleteRegex"`
-		RestarterAnnotationName      string `json:"restarterAnnotation"`
+		ManagedKafkaEphemDeleteRegex  string `json:"managedKafkaEphemDeleteRegex"`
+		RestarterAnnotationName       string `json:"restarterAnnotation"`
+		CircuitBreakerThreshold       int    `json:"circuitBreakerThreshold"`
+		CircuitBreakerCooldownMinutes int    `json:"circuitBreakerCooldownMinutes"`
+		ProviderTimeoutSeconds        int    `json:"providerTimeoutSeconds"`
+		AuditLog                      struct {
+			Sink string `json:"sink"`
+			Path string `json:"path"`
+		} `json:"auditLog"`
 	} `json:"settings"`
 }
 
@@ -57,13 +65,13 @@ func getConfig() ClowderConfig {
 		configPath = path
 	}
 
-	fmt.Printf("Loading config from: %s\n", configPath)
+	fmt.Fprintf(os.Stderr, "Loading config from: %s\n", configPath)
 
 	jsonData, err := os.ReadFile(configPath)
 
 	if err != nil {
-		fmt.Printf("Config file not found\n")
-		return ClowderConfig{}
+		fmt.Fprintf(os.Stderr, "Config file not found\n")
+		return withDefaults(ClowderConfig{})
 	}
 
 	clowderConfig := ClowderConfig{}
@@ -71,13 +79,27 @@ func getConfig() ClowderConfig {
 
 	if err != nil {
 		fmt.Printf("Couldn't parse json:\n" + err.Error())
-		return ClowderConfig{}
+		return withDefaults(ClowderConfig{})
 	}
 
+	return withDefaults(clowderConfig)
+}
+
+// withDefaults fills in the settings the config leaves unset, whether or not a config file could
+// be loaded.
+func withDefaults(clowderConfig ClowderConfig) ClowderConfig {
 	if clowderConfig.Settings.RestarterAnnotationName == "" {
 		clowderConfig.Settings.RestarterAnnotationName = "qontract.recycle"
 	}
 
+	if clowderConfig.Settings.CircuitBreakerCooldownMinutes <= 0 {
+		clowderConfig.Settings.CircuitBreakerCooldownMinutes = 30
+	}
+
+	if clowderConfig.Settings.ProviderTimeoutSeconds <= 0 {
+		clowderConfig.Settings.ProviderTimeoutSeconds = 120
+	}
+
 	return clowderConfig
 }
 
@@ -3,6 +3,7 @@ package clowderconfig
 import (
 	"encoding/json"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -27,3 +28,19 @@ func TestConfig(t *testing.T) {
 	assert.Equal(t, debug.Cache.Update, false)
 	assert.Equal(t, debug.Cache.Apply, true)
 }
+
+func TestConfigDefaults(t *testing.T) {
+	// A missing config file still gets the defaults
+	t.Setenv("CLOWDER_CONFIG_PATH", "missing.json")
+	config := getConfig()
+	assert.Equal(t, 120, config.Settings.ProviderTimeoutSeconds)
+	assert.Equal(t, 30, config.Settings.CircuitBreakerCooldownMinutes)
+	assert.Equal(t, "qontract.recycle", config.Settings.RestarterAnnotationName)
+
+	// As does one that doesn't parse
+	path := filepath.Join(t.TempDir(), "clowder_config.json")
+	assert.NoError(t, os.WriteFile(path, []byte("{"), 0600))
+	t.Setenv("CLOWDER_CONFIG_PATH", path)
+	config = getConfig()
+	assert.Equal(t, 120, config.Settings.ProviderTimeoutSeconds)
+}
@@ -19,7 +19,6 @@ package controllers
 import (
 	"context"
 	"fmt"
-	"time"
 
 	crd "github.com/RedHatInsights/clowder/apis/cloud.redhat.com/v1alpha1"
 	rc "github.com/RedHatInsights/rhc-osdk-utils/resourceCache"
@@ -35,10 +34,10 @@ import (
 	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/errors"
 	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers/iqe"
 	jobProvider "github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers/job"
+	provutils "github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers/utils"
 
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
-	"k8s.io/client-go/util/workqueue"
 
 	"github.com/RedHatInsights/rhc-osdk-utils/utils"
 )
@@ -77,7 +76,7 @@ func (r *ClowdJobInvocationReconciler) Reconcile(ctx context.Context, req ctrl.R
 	}
 
 	cacheConfig := rc.NewCacheConfig(Scheme, nil, ProtectedGVKs, rc.Options{StrictGVK: true, DebugOptions: DebugOptions})
-	cache := rc.NewObjectCache(ctx, r.Client, &log, cacheConfig)
+	cache := rc.NewObjectCache(ctx, newAuditClient(r.Client, auditLog, "ClowdJobInvocation", &cji), &log, cacheConfig)
 	cache.AddPossibleGVKFromIdent(
 		iqe.IqeSecret,
 		iqe.VaultSecret,
@@ -156,9 +155,7 @@ func (r *ClowdJobInvocationReconciler) Reconcile(ctx context.Context, req ctrl.R
 	// Get the ClowdEnv for InvokeJob. Env is needed to build out our pod
 	// template for each job
 	env := crd.ClowdEnvironment{}
-	envErr := r.Client.Get(ctx, types.NamespacedName{
-		Name: app.Spec.EnvName,
-	}, &env)
+	envErr := r.Client.Get(ctx, app.GetEnvNamespacedName(), &env)
 
 	if envErr != nil {
 		r.Recorder.Eventf(&cji, "Warning", "ClowdEnvMissing", "ClowdEnv [%s] is missing; Job cannot be invoked", app.Spec.EnvName)
@@ -186,7 +183,7 @@ func (r *ClowdJobInvocationReconciler) Reconcile(ctx context.Context, req ctrl.R
 		// We have a match that isn't running and can invoke the job
 		r.Log.Info("Invoking job", "jobinvocation", job.Name, "namespace", app.Namespace)
 
-		if err := r.InvokeJob(&cache, &job, &app, &env, &cji); err != nil {
+		if err := r.InvokeJob(ctx, &cache, &job, &app, &env, &cji); err != nil {
 			r.Log.Error(err, "Job Invocation Failed", "jobinvocation", jobName, "namespace", app.Namespace)
 			if condErr := SetClowdJobInvocationConditions(ctx, r.Client, &cji, crd.ReconciliationFailed, err); condErr != nil {
 				return ctrl.Result{}, condErr
@@ -260,7 +257,7 @@ func (r *ClowdJobInvocationReconciler) Reconcile(ctx context.Context, req ctrl.R
 
 // InvokeJob is responsible for applying the Job. It also updates and reports
 // the status of that job
-func (r *ClowdJobInvocationReconciler) InvokeJob(cache *rc.ObjectCache, job *crd.Job, app *crd.ClowdApp, env *crd.ClowdEnvironment, cji *crd.ClowdJobInvocation) error {
+func (r *ClowdJobInvocationReconciler) InvokeJob(ctx context.Context, cache *rc.ObjectCache, job *crd.Job, app *crd.ClowdApp, env *crd.ClowdEnvironment, cji *crd.ClowdJobInvocation) error {
 	// Update job name to avoid collisions
 	randomString := utils.RandStringLower(7)
 	jobName := fmt.Sprintf("%s-%s", job.Name, randomString)
@@ -276,6 +273,10 @@ func (r *ClowdJobInvocationReconciler) InvokeJob(cache *rc.ObjectCache, job *crd
 	}
 	nn.Name = jobName
 
+	if err := provutils.CheckJobServiceAccount(ctx, r.Client, app, job); err != nil {
+		return err
+	}
+
 	j := batchv1.Job{}
 	if err := cache.Create(iqe.ClowdJob, nn, &j); err != nil {
 		return err
@@ -320,7 +321,7 @@ func (r *ClowdJobInvocationReconciler) SetupWithManager(mgr ctrl.Manager) error
 		For(&crd.ClowdJobInvocation{}).
 		Owns(&batchv1.Job{}).
 		WithOptions(controller.Options{
-			RateLimiter: workqueue.NewItemExponentialFailureRateLimiter(time.Duration(500*time.Millisecond), time.Duration(60*time.Second)),
+			RateLimiter: RateLimiter.newRateLimiter(),
 		}).
 		Complete(r)
 }
@@ -1,11 +1,43 @@
 package config
 
 import (
+	"encoding/json"
 	"strconv"
 
 	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/yaml"
 )
 
+// The formats the AppConfig can be presented to apps in.
+const (
+	FormatJSON = "json"
+	FormatYAML = "yaml"
+)
+
+// FileName returns the name of the file the AppConfig is presented to apps as in the given
+// format, cdappconfig.json unless YAML is asked for.
+func FileName(format string) string {
+	if format == FormatYAML {
+		return "cdappconfig.yaml"
+	}
+	return "cdappconfig.json"
+}
+
+// Marshal renders the AppConfig in the given format, JSON unless YAML is asked for. The YAML is
+// converted from the JSON, so both formats present the same keys from the same struct tags.
+func (c *AppConfig) Marshal(format string) ([]byte, error) {
+	data, err := json.Marshal(c)
+	if err != nil || format != FormatYAML {
+		return data, err
+	}
+	return yaml.JSONToYAML(data)
+}
+
+// Unmarshal reads an AppConfig rendered in either format by Marshal.
+func (c *AppConfig) Unmarshal(data []byte) error {
+	return yaml.Unmarshal(data, c)
+}
+
 // Populate sets the database configuration on the object from the passed in map.
 func (dbc *DatabaseConfig) Populate(data *map[string]string) error {
 	port, err := strconv.ParseUint((*data)["port"], 10, 16)
@@ -21,7 +53,60 @@ func (dbc *DatabaseConfig) Populate(data *map[string]string) error {
 	return nil
 }
 
+// SetDatabase presents the database to the app as its primary database and under the given name
+// in Databases, so that the two always match.
+func (c *AppConfig) SetDatabase(name string, dbc *DatabaseConfig) {
+	c.Database = dbc
+	c.Databases = map[string]DatabaseConfig{name: *dbc}
+}
+
 type DatabaseConfigContainer struct {
 	Config DatabaseConfig       `json:"config"`
 	Ref    types.NamespacedName `json:"ref"`
 }
+
+// secretKeys lists the JSON field names in the AppConfig that hold credentials.
+var secretKeys = map[string]bool{
+	"password":          true,
+	"adminPassword":     true,
+	"accessKeyId":       true,
+	"secretAccessKey":   true,
+	"accessKey":         true,
+	"secretKey":         true,
+	"clientAccessToken": true,
+	"sessionToken":      true,
+}
+
+// RedactedJSON returns the JSON representation of the AppConfig with all credentials stripped
+// out, leaving only the values that are safe to expose outside of a Secret.
+func (c *AppConfig) RedactedJSON() ([]byte, error) {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	return json.MarshalIndent(redact(raw), "", "  ")
+}
+
+func redact(obj interface{}) interface{} {
+	switch v := obj.(type) {
+	case map[string]interface{}:
+		for key, val := range v {
+			if secretKeys[key] {
+				delete(v, key)
+				continue
+			}
+			v[key] = redact(val)
+		}
+	case []interface{}:
+		for i, val := range v {
+			v[i] = redact(val)
+		}
+	}
+	return obj
+}
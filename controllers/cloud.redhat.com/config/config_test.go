@@ -1,6 +1,7 @@
 package config
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -26,3 +27,126 @@ func TestDbasePopulate(t *testing.T) {
 	assert.Equal(t, inputData["pgPass"], "pgPass", "they should be equal")
 	assert.Equal(t, inputData["name"], "name", "they should be equal")
 }
+
+func TestRedactedJSON(t *testing.T) {
+	password := "password"
+	config := &AppConfig{
+		Database: &DatabaseConfig{
+			Hostname:      "hostname",
+			Username:      "username",
+			Password:      password,
+			AdminUsername: "postgres",
+			AdminPassword: password,
+		},
+		Kafka: &KafkaConfig{
+			Brokers: []BrokerConfig{{
+				Hostname: "broker",
+				Sasl:     &KafkaSASLConfig{Username: &password, Password: &password},
+			}},
+		},
+		ObjectStore: &ObjectStoreConfig{
+			Hostname:  "minio",
+			AccessKey: &password,
+			SecretKey: &password,
+			Buckets:   []ObjectStoreBucket{{Name: "bucket", AccessKey: &password, SecretKey: &password}},
+			Scopes: []ObjectStoreScope{{
+				Name:         "reader",
+				Buckets:      []string{"bucket"},
+				AccessKey:    &password,
+				SecretKey:    &password,
+				SessionToken: &password,
+			}},
+		},
+	}
+
+	data, err := config.RedactedJSON()
+	assert.NoError(t, err)
+
+	redacted := map[string]interface{}{}
+	assert.NoError(t, json.Unmarshal(data, &redacted))
+
+	db := redacted["database"].(map[string]interface{})
+	assert.Equal(t, "hostname", db["hostname"])
+	assert.Equal(t, "username", db["username"])
+	assert.NotContains(t, db, "password")
+	assert.NotContains(t, db, "adminPassword")
+
+	broker := redacted["kafka"].(map[string]interface{})["brokers"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "broker", broker["hostname"])
+	assert.NotContains(t, broker["sasl"], "password")
+
+	objectStore := redacted["objectStore"].(map[string]interface{})
+	assert.NotContains(t, objectStore, "accessKey")
+	assert.NotContains(t, objectStore, "secretKey")
+	assert.NotContains(t, objectStore["buckets"].([]interface{})[0], "secretKey")
+
+	scope := objectStore["scopes"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "reader", scope["name"])
+	assert.NotContains(t, scope, "accessKey")
+	assert.NotContains(t, scope, "secretKey")
+	assert.NotContains(t, scope, "sessionToken")
+
+	assert.Equal(t, password, config.Database.Password, "original config should be untouched")
+}
+
+func TestSetDatabase(t *testing.T) {
+	config := &AppConfig{}
+	config.SetDatabase("inventory", &DatabaseConfig{Hostname: "inventory-db", Name: "inventory", Port: 5432})
+
+	assert.Equal(t, "inventory-db", config.Database.Hostname)
+	assert.Len(t, config.Databases, 1)
+	assert.Equal(t, *config.Database, config.Databases["inventory"])
+
+	// Both are written to the app config
+	data, err := json.Marshal(config)
+	assert.NoError(t, err)
+
+	loaded := &AppConfig{}
+	assert.NoError(t, json.Unmarshal(data, loaded))
+	assert.Equal(t, "inventory-db", loaded.Database.Hostname)
+	assert.Equal(t, "inventory-db", loaded.Databases["inventory"].Hostname)
+}
+
+func TestMarshalFormats(t *testing.T) {
+	webPort := 8000
+	config := &AppConfig{
+		WebPort:     &webPort,
+		MetricsPort: 9000,
+		MetricsPath: "/metrics",
+		Database: &DatabaseConfig{
+			Hostname: "hostname",
+			Port:     5432,
+			Username: "username",
+			Password: "password",
+		},
+		GlobalConfig: map[string]string{"region": "us-east-1"},
+	}
+
+	jsonData, err := config.Marshal(FormatJSON)
+	assert.NoError(t, err)
+	yamlData, err := config.Marshal(FormatYAML)
+	assert.NoError(t, err)
+
+	assert.True(t, json.Valid(jsonData))
+	assert.False(t, json.Valid(yamlData))
+	assert.Contains(t, string(yamlData), "metricsPort: 9000\n")
+
+	// Both formats read back to the same config
+	for _, data := range [][]byte{jsonData, yamlData} {
+		parsed := &AppConfig{}
+		assert.NoError(t, parsed.Unmarshal(data))
+		assert.Equal(t, config, parsed)
+	}
+
+	assert.Equal(t, "cdappconfig.json", FileName(FormatJSON))
+	assert.Equal(t, "cdappconfig.yaml", FileName(FormatYAML))
+}
+
+func TestObjectStoreForcePathStyleOptional(t *testing.T) {
+	// Configs written before forcePathStyle was added still load, and address buckets by hostname
+	c := &ObjectStoreConfig{}
+	assert.NoError(t, json.Unmarshal([]byte(`{"hostname": "minio", "port": 9000, "tls": false}`), c))
+	assert.False(t, c.ForcePathStyle)
+
+	assert.Error(t, json.Unmarshal([]byte(`{"hostname": "minio", "port": 9000}`), c))
+}
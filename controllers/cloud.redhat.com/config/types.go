@@ -74,24 +74,43 @@ type AppConfig struct {
 	// Database corresponds to the JSON schema field "database".
 	Database *DatabaseConfig `json:"database,omitempty"`
 
+	// The app's databases keyed by the name the app requests them by. The primary
+	// database is also presented as database.
+	Databases map[string]DatabaseConfig `json:"databases,omitempty"`
+
 	// Endpoints corresponds to the JSON schema field "endpoints".
 	Endpoints []DependencyEndpoint `json:"endpoints,omitempty"`
 
+	// The external hostname the app's public web services are served on. Only set
+	// when the app is exposed outside the cluster.
+	ExternalHostname *string `json:"externalHostname,omitempty"`
+
 	// FeatureFlags corresponds to the JSON schema field "featureFlags".
 	FeatureFlags *FeatureFlagsConfig `json:"featureFlags,omitempty"`
 
+	// Settings shared by every app in the environment, merged with the app's own,
+	// which take precedence.
+	GlobalConfig map[string]string `json:"globalConfig,omitempty"`
+
 	// A set of configMap/secret hashes
 	HashCache *string `json:"hashCache,omitempty"`
 
 	// InMemoryDb corresponds to the JSON schema field "inMemoryDb".
 	InMemoryDb *InMemoryDBConfig `json:"inMemoryDb,omitempty"`
 
+	// The named in-memory stores of the app.
+	InMemoryDbs []InMemoryDBConfig `json:"inMemoryDbs,omitempty"`
+
 	// Kafka corresponds to the JSON schema field "kafka".
 	Kafka *KafkaConfig `json:"kafka,omitempty"`
 
 	// Logging corresponds to the JSON schema field "logging".
 	Logging LoggingConfig `json:"logging"`
 
+	// Set while migrations run against the app's database. Apps should stop writing
+	// to the database until it is unset.
+	MaintenanceMode *bool `json:"maintenanceMode,omitempty"`
+
 	// Metadata corresponds to the JSON schema field "metadata".
 	Metadata *AppMetadata `json:"metadata,omitempty"`
 
@@ -120,6 +139,13 @@ type AppConfig struct {
 	// Defines the port CA path
 	TlsCAPath *string `json:"tlsCAPath,omitempty"`
 
+	// Tracing corresponds to the JSON schema field "tracing".
+	Tracing *TracingConfig `json:"tracing,omitempty"`
+
+	// The path to a bundle of CA certificates to trust for outbound TLS, in addition
+	// to the system's.
+	TrustedCAPath *string `json:"trustedCAPath,omitempty"`
+
 	// Deprecated: Use 'publicPort' instead.
 	WebPort *int `json:"webPort,omitempty"`
 }
@@ -243,6 +269,30 @@ func (j *ObjectStoreBucket) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
+// UnmarshalJSON implements json.Unmarshaler.
+func (j *ObjectStoreScope) UnmarshalJSON(b []byte) error {
+	var raw map[string]interface{}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	if v, ok := raw["buckets"]; !ok || v == nil {
+		return fmt.Errorf("field buckets: required")
+	}
+	if v, ok := raw["name"]; !ok || v == nil {
+		return fmt.Errorf("field name: required")
+	}
+	if v, ok := raw["readOnly"]; !ok || v == nil {
+		return fmt.Errorf("field readOnly: required")
+	}
+	type Plain ObjectStoreScope
+	var plain Plain
+	if err := json.Unmarshal(b, &plain); err != nil {
+		return err
+	}
+	*j = ObjectStoreScope(plain)
+	return nil
+}
+
 // Arbitrary metadata pertaining to the application application
 type AppMetadata struct {
 	// Metadata pertaining to an application's deployments
@@ -339,6 +389,24 @@ func (j *InMemoryDBConfig) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
+// UnmarshalJSON implements json.Unmarshaler.
+func (j *TracingConfig) UnmarshalJSON(b []byte) error {
+	var raw map[string]interface{}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	if v, ok := raw["endpoint"]; !ok || v == nil {
+		return fmt.Errorf("field endpoint: required")
+	}
+	type Plain TracingConfig
+	var plain Plain
+	if err := json.Unmarshal(b, &plain); err != nil {
+		return err
+	}
+	*j = TracingConfig(plain)
+	return nil
+}
+
 type BrokerConfigAuthtype string
 
 // UnmarshalJSON implements json.Unmarshaler.
@@ -477,6 +545,10 @@ type DatabaseConfig struct {
 	// Defines the hostname of the database configured for the ClowdApp.
 	Hostname string `json:"hostname"`
 
+	// Defines the max_connections of the database server, the most connections all
+	// of its clients may hold at once.
+	MaxConnections *int `json:"maxConnections,omitempty"`
+
 	// Defines the database name.
 	Name string `json:"name"`
 
@@ -545,9 +617,15 @@ const FeatureFlagsConfigSchemeHttps FeatureFlagsConfigScheme = "https"
 
 // In Memory DB Configuration
 type InMemoryDBConfig struct {
+	// Defines the index of the logical database the app should use.
+	DbIndex *int `json:"dbIndex,omitempty"`
+
 	// Defines the hostname for the In Memory DB server configuration.
 	Hostname string `json:"hostname"`
 
+	// Defines the name of the store, for a named in-memory store.
+	Name *string `json:"name,omitempty"`
+
 	// Defines the password for the In Memory DB server configuration.
 	Password *string `json:"password,omitempty"`
 
@@ -617,12 +695,23 @@ type ObjectStoreConfig struct {
 	// Buckets corresponds to the JSON schema field "buckets".
 	Buckets []ObjectStoreBucket `json:"buckets,omitempty"`
 
+	// The PEM encoded CA certificates to trust when connecting to the Object Storage
+	// server over TLS.
+	CaCert *string `json:"caCert,omitempty"`
+
+	// Details if clients must address buckets in the path of their requests rather
+	// than in the hostname. Defaults to false.
+	ForcePathStyle bool `json:"forcePathStyle"`
+
 	// Defines the hostname for the Object Storage server configuration.
 	Hostname string `json:"hostname"`
 
 	// Defines the port for the Object Storage server configuration.
 	Port int `json:"port"`
 
+	// Defines the named credential scopes requested by the app.
+	Scopes []ObjectStoreScope `json:"scopes,omitempty"`
+
 	// Defines the secret key for the Object Storage server configuration.
 	SecretKey *string `json:"secretKey,omitempty"`
 
@@ -630,6 +719,27 @@ type ObjectStoreConfig struct {
 	Tls bool `json:"tls"`
 }
 
+// Object Storage Credential Scope
+type ObjectStoreScope struct {
+	// Defines the access key for the specified scope.
+	AccessKey *string `json:"accessKey,omitempty"`
+
+	// The buckets the scope's credentials grant access to.
+	Buckets []string `json:"buckets"`
+
+	// The name of the scope, as requested in the ClowdApp.
+	Name string `json:"name"`
+
+	// Details if the scope's credentials only allow reading objects.
+	ReadOnly bool `json:"readOnly"`
+
+	// Defines the secret key for the specified scope.
+	SecretKey *string `json:"secretKey,omitempty"`
+
+	// Defines the session token that must accompany the keys, if any.
+	SessionToken *string `json:"sessionToken,omitempty"`
+}
+
 // Dependent service connection info
 type PrivateDependencyEndpoint struct {
 	// The app name of the ClowdApp hosting the service.
@@ -657,6 +767,16 @@ type TopicConfig struct {
 	RequestedName string `json:"requestedName"`
 }
 
+// Tracing Configuration
+type TracingConfig struct {
+	// Defines the OTLP gRPC endpoint of the OpenTelemetry collector the app should
+	// export traces to.
+	Endpoint string `json:"endpoint"`
+
+	// Defines the service name the app should report its traces under.
+	ServiceName *string `json:"serviceName,omitempty"`
+}
+
 var enumValues_BrokerConfigAuthtype = []interface{}{
 	"mtls",
 	"sasl",
@@ -5,6 +5,7 @@ import (
 	errlib "errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/go-logr/logr"
 	"go.uber.org/zap"
@@ -108,6 +109,68 @@ func (e *MissingDependencies) Error() string {
 	return fmt.Sprintf("Missing dependencies: [%s]", body)
 }
 
+// ProviderTimeout is returned when a provider failed after running past its deadline, usually
+// because a call it made to an external API was cancelled.
+type ProviderTimeout struct {
+	Provider string
+	Timeout  time.Duration
+	Cause    error
+}
+
+// Error returns a string representation of the provider timeout
+func (e *ProviderTimeout) Error() string {
+	return fmt.Sprintf("provider %s timed out after %s: %s", e.Provider, e.Timeout, e.Cause)
+}
+
+func (e *ProviderTimeout) Unwrap() error {
+	return e.Cause
+}
+
+// MissingProviders is returned when an app requires providers that its environment does not
+// offer. Each entry names a provider along with the mode it is in.
+type MissingProviders struct {
+	Env       string
+	Providers []string
+}
+
+// Error returns a string representation of the missing providers
+func (e *MissingProviders) Error() string {
+	return fmt.Sprintf("environment %s does not offer required providers: %s", e.Env, strings.Join(e.Providers, ", "))
+}
+
+// DatabaseImageMissing is returned when there is no image to run a local database of the
+// requested PostgreSQL version with.
+type DatabaseImageMissing struct {
+	Env     string
+	Version int32
+}
+
+// Error returns a string representation of the missing database image
+func (e *DatabaseImageMissing) Error() string {
+	return fmt.Sprintf("no database image is set for PostgreSQL %d", e.Version)
+}
+
+// KafkaTopicCreationFailed is returned when a topic could not be created on the Kafka cluster.
+// Transient is set when the cluster was unavailable on every attempt, so that the creation may
+// succeed on a later reconcile, and unset when the cluster rejected the topic. RetryAfter is set
+// while retries remain, to how long is left before the next one is due.
+type KafkaTopicCreationFailed struct {
+	Topic      string
+	Attempts   int
+	Transient  bool
+	RetryAfter time.Duration
+	Cause      error
+}
+
+// Error returns a string representation of the failed topic creation
+func (e *KafkaTopicCreationFailed) Error() string {
+	return fmt.Sprintf("creating topic %s failed after %d attempts: %s", e.Topic, e.Attempts, e.Cause)
+}
+
+func (e *KafkaTopicCreationFailed) Unwrap() error {
+	return e.Cause
+}
+
 // RootCause takes an error an unwraps it, if it is nil, it calls RootCause on the returned err,
 // this will recursively find an error that has an unwrapped value.
 func RootCause(err error) error {
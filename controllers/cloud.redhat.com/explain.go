@@ -0,0 +1,282 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	crd "github.com/RedHatInsights/clowder/apis/cloud.redhat.com/v1alpha1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// ExplainAnnotation is set to "true" on a ClowdApp to have the reasons its reconciles change an
+// object recorded on the object.
+const ExplainAnnotation = "clowder/explain"
+
+// ChangeReasonAnnotation holds the reasons the last reconcile that changed an object changed it.
+const ChangeReasonAnnotation = "clowder/change-reason"
+
+// maxChangeReasons is how many reasons are given for a change before the rest are counted.
+const maxChangeReasons = 5
+
+// explainClient records why each write made through it changes its object, in an annotation on
+// the object and as an event on it. The object as the cluster had it is the one last read through
+// the client, which the resource cache does for every object before a provider modifies it.
+type explainClient struct {
+	client.Client
+	recorder  record.EventRecorder
+	originals map[string]client.Object
+}
+
+// newExplainClient wraps the client for a reconcile of the app, if the app asks for its changes
+// to be explained. Otherwise the client is returned as is.
+func newExplainClient(c client.Client, recorder record.EventRecorder, app *crd.ClowdApp) client.Client {
+	if app.GetAnnotations()[ExplainAnnotation] != "true" {
+		return c
+	}
+	return &explainClient{Client: c, recorder: recorder, originals: map[string]client.Object{}}
+}
+
+func (e *explainClient) key(obj client.Object) (string, error) {
+	gvk, err := apiutil.GVKForObject(obj, e.Scheme())
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s/%s/%s", gvk.String(), obj.GetNamespace(), obj.GetName()), nil
+}
+
+func (e *explainClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	if err := e.Client.Get(ctx, key, obj, opts...); err != nil {
+		return err
+	}
+	if k, err := e.key(obj); err == nil {
+		e.originals[k] = obj.DeepCopyObject().(client.Object)
+	}
+	return nil
+}
+
+func (e *explainClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	setChangeReason(obj, "created")
+	if err := e.Client.Create(ctx, obj, opts...); err != nil {
+		return err
+	}
+	e.recorder.Event(obj, "Normal", "Changed", "created")
+	return nil
+}
+
+func (e *explainClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	k, err := e.key(obj)
+	original, ok := e.originals[k]
+	if err != nil || !ok {
+		return e.Client.Update(ctx, obj, opts...)
+	}
+
+	// The object as written lacks the fields the server defaults, so what changed is only known
+	// once the server has returned it. It is written with the reason it had, and the reason is
+	// only replaced if the write moved its resourceVersion and the server's copy differs.
+	setChangeReason(obj, original.GetAnnotations()[ChangeReasonAnnotation])
+	if err := e.Client.Update(ctx, obj, opts...); err != nil {
+		return err
+	}
+	e.originals[k] = obj.DeepCopyObject().(client.Object)
+	if obj.GetResourceVersion() == original.GetResourceVersion() {
+		return nil
+	}
+
+	reasons := explainChange(original, obj)
+	if len(reasons) == 0 {
+		return nil
+	}
+
+	reason := joinReasons(reasons)
+	patch := client.MergeFrom(obj.DeepCopyObject().(client.Object))
+	setChangeReason(obj, reason)
+	if err := e.Client.Patch(ctx, obj, patch); err != nil {
+		return err
+	}
+	e.originals[k] = obj.DeepCopyObject().(client.Object)
+	e.recorder.Event(obj, "Normal", "Changed", reason)
+	return nil
+}
+
+func setChangeReason(obj client.Object, reason string) {
+	annotations := obj.GetAnnotations()
+	if reason == "" {
+		delete(annotations, ChangeReasonAnnotation)
+		obj.SetAnnotations(annotations)
+		return
+	}
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[ChangeReasonAnnotation] = reason
+	obj.SetAnnotations(annotations)
+}
+
+func joinReasons(reasons []string) string {
+	if len(reasons) > maxChangeReasons {
+		more := len(reasons) - maxChangeReasons
+		reasons = append(reasons[:maxChangeReasons:maxChangeReasons], fmt.Sprintf("%d more changes", more))
+	}
+	return strings.Join(reasons, "; ")
+}
+
+// explainChange returns a short reason for each part of the object that differs from the
+// original, in a stable order. The images and config hash of pod templates are called out, as
+// they are what usually restarts an app's pods; the values of any other fields are left out, so
+// that the contents of secrets never end up in a reason.
+func explainChange(original client.Object, obj client.Object) []string {
+	before, err := runtime.DefaultUnstructuredConverter.ToUnstructured(original)
+	if err != nil {
+		return nil
+	}
+	after, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil
+	}
+
+	reasons := []string{}
+
+	if !reflect.DeepEqual(original.GetLabels(), obj.GetLabels()) {
+		reasons = append(reasons, "labels changed")
+	}
+	if !reflect.DeepEqual(withoutChangeReason(original.GetAnnotations()), withoutChangeReason(obj.GetAnnotations())) {
+		reasons = append(reasons, "annotations changed")
+	}
+
+	for _, field := range changedKeys(before, after, "apiVersion", "kind", "metadata", "status") {
+		spec, isMap := after[field].(map[string]interface{})
+		oldSpec, wasMap := before[field].(map[string]interface{})
+		if field != "spec" || !isMap || !wasMap {
+			reasons = append(reasons, fmt.Sprintf("%s changed", field))
+			continue
+		}
+		reasons = append(reasons, explainSpecChange(oldSpec, spec)...)
+	}
+
+	return reasons
+}
+
+// explainSpecChange gives the reasons for the changes to a spec, calling out the changes to its
+// pod template if it has one.
+func explainSpecChange(before map[string]interface{}, after map[string]interface{}) []string {
+	reasons := []string{}
+	for _, field := range changedKeys(before, after) {
+		oldTemplate, wasMap := before[field].(map[string]interface{})
+		template, isMap := after[field].(map[string]interface{})
+		if field == "template" && wasMap && isMap {
+			reasons = append(reasons, explainTemplateChange(oldTemplate, template)...)
+			continue
+		}
+		if field == "replicas" {
+			reasons = append(reasons, fmt.Sprintf("replicas changed from %v to %v", before[field], after[field]))
+			continue
+		}
+		reasons = append(reasons, fmt.Sprintf("spec.%s changed", field))
+	}
+	return reasons
+}
+
+// explainTemplateChange gives the reasons for the changes to a pod template.
+func explainTemplateChange(before map[string]interface{}, after map[string]interface{}) []string {
+	reasons := []string{}
+
+	oldHash := nestedString(before, "metadata", "annotations", "configHash")
+	hash := nestedString(after, "metadata", "annotations", "configHash")
+	if oldHash != hash {
+		reasons = append(reasons, "config hash changed")
+	}
+
+	oldImages := containerImages(before)
+	images := containerImages(after)
+	names := []string{}
+	for name := range images {
+		names = append(names, name)
+	}
+	for name := range oldImages {
+		if _, ok := images[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		oldImage, had := oldImages[name]
+		image, has := images[name]
+		switch {
+		case !had:
+			reasons = append(reasons, fmt.Sprintf("container %s added", name))
+		case !has:
+			reasons = append(reasons, fmt.Sprintf("container %s removed", name))
+		case oldImage != image:
+			reasons = append(reasons, fmt.Sprintf("image of container %s changed from %s to %s", name, oldImage, image))
+		}
+	}
+
+	if len(reasons) == 0 {
+		reasons = append(reasons, "pod template changed")
+	}
+	return reasons
+}
+
+// containerImages returns the image of each of the pod template's containers and init
+// containers, by container name.
+func containerImages(template map[string]interface{}) map[string]string {
+	images := map[string]string{}
+	spec, _ := template["spec"].(map[string]interface{})
+	for _, field := range []string{"initContainers", "containers"} {
+		containers, _ := spec[field].([]interface{})
+		for _, c := range containers {
+			container, _ := c.(map[string]interface{})
+			name, _ := container["name"].(string)
+			image, _ := container["image"].(string)
+			images[name] = image
+		}
+	}
+	return images
+}
+
+func nestedString(m map[string]interface{}, fields ...string) string {
+	for _, field := range fields[:len(fields)-1] {
+		m, _ = m[field].(map[string]interface{})
+	}
+	value, _ := m[fields[len(fields)-1]].(string)
+	return value
+}
+
+// changedKeys returns the keys whose values differ between the two maps, in order, leaving out
+// those that are ignored.
+func changedKeys(before map[string]interface{}, after map[string]interface{}, ignored ...string) []string {
+	skip := map[string]bool{}
+	for _, key := range ignored {
+		skip[key] = true
+	}
+
+	keys := []string{}
+	for key := range after {
+		if !skip[key] && !reflect.DeepEqual(before[key], after[key]) {
+			keys = append(keys, key)
+		}
+	}
+	for key := range before {
+		if _, ok := after[key]; !ok && !skip[key] {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func withoutChangeReason(annotations map[string]string) map[string]string {
+	out := map[string]string{}
+	for key, value := range annotations {
+		if key != ChangeReasonAnnotation {
+			out[key] = value
+		}
+	}
+	return out
+}
@@ -0,0 +1,131 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	crd "github.com/RedHatInsights/clowder/apis/cloud.redhat.com/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	apps "k8s.io/api/apps/v1"
+	core "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func explainDeployment(image string, hash string, replicas int32) *apps.Deployment {
+	d := &apps.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "puptoo-processor", Namespace: "test"}}
+	d.Spec.Replicas = &replicas
+	d.Spec.Template.Annotations = map[string]string{"configHash": hash}
+	d.Spec.Template.Spec.Containers = []core.Container{{Name: "puptoo-processor", Image: image}}
+	return d
+}
+
+func TestExplainChange(t *testing.T) {
+	old := explainDeployment("quay.io/puptoo:1", "abc", 1)
+
+	assert.Equal(t, []string{}, explainChange(old, old.DeepCopy()))
+
+	d := explainDeployment("quay.io/puptoo:2", "def", 3)
+	assert.Equal(t, []string{
+		"replicas changed from 1 to 3",
+		"config hash changed",
+		"image of container puptoo-processor changed from quay.io/puptoo:1 to quay.io/puptoo:2",
+	}, explainChange(old, d))
+
+	d = explainDeployment("quay.io/puptoo:1", "abc", 1)
+	d.Spec.Template.Spec.Containers[0].Env = []core.EnvVar{{Name: "LOG_LEVEL", Value: "debug"}}
+	d.Labels = map[string]string{"app": "puptoo"}
+	assert.Equal(t, []string{"labels changed", "pod template changed"}, explainChange(old, d))
+
+	// The values of a secret are never given
+	secret := &core.Secret{Data: map[string][]byte{"password": []byte("hunter2")}}
+	changed := secret.DeepCopy()
+	changed.Data["password"] = []byte("hunter3")
+	assert.Equal(t, []string{"data changed"}, explainChange(secret, changed))
+}
+
+func TestExplainClient(t *testing.T) {
+	ctx := context.Background()
+	cluster := fake.NewClientBuilder().WithScheme(Scheme).Build()
+	recorder := record.NewFakeRecorder(10)
+	key := types.NamespacedName{Name: "puptoo-processor", Namespace: "test"}
+
+	// Apps that don't ask for explanations get the client as it is
+	app := &crd.ClowdApp{ObjectMeta: metav1.ObjectMeta{Name: "puptoo", Namespace: "test"}}
+	assert.Equal(t, cluster, newExplainClient(cluster, recorder, app))
+
+	app.Annotations = map[string]string{ExplainAnnotation: "true"}
+	c := newExplainClient(cluster, recorder, app)
+	assert.NoError(t, c.Create(ctx, explainDeployment("quay.io/puptoo:1", "abc", 1)))
+	assert.Equal(t, "Normal Changed created", <-recorder.Events)
+
+	d := &apps.Deployment{}
+	assert.NoError(t, c.Get(ctx, key, d))
+	assert.Equal(t, "created", d.Annotations[ChangeReasonAnnotation])
+	d.Spec.Template.Spec.Containers[0].Image = "quay.io/puptoo:2"
+	assert.NoError(t, c.Update(ctx, d))
+	assert.Equal(t, "Normal Changed image of container puptoo-processor changed from quay.io/puptoo:1 to quay.io/puptoo:2", <-recorder.Events)
+
+	d = &apps.Deployment{}
+	assert.NoError(t, cluster.Get(ctx, key, d))
+	assert.Equal(t, "image of container puptoo-processor changed from quay.io/puptoo:1 to quay.io/puptoo:2", d.Annotations[ChangeReasonAnnotation])
+
+	// A write that doesn't change anything keeps the last reason and records nothing
+	c = newExplainClient(cluster, recorder, app)
+	d = &apps.Deployment{}
+	assert.NoError(t, c.Get(ctx, key, d))
+	d.Annotations = nil
+	assert.NoError(t, c.Update(ctx, d))
+	assert.Empty(t, recorder.Events)
+	assert.Equal(t, "image of container puptoo-processor changed from quay.io/puptoo:1 to quay.io/puptoo:2", d.Annotations[ChangeReasonAnnotation])
+}
+
+// defaultingClient fills in the termination message path of containers on update, as the API
+// server does.
+type defaultingClient struct {
+	client.Client
+}
+
+func (c *defaultingClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	if d, ok := obj.(*apps.Deployment); ok {
+		for i := range d.Spec.Template.Spec.Containers {
+			if d.Spec.Template.Spec.Containers[i].TerminationMessagePath == "" {
+				d.Spec.Template.Spec.Containers[i].TerminationMessagePath = core.TerminationMessagePathDefault
+			}
+		}
+	}
+	return c.Client.Update(ctx, obj, opts...)
+}
+
+func TestExplainClientNoopReconcile(t *testing.T) {
+	ctx := context.Background()
+	cluster := &defaultingClient{Client: fake.NewClientBuilder().WithScheme(Scheme).Build()}
+	recorder := record.NewFakeRecorder(10)
+	key := types.NamespacedName{Name: "puptoo-processor", Namespace: "test"}
+	app := &crd.ClowdApp{ObjectMeta: metav1.ObjectMeta{Name: "puptoo", Namespace: "test", Annotations: map[string]string{ExplainAnnotation: "true"}}}
+
+	d := explainDeployment("quay.io/puptoo:1", "abc", 1)
+	d.Spec.Template.Spec.Containers[0].TerminationMessagePath = core.TerminationMessagePathDefault
+	assert.NoError(t, newExplainClient(cluster, recorder, app).Create(ctx, d))
+	<-recorder.Events
+
+	// A reconcile rebuilds the containers without the fields the server defaulted
+	c := newExplainClient(cluster, recorder, app)
+	d = &apps.Deployment{}
+	assert.NoError(t, c.Get(ctx, key, d))
+	d.Spec.Template.Spec.Containers = explainDeployment("quay.io/puptoo:1", "abc", 1).Spec.Template.Spec.Containers
+	assert.NoError(t, c.Update(ctx, d))
+	assert.Empty(t, recorder.Events)
+
+	d = &apps.Deployment{}
+	assert.NoError(t, cluster.Get(ctx, key, d))
+	assert.Equal(t, "created", d.Annotations[ChangeReasonAnnotation])
+}
+
+func TestJoinReasons(t *testing.T) {
+	assert.Equal(t, "a; b", joinReasons([]string{"a", "b"}))
+	assert.Equal(t, "a; b; c; d; e; 2 more changes", joinReasons([]string{"a", "b", "c", "d", "e", "f", "g"}))
+}
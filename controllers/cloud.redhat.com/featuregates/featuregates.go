@@ -0,0 +1,157 @@
+// Package featuregates holds the gates that switch Clowder's providers and behaviors on or off
+// when the operator starts, so that new features can be rolled out progressively.
+package featuregates
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Stage is how far a feature has matured.
+type Stage string
+
+const (
+	// Alpha features are experimental and off unless enabled.
+	Alpha Stage = "Alpha"
+	// Beta features are on by default, but can still be disabled.
+	Beta Stage = "Beta"
+	// GA features are always on, and their gates are removed in a later release.
+	GA Stage = "GA"
+)
+
+// Gate names a feature that can be switched on or off.
+type Gate string
+
+const (
+	// ObjectStore runs the object store provider.
+	ObjectStore Gate = "ObjectStore"
+	// FeatureFlags runs the feature flags provider.
+	FeatureFlags Gate = "FeatureFlags"
+	// DeploymentRolloutOrder rolls out the deployments of an app in the order of their dependsOn.
+	// The rollout provider runs regardless, so that it resumes the deployments it paused once
+	// the gate is disabled.
+	DeploymentRolloutOrder Gate = "DeploymentRolloutOrder"
+	// NamedServices creates the additional services of deployments.
+	NamedServices Gate = "NamedServices"
+)
+
+// GateSpec describes a gate.
+type GateSpec struct {
+	// Whether the feature is on unless the gate is set.
+	Default bool
+	// The maturity of the feature.
+	Stage Stage
+	// The name of the provider that only runs while the feature is on, if any.
+	Provider string
+}
+
+// knownGates are the gates Clowder understands.
+var knownGates = map[Gate]GateSpec{
+	ObjectStore:            {Default: true, Stage: Beta, Provider: "objectstore"},
+	FeatureFlags:           {Default: true, Stage: Beta, Provider: "featureflags"},
+	DeploymentRolloutOrder: {Default: false, Stage: Alpha},
+	NamedServices:          {Default: false, Stage: Alpha, Provider: "namedservice"},
+}
+
+// FeatureGates holds the gates that were set, and answers for the rest from their defaults. It
+// implements flag.Value, taking a comma separated list of Gate=bool pairs.
+type FeatureGates struct {
+	mu      sync.RWMutex
+	enabled map[Gate]bool
+}
+
+// New returns feature gates with every gate at its default.
+func New() *FeatureGates {
+	return &FeatureGates{enabled: map[Gate]bool{}}
+}
+
+// Gates are the feature gates Clowder was started with.
+var Gates = New()
+
+// Set parses a list of gates such as "ObjectStore=false,NamedServices=true". Unknown gates,
+// values that are not booleans and disabling a GA feature are errors.
+func (f *FeatureGates) Set(value string) error {
+	enabled := map[Gate]bool{}
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		name, val, ok := strings.Cut(pair, "=")
+		if !ok {
+			return fmt.Errorf("feature gate %q is not of the form Gate=true|false", pair)
+		}
+
+		gate := Gate(strings.TrimSpace(name))
+		spec, known := knownGates[gate]
+		if !known {
+			return fmt.Errorf("unknown feature gate %s", gate)
+		}
+
+		on, err := strconv.ParseBool(strings.TrimSpace(val))
+		if err != nil {
+			return fmt.Errorf("feature gate %s has invalid value %q", gate, val)
+		}
+
+		if spec.Stage == GA && !on {
+			return fmt.Errorf("feature gate %s is GA and cannot be disabled", gate)
+		}
+		enabled[gate] = on
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for gate, on := range enabled {
+		f.enabled[gate] = on
+	}
+	return nil
+}
+
+// String returns the gates that were set, in the form Set takes.
+func (f *FeatureGates) String() string {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	pairs := []string{}
+	for gate, on := range f.enabled {
+		pairs = append(pairs, fmt.Sprintf("%s=%t", gate, on))
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// Enabled returns whether the feature is on.
+func (f *FeatureGates) Enabled(gate Gate) bool {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	if on, ok := f.enabled[gate]; ok {
+		return on
+	}
+	return knownGates[gate].Default
+}
+
+// ProviderEnabled returns whether the named provider may run. Providers without a gate always
+// may.
+func (f *FeatureGates) ProviderEnabled(provider string) bool {
+	for gate, spec := range knownGates {
+		if spec.Provider == provider {
+			return f.Enabled(gate)
+		}
+	}
+	return true
+}
+
+// Usage describes the known gates, for the help of the flag.
+func Usage() string {
+	gates := []string{}
+	for gate, spec := range knownGates {
+		gates = append(gates, fmt.Sprintf("%s=true|false (%s - default=%t)", gate, spec.Stage, spec.Default))
+	}
+	sort.Strings(gates)
+	return "A set of key=value pairs that switch features on or off. Options are:\n" + strings.Join(gates, "\n")
+}
@@ -0,0 +1,40 @@
+package featuregates
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFeatureGates(t *testing.T) {
+	gates := New()
+	assert.True(t, gates.Enabled(ObjectStore))
+	assert.False(t, gates.Enabled(NamedServices))
+	assert.True(t, gates.ProviderEnabled("objectstore"))
+	assert.False(t, gates.ProviderEnabled("namedservice"))
+	assert.True(t, gates.ProviderEnabled("deployment"))
+
+	assert.NoError(t, gates.Set("ObjectStore=false, NamedServices=true"))
+	assert.False(t, gates.Enabled(ObjectStore))
+	assert.False(t, gates.ProviderEnabled("objectstore"))
+	assert.True(t, gates.ProviderEnabled("namedservice"))
+	assert.Equal(t, "NamedServices=true,ObjectStore=false", gates.String())
+
+	assert.NoError(t, gates.Set(""))
+	assert.Equal(t, "NamedServices=true,ObjectStore=false", gates.String())
+}
+
+func TestFeatureGatesInvalid(t *testing.T) {
+	knownGates["Graduated"] = GateSpec{Default: true, Stage: GA}
+	defer delete(knownGates, "Graduated")
+
+	gates := New()
+	assert.ErrorContains(t, gates.Set("Unknown=true"), "unknown feature gate Unknown")
+	assert.ErrorContains(t, gates.Set("ObjectStore"), "is not of the form Gate=true|false")
+	assert.ErrorContains(t, gates.Set("ObjectStore=maybe"), `feature gate ObjectStore has invalid value "maybe"`)
+	assert.ErrorContains(t, gates.Set("Graduated=false"), "feature gate Graduated is GA and cannot be disabled")
+
+	// Nothing is applied from a list with an invalid gate
+	assert.Error(t, gates.Set("NamedServices=true,Unknown=true"))
+	assert.False(t, gates.Enabled(NamedServices))
+}
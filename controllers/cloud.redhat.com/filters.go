@@ -9,6 +9,7 @@ import (
 	strimzi "github.com/RedHatInsights/strimzi-client-go/apis/kafka.strimzi.io/v1beta2"
 	"github.com/go-logr/logr"
 	apps "k8s.io/api/apps/v1"
+	batch "k8s.io/api/batch/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
@@ -66,6 +67,29 @@ func deploymentUpdateFunc(e event.UpdateEvent) bool {
 	return false
 }
 
+// jobUpdateFunc reconciles when a job's pods succeed or fail, so that its outcome is reflected in
+// the status of its owner.
+func jobUpdateFunc(e event.UpdateEvent) bool {
+	objOld := e.ObjectOld.(*batch.Job)
+	objNew := e.ObjectNew.(*batch.Job)
+	if objNew.GetGeneration() != objOld.GetGeneration() {
+		return true
+	}
+	return objOld.Status.Succeeded != objNew.Status.Succeeded || objOld.Status.Failed != objNew.Status.Failed
+}
+
+func daemonSetUpdateFunc(e event.UpdateEvent) bool {
+	objOld := e.ObjectOld.(*apps.DaemonSet)
+	objNew := e.ObjectNew.(*apps.DaemonSet)
+	if objNew.GetGeneration() != objOld.GetGeneration() {
+		return true
+	}
+	if objOld.Status.NumberAvailable != objNew.Status.NumberAvailable {
+		return true
+	}
+	return objOld.Status.DesiredNumberScheduled != objNew.Status.DesiredNumberScheduled
+}
+
 func kafkaUpdateFunc(e event.UpdateEvent) bool {
 	objOld := e.ObjectOld.(*strimzi.Kafka)
 	objNew := e.ObjectNew.(*strimzi.Kafka)
@@ -114,6 +138,14 @@ func deploymentFilter(logr logr.Logger, ctrlName string) HandlerFuncs {
 	return genFilterFunc(deploymentUpdateFunc, logr, ctrlName)
 }
 
+func daemonSetFilter(logr logr.Logger, ctrlName string) HandlerFuncs {
+	return genFilterFunc(daemonSetUpdateFunc, logr, ctrlName)
+}
+
+func jobFilter(logr logr.Logger, ctrlName string) HandlerFuncs {
+	return genFilterFunc(jobUpdateFunc, logr, ctrlName)
+}
+
 func kafkaFilter(logr logr.Logger, ctrlName string) HandlerFuncs {
 	return genFilterFunc(kafkaUpdateFunc, logr, ctrlName)
 }
@@ -182,6 +182,20 @@ func (hc *HashCache) AddClowdObjectToObject(clowdObj object.ClowdObject, obj cli
 		return nil
 	}
 
+	return hc.addClowdObjectToObject(clowdObj, obj)
+}
+
+// AddDependencyToObject hashes an object that a ClowdObject explicitly depends on and records the
+// dependency, whether or not the object carries the restarter annotation.
+func (hc *HashCache) AddDependencyToObject(clowdObj object.ClowdObject, obj client.Object) error {
+	if _, err := hc.CreateOrUpdateObject(obj); err != nil {
+		return err
+	}
+
+	return hc.addClowdObjectToObject(clowdObj, obj)
+}
+
+func (hc *HashCache) addClowdObjectToObject(clowdObj object.ClowdObject, obj client.Object) error {
 	var oType string
 
 	switch obj.(type) {
@@ -130,6 +130,46 @@ func TestHashCacheAddClowdObj(t *testing.T) {
 	assert.Contains(t, obj.ClowdApps, clowdObjNamespaceName)
 }
 
+func TestHashCacheAddDependency(t *testing.T) {
+	cm := &core.ConfigMap{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      "external",
+			Namespace: "def",
+		},
+		Data: map[string]string{"test": "test"},
+	}
+
+	capp := &crd.ClowdApp{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      "testapp",
+			Namespace: "def",
+		},
+	}
+
+	hc := NewHashCache()
+	before := hc.GetSuperHashForClowdObject(capp)
+
+	// Without the restarter annotation the object is only tracked when declared as a dependency
+	err := hc.AddClowdObjectToObject(capp, cm)
+	assert.NoError(t, err)
+	_, err = hc.Read(cm)
+	assert.ErrorIs(t, err, ItemNotFoundError{item: "external/def"})
+
+	err = hc.AddDependencyToObject(capp, cm)
+	assert.NoError(t, err)
+	obj, err := hc.Read(cm)
+	assert.NoError(t, err)
+	assert.Contains(t, obj.ClowdApps, types.NamespacedName{Name: "testapp", Namespace: "def"})
+
+	first := hc.GetSuperHashForClowdObject(capp)
+	assert.NotEqual(t, before, first)
+
+	cm.Data = map[string]string{"test": "changed"}
+	err = hc.AddDependencyToObject(capp, cm)
+	assert.NoError(t, err)
+	assert.NotEqual(t, first, hc.GetSuperHashForClowdObject(capp))
+}
+
 func TestHashCacheDeleteClowdObj(t *testing.T) {
 	sec := &core.Secret{
 		ObjectMeta: v1.ObjectMeta{
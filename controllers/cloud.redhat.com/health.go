@@ -0,0 +1,70 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	crd "github.com/RedHatInsights/clowder/apis/cloud.redhat.com/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+// cacheSyncTimeout bounds how long a readiness probe will wait on the informer
+// caches before reporting them as not synced.
+const cacheSyncTimeout = 2 * time.Second
+
+// requiredKinds are the Clowder kinds that must be served by the API server
+// for the operator to do any useful work.
+var requiredKinds = []string{"ClowdApp", "ClowdEnvironment", "ClowdJobInvocation"}
+
+// crdCheck returns a checker that fails if any of the Clowder CRDs are not
+// installed in the cluster.
+func crdCheck(mapper meta.RESTMapper) healthz.Checker {
+	return func(_ *http.Request) error {
+		for _, kind := range requiredKinds {
+			gk := schema.GroupKind{Group: crd.GroupVersion.Group, Kind: kind}
+			if _, err := mapper.RESTMapping(gk, crd.GroupVersion.Version); err != nil {
+				return fmt.Errorf("crd for %s not installed: %w", kind, err)
+			}
+		}
+		return nil
+	}
+}
+
+// cacheSyncCheck returns a checker that fails until the manager's informer
+// caches have synced.
+func cacheSyncCheck(c cache.Cache, timeout time.Duration) healthz.Checker {
+	return func(req *http.Request) error {
+		ctx, cancel := context.WithTimeout(req.Context(), timeout)
+		defer cancel()
+		if !c.WaitForCacheSync(ctx) {
+			return fmt.Errorf("informer caches not synced")
+		}
+		return nil
+	}
+}
+
+func addHealthChecks(mgr manager.Manager) error {
+	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
+		setupLog.Error(err, "unable to set up health check")
+		return err
+	}
+	if err := mgr.AddReadyzCheck("readyz", healthz.Ping); err != nil {
+		setupLog.Error(err, "unable to set up ready check")
+		return err
+	}
+	if err := mgr.AddReadyzCheck("crds", crdCheck(mgr.GetRESTMapper())); err != nil {
+		setupLog.Error(err, "unable to set up crd check")
+		return err
+	}
+	if err := mgr.AddReadyzCheck("informers", cacheSyncCheck(mgr.GetCache(), cacheSyncTimeout)); err != nil {
+		setupLog.Error(err, "unable to set up informer check")
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,23 @@
+package controllers
+
+import (
+	"net/http"
+	"testing"
+
+	crd "github.com/RedHatInsights/clowder/apis/cloud.redhat.com/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestCRDCheck(t *testing.T) {
+	mapper := meta.NewDefaultRESTMapper([]schema.GroupVersion{crd.GroupVersion})
+	req, _ := http.NewRequest(http.MethodGet, "/readyz", nil)
+
+	assert.Error(t, crdCheck(mapper)(req))
+
+	for _, kind := range requiredKinds {
+		mapper.Add(crd.GroupVersion.WithKind(kind), meta.RESTScopeNamespace)
+	}
+	assert.NoError(t, crdCheck(mapper)(req))
+}
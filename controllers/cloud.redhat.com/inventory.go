@@ -0,0 +1,87 @@
+package controllers
+
+import (
+	"context"
+	"sort"
+
+	crd "github.com/RedHatInsights/clowder/apis/cloud.redhat.com/v1alpha1"
+	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/errors"
+	rc "github.com/RedHatInsights/rhc-osdk-utils/resourceCache"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// GetAppInventory lists the objects of the given kinds that the app owns, the same way the
+// resource cache finds the objects it no longer needs, sorted by kind, namespace and name.
+func GetAppInventory(ctx context.Context, c client.Client, app *crd.ClowdApp, gvks rc.GVKMap) ([]crd.ManagedObject, error) {
+	opts := []client.ListOption{
+		client.MatchingLabels{app.GetPrimaryLabel(): app.GetClowdName()},
+		client.InNamespace(app.Namespace),
+	}
+
+	inventory := []crd.ManagedObject{}
+	for gvk := range gvks {
+		objList := unstructured.UnstructuredList{}
+		objList.SetGroupVersionKind(gvk)
+
+		if err := c.List(ctx, &objList, opts...); err != nil {
+			return nil, errors.Wrap("could not list "+gvk.Kind+" for inventory", err)
+		}
+
+		for _, obj := range objList.Items {
+			if !isOwnedBy(&obj, app) {
+				continue
+			}
+			inventory = append(inventory, crd.ManagedObject{
+				APIVersion:      gvk.GroupVersion().String(),
+				Kind:            gvk.Kind,
+				Name:            obj.GetName(),
+				Namespace:       obj.GetNamespace(),
+				UID:             obj.GetUID(),
+				ResourceVersion: obj.GetResourceVersion(),
+			})
+		}
+	}
+
+	sort.Slice(inventory, func(i, j int) bool {
+		a, b := inventory[i], inventory[j]
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		if a.APIVersion != b.APIVersion {
+			return a.APIVersion < b.APIVersion
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Name < b.Name
+	})
+
+	return inventory, nil
+}
+
+// sameInventory reports whether two inventories hold the same objects. The resourceVersions are
+// not compared, as they move with every status change of the objects, and would otherwise have
+// the app's status written on each reconcile.
+func sameInventory(a []crd.ManagedObject, b []crd.ManagedObject) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		x, y := a[i], b[i]
+		x.ResourceVersion, y.ResourceVersion = "", ""
+		if x != y {
+			return false
+		}
+	}
+	return true
+}
+
+func isOwnedBy(obj client.Object, app *crd.ClowdApp) bool {
+	for _, ownerRef := range obj.GetOwnerReferences() {
+		if ownerRef.UID == app.GetUID() {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,77 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	crd "github.com/RedHatInsights/clowder/apis/cloud.redhat.com/v1alpha1"
+	rc "github.com/RedHatInsights/rhc-osdk-utils/resourceCache"
+	"github.com/stretchr/testify/assert"
+	apps "k8s.io/api/apps/v1"
+	core "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestGetAppInventory(t *testing.T) {
+	app := &crd.ClowdApp{ObjectMeta: metav1.ObjectMeta{Name: "puptoo", Namespace: "test", UID: "app-uid"}}
+	owned := func(name string, uid string) metav1.ObjectMeta {
+		return metav1.ObjectMeta{
+			Name:            name,
+			Namespace:       "test",
+			UID:             "uid-" + types.UID(name),
+			Labels:          map[string]string{"app": "puptoo"},
+			OwnerReferences: []metav1.OwnerReference{{Name: "puptoo", UID: types.UID(uid)}},
+		}
+	}
+
+	c := fake.NewClientBuilder().WithScheme(Scheme).WithObjects(
+		&core.ConfigMap{ObjectMeta: owned("puptoo-config", "app-uid")},
+		&core.Service{ObjectMeta: owned("puptoo-api", "app-uid")},
+		&apps.Deployment{ObjectMeta: owned("puptoo-api", "app-uid")},
+		// Labelled for the app but owned by someone else
+		&core.ConfigMap{ObjectMeta: owned("puptoo-other", "other-uid")},
+	).Build()
+
+	gvks := rc.GVKMap{}
+	for _, obj := range []client.Object{&core.ConfigMap{}, &core.Service{}, &apps.Deployment{}, &core.Secret{}} {
+		gvk, err := apiutil.GVKForObject(obj, Scheme)
+		assert.NoError(t, err)
+		gvks[gvk] = true
+	}
+
+	inventory, err := GetAppInventory(context.Background(), c, app, gvks)
+	assert.NoError(t, err)
+	assert.Len(t, inventory, 3)
+
+	assert.Equal(t, crd.ManagedObject{
+		APIVersion:      "v1",
+		Kind:            "ConfigMap",
+		Name:            "puptoo-config",
+		Namespace:       "test",
+		UID:             "uid-puptoo-config",
+		ResourceVersion: "999",
+	}, inventory[0])
+	assert.Equal(t, "Deployment", inventory[1].Kind)
+	assert.Equal(t, "apps/v1", inventory[1].APIVersion)
+	assert.Equal(t, "Service", inventory[2].Kind)
+}
+
+func TestSameInventory(t *testing.T) {
+	inventory := []crd.ManagedObject{
+		{APIVersion: "v1", Kind: "ConfigMap", Name: "puptoo-config", Namespace: "test", UID: "uid-1", ResourceVersion: "1"},
+		{APIVersion: "v1", Kind: "Service", Name: "puptoo-api", Namespace: "test", UID: "uid-2", ResourceVersion: "2"},
+	}
+
+	bumped := []crd.ManagedObject{inventory[0], inventory[1]}
+	bumped[1].ResourceVersion = "3"
+	assert.True(t, sameInventory(inventory, bumped))
+
+	recreated := []crd.ManagedObject{inventory[0], inventory[1]}
+	recreated[1].UID = "uid-3"
+	assert.False(t, sameInventory(inventory, recreated))
+	assert.False(t, sameInventory(inventory, inventory[:1]))
+}
@@ -81,6 +81,13 @@ var (
 		},
 		[]string{"provider", "source"},
 	)
+	providerRunsMetric = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "clowder_provider_runs_total",
+			Help: "Provider runs by outcome",
+		},
+		[]string{"provider", "source", "outcome"},
+	)
 	requestMetrics = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
 			Name: "clowder_reconcile_requests",
@@ -104,9 +111,31 @@ func init() {
 		managedEnvsMetric,
 		clowderVersion,
 		providerMetrics,
+		providerRunsMetric,
 		requestMetrics,
 		presentAppsMetric,
 		presentEnvsMetric,
 		reconciliationMetrics,
 	)
 }
+
+// The outcomes of a provider run counted by clowder_provider_runs_total. Providers behind a
+// disabled feature gate are skipped.
+const (
+	providerRunSuccess = "success"
+	providerRunError   = "error"
+	providerRunSkipped = "skipped"
+)
+
+// recordProviderRun counts a run of the provider for the given source, clowdapp or clowdenv.
+func recordProviderRun(provider string, source string, outcome string) {
+	providerRunsMetric.With(prometheus.Labels{"provider": provider, "source": source, "outcome": outcome}).Inc()
+}
+
+// providerRunOutcome returns the outcome of a provider run that returned the given error.
+func providerRunOutcome(err error) string {
+	if err != nil {
+		return providerRunError
+	}
+	return providerRunSuccess
+}
@@ -0,0 +1,27 @@
+package controllers
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecordProviderRun(t *testing.T) {
+	runs := func(outcome string) float64 {
+		return testutil.ToFloat64(providerRunsMetric.With(prometheus.Labels{
+			"provider": "test-provider", "source": "clowdapp", "outcome": outcome,
+		}))
+	}
+
+	recordProviderRun("test-provider", "clowdapp", providerRunOutcome(nil))
+	recordProviderRun("test-provider", "clowdapp", providerRunOutcome(fmt.Errorf("failed")))
+	recordProviderRun("test-provider", "clowdapp", providerRunOutcome(fmt.Errorf("failed")))
+	recordProviderRun("test-provider", "clowdapp", providerRunSkipped)
+
+	assert.Equal(t, float64(1), runs(providerRunSuccess))
+	assert.Equal(t, float64(2), runs(providerRunError))
+	assert.Equal(t, float64(1), runs(providerRunSkipped))
+}
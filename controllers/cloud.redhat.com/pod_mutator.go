@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
 
 	core "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
@@ -14,6 +15,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 
+	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers/sidecar"
 	"github.com/RedHatInsights/rhc-osdk-utils/utils"
 )
 
@@ -147,6 +149,7 @@ func (p *mutantPod) Handle(_ context.Context, req admission.Request) admission.R
 				},
 			},
 		}
+		container.VolumeMounts = sidecarVolumeMounts(pod)
 
 		if ridx == -1 {
 			pod.Spec.Containers = append(pod.Spec.Containers, container)
@@ -162,6 +165,26 @@ func (p *mutantPod) Handle(_ context.Context, req admission.Request) admission.R
 	return admission.PatchResponseFromRaw(req.Object.Raw, marshaledObj)
 }
 
+// sidecarVolumeMounts returns the mounts of the shared sidecar volumes listed on the pod, as they
+// are mounted in its app container.
+func sidecarVolumeMounts(pod *core.Pod) []core.VolumeMount {
+	names := map[string]bool{}
+	for _, name := range strings.Split(pod.GetAnnotations()[sidecar.SidecarVolumesAnnotation], ",") {
+		names[name] = name != ""
+	}
+
+	var mounts []core.VolumeMount
+	if len(pod.Spec.Containers) == 0 {
+		return mounts
+	}
+	for _, mount := range pod.Spec.Containers[0].VolumeMounts {
+		if names[mount.Name] {
+			mounts = append(mounts, mount)
+		}
+	}
+	return mounts
+}
+
 func (p *mutantPod) InjectDecoder(d *admission.Decoder) error {
 	p.decoder = d
 	return nil
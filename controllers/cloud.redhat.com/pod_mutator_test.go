@@ -0,0 +1,24 @@
+package controllers
+
+import (
+	"testing"
+
+	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers/sidecar"
+	"github.com/stretchr/testify/assert"
+	core "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSidecarVolumeMounts(t *testing.T) {
+	sockets := core.VolumeMount{Name: "sockets", MountPath: "/var/run/sockets"}
+	pod := &core.Pod{Spec: core.PodSpec{Containers: []core.Container{{
+		Name:         "app",
+		VolumeMounts: []core.VolumeMount{{Name: "config", MountPath: "/cdapp"}, sockets},
+	}}}}
+
+	assert.Empty(t, sidecarVolumeMounts(pod), "pods without shared volumes mount nothing in the auth sidecar")
+
+	// Only the shared volumes are mounted in the auth sidecar, at the app container's paths
+	pod.ObjectMeta = metav1.ObjectMeta{Annotations: map[string]string{sidecar.SidecarVolumesAnnotation: "sockets"}}
+	assert.Equal(t, []core.VolumeMount{sockets}, sidecarVolumeMounts(pod))
+}
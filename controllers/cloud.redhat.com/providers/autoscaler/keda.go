@@ -6,12 +6,19 @@ import (
 	crd "github.com/RedHatInsights/clowder/apis/cloud.redhat.com/v1alpha1"
 	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/config"
 	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers"
+	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers/database"
 	deployProvider "github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers/deployment"
 	keda "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
 	apps "k8s.io/api/apps/v1"
 	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/RedHatInsights/rhc-osdk-utils/utils"
 )
 
+// KedaPausedReplicasAnnotation has KEDA hold the target of a ScaledObject at the given replicas
+// instead of scaling it.
+const KedaPausedReplicasAnnotation = "autoscaling.keda.sh/paused-replicas"
+
 func makeAutoScalers(deployment *crd.Deployment, app *crd.ClowdApp, c *config.AppConfig, asp *providers.Provider) error {
 	s := &keda.ScaledObject{}
 	nn := app.GetDeploymentNamespacedName(deployment)
@@ -74,6 +81,14 @@ func initAutoScaler(env *crd.ClowdEnvironment, app *crd.ClowdApp, d *apps.Deploy
 	scalerSpec.Triggers = triggers
 
 	s.Spec = scalerSpec
+
+	// A deployment drained for an upgrade of its database is held at zero replicas, which KEDA
+	// would otherwise scale it back up from while the database is restarted
+	if _, drained := d.GetAnnotations()[database.DrainedReplicasAnnotation]; drained {
+		utils.UpdateAnnotations(s, map[string]string{KedaPausedReplicasAnnotation: "0"})
+	} else {
+		delete(s.Annotations, KedaPausedReplicasAnnotation)
+	}
 }
 
 func getTriggerRoute(triggerType string, c *config.AppConfig, env *crd.ClowdEnvironment) map[string]string {
@@ -0,0 +1,33 @@
+package autoscaler
+
+import (
+	"testing"
+
+	crd "github.com/RedHatInsights/clowder/apis/cloud.redhat.com/v1alpha1"
+	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/config"
+	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers/database"
+	keda "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	apps "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestKedaPausedWhileDrained(t *testing.T) {
+	env := &crd.ClowdEnvironment{}
+	app := &crd.ClowdApp{ObjectMeta: metav1.ObjectMeta{Name: "puptoo", Namespace: "test"}}
+	deployment := &crd.Deployment{Name: "processor", AutoScaler: &crd.AutoScaler{}}
+	nn := types.NamespacedName{Name: "puptoo-processor", Namespace: "test"}
+
+	d := &apps.Deployment{ObjectMeta: metav1.ObjectMeta{Name: nn.Name, Namespace: nn.Namespace}}
+	d.Annotations = map[string]string{database.DrainedReplicasAnnotation: "3"}
+
+	s := &keda.ScaledObject{}
+	initAutoScaler(env, app, d, s, nn, deployment, &config.AppConfig{})
+	assert.Equal(t, "0", s.Annotations[KedaPausedReplicasAnnotation])
+
+	// Once the drain is over, KEDA scales the deployment again
+	delete(d.Annotations, database.DrainedReplicasAnnotation)
+	initAutoScaler(env, app, d, s, nn, deployment, &config.AppConfig{})
+	assert.NotContains(t, s.Annotations, KedaPausedReplicasAnnotation)
+}
@@ -25,6 +25,10 @@ func (asp *autoScaleProviderRouter) EnvProvide() error {
 func (asp *autoScaleProviderRouter) Provide(app *crd.ClowdApp) error {
 	var err error
 	for _, deployment := range app.Spec.Deployments {
+		// DaemonSets run one pod per node and cannot be scaled
+		if deployment.IsDaemonSet() {
+			continue
+		}
 		// If we find a SimpleAutoScaler config create one
 		if deployment.AutoScalerSimple != nil {
 			err = ProvideSimpleAutoScaler(app, asp.GetConfig(), &asp.Provider, deployment)
@@ -23,7 +23,9 @@ const (
 	DeploymentKind       = "Deployment"
 )
 
-// Creates a simple HPA in the resource cache for the deployment and ClowdApp
+// Creates a simple HPA in the resource cache for the deployment and ClowdApp. An HPA does not
+// scale a deployment that has been scaled to zero, so one drained for an upgrade of its database
+// stays drained without pausing the HPA.
 func ProvideSimpleAutoScaler(app *crd.ClowdApp, appConfig *config.AppConfig, sp *providers.Provider, deployment crd.Deployment) error {
 	cachedDeployment, err := getDeploymentFromCache(&deployment, app, sp)
 	if err != nil {
@@ -6,13 +6,17 @@ import (
 	"fmt"
 
 	crd "github.com/RedHatInsights/clowder/apis/cloud.redhat.com/v1alpha1"
+	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/config"
 	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/errors"
+	p "github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers"
 	deployProvider "github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers/deployment"
+	provutils "github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers/utils"
 	"github.com/RedHatInsights/rhc-osdk-utils/utils"
-	apps "k8s.io/api/apps/v1"
 	core "k8s.io/api/core/v1"
 	k8serr "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
 )
 
 func (ch *confighashProvider) envConfigMap(app *crd.ClowdApp, env core.EnvVar) error {
@@ -105,8 +109,8 @@ func (ch *confighashProvider) volSecret(app *crd.ClowdApp, volume core.Volume) e
 	return ch.HashCache.AddClowdObjectToObject(app, sec)
 }
 
-func (ch *confighashProvider) iterateEnvVars(app *crd.ClowdApp, deployment apps.Deployment) error {
-	for _, cont := range deployment.Spec.Template.Spec.Containers {
+func (ch *confighashProvider) iterateEnvVars(app *crd.ClowdApp, template *core.PodTemplateSpec) error {
+	for _, cont := range template.Spec.Containers {
 		for _, env := range cont.Env {
 			if err := ch.envConfigMap(app, env); err != nil {
 				return err
@@ -120,8 +124,8 @@ func (ch *confighashProvider) iterateEnvVars(app *crd.ClowdApp, deployment apps.
 	return nil
 }
 
-func (ch *confighashProvider) iterateVolumes(app *crd.ClowdApp, deployment apps.Deployment) error {
-	for _, volume := range deployment.Spec.Template.Spec.Volumes {
+func (ch *confighashProvider) iterateVolumes(app *crd.ClowdApp, template *core.PodTemplateSpec) error {
+	for _, volume := range template.Spec.Volumes {
 		if err := ch.volConfigMap(app, volume); err != nil {
 			return err
 		}
@@ -133,18 +137,70 @@ func (ch *confighashProvider) iterateVolumes(app *crd.ClowdApp, deployment apps.
 	return nil
 }
 
-func (ch *confighashProvider) updateHashCache(dList *apps.DeploymentList, app *crd.ClowdApp) error {
-	for _, deployment := range dList.Items {
-		deploy := deployment
-		if err := ch.iterateEnvVars(app, deploy); err != nil {
+func (ch *confighashProvider) updateHashCache(workloads []*deployProvider.Workload, app *crd.ClowdApp) error {
+	for _, w := range workloads {
+		if err := ch.iterateEnvVars(app, w.Template); err != nil {
 			return err
 		}
-		if err := ch.iterateVolumes(app, deploy); err != nil {
+		if err := ch.iterateVolumes(app, w.Template); err != nil {
 			return err
 		}
 	}
 	return nil
 }
+
+// addConfigDependencies folds the ConfigMaps and Secrets the app declares as config dependencies
+// into the hash cache, so that changes to them restart the app.
+func (ch *confighashProvider) addConfigDependencies(app *crd.ClowdApp) error {
+	for _, dep := range app.Spec.ConfigDependencies {
+		nn := types.NamespacedName{
+			Name:      dep.Name,
+			Namespace: app.Namespace,
+		}
+
+		var obj client.Object
+		switch dep.Kind {
+		case "ConfigMap":
+			obj = &core.ConfigMap{}
+		case "Secret":
+			obj = &core.Secret{}
+		default:
+			return errors.NewClowderError(fmt.Sprintf("%s is not a valid config dependency kind", dep.Kind))
+		}
+
+		if err := ch.Client.Get(ch.Ctx, nn, obj); err != nil {
+			return errors.Wrap(fmt.Sprintf("could not get config dependency %s/%s", dep.Kind, dep.Name), err)
+		}
+
+		if err := ch.HashCache.AddDependencyToObject(app, obj); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// HashConfig returns the JSON rendering of the app config, as presented in the
+// cdappconfig.json secret, along with its hash. The maintenanceMode flag is left out
+// of the hash, so that pods are not restarted as their migrations start and finish.
+func HashConfig(c *config.AppConfig) ([]byte, string, error) {
+	jsonData, err := json.Marshal(c)
+	if err != nil {
+		return nil, "", errors.Wrap("Failed to marshal config JSON", err)
+	}
+
+	hashed := *c
+	hashed.MaintenanceMode = nil
+	hashData, err := json.Marshal(&hashed)
+	if err != nil {
+		return nil, "", errors.Wrap("Failed to marshal config JSON", err)
+	}
+
+	h := sha256.New()
+	h.Write([]byte(hashData))
+	return jsonData, fmt.Sprintf("%x", h.Sum(nil)), nil
+}
+
 func (ch *confighashProvider) persistConfig(app *crd.ClowdApp) (string, error) {
 
 	// In any case, we want to overwrite the secret, so this just
@@ -156,12 +212,16 @@ func (ch *confighashProvider) persistConfig(app *crd.ClowdApp) (string, error) {
 		return "", err
 	}
 
-	dList := apps.DeploymentList{}
-	if err := ch.Cache.List(deployProvider.CoreDeployment, &dList); err != nil {
+	workloads, err := deployProvider.GetWorkloads(ch.Cache, app)
+	if err != nil {
 		return "", err
 	}
 
-	if err := ch.updateHashCache(&dList, app); err != nil {
+	if err := ch.updateHashCache(workloads, app); err != nil {
+		return "", err
+	}
+
+	if err := ch.addConfigDependencies(app); err != nil {
 		return "", err
 	}
 
@@ -173,20 +233,26 @@ func (ch *confighashProvider) persistConfig(app *crd.ClowdApp) (string, error) {
 		),
 	)
 
-	jsonData, err := json.Marshal(ch.Config)
+	_, hash, err := HashConfig(ch.Config)
 	if err != nil {
-		return "", errors.Wrap("Failed to marshal config JSON", err)
+		return "", err
 	}
 
-	h := sha256.New()
-	h.Write([]byte(jsonData))
-	hash := fmt.Sprintf("%x", h.Sum(nil))
+	format := provutils.ConfigFormat(ch.Env)
+	data, err := ch.Config.Marshal(format)
+	if err != nil {
+		return "", errors.Wrap("Failed to marshal config", err)
+	}
 
+	// The data is dropped so that the file of the other format does not linger in the secret with
+	// stale config once the environment switches formats
+	secret.Data = nil
 	secret.StringData = map[string]string{
-		"cdappconfig.json": string(jsonData),
+		config.FileName(format): string(data),
 	}
 
 	app.SetObjectMeta(secret)
+	p.SetSecretLabels(secret, app)
 
 	err = ch.Cache.Update(CoreConfigSecret, secret)
 
@@ -194,5 +260,38 @@ func (ch *confighashProvider) persistConfig(app *crd.ClowdApp) (string, error) {
 		return "", err
 	}
 
+	if ch.Env.Spec.ConfigExport.Enabled {
+		if err := ch.exportConfig(app); err != nil {
+			return "", err
+		}
+	}
+
 	return hash, err
 }
+
+func (ch *confighashProvider) exportConfig(app *crd.ClowdApp) error {
+	cm := &core.ConfigMap{}
+	if err := ch.Cache.Create(CoreConfigExportConfigMap, app.GetNamespacedName("%s"), cm); err != nil {
+		return err
+	}
+
+	data, err := ch.Config.RedactedJSON()
+	if err != nil {
+		return errors.Wrap("Failed to marshal exported config JSON", err)
+	}
+
+	format := provutils.ConfigFormat(ch.Env)
+	if format == config.FormatYAML {
+		if data, err = yaml.JSONToYAML(data); err != nil {
+			return errors.Wrap("Failed to convert exported config to YAML", err)
+		}
+	}
+
+	cm.Data = map[string]string{
+		config.FileName(format): string(data),
+	}
+
+	app.SetObjectMeta(cm)
+
+	return ch.Cache.Update(CoreConfigExportConfigMap, cm)
+}
@@ -5,7 +5,6 @@ import (
 	p "github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers"
 	cronjobProvider "github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers/cronjob"
 	deployProvider "github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers/deployment"
-	apps "k8s.io/api/apps/v1"
 	batch "k8s.io/api/batch/v1"
 	core "k8s.io/api/core/v1"
 
@@ -20,9 +19,12 @@ type confighashProvider struct {
 // CoreConfigSecret is the config that is presented as the cdappconfig.json file.
 var CoreConfigSecret = rc.NewSingleResourceIdent(ProvName, "core_config_secret", &core.Secret{})
 
+// CoreConfigExportConfigMap is the redacted copy of the config exported for inspection.
+var CoreConfigExportConfigMap = rc.NewSingleResourceIdent(ProvName, "core_config_export_config_map", &core.ConfigMap{})
+
 // NewConfigHashProvider returns a new End provider run at the end of the provider set.
 func NewConfigHashProvider(p *p.Provider) (p.ClowderProvider, error) {
-	p.Cache.AddPossibleGVKFromIdent(CoreConfigSecret)
+	p.Cache.AddPossibleGVKFromIdent(CoreConfigSecret, CoreConfigExportConfigMap)
 	return &confighashProvider{Provider: *p}, nil
 }
 
@@ -38,17 +40,22 @@ func (ch *confighashProvider) Provide(app *crd.ClowdApp) error {
 		return err
 	}
 
-	dList := apps.DeploymentList{}
-	if err := ch.Cache.List(deployProvider.CoreDeployment, &dList); err != nil {
-		return err
-	}
+	for i := range app.Spec.Deployments {
+		deployment := &app.Spec.Deployments[i]
+		// Pods that reload their config in place keep the hash they were started with
+		if deployment.ConfigReload == crd.ConfigReloadInPlace {
+			continue
+		}
+
+		w, err := deployProvider.GetWorkload(ch.Cache, app, deployment)
+		if err != nil {
+			return err
+		}
 
-	for _, deployment := range dList.Items {
-		depInner := deployment
 		annotations := map[string]string{"configHash": hash}
-		utils.UpdateAnnotations(&depInner.Spec.Template, annotations)
+		utils.UpdateAnnotations(w.Template, annotations)
 
-		if err := ch.Cache.Update(deployProvider.CoreDeployment, &depInner); err != nil {
+		if err := w.Update(ch.Cache); err != nil {
 			return err
 		}
 	}
@@ -0,0 +1,107 @@
+package confighash
+
+import (
+	"context"
+	"testing"
+
+	crd "github.com/RedHatInsights/clowder/apis/cloud.redhat.com/v1alpha1"
+	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/config"
+	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/hashcache"
+	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers"
+	deployProvider "github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers/deployment"
+	rc "github.com/RedHatInsights/rhc-osdk-utils/resourceCache"
+	"github.com/RedHatInsights/rhc-osdk-utils/utils"
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	apps "k8s.io/api/apps/v1"
+	core "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// provideConfigHash builds the desired deployments of the app in a fresh cache and runs the
+// provider with the given config, returning the cache.
+func provideConfigHash(t *testing.T, c client.Client, env *crd.ClowdEnvironment, app *crd.ClowdApp, cfg *config.AppConfig) *rc.ObjectCache {
+	ctx := context.Background()
+	log := logr.Discard()
+	cache := rc.NewObjectCache(ctx, c, &log, rc.NewCacheConfig(nil, nil, nil))
+	cache.AddPossibleGVKFromIdent(deployProvider.CoreDeployment)
+
+	for i := range app.Spec.Deployments {
+		nn := app.GetDeploymentNamespacedName(&app.Spec.Deployments[i])
+		d := &apps.Deployment{}
+		assert.NoError(t, cache.Create(deployProvider.CoreDeployment, nn, d))
+		d.Name, d.Namespace = nn.Name, nn.Namespace
+		assert.NoError(t, cache.Update(deployProvider.CoreDeployment, d))
+	}
+
+	hashCache := hashcache.NewHashCache()
+	prov, err := NewConfigHashProvider(&providers.Provider{Ctx: ctx, Client: c, Cache: &cache, Env: env, Config: cfg, HashCache: &hashCache, Log: log})
+	assert.NoError(t, err)
+	assert.NoError(t, prov.Provide(app))
+	return &cache
+}
+
+func TestProvideConfigReload(t *testing.T) {
+	app := &crd.ClowdApp{
+		ObjectMeta: metav1.ObjectMeta{Name: "puptoo", Namespace: "test"},
+		Spec: crd.ClowdAppSpec{Deployments: []crd.Deployment{
+			{Name: "api"},
+			{Name: "worker", ConfigReload: crd.ConfigReloadInPlace},
+		}},
+	}
+
+	cache := provideConfigHash(t, fake.NewClientBuilder().Build(), &crd.ClowdEnvironment{}, app, &config.AppConfig{})
+
+	api := &apps.Deployment{}
+	assert.NoError(t, cache.Get(deployProvider.CoreDeployment, api, app.GetDeploymentNamespacedName(&app.Spec.Deployments[0])))
+	assert.NotEmpty(t, api.Spec.Template.Annotations["configHash"])
+
+	// Pods that reload their config in place are not restarted when it changes
+	worker := &apps.Deployment{}
+	assert.NoError(t, cache.Get(deployProvider.CoreDeployment, worker, app.GetDeploymentNamespacedName(&app.Spec.Deployments[1])))
+	assert.NotContains(t, worker.Spec.Template.Annotations, "configHash")
+
+	secret := &core.Secret{}
+	assert.NoError(t, cache.Get(CoreConfigSecret, secret))
+	assert.Contains(t, secret.StringData, "cdappconfig.json")
+}
+
+func TestHashConfigMaintenanceMode(t *testing.T) {
+	cfg := &config.AppConfig{}
+	_, hash, err := HashConfig(cfg)
+	assert.NoError(t, err)
+
+	// Pods are not restarted as maintenance mode comes and goes, but the flag is still rendered
+	cfg.MaintenanceMode = utils.TruePtr()
+	data, maintenanceHash, err := HashConfig(cfg)
+	assert.NoError(t, err)
+	assert.Equal(t, hash, maintenanceHash)
+	assert.Contains(t, string(data), `"maintenanceMode":true`)
+	assert.Equal(t, true, *cfg.MaintenanceMode, "the config should be left as it was")
+}
+
+func TestProvideConfigFormatSwitch(t *testing.T) {
+	app := &crd.ClowdApp{
+		ObjectMeta: metav1.ObjectMeta{Name: "puptoo", Namespace: "test"},
+		Spec:       crd.ClowdAppSpec{Deployments: []crd.Deployment{{Name: "api"}}},
+	}
+
+	// The secret as written while the environment presented the config as JSON
+	existing := &core.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "puptoo", Namespace: "test"},
+		Data:       map[string][]byte{"cdappconfig.json": []byte(`{"webPort": 8000}`)},
+	}
+
+	env := &crd.ClowdEnvironment{}
+	env.Spec.Providers.Deployment.ConfigFormat = config.FormatYAML
+	c := fake.NewClientBuilder().WithObjects(existing).Build()
+	cache := provideConfigHash(t, c, env, app, &config.AppConfig{})
+
+	secret := &core.Secret{}
+	assert.NoError(t, cache.Get(CoreConfigSecret, secret))
+	assert.Contains(t, secret.StringData, "cdappconfig.yaml")
+	assert.NotContains(t, secret.StringData, "cdappconfig.json")
+	assert.NotContains(t, secret.Data, "cdappconfig.json")
+}
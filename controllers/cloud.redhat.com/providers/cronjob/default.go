@@ -3,6 +3,7 @@ package cronjob
 import (
 	crd "github.com/RedHatInsights/clowder/apis/cloud.redhat.com/v1alpha1"
 	p "github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers"
+	provutils "github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers/utils"
 	rc "github.com/RedHatInsights/rhc-osdk-utils/resourceCache"
 	batch "k8s.io/api/batch/v1"
 )
@@ -28,6 +29,9 @@ func (j *cronjobProvider) Provide(app *crd.ClowdApp) error {
 	for _, cronjob := range app.Spec.Jobs {
 		innerCronjob := cronjob
 		if innerCronjob.Schedule != "" && !innerCronjob.Disabled {
+			if err := provutils.CheckJobServiceAccount(j.Ctx, j.Client, app, &innerCronjob); err != nil {
+				return err
+			}
 			if err := j.makeCronJob(&innerCronjob, app); err != nil {
 				return err
 			}
@@ -50,7 +50,7 @@ func buildPodTemplate(app *crd.ClowdApp, env *crd.ClowdEnvironment, pt *core.Pod
 	pt.ObjectMeta.Labels = labels
 
 	envvar := pod.Env
-	envvar = append(envvar, core.EnvVar{Name: "ACG_CONFIG", Value: "/cdapp/cdappconfig.json"})
+	envvar = append(envvar, core.EnvVar{Name: "ACG_CONFIG", Value: provutils.ConfigFilePath(env)})
 
 	for _, env := range envvar {
 		if env.ValueFrom != nil {
@@ -77,13 +77,14 @@ func buildPodTemplate(app *crd.ClowdApp, env *crd.ClowdEnvironment, pt *core.Pod
 	}
 
 	c := core.Container{
-		Name:         nn.Name,
-		Image:        pod.Image,
-		Command:      pod.Command,
-		Args:         pod.Args,
-		Env:          envvar,
-		Resources:    deployProvider.ProcessResources(&pod, env),
-		VolumeMounts: pod.VolumeMounts,
+		Name:          nn.Name,
+		Image:         pod.Image,
+		Command:       pod.Command,
+		Args:          pod.Args,
+		Env:           envvar,
+		Resources:     deployProvider.ProcessResources(&pod, env),
+		VolumeMounts:  pod.VolumeMounts,
+		VolumeDevices: pod.VolumeDevices,
 		Ports: []core.ContainerPort{{
 			Name:          "metrics",
 			ContainerPort: env.Spec.Providers.Metrics.Port,
@@ -96,6 +97,7 @@ func buildPodTemplate(app *crd.ClowdApp, env *crd.ClowdEnvironment, pt *core.Pod
 	if !env.Spec.Providers.Deployment.OmitPullPolicy {
 		c.ImagePullPolicy = core.PullIfNotPresent
 	}
+	provutils.ApplyImageSettings(env, &c)
 
 	if pod.MachinePool != "" {
 		pt.Spec.Tolerations = []core.Toleration{{
@@ -109,7 +111,8 @@ func buildPodTemplate(app *crd.ClowdApp, env *crd.ClowdEnvironment, pt *core.Pod
 	}
 
 	// set service account for pod
-	pt.Spec.ServiceAccountName = app.GetClowdSAName()
+	pt.Spec.ServiceAccountName = app.GetJobSAName(cronjob)
+	provutils.SetJobPullSecrets(env, cronjob, &pt.Spec)
 	pt.Spec.TerminationGracePeriodSeconds = utils.Int64Ptr(30)
 	pt.Spec.SecurityContext = &core.PodSecurityContext{}
 	pt.Spec.SchedulerName = "default-scheduler"
@@ -129,7 +132,7 @@ func buildPodTemplate(app *crd.ClowdApp, env *crd.ClowdEnvironment, pt *core.Pod
 
 	pt.Spec.Containers = []core.Container{c}
 
-	ics, err := deployProvider.ProcessInitContainers(nn, &c, pod.InitContainers)
+	ics, err := deployProvider.ProcessInitContainers(env, nn, &c, pod.InitContainers)
 
 	if err != nil {
 		return err
@@ -141,11 +144,12 @@ func buildPodTemplate(app *crd.ClowdApp, env *crd.ClowdEnvironment, pt *core.Pod
 		Name: "config-secret",
 		VolumeSource: core.VolumeSource{
 			Secret: &core.SecretVolumeSource{
-				DefaultMode: utils.Int32Ptr(420),
+				DefaultMode: provutils.ConfigSecretMode(env),
 				SecretName:  app.ObjectMeta.Name,
 			},
 		},
 	})
+	provutils.AddTrustedCAVolume(env, app.Name, &pt.Spec)
 
 	for _, vol := range pt.Spec.Volumes {
 		if vol.VolumeSource.ConfigMap != nil && (vol.VolumeSource.ConfigMap.DefaultMode == nil || *vol.VolumeSource.ConfigMap.DefaultMode == 0) {
@@ -119,7 +119,7 @@ func (a *appInterface) Provide(app *crd.ClowdApp) error {
 		return err
 	}
 
-	a.Config.Database = &matched.Config
+	a.Config.SetDatabase(dbSpec.Name, &matched.Config)
 
 	return nil
 }
@@ -0,0 +1,232 @@
+package database
+
+import (
+	"strconv"
+
+	crd "github.com/RedHatInsights/clowder/apis/cloud.redhat.com/v1alpha1"
+	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/errors"
+	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers"
+	deployProvider "github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers/deployment"
+
+	apps "k8s.io/api/apps/v1"
+	core "k8s.io/api/core/v1"
+	k8serr "k8s.io/apimachinery/pkg/api/errors"
+
+	rc "github.com/RedHatInsights/rhc-osdk-utils/resourceCache"
+	"github.com/RedHatInsights/rhc-osdk-utils/utils"
+)
+
+// DrainAnnotation is set on a local DB deployment while the app's deployments are scaled down for
+// an upgrade of the database, holding the step the upgrade is at.
+const DrainAnnotation = "clowder/database-drain"
+
+// DrainedReplicasAnnotation is set on an app's deployment while it is scaled down for an upgrade of
+// its database, holding the replicas it is scaled back up to afterwards. The autoscaler provider
+// pauses the deployment's autoscaler while it is set, so that it does not scale the deployment
+// back up during the upgrade.
+const DrainedReplicasAnnotation = "clowder/drained-replicas"
+
+const (
+	// DrainScalingDown is the step of a drain where the new database image is held back until the
+	// app's deployments have no pods left.
+	DrainScalingDown = "scaling-down"
+	// DrainUpgrading is the step of a drain where the new image is rolled out to the database
+	// while the app's deployments stay scaled down.
+	DrainUpgrading = "upgrading"
+)
+
+// nextDrainStep returns the step a drain moves on to from the given one. A drain starts when the
+// database image changes, moves on to the upgrade once the app's deployments are drained, and ends
+// once the database is ready on the new image. A drain whose image change goes away before the
+// upgrade starts, because it was reverted or the maintenance window closed, ends straight away.
+func nextDrainStep(step string, imageChange bool, drained bool, dbReady bool) string {
+	switch step {
+	case "":
+		if imageChange {
+			return DrainScalingDown
+		}
+	case DrainScalingDown:
+		if !imageChange {
+			return ""
+		}
+		if drained {
+			return DrainUpgrading
+		}
+		return DrainScalingDown
+	case DrainUpgrading:
+		if !dbReady {
+			return DrainUpgrading
+		}
+	}
+	return ""
+}
+
+// drainForUpgrade scales the app's deployments down while a new image is rolled out to its
+// database, and back up once it is done, so that the app's connections are closed before the
+// database is restarted rather than dropped. The step the drain is at is recorded on the database
+// deployment, so that it can be surfaced in the app's status, and followed by the apps sharing the
+// database through sharedDbAppName, whose deployments are drained by drainForSharedDB. The upgrade
+// waits for their deployments to be drained as well.
+func (db *localDbProvider) drainForUpgrade(app *crd.ClowdApp, dd *apps.Deployment, current *core.PodTemplateSpec) error {
+	if _, draining := dd.GetAnnotations()[DrainAnnotation]; !draining && !app.Spec.Database.DrainOnUpgrade {
+		return nil
+	}
+
+	workloads, err := deployProvider.GetWorkloads(db.Cache, app)
+	if err != nil {
+		return err
+	}
+
+	step := ""
+	if app.Spec.Database.DrainOnUpgrade && app.Spec.Database.MaintenanceWindow != nil && current != nil {
+		desired := &dd.Spec.Template.Spec.Containers[0]
+		imageChange := desired.Image != current.Spec.Containers[0].Image
+
+		drained := workloadsDrained(workloads)
+		if drained {
+			if drained, err = db.sharingAppsDrained(app); err != nil {
+				return err
+			}
+		}
+
+		step = nextDrainStep(dd.GetAnnotations()[DrainAnnotation], imageChange, drained, databaseReady(dd))
+		if step == DrainScalingDown {
+			desired.Image = current.Spec.Containers[0].Image
+		}
+	}
+
+	if step == "" {
+		delete(dd.Annotations, DrainAnnotation)
+	} else {
+		utils.UpdateAnnotations(dd, map[string]string{DrainAnnotation: step})
+	}
+
+	return drainWorkloads(db.Cache, workloads, step != "")
+}
+
+// drainForSharedDB drains the deployments of an app using the local database of refApp through
+// sharedDbAppName while that database is upgraded, following the step of the drain recorded on
+// its deployment, and scales them back up once the drain is over.
+func (db *localDbProvider) drainForSharedDB(app *crd.ClowdApp, refApp *crd.ClowdApp) error {
+	step := ""
+
+	// This is a REAL call here, the database deployment belongs to the app we depend on
+	dd := &apps.Deployment{}
+	nn := providers.GetNamespacedName(refApp, "db")
+	if err := db.Client.Get(db.Ctx, nn, dd); err == nil {
+		step = dd.GetAnnotations()[DrainAnnotation]
+	} else if !k8serr.IsNotFound(err) {
+		return errors.Wrap("couldn't get shared db deployment", err)
+	}
+
+	workloads, err := deployProvider.GetWorkloads(db.Cache, app)
+	if err != nil {
+		return err
+	}
+	return drainWorkloads(db.Cache, workloads, step != "")
+}
+
+// sharingAppsDrained returns true when none of the deployments of the apps sharing the app's
+// database through sharedDbAppName have any pods left. They are drained by the reconciles of those
+// apps, so their deployments are read from the cluster rather than the cache.
+func (db *localDbProvider) sharingAppsDrained(app *crd.ClowdApp) (bool, error) {
+	appList := &crd.ClowdAppList{}
+	if err := crd.GetAppInSameEnv(db.Ctx, db.Client, app, appList); err != nil {
+		return false, errors.Wrap("couldn't list apps sharing the db", err)
+	}
+
+	for _, iapp := range appList.Items {
+		if iapp.Spec.EnvName != app.Spec.EnvName || iapp.Spec.Database.SharedDBAppName != app.Name {
+			continue
+		}
+		for i := range iapp.Spec.Deployments {
+			deployment := &iapp.Spec.Deployments[i]
+			if deployment.IsDaemonSet() {
+				continue
+			}
+			d := &apps.Deployment{}
+			if err := db.Client.Get(db.Ctx, iapp.GetDeploymentNamespacedName(deployment), d); err != nil {
+				if k8serr.IsNotFound(err) {
+					continue
+				}
+				return false, errors.Wrap("couldn't get deployment of app sharing the db", err)
+			}
+			if d.Status.Replicas != 0 {
+				return false, nil
+			}
+		}
+	}
+	return true, nil
+}
+
+// drainWorkloads scales the deployments among the workloads down when drain is set, and back up
+// to the replicas they had otherwise.
+func drainWorkloads(cache *rc.ObjectCache, workloads []*deployProvider.Workload, drain bool) error {
+	for _, w := range workloads {
+		d, ok := w.Object.(*apps.Deployment)
+		if !ok {
+			// DaemonSets cannot be scaled down, they keep running through the upgrade
+			continue
+		}
+		if drain {
+			drainReplicas(d)
+		} else if !restoreDrainedReplicas(d) {
+			continue
+		}
+		if err := w.Update(cache); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// workloadsDrained returns true when none of the app's deployments have any pods left.
+func workloadsDrained(workloads []*deployProvider.Workload) bool {
+	for _, w := range workloads {
+		if d, ok := w.Object.(*apps.Deployment); ok && d.Status.Replicas != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// databaseReady returns true when the database deployment has rolled out its current spec and no
+// pods of the previous one are left.
+func databaseReady(dd *apps.Deployment) bool {
+	replicas := int32(1)
+	if dd.Spec.Replicas != nil {
+		replicas = *dd.Spec.Replicas
+	}
+	return dd.Status.ObservedGeneration >= dd.Generation &&
+		dd.Status.Replicas == replicas &&
+		dd.Status.UpdatedReplicas == replicas &&
+		dd.Status.ReadyReplicas == replicas
+}
+
+// drainReplicas scales the deployment to zero, recording the replicas it had so that they can be
+// restored after the upgrade.
+func drainReplicas(d *apps.Deployment) {
+	if _, ok := d.GetAnnotations()[DrainedReplicasAnnotation]; !ok {
+		replicas := int32(1)
+		if d.Spec.Replicas != nil {
+			replicas = *d.Spec.Replicas
+		}
+		utils.UpdateAnnotations(d, map[string]string{DrainedReplicasAnnotation: strconv.Itoa(int(replicas))})
+	}
+	d.Spec.Replicas = utils.Int32Ptr(0)
+}
+
+// restoreDrainedReplicas scales a drained deployment back up to the replicas it had before, or to
+// its minimum if that is now higher. It returns false if the deployment was not drained.
+func restoreDrainedReplicas(d *apps.Deployment) bool {
+	value, ok := d.GetAnnotations()[DrainedReplicasAnnotation]
+	if !ok {
+		return false
+	}
+	delete(d.Annotations, DrainedReplicasAnnotation)
+
+	if replicas, err := strconv.Atoi(value); err == nil && (d.Spec.Replicas == nil || *d.Spec.Replicas < int32(replicas)) {
+		d.Spec.Replicas = utils.Int32Ptr(replicas)
+	}
+	return true
+}
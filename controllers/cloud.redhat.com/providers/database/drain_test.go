@@ -0,0 +1,122 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	apps "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	crd "github.com/RedHatInsights/clowder/apis/cloud.redhat.com/v1alpha1"
+	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers"
+	"github.com/RedHatInsights/rhc-osdk-utils/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNextDrainStep(t *testing.T) {
+	tests := []struct {
+		name        string
+		step        string
+		imageChange bool
+		drained     bool
+		dbReady     bool
+		want        string
+	}{
+		{"nothing to upgrade", "", false, false, true, ""},
+		{"image change starts the drain", "", true, false, true, DrainScalingDown},
+		{"waits for the deployments to drain", DrainScalingDown, true, false, true, DrainScalingDown},
+		{"upgrades once drained", DrainScalingDown, true, true, true, DrainUpgrading},
+		{"image change withdrawn", DrainScalingDown, false, false, true, ""},
+		{"waits for the database", DrainUpgrading, false, true, false, DrainUpgrading},
+		{"done once the database is ready", DrainUpgrading, false, true, true, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, nextDrainStep(tt.step, tt.imageChange, tt.drained, tt.dbReady))
+		})
+	}
+}
+
+func TestDatabaseReady(t *testing.T) {
+	dd := &apps.Deployment{}
+	dd.Generation = 2
+	dd.Spec.Replicas = utils.Int32Ptr(1)
+	dd.Status = apps.DeploymentStatus{ObservedGeneration: 1, Replicas: 1, UpdatedReplicas: 1, ReadyReplicas: 1}
+	assert.False(t, databaseReady(dd))
+
+	// The pod of the previous image is still running next to the new one
+	dd.Status = apps.DeploymentStatus{ObservedGeneration: 2, Replicas: 2, UpdatedReplicas: 1, ReadyReplicas: 1}
+	assert.False(t, databaseReady(dd))
+
+	dd.Status = apps.DeploymentStatus{ObservedGeneration: 2, Replicas: 1, UpdatedReplicas: 1, ReadyReplicas: 1}
+	assert.True(t, databaseReady(dd))
+}
+
+func TestDrainReplicas(t *testing.T) {
+	d := &apps.Deployment{}
+	d.Spec.Replicas = utils.Int32Ptr(5)
+
+	drainReplicas(d)
+	assert.Equal(t, int32(0), *d.Spec.Replicas)
+	assert.Equal(t, "5", d.Annotations[DrainedReplicasAnnotation])
+
+	// Later reconciles keep the replicas recorded before the drain
+	d.Spec.Replicas = utils.Int32Ptr(2)
+	drainReplicas(d)
+	assert.Equal(t, "5", d.Annotations[DrainedReplicasAnnotation])
+
+	// The deployment provider has put the drained deployment back at its minimum replicas
+	d.Spec.Replicas = utils.Int32Ptr(2)
+	assert.True(t, restoreDrainedReplicas(d))
+	assert.Equal(t, int32(5), *d.Spec.Replicas)
+	assert.NotContains(t, d.Annotations, DrainedReplicasAnnotation)
+
+	assert.False(t, restoreDrainedReplicas(d))
+}
+
+func TestSharingAppsDrained(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, clientgoscheme.AddToScheme(scheme))
+	assert.NoError(t, crd.AddToScheme(scheme))
+
+	app := &crd.ClowdApp{ObjectMeta: metav1.ObjectMeta{Name: "puptoo", Namespace: "test"}}
+	app.Spec.EnvName = "env"
+
+	sharing := &crd.ClowdApp{ObjectMeta: metav1.ObjectMeta{Name: "reader", Namespace: "test"}}
+	sharing.Spec.EnvName = "env"
+	sharing.Spec.Database.SharedDBAppName = "puptoo"
+	sharing.Spec.Deployments = []crd.Deployment{{Name: "api"}}
+
+	other := &crd.ClowdApp{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "test"}}
+	other.Spec.EnvName = "env"
+	other.Spec.Deployments = []crd.Deployment{{Name: "api"}}
+
+	deployment := func(name string, replicas int32) *apps.Deployment {
+		d := &apps.Deployment{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test"}}
+		d.Status.Replicas = replicas
+		return d
+	}
+
+	ctx := context.Background()
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		app, sharing, other, deployment("reader-api", 2), deployment("other-api", 3),
+	).Build()
+	db := &localDbProvider{Provider: providers.Provider{Ctx: ctx, Client: c}}
+
+	drained, err := db.sharingAppsDrained(app)
+	assert.NoError(t, err)
+	assert.False(t, drained)
+
+	// Apps that don't share the database are left to run
+	d := &apps.Deployment{}
+	assert.NoError(t, c.Get(ctx, sharing.GetDeploymentNamespacedName(&sharing.Spec.Deployments[0]), d))
+	d.Status.Replicas = 0
+	assert.NoError(t, c.Status().Update(ctx, d))
+
+	drained, err = db.sharingAppsDrained(app)
+	assert.NoError(t, err)
+	assert.True(t, drained)
+}
@@ -1,8 +1,11 @@
 package database
 
 import (
+	"context"
 	"fmt"
+	"strconv"
 	"strings"
+	"time"
 
 	crd "github.com/RedHatInsights/clowder/apis/cloud.redhat.com/v1alpha1"
 	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/config"
@@ -13,7 +16,12 @@ import (
 
 	apps "k8s.io/api/apps/v1"
 	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	k8serr "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	rc "github.com/RedHatInsights/rhc-osdk-utils/resourceCache"
 	"github.com/RedHatInsights/rhc-osdk-utils/utils"
@@ -31,6 +39,10 @@ var LocalDBPVC = rc.NewSingleResourceIdent(ProvName, "local_db_pvc", &core.Persi
 // LocalDBSecret is the ident referring to the local DB secret object.
 var LocalDBSecret = rc.NewSingleResourceIdent(ProvName, "local_db_secret", &core.Secret{})
 
+// PendingMaintenanceAnnotation is set on a local DB deployment, listing the changes that are
+// waiting for the app's maintenance window.
+const PendingMaintenanceAnnotation = "clowder/pending-maintenance"
+
 type localDbProvider struct {
 	providers.Provider
 }
@@ -42,10 +54,23 @@ func NewLocalDBProvider(p *providers.Provider) (providers.ClowderProvider, error
 		LocalDBService,
 		LocalDBPVC,
 		LocalDBSecret,
+		LocalDBMigrationJob,
 	)
 	return &localDbProvider{Provider: *p}, nil
 }
 
+// HasLocalDB reports whether the app's database runs in a local DB deployment of its own, which
+// is the case in local mode for apps that name a database rather than sharing another app's.
+func HasLocalDB(env *crd.ClowdEnvironment, app *crd.ClowdApp) bool {
+	if env.Spec.Providers.Database.Mode != "local" {
+		return false
+	}
+	if mode := app.Spec.Database.ModeOverride; mode != "" && mode != "local" {
+		return false
+	}
+	return app.Spec.Database.Name != "" && app.Spec.Database.SharedDBAppName == ""
+}
+
 func (db *localDbProvider) EnvProvide() error {
 	return nil
 }
@@ -75,21 +100,21 @@ func (db *localDbProvider) Provide(app *crd.ClowdApp) error {
 
 	dbCfg := config.DatabaseConfig{}
 
-	password, err := utils.RandPassword(16, provutils.RCharSet)
+	password, err := provutils.GeneratePassword(db.Env)
 	if err != nil {
 		return errors.Wrap("password generate failed", err)
 	}
 
-	pgPassword, err := utils.RandPassword(16, provutils.RCharSet)
+	pgPassword, err := provutils.GeneratePassword(db.Env)
 	if err != nil {
 		return errors.Wrap("pgPassword generate failed", err)
 	}
 
 	dataInit := func() map[string]string {
 
-		hostname := fmt.Sprintf("%v.%v.svc", nn.Name, nn.Namespace)
+		hostname := db.Env.GetServiceHostname(nn.Name, nn.Namespace)
 		port := "5432"
-		username := utils.RandString(16)
+		username := provutils.GenerateUsername(db.Env)
 		name := app.Spec.Database.Name
 
 		return map[string]string{
@@ -116,20 +141,25 @@ func (db *localDbProvider) Provide(app *crd.ClowdApp) error {
 	if err != nil {
 		return errors.Wrap("couldn't convert to int", err)
 	}
+	dbCfg.Hostname = db.Env.GetServiceHostname(nn.Name, nn.Namespace)
 	dbCfg.AdminUsername = "postgres"
 	dbCfg.SslMode = "disable"
 
-	var image string
+	dbURL := ConnectionURL(&dbCfg, app.Spec.Database.URLFormat)
+	if (*secMap)[URLKey] != dbURL {
+		if err := setSecretURL(db.Cache, LocalDBSecret, nn, dbURL); err != nil {
+			return errors.Wrap("couldn't set database URL", err)
+		}
+	}
 
 	var dbVersion int32 = 12
 	if app.Spec.Database.Version != nil {
 		dbVersion = *(app.Spec.Database.Version)
 	}
 
-	image, ok := imageList[dbVersion]
-
-	if !ok {
-		return errors.NewClowderError(fmt.Sprintf("Requested image version (%v), doesn't exist", dbVersion))
+	image, err := getImage(db.Env, dbVersion)
+	if err != nil {
+		return err
 	}
 
 	if app.Spec.Cyndi.Enabled {
@@ -139,9 +169,34 @@ func (db *localDbProvider) Provide(app *crd.ClowdApp) error {
 	}
 
 	resources := sizing.GetResourceRequirementsForSize(app.Spec.Database.DBResourceSize)
+	setEphemeralStorage(&resources, app.Spec.Database.EphemeralStorage)
+
+	var current *core.PodTemplateSpec
+	if len(dd.Spec.Template.Spec.Containers) > 0 {
+		current = dd.Spec.Template.DeepCopy()
+	}
 
 	labels := &map[string]string{"sub": "local_db"}
-	provutils.MakeLocalDB(dd, nn, app, labels, &dbCfg, image, db.Env.Spec.Providers.Database.PVC, app.Spec.Database.Name, &resources)
+	envVarNames := provutils.GetDBEnvVarNames(db.Env)
+	provutils.MakeLocalDB(dd, nn, app, db.Env, labels, &dbCfg, image, db.Env.Spec.Providers.Database.PVC, app.Spec.Database.Name, &resources, envVarNames)
+
+	setImagePullPolicy(dd, app.Spec.Database.ImagePullPolicy)
+	setReadinessQuery(dd, envVarNames, app.Spec.Database.ReadinessQuery)
+	setMaxConnections(dd, envVarNames, app.Spec.Database.MaxConnections)
+	setProbeThresholds(dd, app.Spec.Database.LivenessProbe, app.Spec.Database.ReadinessProbe)
+	setMetricsExporter(dd, db.Env, nn.Name, app.Spec.Database.Name)
+
+	var zone string
+	if db.Env.Spec.Providers.Database.PVC && db.Env.Spec.Providers.Database.ZoneAwareScheduling {
+		if zone, err = getVolumeZone(db.Ctx, db.Client, nn); err != nil {
+			return err
+		}
+	}
+	setZoneAffinity(dd, zone)
+	deferDisruptiveChanges(dd, current, app.Spec.Database.MaintenanceWindow, time.Now())
+	if err := db.drainForUpgrade(app, dd, current); err != nil {
+		return err
+	}
 
 	if err = db.Cache.Update(LocalDBDeployment, dd); err != nil {
 		return err
@@ -152,7 +207,10 @@ func (db *localDbProvider) Provide(app *crd.ClowdApp) error {
 		return err
 	}
 
-	provutils.MakeLocalDBService(s, nn, app, labels)
+	provutils.MakeLocalDBService(s, nn, app, labels, db.Env.Spec.Providers.Database.ServiceAnnotations)
+	setServiceSelector(s, app.Spec.Database.ServiceSelector)
+	provutils.SetDBSessionAffinity(s, db.Env.Spec.Providers.Database.SessionAffinity)
+	setMetricsPort(s, db.Env)
 
 	if err = db.Cache.Update(LocalDBService, s); err != nil {
 		return err
@@ -172,7 +230,13 @@ func (db *localDbProvider) Provide(app *crd.ClowdApp) error {
 			return err
 		}
 	}
-	db.Config.Database = &dbCfg
+
+	if err := db.provideMigrations(app, dd); err != nil {
+		return err
+	}
+
+	dbCfg.MaxConnections = getMaxConnections(app.Spec.Database.MaxConnections)
+	db.Config.SetDatabase(app.Spec.Database.Name, &dbCfg)
 	return nil
 }
 
@@ -192,6 +256,10 @@ func (db *localDbProvider) processSharedDB(app *crd.ClowdApp) error {
 		return err
 	}
 
+	if err := db.drainForSharedDB(app, refApp); err != nil {
+		return err
+	}
+
 	secret := core.Secret{}
 
 	inn := types.NamespacedName{
@@ -215,9 +283,310 @@ func (db *localDbProvider) processSharedDB(app *crd.ClowdApp) error {
 	if err != nil {
 		return errors.Wrap("couldn't convert to int", err)
 	}
+	dbCfg.Hostname = db.Env.GetServiceHostname(inn.Name, inn.Namespace)
 	dbCfg.AdminUsername = "postgres"
+	dbCfg.MaxConnections = getMaxConnections(refApp.Spec.Database.MaxConnections)
 
-	db.Config.Database = &dbCfg
+	db.Config.SetDatabase(refApp.Spec.Database.Name, &dbCfg)
 
 	return nil
 }
+
+// deferDisruptiveChanges holds back every change to the pod template of the
+// database, which would restart the database, while outside the app's
+// maintenance window. The deferred changes are recorded on the deployment so
+// that they can be surfaced in the app's status.
+func deferDisruptiveChanges(dd *apps.Deployment, current *core.PodTemplateSpec, window *crd.MaintenanceWindow, now time.Time) {
+	delete(dd.Annotations, PendingMaintenanceAnnotation)
+
+	if window == nil || current == nil || window.IsOpen(now) {
+		return
+	}
+
+	pending := templateChanges(&dd.Spec.Template, current)
+	if len(pending) == 0 {
+		return
+	}
+
+	dd.Spec.Template = *current.DeepCopy()
+	utils.UpdateAnnotations(dd, map[string]string{
+		PendingMaintenanceAnnotation: strings.Join(pending, ","),
+	})
+}
+
+// templateChanges names the changes between the current and desired pod templates of the
+// database. Changes to the database container are named by field, any other change to the
+// template, such as its sidecars, volumes or scheduling, is named podTemplate.
+func templateChanges(desired *core.PodTemplateSpec, current *core.PodTemplateSpec) []string {
+	if equality.Semantic.DeepEqual(desired, current) {
+		return nil
+	}
+	if len(desired.Spec.Containers) == 0 || len(current.Spec.Containers) == 0 {
+		return []string{"podTemplate"}
+	}
+
+	desiredRest, currentRest := desired.DeepCopy(), current.DeepCopy()
+	d, c := &desiredRest.Spec.Containers[0], &currentRest.Spec.Containers[0]
+
+	var changes []string
+	for _, field := range []struct {
+		name    string
+		desired interface{}
+		current interface{}
+		clear   func(*core.Container)
+	}{
+		{"image", d.Image, c.Image, func(c *core.Container) { c.Image = "" }},
+		{"resources", d.Resources, c.Resources, func(c *core.Container) { c.Resources = core.ResourceRequirements{} }},
+		{"args", d.Args, c.Args, func(c *core.Container) { c.Args = nil }},
+		{"env", d.Env, c.Env, func(c *core.Container) { c.Env = nil }},
+		{"livenessProbe", d.LivenessProbe, c.LivenessProbe, func(c *core.Container) { c.LivenessProbe = nil }},
+		{"readinessProbe", d.ReadinessProbe, c.ReadinessProbe, func(c *core.Container) { c.ReadinessProbe = nil }},
+	} {
+		if !equality.Semantic.DeepEqual(field.desired, field.current) {
+			changes = append(changes, field.name)
+		}
+		field.clear(d)
+		field.clear(c)
+	}
+
+	if !equality.Semantic.DeepEqual(desiredRest, currentRest) {
+		changes = append(changes, "podTemplate")
+	}
+
+	return changes
+}
+
+// getVolumeZone returns the zone of the volume bound to the named PVC. It is empty if the PVC does
+// not exist yet, is not bound, or its volume is not restricted to a zone.
+func getVolumeZone(ctx context.Context, pClient client.Client, nn types.NamespacedName) (string, error) {
+	pvc := &core.PersistentVolumeClaim{}
+	if err := pClient.Get(ctx, nn, pvc); err != nil {
+		if k8serr.IsNotFound(err) {
+			return "", nil
+		}
+		return "", errors.Wrap("get db pvc", err)
+	}
+	if pvc.Spec.VolumeName == "" {
+		return "", nil
+	}
+
+	pv := &core.PersistentVolume{}
+	if err := pClient.Get(ctx, types.NamespacedName{Name: pvc.Spec.VolumeName}, pv); err != nil {
+		if k8serr.IsNotFound(err) {
+			return "", nil
+		}
+		return "", errors.Wrap("get db pv", err)
+	}
+
+	return volumeZone(pv), nil
+}
+
+// volumeZone reads the zone from the volume's node affinity, falling back to the zone labels set
+// by older provisioners.
+func volumeZone(pv *core.PersistentVolume) string {
+	zoneKeys := []string{core.LabelTopologyZone, core.LabelFailureDomainBetaZone}
+
+	if pv.Spec.NodeAffinity != nil && pv.Spec.NodeAffinity.Required != nil {
+		for _, term := range pv.Spec.NodeAffinity.Required.NodeSelectorTerms {
+			for _, expr := range term.MatchExpressions {
+				for _, key := range zoneKeys {
+					if expr.Key == key && expr.Operator == core.NodeSelectorOpIn && len(expr.Values) == 1 {
+						return expr.Values[0]
+					}
+				}
+			}
+		}
+	}
+
+	for _, key := range zoneKeys {
+		if zone, ok := pv.GetLabels()[key]; ok {
+			return zone
+		}
+	}
+	return ""
+}
+
+// setZoneAffinity requires the database pod to run in the given zone, or removes the requirement
+// if the zone is empty. Only the node affinity is touched, any other affinity of the pod is kept.
+func setZoneAffinity(dd *apps.Deployment, zone string) {
+	affinity := dd.Spec.Template.Spec.Affinity
+	if zone == "" {
+		if affinity == nil {
+			return
+		}
+		affinity.NodeAffinity = nil
+		if affinity.PodAffinity == nil && affinity.PodAntiAffinity == nil {
+			dd.Spec.Template.Spec.Affinity = nil
+		}
+		return
+	}
+	if affinity == nil {
+		affinity = &core.Affinity{}
+		dd.Spec.Template.Spec.Affinity = affinity
+	}
+	affinity.NodeAffinity = &core.NodeAffinity{
+		RequiredDuringSchedulingIgnoredDuringExecution: &core.NodeSelector{
+			NodeSelectorTerms: []core.NodeSelectorTerm{{
+				MatchExpressions: []core.NodeSelectorRequirement{{
+					Key:      core.LabelTopologyZone,
+					Operator: core.NodeSelectorOpIn,
+					Values:   []string{zone},
+				}},
+			}},
+		},
+	}
+}
+
+// setImagePullPolicy overrides the pull policy the environment gives the database container, so
+// the database image can be pulled independently of the app's own images.
+func setImagePullPolicy(dd *apps.Deployment, policy core.PullPolicy) {
+	if policy == "" {
+		return
+	}
+	dd.Spec.Template.Spec.Containers[0].ImagePullPolicy = policy
+}
+
+// setReadinessQuery replaces the SELECT 1 run by the database readiness probe with the app's own
+// statement. The liveness probe is left alone so a slow bootstrap does not restart the database.
+func setReadinessQuery(dd *apps.Deployment, names crd.DatabaseEnvVarNames, query string) {
+	if query == "" {
+		return
+	}
+	probe := dd.Spec.Template.Spec.Containers[0].ReadinessProbe
+	probe.ProbeHandler = provutils.MakeLocalDBProbeHandler(names, query)
+}
+
+// setProbeThresholds applies the app's failure and success thresholds to the database probes,
+// keeping the defaults set by MakeLocalDB for any that are not given.
+func setProbeThresholds(dd *apps.Deployment, liveness *crd.DatabaseProbeThresholds, readiness *crd.DatabaseProbeThresholds) {
+	c := &dd.Spec.Template.Spec.Containers[0]
+	applyProbeThresholds(c.LivenessProbe, liveness)
+	applyProbeThresholds(c.ReadinessProbe, readiness)
+}
+
+func applyProbeThresholds(probe *core.Probe, thresholds *crd.DatabaseProbeThresholds) {
+	if thresholds == nil {
+		return
+	}
+	if thresholds.FailureThreshold != nil {
+		probe.FailureThreshold = *thresholds.FailureThreshold
+	}
+	if thresholds.SuccessThreshold != nil {
+		probe.SuccessThreshold = *thresholds.SuccessThreshold
+	}
+}
+
+// defaultMaxConnections is the max_connections both the RHEL and upstream postgres images use
+// unless told otherwise.
+const defaultMaxConnections = 100
+
+// setMaxConnections overrides the max_connections of the database, through the variable read by
+// the RHEL image or as a server argument to the upstream image.
+func setMaxConnections(dd *apps.Deployment, names crd.DatabaseEnvVarNames, maxConnections *int32) {
+	if maxConnections == nil {
+		return
+	}
+	c := &dd.Spec.Template.Spec.Containers[0]
+	value := strconv.Itoa(int(*maxConnections))
+	if names.Preset == "upstream" {
+		c.Args = []string{"-c", "max_connections=" + value}
+		return
+	}
+	c.Env = append(c.Env, core.EnvVar{Name: "POSTGRESQL_MAX_CONNECTIONS", Value: value})
+}
+
+// getMaxConnections returns the max_connections of a database that sets the given value, which
+// is the image's default if it sets none.
+func getMaxConnections(value *int32) *int {
+	maxConnections := defaultMaxConnections
+	if value != nil {
+		maxConnections = int(*value)
+	}
+	return &maxConnections
+}
+
+// metricsExporterPort is the port the postgres_exporter sidecar serves database metrics on.
+const metricsExporterPort = 9187
+
+// setMetricsExporter adds a postgres_exporter sidecar to the database if the environment enables
+// it. The exporter logs in to the named database with the credentials in the database secret.
+func setMetricsExporter(dd *apps.Deployment, env *crd.ClowdEnvironment, secretName string, dbName string) {
+	if !env.Spec.Providers.Database.MetricsExporter {
+		return
+	}
+
+	secretKey := func(key string) *core.EnvVarSource {
+		return &core.EnvVarSource{
+			SecretKeyRef: &core.SecretKeySelector{
+				LocalObjectReference: core.LocalObjectReference{Name: secretName},
+				Key:                  key,
+			},
+		}
+	}
+
+	c := core.Container{
+		Name:  "metrics-exporter",
+		Image: DefaultImageDatabaseExporter,
+		Env: []core.EnvVar{
+			{Name: "DATA_SOURCE_URI", Value: fmt.Sprintf("localhost:5432/%s?sslmode=disable", dbName)},
+			{Name: "DATA_SOURCE_USER", ValueFrom: secretKey("username")},
+			{Name: "DATA_SOURCE_PASS", ValueFrom: secretKey("password")},
+		},
+		Ports: []core.ContainerPort{{
+			Name:          "metrics",
+			ContainerPort: metricsExporterPort,
+			Protocol:      core.ProtocolTCP,
+		}},
+		Resources: core.ResourceRequirements{
+			Limits: core.ResourceList{
+				"cpu":    resource.MustParse("100m"),
+				"memory": resource.MustParse("128Mi"),
+			},
+			Requests: core.ResourceList{
+				"cpu":    resource.MustParse("20m"),
+				"memory": resource.MustParse("64Mi"),
+			},
+		},
+		TerminationMessagePath:   "/dev/termination-log",
+		TerminationMessagePolicy: core.TerminationMessageReadFile,
+		ImagePullPolicy:          core.PullIfNotPresent,
+	}
+	provutils.ApplyImageSettings(env, &c)
+
+	dd.Spec.Template.Spec.Containers = append(dd.Spec.Template.Spec.Containers, c)
+}
+
+// setMetricsPort exposes the metrics port of the postgres_exporter sidecar on the database
+// service, next to the database port, if the environment enables the exporter.
+func setMetricsPort(s *core.Service, env *crd.ClowdEnvironment) {
+	if !env.Spec.Providers.Database.MetricsExporter {
+		return
+	}
+	s.Spec.Ports = append(s.Spec.Ports, core.ServicePort{
+		Name:       "metrics",
+		Port:       metricsExporterPort,
+		Protocol:   core.ProtocolTCP,
+		TargetPort: intstr.FromInt(metricsExporterPort),
+	})
+}
+
+// setServiceSelector replaces the computed selector of the database service, used when adopting
+// an existing database whose pods are labelled differently.
+func setServiceSelector(s *core.Service, selector map[string]string) {
+	if len(selector) == 0 {
+		return
+	}
+	s.Spec.Selector = selector
+}
+
+func setEphemeralStorage(resources *core.ResourceRequirements, storage *crd.EphemeralStorageRequirements) {
+	if storage == nil {
+		return
+	}
+	if storage.Request != nil {
+		resources.Requests[core.ResourceEphemeralStorage] = *storage.Request
+	}
+	if storage.Limit != nil {
+		resources.Limits[core.ResourceEphemeralStorage] = *storage.Limit
+	}
+}
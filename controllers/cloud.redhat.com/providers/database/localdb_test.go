@@ -3,10 +3,12 @@ package database
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	apps "k8s.io/api/apps/v1"
 	core "k8s.io/api/core/v1"
 
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
@@ -68,6 +70,7 @@ func TestLocalDBPVC(t *testing.T) {
 	}
 
 	assert.True(t, accessModeFlag, "access mode does not equal ReadWriteOnce")
+	assert.Equal(t, core.PersistentVolumeFilesystem, *pvc.Spec.VolumeMode, "volume mode was not Filesystem")
 }
 
 func TestLocalDBService(t *testing.T) {
@@ -83,14 +86,28 @@ func TestLocalDBService(t *testing.T) {
 	s := core.Service{}
 
 	labels := &map[string]string{"sub": "test_db"}
-	provutils.MakeLocalDBService(&s, nn, &app, labels)
+	provutils.MakeLocalDBService(&s, nn, &app, labels, nil)
 
 	assert.Equal(t, s.Name, nn.Name, "name did not match expected")
-	assert.Equal(t, servicePorts[0], s.Spec.Ports[0], "port did not match the expected database port")
+	assert.Equal(t, servicePorts, s.Spec.Ports, "ports did not match the expected database port")
 	assert.Equal(t, "db", s.Spec.Selector["service"], "db selector was not set")
 	assert.Equal(t, app.Name, s.Spec.Selector["app"], "db app name selector was not set")
 }
 
+func TestLocalDBServiceAnnotations(t *testing.T) {
+	nn, app := getBaseElements()
+
+	s := core.Service{}
+
+	labels := &map[string]string{"sub": "test_db"}
+	annotations := map[string]string{
+		"service.beta.kubernetes.io/aws-load-balancer-internal": "true",
+	}
+	provutils.MakeLocalDBService(&s, nn, &app, labels, annotations)
+
+	assert.Equal(t, "true", s.GetAnnotations()["service.beta.kubernetes.io/aws-load-balancer-internal"], "service annotation was not set")
+}
+
 func TestLocalDBDeployment(t *testing.T) {
 	nn, app := getBaseElements()
 
@@ -123,9 +140,409 @@ func TestLocalDBDeployment(t *testing.T) {
 	image := "imagename:tag"
 
 	labels := &map[string]string{"sub": "test_db"}
-	provutils.MakeLocalDB(&d, nn, &app, labels, &cfg, image, true, "", nil)
+	provutils.MakeLocalDB(&d, nn, &app, &crd.ClowdEnvironment{}, labels, &cfg, image, true, "", nil, provutils.RHELDBEnvVarNames)
 
 	assert.Equal(t, image, d.Spec.Template.Spec.Containers[0].Image, "image requested does not match the one in spec")
 	assert.Equal(t, int32(5432), d.Spec.Template.Spec.Containers[0].Ports[0].ContainerPort, "port requested does not match the one in spec")
 	assert.Equal(t, &d.Spec.Template.Spec.Containers[0].Env, &envVars, "envvars didn't match")
 }
+
+func TestLocalDBEnvVarNames(t *testing.T) {
+	nn, app := getBaseElements()
+	cfg := config.DatabaseConfig{Username: "user", Password: "pass", AdminUsername: "postgres", AdminPassword: "admin"}
+	labels := &map[string]string{"sub": "test_db"}
+
+	env := &crd.ClowdEnvironment{}
+	assert.Equal(t, provutils.RHELDBEnvVarNames, provutils.GetDBEnvVarNames(env), "names should default to the rhel image")
+
+	env.Spec.Providers.Database.EnvVarNames = crd.DatabaseEnvVarNames{Preset: "upstream", AdminUser: "PG_ADMIN_USER"}
+	names := provutils.GetDBEnvVarNames(env)
+
+	d := apps.Deployment{}
+	provutils.MakeLocalDB(&d, nn, &app, env, labels, &cfg, "postgres:15", false, "db", nil, names)
+
+	c := d.Spec.Template.Spec.Containers[0]
+	assert.Equal(t, []core.EnvVar{
+		{Name: "POSTGRES_USER", Value: "user"},
+		{Name: "POSTGRES_PASSWORD", Value: "pass"},
+		{Name: "PG_ADMIN_USER", Value: "postgres"},
+		{Name: "POSTGRES_DB", Value: "db"},
+		{Name: "PGDATA", Value: "/var/lib/pgsql/data/pgdata"},
+	}, c.Env, "upstream names were not used")
+	assert.Equal(t, []string{"psql", "-U", "$(POSTGRES_USER)", "-d", "$(POSTGRES_DB)", "-c", "SELECT 1"}, c.LivenessProbe.Exec.Command)
+
+	setReadinessQuery(&d, names, "SELECT 2")
+	assert.Equal(t, "$(POSTGRES_USER)", d.Spec.Template.Spec.Containers[0].ReadinessProbe.Exec.Command[2])
+}
+
+func TestLocalDBEphemeralStorage(t *testing.T) {
+	resources := sizing.GetResourceRequirementsForSize("")
+
+	setEphemeralStorage(&resources, nil)
+	assert.NotContains(t, resources.Limits, core.ResourceEphemeralStorage)
+
+	request, limit := resource.MustParse("1Gi"), resource.MustParse("2Gi")
+	setEphemeralStorage(&resources, &crd.EphemeralStorageRequirements{Request: &request, Limit: &limit})
+	assert.Equal(t, request, resources.Requests[core.ResourceEphemeralStorage])
+	assert.Equal(t, limit, resources.Limits[core.ResourceEphemeralStorage])
+}
+
+func TestDeferDisruptiveChanges(t *testing.T) {
+	window := &crd.MaintenanceWindow{
+		Start:    "02:00",
+		Duration: metav1.Duration{Duration: time.Hour},
+	}
+	current := &core.PodTemplateSpec{Spec: core.PodSpec{
+		Containers: []core.Container{{
+			Image: "quay.io/cloudservices/postgresql-rds:12",
+			Resources: core.ResourceRequirements{
+				Limits: core.ResourceList{core.ResourceMemory: resource.MustParse("1Gi")},
+			},
+		}},
+	}}
+
+	newDeployment := func() *apps.Deployment {
+		return &apps.Deployment{Spec: apps.DeploymentSpec{Template: core.PodTemplateSpec{Spec: core.PodSpec{
+			Containers: []core.Container{{
+				Image: "quay.io/cloudservices/postgresql-rds:13",
+				Resources: core.ResourceRequirements{
+					Limits: core.ResourceList{core.ResourceMemory: resource.MustParse("2Gi")},
+				},
+			}},
+		}}}}
+	}
+
+	outside := time.Date(2022, 10, 11, 12, 0, 0, 0, time.UTC)
+	dd := newDeployment()
+	deferDisruptiveChanges(dd, current, window, outside)
+	assert.Equal(t, *current, dd.Spec.Template)
+	assert.Equal(t, "image,resources", dd.Annotations[PendingMaintenanceAnnotation])
+
+	inside := time.Date(2022, 10, 11, 2, 30, 0, 0, time.UTC)
+	deferDisruptiveChanges(dd, current, window, inside)
+	assert.Equal(t, current.Spec.Containers[0].Image, dd.Spec.Template.Spec.Containers[0].Image)
+	assert.NotContains(t, dd.Annotations, PendingMaintenanceAnnotation)
+
+	dd = newDeployment()
+	deferDisruptiveChanges(dd, current, window, inside)
+	assert.Equal(t, "quay.io/cloudservices/postgresql-rds:13", dd.Spec.Template.Spec.Containers[0].Image)
+
+	dd = newDeployment()
+	deferDisruptiveChanges(dd, nil, window, outside)
+	assert.Equal(t, "quay.io/cloudservices/postgresql-rds:13", dd.Spec.Template.Spec.Containers[0].Image)
+	assert.NotContains(t, dd.Annotations, PendingMaintenanceAnnotation)
+
+	// Every other change to the pod template restarts the database as well, and is deferred
+	nn, app := getBaseElements()
+	labels := &map[string]string{"sub": "local_db"}
+	env := &crd.ClowdEnvironment{}
+	running := apps.Deployment{}
+	provutils.MakeLocalDB(&running, nn, &app, env, labels, &config.DatabaseConfig{}, "imagename:tag", false, "", nil, provutils.UpstreamDBEnvVarNames)
+
+	for _, tc := range []struct {
+		name    string
+		change  func(*apps.Deployment)
+		pending string
+	}{
+		{"args", func(d *apps.Deployment) {
+			setMaxConnections(d, provutils.UpstreamDBEnvVarNames, utils.Int32Ptr(250))
+		}, "args"},
+		{"env", func(d *apps.Deployment) {
+			setMaxConnections(d, provutils.RHELDBEnvVarNames, utils.Int32Ptr(250))
+		}, "env"},
+		{"probes", func(d *apps.Deployment) {
+			setReadinessQuery(d, provutils.UpstreamDBEnvVarNames, "SELECT 1 FROM schema_migrations")
+			setProbeThresholds(d, &crd.DatabaseProbeThresholds{FailureThreshold: utils.Int32Ptr(10)}, nil)
+		}, "livenessProbe,readinessProbe"},
+		{"sidecar", func(d *apps.Deployment) {
+			env := &crd.ClowdEnvironment{}
+			env.Spec.Providers.Database.MetricsExporter = true
+			setMetricsExporter(d, env, nn.Name, "reqapp")
+		}, "podTemplate"},
+		{"affinity", func(d *apps.Deployment) {
+			setZoneAffinity(d, "us-east-1a")
+		}, "podTemplate"},
+	} {
+		dd := running.DeepCopy()
+		tc.change(dd)
+		assert.NotEqual(t, running.Spec.Template, dd.Spec.Template, tc.name)
+
+		deferDisruptiveChanges(dd, &running.Spec.Template, window, outside)
+		assert.Equal(t, running.Spec.Template, dd.Spec.Template, tc.name)
+		assert.Equal(t, tc.pending, dd.Annotations[PendingMaintenanceAnnotation], tc.name)
+	}
+
+	dd = running.DeepCopy()
+	deferDisruptiveChanges(dd, &running.Spec.Template, window, outside)
+	assert.NotContains(t, dd.Annotations, PendingMaintenanceAnnotation, "an unchanged template has nothing pending")
+}
+
+func TestLocalDBImageSettings(t *testing.T) {
+	nn, app := getBaseElements()
+	env := &crd.ClowdEnvironment{
+		Spec: crd.ClowdEnvironmentSpec{
+			Providers: crd.ProvidersConfig{
+				Deployment: crd.DeploymentConfig{
+					ImagePullPolicy: core.PullNever,
+					RegistryMirror:  "mirror.example.com:5000/",
+				},
+			},
+		},
+	}
+
+	d := apps.Deployment{}
+	labels := &map[string]string{"sub": "test_db"}
+	provutils.MakeLocalDB(&d, nn, &app, env, labels, &config.DatabaseConfig{}, "quay.io/cloudservices/postgresql-rds:12", false, "", nil, provutils.RHELDBEnvVarNames)
+
+	assert.Equal(t, "mirror.example.com:5000/cloudservices/postgresql-rds:12", d.Spec.Template.Spec.Containers[0].Image)
+	assert.Equal(t, core.PullNever, d.Spec.Template.Spec.Containers[0].ImagePullPolicy)
+}
+
+func TestLocalDBImagePullPolicyOverride(t *testing.T) {
+	nn, app := getBaseElements()
+	env := &crd.ClowdEnvironment{
+		Spec: crd.ClowdEnvironmentSpec{
+			Providers: crd.ProvidersConfig{
+				Deployment: crd.DeploymentConfig{ImagePullPolicy: core.PullAlways},
+			},
+		},
+	}
+
+	d := apps.Deployment{}
+	labels := &map[string]string{"sub": "test_db"}
+	provutils.MakeLocalDB(&d, nn, &app, env, labels, &config.DatabaseConfig{}, "quay.io/cloudservices/postgresql-rds:12", false, "", nil, provutils.RHELDBEnvVarNames)
+	setImagePullPolicy(&d, "")
+	assert.Equal(t, core.PullAlways, d.Spec.Template.Spec.Containers[0].ImagePullPolicy)
+
+	setImagePullPolicy(&d, core.PullIfNotPresent)
+	assert.Equal(t, core.PullIfNotPresent, d.Spec.Template.Spec.Containers[0].ImagePullPolicy)
+}
+
+func TestLocalDBServiceSelector(t *testing.T) {
+	nn, app := getBaseElements()
+
+	s := core.Service{}
+	labels := &map[string]string{"sub": "local_db"}
+	provutils.MakeLocalDBService(&s, nn, &app, labels, nil)
+
+	setServiceSelector(&s, nil)
+	assert.Equal(t, "db", s.Spec.Selector["service"], "computed selector was not kept")
+
+	selector := map[string]string{"name": "legacy-postgres"}
+	setServiceSelector(&s, selector)
+	assert.Equal(t, selector, s.Spec.Selector, "selector override was not applied")
+}
+
+func TestLocalDBServiceSessionAffinity(t *testing.T) {
+	nn, app := getBaseElements()
+
+	s := core.Service{}
+	labels := &map[string]string{"sub": "local_db"}
+	provutils.MakeLocalDBService(&s, nn, &app, labels, nil)
+
+	provutils.SetDBSessionAffinity(&s, nil)
+	assert.Equal(t, core.ServiceAffinityNone, s.Spec.SessionAffinity, "affinity did not default to None")
+	assert.Nil(t, s.Spec.SessionAffinityConfig)
+
+	timeout := int32(600)
+	provutils.SetDBSessionAffinity(&s, &crd.DatabaseSessionAffinity{Type: core.ServiceAffinityClientIP, TimeoutSeconds: &timeout})
+	assert.Equal(t, core.ServiceAffinityClientIP, s.Spec.SessionAffinity, "affinity was not set")
+	assert.Equal(t, int32(600), *s.Spec.SessionAffinityConfig.ClientIP.TimeoutSeconds, "affinity timeout was not set")
+
+	provutils.SetDBSessionAffinity(&s, &crd.DatabaseSessionAffinity{Type: core.ServiceAffinityClientIP})
+	assert.Equal(t, int32(10800), *s.Spec.SessionAffinityConfig.ClientIP.TimeoutSeconds, "affinity timeout did not default")
+
+	provutils.SetDBSessionAffinity(&s, &crd.DatabaseSessionAffinity{Type: core.ServiceAffinityNone})
+	assert.Equal(t, core.ServiceAffinityNone, s.Spec.SessionAffinity, "affinity was not reset")
+	assert.Nil(t, s.Spec.SessionAffinityConfig)
+}
+
+func TestLocalDBServiceMetricsPort(t *testing.T) {
+	nn, app := getBaseElements()
+	labels := &map[string]string{"sub": "local_db"}
+	databasePort := core.ServicePort{
+		Name:       "database",
+		Port:       5432,
+		Protocol:   core.ProtocolTCP,
+		TargetPort: intstr.FromInt(5432),
+	}
+
+	env := &crd.ClowdEnvironment{}
+	s := core.Service{}
+	provutils.MakeLocalDBService(&s, nn, &app, labels, nil)
+	setMetricsPort(&s, env)
+	assert.Equal(t, []core.ServicePort{databasePort}, s.Spec.Ports, "metrics port should only be exposed with the exporter")
+
+	env.Spec.Providers.Database.MetricsExporter = true
+	s = core.Service{}
+	provutils.MakeLocalDBService(&s, nn, &app, labels, nil)
+	setMetricsPort(&s, env)
+	assert.Equal(t, []core.ServicePort{databasePort, {
+		Name:       "metrics",
+		Port:       9187,
+		Protocol:   core.ProtocolTCP,
+		TargetPort: intstr.FromInt(9187),
+	}}, s.Spec.Ports)
+}
+
+func TestLocalDBMetricsExporter(t *testing.T) {
+	nn, app := getBaseElements()
+	labels := &map[string]string{"sub": "local_db"}
+
+	env := &crd.ClowdEnvironment{}
+	d := apps.Deployment{}
+	provutils.MakeLocalDB(&d, nn, &app, env, labels, &config.DatabaseConfig{}, "imagename:tag", false, "", nil, provutils.RHELDBEnvVarNames)
+	setMetricsExporter(&d, env, nn.Name, "reqapp")
+	assert.Len(t, d.Spec.Template.Spec.Containers, 1, "exporter should only run when enabled")
+
+	env.Spec.Providers.Database.MetricsExporter = true
+	setMetricsExporter(&d, env, nn.Name, "reqapp")
+	assert.Len(t, d.Spec.Template.Spec.Containers, 2, "exporter sidecar was not added")
+
+	c := d.Spec.Template.Spec.Containers[1]
+	assert.Equal(t, "metrics-exporter", c.Name)
+	assert.Equal(t, DefaultImageDatabaseExporter, c.Image)
+	assert.Equal(t, []core.ContainerPort{{Name: "metrics", ContainerPort: 9187, Protocol: core.ProtocolTCP}}, c.Ports)
+	assert.Equal(t, "localhost:5432/reqapp?sslmode=disable", c.Env[0].Value)
+	assert.Equal(t, nn.Name, c.Env[1].ValueFrom.SecretKeyRef.Name)
+	assert.Equal(t, "username", c.Env[1].ValueFrom.SecretKeyRef.Key)
+	assert.Equal(t, "password", c.Env[2].ValueFrom.SecretKeyRef.Key)
+}
+
+func TestLocalDBReadinessQuery(t *testing.T) {
+	nn, app := getBaseElements()
+
+	d := apps.Deployment{}
+	labels := &map[string]string{"sub": "local_db"}
+	provutils.MakeLocalDB(&d, nn, &app, &crd.ClowdEnvironment{}, labels, &config.DatabaseConfig{}, "imagename:tag", false, "", nil, provutils.RHELDBEnvVarNames)
+
+	setReadinessQuery(&d, provutils.RHELDBEnvVarNames, "")
+	c := d.Spec.Template.Spec.Containers[0]
+	assert.Equal(t, "SELECT 1", c.ReadinessProbe.Exec.Command[6], "default readiness query was changed")
+
+	setReadinessQuery(&d, provutils.RHELDBEnvVarNames, "SELECT 1 FROM schema_migrations LIMIT 1")
+	c = d.Spec.Template.Spec.Containers[0]
+	assert.Equal(t, "SELECT 1 FROM schema_migrations LIMIT 1", c.ReadinessProbe.Exec.Command[6], "readiness query was not applied")
+	assert.Equal(t, "SELECT 1", c.LivenessProbe.Exec.Command[6], "liveness query should not change")
+}
+
+func TestLocalDBMaxConnections(t *testing.T) {
+	nn, app := getBaseElements()
+	labels := &map[string]string{"sub": "local_db"}
+
+	d := apps.Deployment{}
+	provutils.MakeLocalDB(&d, nn, &app, &crd.ClowdEnvironment{}, labels, &config.DatabaseConfig{}, "imagename:tag", false, "", nil, provutils.RHELDBEnvVarNames)
+	envCount := len(d.Spec.Template.Spec.Containers[0].Env)
+	setMaxConnections(&d, provutils.RHELDBEnvVarNames, nil)
+	assert.Len(t, d.Spec.Template.Spec.Containers[0].Env, envCount, "image default should be left alone")
+	assert.Equal(t, 100, *getMaxConnections(app.Spec.Database.MaxConnections), "image default should be reported")
+
+	app.Spec.Database.MaxConnections = utils.Int32Ptr(250)
+	setMaxConnections(&d, provutils.RHELDBEnvVarNames, app.Spec.Database.MaxConnections)
+	assert.Contains(t, d.Spec.Template.Spec.Containers[0].Env, core.EnvVar{Name: "POSTGRESQL_MAX_CONNECTIONS", Value: "250"})
+	assert.Equal(t, 250, *getMaxConnections(app.Spec.Database.MaxConnections))
+
+	d = apps.Deployment{}
+	provutils.MakeLocalDB(&d, nn, &app, &crd.ClowdEnvironment{}, labels, &config.DatabaseConfig{}, "imagename:tag", false, "", nil, provutils.UpstreamDBEnvVarNames)
+	setMaxConnections(&d, provutils.UpstreamDBEnvVarNames, app.Spec.Database.MaxConnections)
+	assert.Equal(t, []string{"-c", "max_connections=250"}, d.Spec.Template.Spec.Containers[0].Args)
+}
+
+func TestLocalDBProbeThresholds(t *testing.T) {
+	nn, app := getBaseElements()
+
+	d := apps.Deployment{}
+	labels := &map[string]string{"sub": "local_db"}
+	provutils.MakeLocalDB(&d, nn, &app, &crd.ClowdEnvironment{}, labels, &config.DatabaseConfig{}, "imagename:tag", false, "", nil, provutils.RHELDBEnvVarNames)
+
+	setProbeThresholds(&d, nil, nil)
+	c := d.Spec.Template.Spec.Containers[0]
+	assert.Equal(t, int32(3), c.LivenessProbe.FailureThreshold, "default liveness failure threshold was changed")
+	assert.Equal(t, int32(3), c.ReadinessProbe.FailureThreshold, "default readiness failure threshold was changed")
+
+	failures, successes := int32(10), int32(2)
+	setProbeThresholds(&d,
+		&crd.DatabaseProbeThresholds{FailureThreshold: &failures},
+		&crd.DatabaseProbeThresholds{SuccessThreshold: &successes},
+	)
+	c = d.Spec.Template.Spec.Containers[0]
+	assert.Equal(t, int32(10), c.LivenessProbe.FailureThreshold, "liveness failure threshold was not applied")
+	assert.Equal(t, int32(1), c.LivenessProbe.SuccessThreshold, "liveness success threshold should not change")
+	assert.Equal(t, int32(3), c.ReadinessProbe.FailureThreshold, "readiness failure threshold should not change")
+	assert.Equal(t, int32(2), c.ReadinessProbe.SuccessThreshold, "readiness success threshold was not applied")
+}
+
+func TestLocalDBVolumeZone(t *testing.T) {
+	pv := &core.PersistentVolume{}
+	assert.Equal(t, "", volumeZone(pv), "unrestricted volume should have no zone")
+
+	pv.Labels = map[string]string{core.LabelFailureDomainBetaZone: "us-east-1a"}
+	assert.Equal(t, "us-east-1a", volumeZone(pv), "zone label was not read")
+
+	pv.Spec.NodeAffinity = &core.VolumeNodeAffinity{
+		Required: &core.NodeSelector{
+			NodeSelectorTerms: []core.NodeSelectorTerm{{
+				MatchExpressions: []core.NodeSelectorRequirement{{
+					Key:      core.LabelTopologyZone,
+					Operator: core.NodeSelectorOpIn,
+					Values:   []string{"us-east-1b"},
+				}},
+			}},
+		},
+	}
+	assert.Equal(t, "us-east-1b", volumeZone(pv), "node affinity zone should take precedence")
+}
+
+func TestLocalDBZoneAffinity(t *testing.T) {
+	d := apps.Deployment{}
+
+	setZoneAffinity(&d, "us-east-1b")
+	terms := d.Spec.Template.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+	assert.Equal(t, []core.NodeSelectorRequirement{{
+		Key:      core.LabelTopologyZone,
+		Operator: core.NodeSelectorOpIn,
+		Values:   []string{"us-east-1b"},
+	}}, terms[0].MatchExpressions)
+
+	setZoneAffinity(&d, "")
+	assert.Nil(t, d.Spec.Template.Spec.Affinity, "affinity was not removed")
+
+	// Other affinities of the pod are left alone
+	antiAffinity := &core.PodAntiAffinity{PreferredDuringSchedulingIgnoredDuringExecution: []core.WeightedPodAffinityTerm{{Weight: 100}}}
+	d.Spec.Template.Spec.Affinity = &core.Affinity{PodAntiAffinity: antiAffinity}
+	setZoneAffinity(&d, "us-east-1b")
+	assert.NotNil(t, d.Spec.Template.Spec.Affinity.NodeAffinity)
+	assert.Equal(t, antiAffinity, d.Spec.Template.Spec.Affinity.PodAntiAffinity)
+
+	setZoneAffinity(&d, "")
+	assert.Nil(t, d.Spec.Template.Spec.Affinity.NodeAffinity, "node affinity was not removed")
+	assert.Equal(t, antiAffinity, d.Spec.Template.Spec.Affinity.PodAntiAffinity)
+}
+
+func TestLocalDBReadinessQueryEnvVarNames(t *testing.T) {
+	nn, app := getBaseElements()
+	labels := &map[string]string{"sub": "local_db"}
+
+	env := &crd.ClowdEnvironment{}
+	env.Spec.Providers.Database.EnvVarNames = crd.DatabaseEnvVarNames{Preset: "upstream"}
+	names := provutils.GetDBEnvVarNames(env)
+
+	d := apps.Deployment{}
+	provutils.MakeLocalDB(&d, nn, &app, env, labels, &config.DatabaseConfig{}, "postgres:15", false, "db", nil, names)
+
+	setReadinessQuery(&d, names, "SELECT 1 FROM schema_migrations LIMIT 1")
+	c := d.Spec.Template.Spec.Containers[0]
+	assert.Equal(t, []string{"psql", "-U", "$(POSTGRES_USER)", "-d", "$(POSTGRES_DB)", "-c", "SELECT 1 FROM schema_migrations LIMIT 1"}, c.ReadinessProbe.Exec.Command)
+	assert.Equal(t, []string{"psql", "-U", "$(POSTGRES_USER)", "-d", "$(POSTGRES_DB)", "-c", "SELECT 1"}, c.LivenessProbe.Exec.Command)
+
+	// Names set on their own replace those of the preset in the probes too
+	env.Spec.Providers.Database.EnvVarNames.User = "DB_OWNER"
+	names = provutils.GetDBEnvVarNames(env)
+
+	d = apps.Deployment{}
+	provutils.MakeLocalDB(&d, nn, &app, env, labels, &config.DatabaseConfig{}, "postgres:15", false, "db", nil, names)
+
+	c = d.Spec.Template.Spec.Containers[0]
+	assert.Equal(t, "$(DB_OWNER)", c.ReadinessProbe.Exec.Command[2])
+	assert.Equal(t, "$(POSTGRES_DB)", c.ReadinessProbe.Exec.Command[4])
+}
@@ -0,0 +1,194 @@
+package database
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+
+	crd "github.com/RedHatInsights/clowder/apis/cloud.redhat.com/v1alpha1"
+	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/errors"
+
+	apps "k8s.io/api/apps/v1"
+	batch "k8s.io/api/batch/v1"
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	rc "github.com/RedHatInsights/rhc-osdk-utils/resourceCache"
+	"github.com/RedHatInsights/rhc-osdk-utils/utils"
+)
+
+// LocalDBMigrationJob is the ident referring to the job running the local DB's migrations.
+var LocalDBMigrationJob = rc.NewSingleResourceIdent(ProvName, "local_db_migration_job", &batch.Job{})
+
+// MigrationsTable is the table in the app's database recording the migration scripts that have run.
+const MigrationsTable = "clowder_migrations"
+
+// MaintenanceModeAnnotation is set to "true" on a ClowdApp to have maintenanceMode set in its
+// config while the migrations of its local database run.
+const MaintenanceModeAnnotation = "clowder/migrations-maintenance-mode"
+
+// migrationsMountPath is where the scripts of the migrations ConfigMap are mounted in the job.
+const migrationsMountPath = "/migrations"
+
+// migrationsBackoffLimit is how many times a failing migration job is retried before it is failed.
+const migrationsBackoffLimit int32 = 3
+
+// migrationsScript waits for the database to come up, then runs each script that is not yet
+// recorded in the migrations table, in the order of their names. A script is recorded in the same
+// transaction it runs in, so one that fails is neither half applied nor recorded, and is retried.
+// ConfigMap keys cannot contain quotes, so the names are safe to use in the SQL.
+var migrationsScript = fmt.Sprintf(`set -e
+until pg_isready -q; do sleep 2; done
+psql -v ON_ERROR_STOP=1 -c "CREATE TABLE IF NOT EXISTS %[1]s (name text PRIMARY KEY, applied_at timestamptz NOT NULL DEFAULT now())"
+for script in $(ls %[2]s | LC_ALL=C sort); do
+  if [ -n "$(psql -tA -c "SELECT 1 FROM %[1]s WHERE name = '$script'")" ]; then
+    continue
+  fi
+  echo "Running migration $script"
+  psql -v ON_ERROR_STOP=1 --single-transaction -f "%[2]s/$script" -c "INSERT INTO %[1]s (name) VALUES ('$script')"
+done
+`, MigrationsTable, migrationsMountPath)
+
+// migrationsHash returns a short hash of the scripts, so that the job is replaced whenever a
+// script is added or changed.
+func migrationsHash(scripts map[string]string) string {
+	keys := make([]string, 0, len(scripts))
+	for key := range scripts {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	h := sha256.New()
+	for _, key := range keys {
+		fmt.Fprintf(h, "%s\x00%s\x00", key, scripts[key])
+	}
+	return hex.EncodeToString(h.Sum(nil))[:8]
+}
+
+// MigrationJobName returns the name of the job running the given migration scripts against the
+// app's local database.
+func MigrationJobName(app *crd.ClowdApp, scripts map[string]string) string {
+	return fmt.Sprintf("%s-db-migrate-%s", app.Name, migrationsHash(scripts))
+}
+
+// provideMigrations runs the app's migration scripts against its local database from a job, using
+// the image of the database's deployment. As jobs cannot be changed once created, a job that
+// already exists is left as it is, and a new one with a new name is made when the scripts change,
+// replacing the old one.
+func (db *localDbProvider) provideMigrations(app *crd.ClowdApp, dd *apps.Deployment) error {
+	migrations := app.Spec.Database.Migrations
+	if migrations == nil {
+		return nil
+	}
+
+	// This is a REAL call here, the ConfigMap is managed by the app's owners, not by Clowder
+	cm := &core.ConfigMap{}
+	cmNN := types.NamespacedName{Name: migrations.ConfigMapName, Namespace: app.Namespace}
+	if err := db.Client.Get(db.Ctx, cmNN, cm); err != nil {
+		return errors.Wrap(fmt.Sprintf("couldn't get migrations configmap [%s]", cmNN.Name), err)
+	}
+
+	nn := types.NamespacedName{Name: MigrationJobName(app, cm.Data), Namespace: app.Namespace}
+	if len(nn.Name) > 63 {
+		return errors.NewClowderError(fmt.Sprintf("migration job name [%s] is longer than 63 characters", nn.Name))
+	}
+
+	j := &batch.Job{}
+	if err := db.Cache.Create(LocalDBMigrationJob, nn, j); err != nil {
+		return err
+	}
+
+	if j.GetUID() == "" {
+		makeMigrationJob(j, nn, app, dd, migrations.ConfigMapName)
+	}
+
+	// A failed job keeps the app in maintenance mode, as it may have left the schema half
+	// migrated, until a job succeeds
+	if app.GetAnnotations()[MaintenanceModeAnnotation] == "true" && !MigrationJobSucceeded(j) {
+		db.Config.MaintenanceMode = utils.TruePtr()
+	}
+
+	return db.Cache.Update(LocalDBMigrationJob, j)
+}
+
+// MigrationJobRunning returns true when the migration job has not yet completed or failed,
+// including when it is only about to be created.
+func MigrationJobRunning(j *batch.Job) bool {
+	for _, condition := range j.Status.Conditions {
+		if (condition.Type == batch.JobComplete || condition.Type == batch.JobFailed) && condition.Status == core.ConditionTrue {
+			return false
+		}
+	}
+	return true
+}
+
+// MigrationJobSucceeded returns true once the migration job has completed.
+func MigrationJobSucceeded(j *batch.Job) bool {
+	for _, condition := range j.Status.Conditions {
+		if condition.Type == batch.JobComplete && condition.Status == core.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
+// makeMigrationJob populates the job that runs the migrations, connecting to the database as the
+// app's user so that the objects the scripts create belong to the app. The job runs under the
+// app's service account, like the database it migrates, rather than the namespace's default one.
+func makeMigrationJob(j *batch.Job, nn types.NamespacedName, app *crd.ClowdApp, dd *apps.Deployment, configMapName string) {
+	dbContainer := dd.Spec.Template.Spec.Containers[0]
+
+	labels := app.GetLabels()
+	labels["service"] = "db-migrations"
+
+	labeler := utils.MakeLabeler(nn, labels, app)
+	labeler(j)
+
+	secretEnv := func(name string, key string) core.EnvVar {
+		return core.EnvVar{
+			Name: name,
+			ValueFrom: &core.EnvVarSource{
+				SecretKeyRef: &core.SecretKeySelector{
+					LocalObjectReference: core.LocalObjectReference{Name: dd.Name},
+					Key:                  key,
+				},
+			},
+		}
+	}
+
+	backoffLimit := migrationsBackoffLimit
+	j.Spec.BackoffLimit = &backoffLimit
+	j.Spec.Template.ObjectMeta.Labels = labels
+	j.Spec.Template.Spec.RestartPolicy = core.RestartPolicyNever
+	j.Spec.Template.Spec.ServiceAccountName = app.GetClowdSAName()
+	j.Spec.Template.Spec.Containers = []core.Container{{
+		Name:    "migrations",
+		Image:   dbContainer.Image,
+		Command: []string{"/bin/bash", "-c", migrationsScript},
+		Env: []core.EnvVar{
+			secretEnv("PGHOST", "db.host"),
+			secretEnv("PGPORT", "db.port"),
+			secretEnv("PGUSER", "db.user"),
+			secretEnv("PGPASSWORD", "db.password"),
+			secretEnv("PGDATABASE", "db.name"),
+		},
+		VolumeMounts: []core.VolumeMount{{
+			Name:      "migrations",
+			MountPath: migrationsMountPath,
+			ReadOnly:  true,
+		}},
+		TerminationMessagePath:   core.TerminationMessagePathDefault,
+		TerminationMessagePolicy: core.TerminationMessageFallbackToLogsOnError,
+		ImagePullPolicy:          dbContainer.ImagePullPolicy,
+	}}
+	j.Spec.Template.Spec.Volumes = []core.Volume{{
+		Name: "migrations",
+		VolumeSource: core.VolumeSource{
+			ConfigMap: &core.ConfigMapVolumeSource{
+				LocalObjectReference: core.LocalObjectReference{Name: configMapName},
+			},
+		},
+	}}
+	j.Spec.Template.Spec.ImagePullSecrets = app.Spec.Database.ImagePullSecrets
+}
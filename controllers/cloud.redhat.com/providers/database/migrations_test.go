@@ -0,0 +1,93 @@
+package database
+
+import (
+	"testing"
+
+	apps "k8s.io/api/apps/v1"
+	batch "k8s.io/api/batch/v1"
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMigrationJobName(t *testing.T) {
+	_, app := getBaseElements()
+	scripts := map[string]string{
+		"001-tables.sql":  "CREATE TABLE hosts (id int);",
+		"002-indexes.sql": "CREATE INDEX ON hosts (id);",
+	}
+
+	name := MigrationJobName(&app, scripts)
+	assert.Regexp(t, "^reqapp-db-migrate-[0-9a-f]{8}$", name)
+	assert.Equal(t, name, MigrationJobName(&app, map[string]string{
+		"002-indexes.sql": "CREATE INDEX ON hosts (id);",
+		"001-tables.sql":  "CREATE TABLE hosts (id int);",
+	}))
+
+	// Adding or changing a script makes a new job
+	scripts["003-columns.sql"] = "ALTER TABLE hosts ADD COLUMN name text;"
+	assert.NotEqual(t, name, MigrationJobName(&app, scripts))
+	delete(scripts, "003-columns.sql")
+	scripts["002-indexes.sql"] = "CREATE UNIQUE INDEX ON hosts (id);"
+	assert.NotEqual(t, name, MigrationJobName(&app, scripts))
+}
+
+func TestMakeMigrationJob(t *testing.T) {
+	_, app := getBaseElements()
+	app.Spec.Database.ImagePullSecrets = []core.LocalObjectReference{{Name: "quay-pull"}}
+
+	dd := &apps.Deployment{}
+	dd.Name = "reqapp-db"
+	dd.Spec.Template.Spec.Containers = []core.Container{{
+		Image:           "quay.io/cloudservices/postgresql-rds:12",
+		ImagePullPolicy: core.PullAlways,
+	}}
+
+	j := &batch.Job{}
+	nn := types.NamespacedName{Name: "reqapp-db-migrate-0123abcd", Namespace: "default"}
+	makeMigrationJob(j, nn, &app, dd, "reqapp-migrations")
+
+	assert.Equal(t, nn.Name, j.Name)
+	assert.Equal(t, "db-migrations", j.Labels["service"])
+	assert.Equal(t, "db-migrations", j.Spec.Template.Labels["service"])
+	assert.Equal(t, core.RestartPolicyNever, j.Spec.Template.Spec.RestartPolicy)
+	assert.Equal(t, app.GetClowdSAName(), j.Spec.Template.Spec.ServiceAccountName)
+	assert.Equal(t, app.Spec.Database.ImagePullSecrets, j.Spec.Template.Spec.ImagePullSecrets)
+
+	c := j.Spec.Template.Spec.Containers[0]
+	assert.Equal(t, "quay.io/cloudservices/postgresql-rds:12", c.Image)
+	assert.Equal(t, core.PullAlways, c.ImagePullPolicy)
+	assert.Contains(t, c.Command[2], MigrationsTable)
+	assert.Equal(t, migrationsMountPath, c.VolumeMounts[0].MountPath)
+	assert.Equal(t, "reqapp-migrations", j.Spec.Template.Spec.Volumes[0].ConfigMap.Name)
+
+	for _, env := range c.Env {
+		assert.Equal(t, "reqapp-db", env.ValueFrom.SecretKeyRef.Name, env.Name)
+	}
+}
+
+func TestMigrationJobRunning(t *testing.T) {
+	j := &batch.Job{}
+	assert.True(t, MigrationJobRunning(j))
+
+	j.Status.Conditions = []batch.JobCondition{{Type: batch.JobComplete, Status: core.ConditionFalse}}
+	assert.True(t, MigrationJobRunning(j))
+
+	j.Status.Conditions = []batch.JobCondition{{Type: batch.JobComplete, Status: core.ConditionTrue}}
+	assert.False(t, MigrationJobRunning(j))
+
+	j.Status.Conditions = []batch.JobCondition{{Type: batch.JobFailed, Status: core.ConditionTrue}}
+	assert.False(t, MigrationJobRunning(j))
+}
+
+func TestMigrationJobSucceeded(t *testing.T) {
+	j := &batch.Job{}
+	assert.False(t, MigrationJobSucceeded(j))
+
+	j.Status.Conditions = []batch.JobCondition{{Type: batch.JobFailed, Status: core.ConditionTrue}}
+	assert.False(t, MigrationJobSucceeded(j))
+
+	j.Status.Conditions = []batch.JobCondition{{Type: batch.JobComplete, Status: core.ConditionTrue}}
+	assert.True(t, MigrationJobSucceeded(j))
+}
@@ -2,6 +2,7 @@ package database
 
 import (
 	"fmt"
+	"strconv"
 
 	crd "github.com/RedHatInsights/clowder/apis/cloud.redhat.com/v1alpha1"
 	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/errors"
@@ -13,6 +14,7 @@ var DefaultImageDatabasePG12 = "quay.io/cloudservices/postgresql-rds:12-9ee2984"
 var DefaultImageDatabasePG13 = "quay.io/cloudservices/postgresql-rds:13-9ee2984"
 var DefaultImageDatabasePG14 = "quay.io/cloudservices/postgresql-rds:14-99c8c27"
 var DefaultImageDatabasePG15 = "quay.io/cloudservices/postgresql-rds:15-e9e67a5"
+var DefaultImageDatabaseExporter = "quay.io/prometheuscommunity/postgres-exporter:v0.15.0"
 
 // ProvName is the providers name ident.
 var ProvName = "database"
@@ -20,8 +22,17 @@ var ProvName = "database"
 var imageList map[int32]string
 
 // GetDatabase returns the correct database provider based on the environment.
+// Apps that set a mode override are handed to the provider for that mode
+// instead, see modeOverrideProvider.
 func GetDatabase(c *p.Provider) (p.ClowderProvider, error) {
-	dbMode := c.Env.Spec.Providers.Database.Mode
+	envProvider, err := getDatabaseForMode(c, string(c.Env.Spec.Providers.Database.Mode))
+	if err != nil {
+		return nil, err
+	}
+	return &modeOverrideProvider{Provider: *c, envProvider: envProvider}, nil
+}
+
+func getDatabaseForMode(c *p.Provider, dbMode string) (p.ClowderProvider, error) {
 	switch dbMode {
 	case "shared":
 		return NewSharedDBProvider(c)
@@ -48,6 +59,25 @@ func init() {
 	}
 }
 
+// getImage returns the image of the given PostgreSQL version, preferring the environment's
+// override over the image built into Clowder. A version whose image has been built out as empty,
+// and is not overridden, is reported as missing rather than creating a database pod that cannot
+// start.
+func getImage(env *crd.ClowdEnvironment, version int32) (string, error) {
+	if image := env.Spec.Providers.Database.Images[strconv.Itoa(int(version))]; image != "" {
+		return image, nil
+	}
+
+	image, ok := imageList[version]
+	if !ok {
+		return "", errors.NewClowderError(fmt.Sprintf("Requested image version (%v), doesn't exist", version))
+	}
+	if image == "" {
+		return "", &errors.DatabaseImageMissing{Env: env.Name, Version: version}
+	}
+	return image, nil
+}
+
 func checkDependency(app *crd.ClowdApp) error {
 	for _, appName := range app.Spec.Dependencies {
 		if app.Spec.Database.SharedDBAppName == appName {
@@ -57,3 +87,44 @@ func checkDependency(app *crd.ClowdApp) error {
 
 	return errors.NewClowderError("The requested app's db was not found in the dependencies")
 }
+
+// modeOverrideProvider wraps the environment's database provider and allows
+// individual apps to be served by a different mode via spec.database.modeOverride.
+type modeOverrideProvider struct {
+	p.Provider
+	envProvider p.ClowderProvider
+}
+
+func (m *modeOverrideProvider) EnvProvide() error {
+	return m.envProvider.EnvProvide()
+}
+
+func (m *modeOverrideProvider) Provide(app *crd.ClowdApp) error {
+	mode := app.Spec.Database.ModeOverride
+	if mode == "" || mode == string(m.Env.Spec.Providers.Database.Mode) {
+		return m.envProvider.Provide(app)
+	}
+
+	if !m.Env.Spec.Providers.Database.AllowAppModeOverride {
+		errStr := fmt.Sprintf("environment %s does not allow app database mode overrides", m.Env.Name)
+		return errors.NewClowderError(errStr)
+	}
+
+	if mode != "app-interface" {
+		errStr := fmt.Sprintf("db mode %s cannot be used as an app override", mode)
+		return errors.NewClowderError(errStr)
+	}
+
+	prov, err := NewAppInterfaceDBProvider(&m.Provider)
+	if err != nil {
+		return err
+	}
+
+	// The CA bundle is normally fetched when the environment is reconciled in
+	// app-interface mode, so make sure it's present before serving the app.
+	if err := prov.EnvProvide(); err != nil {
+		return err
+	}
+
+	return prov.Provide(app)
+}
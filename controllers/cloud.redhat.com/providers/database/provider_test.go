@@ -0,0 +1,90 @@
+package database
+
+import (
+	"testing"
+
+	crd "github.com/RedHatInsights/clowder/apis/cloud.redhat.com/v1alpha1"
+	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/config"
+	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/errors"
+	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newOverrideTestProvider(t *testing.T, allow bool) providers.ClowderProvider {
+	pr := &providers.Provider{
+		Env: &crd.ClowdEnvironment{
+			Spec: crd.ClowdEnvironmentSpec{
+				Providers: crd.ProvidersConfig{
+					Database: crd.DatabaseConfig{
+						Mode:                 "none",
+						AllowAppModeOverride: allow,
+					},
+				},
+			},
+		},
+		Config: &config.AppConfig{},
+	}
+	prov, err := GetDatabase(pr)
+	assert.NoError(t, err)
+	return prov
+}
+
+func TestModeOverrideNotSet(t *testing.T) {
+	prov := newOverrideTestProvider(t, false)
+	app := &crd.ClowdApp{Spec: crd.ClowdAppSpec{Database: crd.DatabaseSpec{Name: "test-db"}}}
+	assert.NoError(t, prov.Provide(app))
+}
+
+func TestModeOverrideNotAllowed(t *testing.T) {
+	prov := newOverrideTestProvider(t, false)
+	app := &crd.ClowdApp{Spec: crd.ClowdAppSpec{Database: crd.DatabaseSpec{
+		Name:         "test-db",
+		ModeOverride: "app-interface",
+	}}}
+	err := prov.Provide(app)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "does not allow app database mode overrides")
+}
+
+func TestModeOverrideUnsupportedMode(t *testing.T) {
+	prov := newOverrideTestProvider(t, true)
+	app := &crd.ClowdApp{Spec: crd.ClowdAppSpec{Database: crd.DatabaseSpec{
+		Name:         "test-db",
+		ModeOverride: "local",
+	}}}
+	assert.Error(t, prov.Provide(app))
+}
+
+func TestGetImageMissing(t *testing.T) {
+	defer func(image string) { imageList[12] = image }(imageList[12])
+
+	env := &crd.ClowdEnvironment{ObjectMeta: metav1.ObjectMeta{Name: "env"}}
+
+	image, err := getImage(env, 12)
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultImageDatabasePG12, image)
+
+	_, err = getImage(env, 11)
+	assert.Error(t, err)
+
+	imageList[12] = ""
+	_, err = getImage(env, 12)
+	var imageErr *errors.DatabaseImageMissing
+	assert.ErrorAs(t, err, &imageErr)
+	assert.Equal(t, "env", imageErr.Env)
+	assert.Equal(t, int32(12), imageErr.Version)
+
+	// The environment's override fills in a missing image, and wins over a built in one
+	env.Spec.Providers.Database.Images = map[string]string{
+		"12": "quay.io/mirror/postgresql:12",
+		"15": "quay.io/mirror/postgresql:15",
+	}
+	image, err = getImage(env, 12)
+	assert.NoError(t, err)
+	assert.Equal(t, "quay.io/mirror/postgresql:12", image)
+
+	image, err = getImage(env, 15)
+	assert.NoError(t, err)
+	assert.Equal(t, "quay.io/mirror/postgresql:15", image)
+}
@@ -24,7 +24,6 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 
 	rc "github.com/RedHatInsights/rhc-osdk-utils/resourceCache"
-	"github.com/RedHatInsights/rhc-osdk-utils/utils"
 )
 
 // SharedDBDeployment is the ident referring to the local DB deployment object.
@@ -73,21 +72,21 @@ func createVersionedDatabase(p *providers.Provider, version int32) (*config.Data
 
 	dbCfg := config.DatabaseConfig{}
 
-	password, err := utils.RandPassword(16, provutils.RCharSet)
+	password, err := provutils.GeneratePassword(p.Env)
 	if err != nil {
 		return nil, errors.Wrap("password generate failed", err)
 	}
 
-	pgPassword, err := utils.RandPassword(16, provutils.RCharSet)
+	pgPassword, err := provutils.GeneratePassword(p.Env)
 	if err != nil {
 		return nil, errors.Wrap("pgPassword generate failed", err)
 	}
 
 	dataInit := func() map[string]string {
 		return map[string]string{
-			"hostname": fmt.Sprintf("%v.%v.svc", nn.Name, nn.Namespace),
+			"hostname": p.Env.GetServiceHostname(nn.Name, nn.Namespace),
 			"port":     "5432",
-			"username": utils.RandString(16),
+			"username": provutils.GenerateUsername(p.Env),
 			"password": password,
 			"pgPass":   pgPassword,
 			"name":     p.Env.Name,
@@ -103,15 +102,13 @@ func createVersionedDatabase(p *providers.Provider, version int32) (*config.Data
 	if err != nil {
 		return nil, errors.Wrap("couldn't convert to int", err)
 	}
+	dbCfg.Hostname = p.Env.GetServiceHostname(nn.Name, nn.Namespace)
 	dbCfg.AdminUsername = "postgres"
 	dbCfg.SslMode = "disable"
 
-	var image string
-
-	image, ok := imageList[version]
-
-	if !ok {
-		return nil, errors.NewClowderError(fmt.Sprintf("Requested image version (%v), doesn't exist", version))
+	image, err := getImage(p.Env, version)
+	if err != nil {
+		return nil, err
 	}
 
 	imgComponents := strings.Split(image, ":")
@@ -120,7 +117,10 @@ func createVersionedDatabase(p *providers.Provider, version int32) (*config.Data
 
 	labels := &map[string]string{"sub": fmt.Sprintf("shared_db_%s", strconv.Itoa(int(version)))}
 
-	provutils.MakeLocalDB(dd, nn, p.Env, labels, &dbCfg, image, p.Env.Spec.Providers.Database.PVC, p.Env.Name, nil)
+	envVarNames := provutils.GetDBEnvVarNames(p.Env)
+	provutils.MakeLocalDB(dd, nn, p.Env, p.Env, labels, &dbCfg, image, p.Env.Spec.Providers.Database.PVC, p.Env.Name, nil, envVarNames)
+	setMaxConnections(dd, envVarNames, p.Env.Spec.Providers.Database.MaxConnections)
+	setMetricsExporter(dd, p.Env, nn.Name, p.Env.Name)
 
 	if err = p.Cache.Update(SharedDBDeployment, dd); err != nil {
 		return nil, err
@@ -131,7 +131,9 @@ func createVersionedDatabase(p *providers.Provider, version int32) (*config.Data
 		return nil, err
 	}
 
-	provutils.MakeLocalDBService(s, nn, p.Env, labels)
+	provutils.MakeLocalDBService(s, nn, p.Env, labels, p.Env.Spec.Providers.Database.ServiceAnnotations)
+	provutils.SetDBSessionAffinity(s, p.Env.Spec.Providers.Database.SessionAffinity)
+	setMetricsPort(s, p.Env)
 
 	if err = p.Cache.Update(SharedDBService, s); err != nil {
 		return nil, err
@@ -314,19 +316,23 @@ func (db *sharedDbProvider) Provide(app *crd.ClowdApp) error {
 		"password": dbCfg.Password,
 		"pgPass":   dbCfg.AdminPassword,
 		"name":     app.Spec.Database.Name,
+		URLKey:     ConnectionURL(&dbCfg, app.Spec.Database.URLFormat),
 	}
 
 	secret.Name = nn.Name
 	secret.Namespace = nn.Namespace
 	secret.ObjectMeta.OwnerReferences = []metav1.OwnerReference{app.MakeOwnerReference()}
 	secret.Type = core.SecretTypeOpaque
+	providers.SetSecretLabels(secret, app)
 
 	if err := db.Cache.Update(SharedDBAppSecret, secret); err != nil {
 		return err
 	}
 
 	dbCfg.Name = app.Spec.Database.Name
-	db.Config.Database = &dbCfg
+	// The shared database is not configured per app, so it has the environment's limit
+	dbCfg.MaxConnections = getMaxConnections(db.Env.Spec.Providers.Database.MaxConnections)
+	db.Config.SetDatabase(app.Spec.Database.Name, &dbCfg)
 
 	return nil
 }
@@ -371,8 +377,9 @@ func (db *sharedDbProvider) processSharedDB(app *crd.ClowdApp) error {
 		return errors.Wrap("couldn't convert to int", err)
 	}
 	dbCfg.AdminUsername = "postgres"
+	dbCfg.MaxConnections = getMaxConnections(db.Env.Spec.Providers.Database.MaxConnections)
 
-	db.Config.Database = &dbCfg
+	db.Config.SetDatabase(refApp.Spec.Database.Name, &dbCfg)
 
 	return nil
 }
@@ -0,0 +1,68 @@
+package database
+
+import (
+	"net"
+	"net/url"
+	"strconv"
+
+	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/config"
+	rc "github.com/RedHatInsights/rhc-osdk-utils/resourceCache"
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// URLKey is the key of the database secret holding the connection URL, for apps that can only be
+// handed a single DATABASE_URL.
+const URLKey = "db.url"
+
+// URLFormatJDBC selects a jdbc:postgresql: connection URL, rather than the default postgres:// one.
+const URLFormatJDBC = "jdbc"
+
+// ConnectionURL returns the URL to connect to the database as the app user, with every part of it
+// encoded. The jdbc format passes the credentials as parameters, as JDBC drivers do not accept
+// them in the authority of the URL.
+func ConnectionURL(dbCfg *config.DatabaseConfig, format string) string {
+	u := &url.URL{
+		Host:    net.JoinHostPort(dbCfg.Hostname, strconv.Itoa(dbCfg.Port)),
+		Path:    "/" + dbCfg.Name,
+		RawPath: "/" + url.PathEscape(dbCfg.Name),
+	}
+
+	query := url.Values{}
+	if dbCfg.SslMode != "" {
+		query.Set("sslmode", dbCfg.SslMode)
+	}
+
+	if format == URLFormatJDBC {
+		u.Scheme = "postgresql"
+		query.Set("user", dbCfg.Username)
+		query.Set("password", dbCfg.Password)
+		u.RawQuery = query.Encode()
+		return "jdbc:" + u.String()
+	}
+
+	u.Scheme = "postgres"
+	u.User = url.UserPassword(dbCfg.Username, dbCfg.Password)
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// setSecretURL stores the connection URL in the database secret held in the cache, for secrets
+// that were generated before they carried one, or whose URL no longer matches their credentials.
+func setSecretURL(cache *rc.ObjectCache, ident rc.ResourceIdent, nn types.NamespacedName, dbURL string) error {
+	secret := &core.Secret{}
+	if err := cache.Get(ident, secret, nn); err != nil {
+		return err
+	}
+
+	if secret.StringData != nil {
+		secret.StringData[URLKey] = dbURL
+	} else {
+		if secret.Data == nil {
+			secret.Data = map[string][]byte{}
+		}
+		secret.Data[URLKey] = []byte(dbURL)
+	}
+
+	return cache.Update(ident, secret)
+}
@@ -1,8 +1,6 @@
 package dependencies
 
 import (
-	"fmt"
-
 	crd "github.com/RedHatInsights/clowder/apis/cloud.redhat.com/v1alpha1"
 	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/config"
 	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/errors"
@@ -27,6 +25,7 @@ func (dep *dependenciesProvider) makeDependencies(app *crd.ClowdApp) error {
 		dep.Provider.Env.Spec.Providers.Web.TLS.Port,
 		dep.Provider.Env.Spec.Providers.Web.PrivatePort,
 		dep.Provider.Env.Spec.Providers.Web.TLS.PrivatePort,
+		dep.Provider.Env.GetDNSSuffix(),
 	)
 
 	// Return if no deps
@@ -55,6 +54,7 @@ func (dep *dependenciesProvider) makeDependencies(app *crd.ClowdApp) error {
 		dep.Provider.Env.Spec.Providers.Web.TLS.Port,
 		dep.Provider.Env.Spec.Providers.Web.PrivatePort,
 		dep.Provider.Env.Spec.Providers.Web.TLS.PrivatePort,
+		dep.Provider.Env.GetDNSSuffix(),
 		app,
 		apps,
 	)
@@ -82,6 +82,7 @@ func makeDepConfig(
 	tlsPort int32,
 	privatePort int32,
 	tlsPrivatePort int32,
+	dnsSuffix string,
 	app *crd.ClowdApp,
 	apps *crd.ClowdAppList,
 ) (missingDeps []string) {
@@ -92,8 +93,8 @@ func makeDepConfig(
 		appMap[iapp.Name] = iapp
 	}
 
-	missingDeps = processAppEndpoints(appMap, app.Spec.Dependencies, depConfig, privDepConfig, webPort, tlsPort, privatePort, tlsPrivatePort)
-	_ = processAppEndpoints(appMap, app.Spec.OptionalDependencies, depConfig, privDepConfig, webPort, tlsPort, privatePort, tlsPrivatePort)
+	missingDeps = processAppEndpoints(appMap, app.Spec.Dependencies, depConfig, privDepConfig, webPort, tlsPort, privatePort, tlsPrivatePort, dnsSuffix)
+	_ = processAppEndpoints(appMap, app.Spec.OptionalDependencies, depConfig, privDepConfig, webPort, tlsPort, privatePort, tlsPrivatePort, dnsSuffix)
 
 	return missingDeps
 }
@@ -107,6 +108,7 @@ func processAppEndpoints(
 	tlsPort int32,
 	privatePort int32,
 	tlsPrivatePort int32,
+	dnsSuffix string,
 ) (missingDeps []string) {
 
 	missingDeps = []string{}
@@ -125,7 +127,7 @@ func processAppEndpoints(
 			if bool(innerDeployment.Web) || innerDeployment.WebServices.Public.Enabled {
 				name := depApp.GetDeploymentNamespacedName(&innerDeployment).Name
 				*depConfig = append(*depConfig, config.DependencyEndpoint{
-					Hostname: fmt.Sprintf("%s.%s.svc", name, depApp.Namespace),
+					Hostname: crd.ServiceHostname(name, depApp.Namespace, dnsSuffix),
 					Port:     int(webPort),
 					Name:     innerDeployment.Name,
 					App:      depApp.Name,
@@ -135,7 +137,7 @@ func processAppEndpoints(
 			if innerDeployment.WebServices.Private.Enabled {
 				name := depApp.GetDeploymentNamespacedName(&innerDeployment).Name
 				*privDepConfig = append(*privDepConfig, config.PrivateDependencyEndpoint{
-					Hostname: fmt.Sprintf("%s.%s.svc", name, depApp.Namespace),
+					Hostname: crd.ServiceHostname(name, depApp.Namespace, dnsSuffix),
 					Port:     int(privatePort),
 					Name:     innerDeployment.Name,
 					App:      depApp.Name,
@@ -0,0 +1,109 @@
+package dependencies
+
+import (
+	"testing"
+
+	crd "github.com/RedHatInsights/clowder/apis/cloud.redhat.com/v1alpha1"
+	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCustomDNSSuffix(t *testing.T) {
+	env := crd.ClowdEnvironment{
+		Spec: crd.ClowdEnvironmentSpec{
+			ServiceConfig: crd.ServiceConfig{
+				DNSSuffix: "svc.cluster.local",
+			},
+		},
+	}
+
+	objMeta := defaultMetaObject()
+
+	app := crd.ClowdApp{
+		ObjectMeta: objMeta,
+		Spec: crd.ClowdAppSpec{
+			Dependencies: []string{"bopper"},
+		},
+	}
+
+	nobjMeta := objMeta
+	nobjMeta.Name = "bopper"
+	nobjMeta.Namespace = "bopperspace"
+	apps := crd.ClowdAppList{
+		Items: []crd.ClowdApp{{
+			ObjectMeta: nobjMeta,
+			Spec: crd.ClowdAppSpec{
+				Deployments: []crd.Deployment{{
+					WebServices: crd.WebServices{
+						Private: crd.PrivateWebService{Enabled: true},
+						Public:  crd.PublicWebService{Enabled: true},
+					},
+					Name: "bopper",
+				}},
+			},
+		}},
+	}
+
+	deps := []config.DependencyEndpoint{}
+	privDeps := []config.PrivateDependencyEndpoint{}
+
+	missing := makeDepConfig(&deps, &privDeps, webPort, tlsPort, privatePort, tlsPrivatePort, env.GetDNSSuffix(), &app, &apps)
+
+	assert.Empty(t, missing)
+	assert.Equal(t, "bopper-bopper.bopperspace.svc.cluster.local", deps[0].Hostname)
+	assert.Equal(t, "bopper-bopper.bopperspace.svc.cluster.local", privDeps[0].Hostname)
+	assert.Equal(t, "reqapp-db.default.svc.cluster.local", env.GetServiceHostname("reqapp-db", "default"))
+}
+
+func TestDefaultDNSSuffix(t *testing.T) {
+	env := crd.ClowdEnvironment{}
+
+	assert.Equal(t, "svc", env.GetDNSSuffix())
+	assert.Equal(t, "reqapp-db.default.svc", env.GetServiceHostname("reqapp-db", "default"))
+}
+
+func TestDependencyHostnames(t *testing.T) {
+	app := crd.ClowdApp{
+		ObjectMeta: defaultMetaObject(),
+		Spec: crd.ClowdAppSpec{
+			Dependencies: []string{"neighbour", "remote"},
+		},
+	}
+
+	makeApp := func(name string, namespace string) crd.ClowdApp {
+		objMeta := defaultMetaObject()
+		objMeta.Name = name
+		objMeta.Namespace = namespace
+		return crd.ClowdApp{
+			ObjectMeta: objMeta,
+			Spec: crd.ClowdAppSpec{
+				Deployments: []crd.Deployment{{
+					Name: "api",
+					WebServices: crd.WebServices{
+						Private: crd.PrivateWebService{Enabled: true},
+						Public:  crd.PublicWebService{Enabled: true},
+					},
+				}},
+			},
+		}
+	}
+	apps := crd.ClowdAppList{Items: []crd.ClowdApp{
+		makeApp("neighbour", "default"),
+		makeApp("remote", "remotespace"),
+	}}
+
+	deps := []config.DependencyEndpoint{}
+	privDeps := []config.PrivateDependencyEndpoint{}
+
+	missing := makeDepConfig(&deps, &privDeps, webPort, tlsPort, privatePort, tlsPrivatePort, "svc", &app, &apps)
+	assert.Empty(t, missing)
+
+	// Dependencies in the app's own namespace are still named with it
+	assert.Equal(t, "neighbour-api.default.svc", deps[0].Hostname)
+	assert.Equal(t, "neighbour-api.default.svc", privDeps[0].Hostname)
+
+	// Dependencies elsewhere carry their own namespace
+	assert.Equal(t, "remote-api.remotespace.svc", deps[1].Hostname)
+	assert.Equal(t, "remote-api.remotespace.svc", privDeps[1].Hostname)
+	assert.Equal(t, crd.ServiceHostname("remote-api", "remotespace", "svc"), deps[1].Hostname)
+}
@@ -68,7 +68,7 @@ func TestSingleDependency(t *testing.T) {
 	deps := []config.DependencyEndpoint{}
 	privDeps := []config.PrivateDependencyEndpoint{}
 
-	missing := makeDepConfig(&deps, &privDeps, webPort, tlsPort, privatePort, tlsPrivatePort, &app, &apps)
+	missing := makeDepConfig(&deps, &privDeps, webPort, tlsPort, privatePort, tlsPrivatePort, "svc", &app, &apps)
 
 	if len(missing) > 0 {
 		t.Errorf("We got a missing dep when there shouldn't have been one")
@@ -125,7 +125,7 @@ func TestMissingDependency(t *testing.T) {
 	deps := []config.DependencyEndpoint{}
 	privDeps := []config.PrivateDependencyEndpoint{}
 
-	missing := makeDepConfig(&deps, &privDeps, webPort, tlsPort, privatePort, tlsPrivatePort, &app, &apps)
+	missing := makeDepConfig(&deps, &privDeps, webPort, tlsPort, privatePort, tlsPrivatePort, "svc", &app, &apps)
 
 	if len(privDeps) > 0 {
 		t.Errorf("We got private deps we shouldn't have")
@@ -191,7 +191,7 @@ func TestOptionalDependency(t *testing.T) {
 	deps := []config.DependencyEndpoint{}
 	privDeps := []config.PrivateDependencyEndpoint{}
 
-	makeDepConfig(&deps, &privDeps, webPort, tlsPort, privatePort, tlsPrivatePort, &app, &apps)
+	makeDepConfig(&deps, &privDeps, webPort, tlsPort, privatePort, tlsPrivatePort, "svc", &app, &apps)
 
 	if len(privDeps) > 0 {
 		t.Errorf("We got private deps we shouldn't have")
@@ -284,7 +284,7 @@ func TestMultiDependency(t *testing.T) {
 	deps := []config.DependencyEndpoint{}
 	privDeps := []config.PrivateDependencyEndpoint{}
 
-	missing := makeDepConfig(&deps, &privDeps, webPort, tlsPort, privatePort, tlsPrivatePort, &app, &apps)
+	missing := makeDepConfig(&deps, &privDeps, webPort, tlsPort, privatePort, tlsPrivatePort, "svc", &app, &apps)
 
 	if len(privDeps) > 0 {
 		t.Errorf("We got private deps we shouldn't have")
@@ -14,8 +14,11 @@ type deploymentProvider struct {
 // CoreDeployment is the deployment for the apps deployments.
 var CoreDeployment = rc.NewMultiResourceIdent(ProvName, "core_deployment", &apps.Deployment{})
 
+// CoreDaemonSet is the daemonset for the apps deployments that are run as DaemonSets.
+var CoreDaemonSet = rc.NewMultiResourceIdent(ProvName, "core_daemonset", &apps.DaemonSet{})
+
 func NewDeploymentProvider(p *providers.Provider) (providers.ClowderProvider, error) {
-	p.Cache.AddPossibleGVKFromIdent(CoreDeployment)
+	p.Cache.AddPossibleGVKFromIdent(CoreDeployment, CoreDaemonSet)
 	return &deploymentProvider{Provider: *p}, nil
 }
 
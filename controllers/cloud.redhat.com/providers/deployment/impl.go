@@ -23,6 +23,10 @@ const (
 
 func (dp *deploymentProvider) makeDeployment(deployment crd.Deployment, app *crd.ClowdApp) error {
 
+	if deployment.IsDaemonSet() {
+		return dp.makeDaemonSet(deployment, app)
+	}
+
 	d := &apps.Deployment{}
 	nn := app.GetDeploymentNamespacedName(&deployment)
 
@@ -37,15 +41,31 @@ func (dp *deploymentProvider) makeDeployment(deployment crd.Deployment, app *crd
 	return dp.Cache.Update(CoreDeployment, d)
 }
 
-func setLocalAnnotations(env *crd.ClowdEnvironment, deployment *crd.Deployment, d *apps.Deployment, app *crd.ClowdApp) {
+func (dp *deploymentProvider) makeDaemonSet(deployment crd.Deployment, app *crd.ClowdApp) error {
+
+	ds := &apps.DaemonSet{}
+	nn := app.GetDeploymentNamespacedName(&deployment)
+
+	if err := dp.Cache.Create(CoreDaemonSet, nn, ds); err != nil {
+		return err
+	}
+
+	if err := initDaemonSet(app, dp.Env, ds, nn, &deployment); err != nil {
+		return err
+	}
+
+	return dp.Cache.Update(CoreDaemonSet, ds)
+}
+
+func setLocalAnnotations(env *crd.ClowdEnvironment, deployment *crd.Deployment, template *core.PodTemplateSpec, app *crd.ClowdApp) {
 	if env.Spec.Providers.Web.Mode == "local" && (deployment.WebServices.Public.Enabled || bool(deployment.Web)) {
 		annotations := map[string]string{
-			"clowder/authsidecar-image":   provutils.GetCaddyImage(env),
+			"clowder/authsidecar-image":   provutils.MirrorImage(env, provutils.GetCaddyImage(env)),
 			"clowder/authsidecar-enabled": "true",
 			"clowder/authsidecar-port":    strconv.Itoa(int(env.Spec.Providers.Web.Port)),
 			"clowder/authsidecar-config":  fmt.Sprintf("caddy-config-%s-%s", app.Name, deployment.Name),
 		}
-		utils.UpdateAnnotations(&d.Spec.Template, annotations)
+		utils.UpdateAnnotations(template, annotations)
 	}
 
 }
@@ -155,11 +175,66 @@ func setReadinessProbe(pod *crd.PodSpec, deployment *crd.Deployment, env *crd.Cl
 		}
 
 		c.ReadinessProbe = &readinessProbe
+	} else if pod.DisableDefaultReadinessProbe {
+		return
 	} else if bool(deployment.Web) || deployment.WebServices.Public.Enabled {
 		readinessProbe := makeBaseProbe(env)
 		readinessProbe.InitialDelaySeconds = 45
 		c.ReadinessProbe = &readinessProbe
+	} else if deployment.WebServices.Private.Enabled {
+		readinessProbe := makeTCPProbe(getPrivatePort(env))
+		c.ReadinessProbe = &readinessProbe
+	}
+}
+
+// setDrainDelay adds a preStop hook that sleeps for the deployment's drain delay, leaving the
+// container without one unless a delay is set.
+func setDrainDelay(deployment *crd.Deployment, c *core.Container) {
+	delay := DrainDelaySeconds(deployment)
+	if delay == 0 {
+		return
+	}
+
+	c.Lifecycle = &core.Lifecycle{
+		PreStop: &core.LifecycleHandler{
+			Exec: &core.ExecAction{
+				Command: []string{"sleep", strconv.Itoa(int(delay))},
+			},
+		},
+	}
+}
+
+// DrainDelaySeconds returns how long the deployment's container sleeps before it is sent SIGTERM,
+// which is no time at all unless the deployment sets a drain delay.
+func DrainDelaySeconds(deployment *crd.Deployment) int32 {
+	if deployment.DrainDelaySeconds != nil {
+		return *deployment.DrainDelaySeconds
+	}
+	return 0
+}
+
+// makeTCPProbe returns a probe that only checks that the given port accepts
+// connections, for services where there is no known health endpoint.
+func makeTCPProbe(port int32) core.Probe {
+	return core.Probe{
+		ProbeHandler: core.ProbeHandler{
+			TCPSocket: &core.TCPSocketAction{
+				Port: intstr.FromInt(int(port)),
+			},
+		},
+		FailureThreshold:    3,
+		InitialDelaySeconds: 10,
+		PeriodSeconds:       10,
+		SuccessThreshold:    1,
+		TimeoutSeconds:      1,
+	}
+}
+
+func getPrivatePort(env *crd.ClowdEnvironment) int32 {
+	if env.Spec.Providers.Web.PrivatePort != 0 {
+		return env.Spec.Providers.Web.PrivatePort
 	}
+	return 10000
 }
 
 func setImagePullPolicy(env *crd.ClowdEnvironment, c *core.Container) {
@@ -180,9 +255,9 @@ func setImagePullPolicy(env *crd.ClowdEnvironment, c *core.Container) {
 
 }
 
-func loadEnvVars(pod crd.PodSpec) []core.EnvVar {
+func loadEnvVars(env *crd.ClowdEnvironment, pod crd.PodSpec) []core.EnvVar {
 	envvars := pod.Env
-	envvars = append(envvars, core.EnvVar{Name: "ACG_CONFIG", Value: "/cdapp/cdappconfig.json"})
+	envvars = append(envvars, core.EnvVar{Name: "ACG_CONFIG", Value: provutils.ConfigFilePath(env)})
 
 	for _, envvar := range envvars {
 		if envvar.ValueFrom != nil {
@@ -204,16 +279,11 @@ func initDeployment(app *crd.ClowdApp, env *crd.ClowdEnvironment, d *apps.Deploy
 
 	d.Kind = "Deployment"
 
-	pod := deployment.PodSpec
-
 	utils.UpdateAnnotations(d, app.ObjectMeta.Annotations, deployment.Metadata.Annotations)
 
-	setLocalAnnotations(env, deployment, d, app)
-
 	setMinReplicas(deployment, d)
 
 	d.Spec.Selector = &metav1.LabelSelector{MatchLabels: labels}
-	d.Spec.Template.ObjectMeta.Labels = labels
 	d.Spec.Strategy = apps.DeploymentStrategy{
 		Type: apps.RollingUpdateDeploymentStrategyType,
 		RollingUpdate: &apps.RollingUpdateDeployment{
@@ -221,20 +291,64 @@ func initDeployment(app *crd.ClowdApp, env *crd.ClowdEnvironment, d *apps.Deploy
 			MaxUnavailable: &intstr.IntOrString{Type: intstr.String, StrVal: string("25%")},
 		},
 	}
-	d.Spec.ProgressDeadlineSeconds = utils.Int32Ptr(600)
-
-	utils.UpdateAnnotations(&d.Spec.Template, pod.Metadata.Annotations)
+	d.Spec.ProgressDeadlineSeconds = utils.Int32Ptr(int(provutils.DefaultProgressDeadlineSeconds))
+	if deployment.ProgressDeadlineSeconds != nil {
+		d.Spec.ProgressDeadlineSeconds = utils.Int32Ptr(int(*deployment.ProgressDeadlineSeconds))
+	}
 
 	setDeploymentStrategy(deployment, d)
 
+	if err := initPodTemplate(app, env, &d.Spec.Template, nn, deployment, labels); err != nil {
+		return err
+	}
+
+	for _, vol := range d.Spec.Template.Spec.Volumes {
+		setRecreateDeploymentStrategyForPVCs(vol, d)
+	}
+
+	return nil
+}
+
+func initDaemonSet(app *crd.ClowdApp, env *crd.ClowdEnvironment, ds *apps.DaemonSet, nn types.NamespacedName, deployment *crd.Deployment) error {
+	labels := app.GetLabels()
+	labels["pod"] = nn.Name
+	app.SetObjectMeta(ds, crd.Name(nn.Name), crd.Labels(labels))
+
+	ds.Kind = "DaemonSet"
+
+	utils.UpdateAnnotations(ds, app.ObjectMeta.Annotations, deployment.Metadata.Annotations)
+
+	ds.Spec.Selector = &metav1.LabelSelector{MatchLabels: labels}
+	ds.Spec.UpdateStrategy = apps.DaemonSetUpdateStrategy{
+		Type: apps.RollingUpdateDaemonSetStrategyType,
+		RollingUpdate: &apps.RollingUpdateDaemonSet{
+			MaxUnavailable: &intstr.IntOrString{Type: intstr.String, StrVal: string("25%")},
+		},
+	}
+
+	return initPodTemplate(app, env, &ds.Spec.Template, nn, deployment, labels)
+}
+
+// initPodTemplate sets up the pod template shared by both kinds of workload a deployment can be
+// run as.
+func initPodTemplate(app *crd.ClowdApp, env *crd.ClowdEnvironment, template *core.PodTemplateSpec, nn types.NamespacedName, deployment *crd.Deployment, labels map[string]string) error {
+	pod := deployment.PodSpec
+
+	setLocalAnnotations(env, deployment, template, app)
+
+	template.ObjectMeta.Labels = labels
+
+	utils.UpdateAnnotations(template, pod.Metadata.Annotations)
+
 	c := core.Container{
 		Name:                     nn.Name,
 		Image:                    pod.Image,
 		Command:                  pod.Command,
 		Args:                     pod.Args,
-		Env:                      loadEnvVars(pod),
+		Env:                      loadEnvVars(env, pod),
 		Resources:                ProcessResources(&pod, env),
 		VolumeMounts:             pod.VolumeMounts,
+		VolumeDevices:            pod.VolumeDevices,
 		TerminationMessagePath:   TerminationLogPath,
 		TerminationMessagePolicy: core.TerminationMessageReadFile,
 		ImagePullPolicy:          core.PullIfNotPresent,
@@ -242,53 +356,55 @@ func initDeployment(app *crd.ClowdApp, env *crd.ClowdEnvironment, d *apps.Deploy
 
 	setLivenessProbe(&pod, deployment, env, &c)
 	setReadinessProbe(&pod, deployment, env, &c)
+	setDrainDelay(deployment, &c)
 	setImagePullPolicy(env, &c)
+	provutils.ApplyImageSettings(env, &c)
 
 	c.VolumeMounts = append(c.VolumeMounts, core.VolumeMount{
 		Name:      "config-secret",
 		MountPath: "/cdapp/",
 	})
 
-	d.Spec.Template.Spec.Containers = []core.Container{c}
+	template.Spec.Containers = []core.Container{c}
 
-	ics, err := ProcessInitContainers(nn, &c, pod.InitContainers)
+	ics, err := ProcessInitContainers(env, nn, &c, pod.InitContainers)
 
 	if err != nil {
 		return err
 	}
 
 	if pod.MachinePool != "" {
-		d.Spec.Template.Spec.Tolerations = []core.Toleration{{
+		template.Spec.Tolerations = []core.Toleration{{
 			Key:      pod.MachinePool,
 			Effect:   core.TaintEffectNoSchedule,
 			Operator: core.TolerationOpEqual,
 			Value:    "true",
 		}}
 	} else {
-		d.Spec.Template.Spec.Tolerations = []core.Toleration{}
+		template.Spec.Tolerations = []core.Toleration{}
 	}
 
-	d.Spec.Template.Spec.InitContainers = ics
+	template.Spec.InitContainers = ics
 
-	d.Spec.Template.Spec.Volumes = pod.Volumes
-	d.Spec.Template.Spec.Volumes = append(d.Spec.Template.Spec.Volumes, core.Volume{
+	template.Spec.Volumes = pod.Volumes
+	template.Spec.Volumes = append(template.Spec.Volumes, core.Volume{
 		Name: "config-secret",
 		VolumeSource: core.VolumeSource{
 			Secret: &core.SecretVolumeSource{
-				DefaultMode: utils.Int32Ptr(420),
+				DefaultMode: provutils.ConfigSecretMode(env),
 				SecretName:  app.ObjectMeta.Name,
 			},
 		},
 	})
+	provutils.AddTrustedCAVolume(env, app.Name, &template.Spec)
 
-	for _, vol := range d.Spec.Template.Spec.Volumes {
+	for _, vol := range template.Spec.Volumes {
 		v := vol
-		setRecreateDeploymentStrategyForPVCs(vol, d)
 		setVolumeSourceConfigMapDefaultMode(&v)
 		setVolumeSourceSecretDefaultMode(&v)
 	}
 
-	ApplyPodAntiAffinity(&d.Spec.Template)
+	applyDeploymentAntiAffinity(template, deployment.PodAntiAffinity)
 
 	return nil
 }
@@ -321,7 +437,7 @@ func setVolumeSourceSecretDefaultMode(vol *core.Volume) {
 }
 
 // ProcessInitContainers returns a container object which has been processed from the container spec.
-func ProcessInitContainers(nn types.NamespacedName, c *core.Container, ics []crd.InitContainer) ([]core.Container, error) {
+func ProcessInitContainers(env *crd.ClowdEnvironment, nn types.NamespacedName, c *core.Container, ics []crd.InitContainer) ([]core.Container, error) {
 	if len(ics) == 0 {
 		return []core.Container{}, nil
 	}
@@ -331,7 +447,7 @@ func ProcessInitContainers(nn types.NamespacedName, c *core.Container, ics []crd
 
 		image := c.Image
 		if ic.Image != "" {
-			image = ic.Image
+			image = provutils.MirrorImage(env, ic.Image)
 		}
 
 		if len(ics) > 1 && ic.Name == "" {
@@ -381,7 +497,7 @@ func ProcessInitContainers(nn types.NamespacedName, c *core.Container, ics []crd
 		} else {
 
 			icStruct.Env = append(
-				icStruct.Env, core.EnvVar{Name: "ACG_CONFIG", Value: "/cdapp/cdappconfig.json"},
+				icStruct.Env, core.EnvVar{Name: "ACG_CONFIG", Value: provutils.ConfigFilePath(env)},
 			)
 
 			for _, envvar := range ic.Env {
@@ -418,6 +534,42 @@ func ApplyPodAntiAffinity(t *core.PodTemplateSpec) {
 	}}
 }
 
+// applyRequiredPodAntiAffinity keeps the pods of a pod template off each other's nodes, while still
+// preferring to spread them across zones.
+func applyRequiredPodAntiAffinity(t *core.PodTemplateSpec) {
+	labelSelector := &metav1.LabelSelector{MatchLabels: t.Labels}
+	t.Spec.Affinity = &core.Affinity{PodAntiAffinity: &core.PodAntiAffinity{
+		RequiredDuringSchedulingIgnoredDuringExecution: []core.PodAffinityTerm{{
+			LabelSelector: labelSelector,
+			TopologyKey:   "kubernetes.io/hostname",
+		}},
+		PreferredDuringSchedulingIgnoredDuringExecution: []core.WeightedPodAffinityTerm{{
+			Weight: 100,
+			PodAffinityTerm: core.PodAffinityTerm{
+				LabelSelector: labelSelector,
+				TopologyKey:   "failure-domain.beta.kubernetes.io/zone",
+			},
+		}},
+	}}
+}
+
+func applyDeploymentAntiAffinity(t *core.PodTemplateSpec, mode crd.PodAntiAffinityMode) {
+	switch mode {
+	case crd.PodAntiAffinityDisabled:
+		// Only the anti-affinity is Clowder's to remove
+		if t.Spec.Affinity != nil {
+			t.Spec.Affinity.PodAntiAffinity = nil
+			if *t.Spec.Affinity == (core.Affinity{}) {
+				t.Spec.Affinity = nil
+			}
+		}
+	case crd.PodAntiAffinityRequired:
+		applyRequiredPodAntiAffinity(t)
+	default:
+		ApplyPodAntiAffinity(t)
+	}
+}
+
 // ProcessResources takes a pod spec and a clowd environment and returns the resource requirements
 // object.
 func ProcessResources(pod *crd.PodSpec, env *crd.ClowdEnvironment) core.ResourceRequirements {
@@ -449,7 +601,7 @@ func ProcessResources(pod *crd.PodSpec, env *crd.ClowdEnvironment) core.Resource
 		rmemory = env.Spec.ResourceDefaults.Requests["memory"]
 	}
 
-	return core.ResourceRequirements{
+	resources := core.ResourceRequirements{
 		Limits: core.ResourceList{
 			"cpu":    lcpu,
 			"memory": lmemory,
@@ -459,4 +611,20 @@ func ProcessResources(pod *crd.PodSpec, env *crd.ClowdEnvironment) core.Resource
 			"memory": rmemory,
 		},
 	}
+
+	setEphemeralStorage(resources.Limits, pod.Resources.Limits, env.Spec.ResourceDefaults.Limits)
+	setEphemeralStorage(resources.Requests, pod.Resources.Requests, env.Spec.ResourceDefaults.Requests)
+
+	return resources
+}
+
+// setEphemeralStorage copies the ephemeral-storage quantity from the pod, or
+// failing that the environment defaults. Unlike cpu and memory it is left unset
+// when neither asks for it, as a zero limit would get the pod evicted.
+func setEphemeralStorage(list, podList, envList core.ResourceList) {
+	if quantity, ok := podList[core.ResourceEphemeralStorage]; ok && !quantity.IsZero() {
+		list[core.ResourceEphemeralStorage] = quantity
+	} else if quantity, ok := envList[core.ResourceEphemeralStorage]; ok && !quantity.IsZero() {
+		list[core.ResourceEphemeralStorage] = quantity
+	}
 }
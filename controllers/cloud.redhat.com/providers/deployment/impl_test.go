@@ -0,0 +1,283 @@
+package deployment
+
+import (
+	"testing"
+
+	crd "github.com/RedHatInsights/clowder/apis/cloud.redhat.com/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	apps "k8s.io/api/apps/v1"
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestProcessResourcesEphemeralStorage(t *testing.T) {
+	env := &crd.ClowdEnvironment{
+		Spec: crd.ClowdEnvironmentSpec{
+			ResourceDefaults: core.ResourceRequirements{
+				Limits: core.ResourceList{
+					core.ResourceEphemeralStorage: resource.MustParse("1Gi"),
+				},
+			},
+		},
+	}
+
+	pod := &crd.PodSpec{
+		Resources: core.ResourceRequirements{
+			Requests: core.ResourceList{
+				core.ResourceEphemeralStorage: resource.MustParse("500Mi"),
+			},
+		},
+	}
+
+	res := ProcessResources(pod, env)
+	assert.Equal(t, resource.MustParse("1Gi"), res.Limits[core.ResourceEphemeralStorage])
+	assert.Equal(t, resource.MustParse("500Mi"), res.Requests[core.ResourceEphemeralStorage])
+
+	res = ProcessResources(&crd.PodSpec{}, &crd.ClowdEnvironment{})
+	assert.NotContains(t, res.Limits, core.ResourceEphemeralStorage)
+	assert.NotContains(t, res.Requests, core.ResourceEphemeralStorage)
+}
+
+func TestReadinessProbeDefaults(t *testing.T) {
+	env := &crd.ClowdEnvironment{
+		Spec: crd.ClowdEnvironmentSpec{
+			Providers: crd.ProvidersConfig{
+				Web: crd.WebConfig{Port: 8000, PrivatePort: 10000},
+			},
+		},
+	}
+
+	private := &crd.Deployment{}
+	private.WebServices.Private.Enabled = true
+
+	c := &core.Container{}
+	setReadinessProbe(&crd.PodSpec{}, private, env, c)
+	assert.NotNil(t, c.ReadinessProbe.TCPSocket)
+	assert.Equal(t, int32(10000), c.ReadinessProbe.TCPSocket.Port.IntVal)
+
+	c = &core.Container{}
+	setReadinessProbe(&crd.PodSpec{DisableDefaultReadinessProbe: true}, private, env, c)
+	assert.Nil(t, c.ReadinessProbe)
+
+	public := &crd.Deployment{}
+	public.WebServices.Public.Enabled = true
+	public.WebServices.Private.Enabled = true
+
+	c = &core.Container{}
+	setReadinessProbe(&crd.PodSpec{}, public, env, c)
+	assert.NotNil(t, c.ReadinessProbe.HTTPGet)
+
+	c = &core.Container{}
+	setReadinessProbe(&crd.PodSpec{}, &crd.Deployment{}, env, c)
+	assert.Nil(t, c.ReadinessProbe)
+}
+
+func TestInitDeploymentImageSettings(t *testing.T) {
+	env := &crd.ClowdEnvironment{
+		Spec: crd.ClowdEnvironmentSpec{
+			Providers: crd.ProvidersConfig{
+				Deployment: crd.DeploymentConfig{
+					ImagePullPolicy: core.PullAlways,
+					RegistryMirror:  "mirror.example.com",
+				},
+			},
+		},
+	}
+	app := &crd.ClowdApp{ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "test"}}
+	deployment := &crd.Deployment{
+		Name: "api",
+		PodSpec: crd.PodSpec{
+			Image:          "quay.io/cloudservices/api:abc123",
+			InitContainers: []crd.InitContainer{{Image: "quay.io/cloudservices/migrate:abc123"}},
+		},
+	}
+	nn := types.NamespacedName{Name: "app-api", Namespace: "test"}
+
+	d := &apps.Deployment{}
+	assert.NoError(t, initDeployment(app, env, d, nn, deployment))
+
+	c := d.Spec.Template.Spec.Containers[0]
+	assert.Equal(t, "mirror.example.com/cloudservices/api:abc123", c.Image)
+	assert.Equal(t, core.PullAlways, c.ImagePullPolicy)
+
+	ic := d.Spec.Template.Spec.InitContainers[0]
+	assert.Equal(t, "mirror.example.com/cloudservices/migrate:abc123", ic.Image)
+	assert.Equal(t, core.PullAlways, ic.ImagePullPolicy)
+}
+
+func TestInitDeploymentConfigSecretMode(t *testing.T) {
+	app := &crd.ClowdApp{ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "test"}}
+	deployment := &crd.Deployment{
+		Name:    "api",
+		PodSpec: crd.PodSpec{Image: "quay.io/cloudservices/api:abc123"},
+	}
+	nn := types.NamespacedName{Name: "app-api", Namespace: "test"}
+
+	configSecretMode := func(d *apps.Deployment) int32 {
+		for _, vol := range d.Spec.Template.Spec.Volumes {
+			if vol.Name == "config-secret" {
+				return *vol.VolumeSource.Secret.DefaultMode
+			}
+		}
+		t.Fatal("config-secret volume not found")
+		return 0
+	}
+
+	d := &apps.Deployment{}
+	assert.NoError(t, initDeployment(app, &crd.ClowdEnvironment{}, d, nn, deployment))
+	assert.Equal(t, int32(0644), configSecretMode(d))
+
+	mode := int32(0400)
+	env := &crd.ClowdEnvironment{
+		Spec: crd.ClowdEnvironmentSpec{
+			Providers: crd.ProvidersConfig{
+				Deployment: crd.DeploymentConfig{ConfigSecretMode: &mode},
+			},
+		},
+	}
+	d = &apps.Deployment{}
+	assert.NoError(t, initDeployment(app, env, d, nn, deployment))
+	assert.Equal(t, int32(0400), configSecretMode(d))
+}
+
+func TestInitDeploymentConfigFormat(t *testing.T) {
+	app := &crd.ClowdApp{ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "test"}}
+	deployment := &crd.Deployment{
+		Name:    "api",
+		PodSpec: crd.PodSpec{Image: "quay.io/cloudservices/api:abc123"},
+	}
+	nn := types.NamespacedName{Name: "app-api", Namespace: "test"}
+
+	acgConfig := func(d *apps.Deployment) string {
+		for _, envVar := range d.Spec.Template.Spec.Containers[0].Env {
+			if envVar.Name == "ACG_CONFIG" {
+				return envVar.Value
+			}
+		}
+		t.Fatal("ACG_CONFIG not set")
+		return ""
+	}
+
+	d := &apps.Deployment{}
+	assert.NoError(t, initDeployment(app, &crd.ClowdEnvironment{}, d, nn, deployment))
+	assert.Equal(t, "/cdapp/cdappconfig.json", acgConfig(d))
+
+	env := &crd.ClowdEnvironment{
+		Spec: crd.ClowdEnvironmentSpec{
+			Providers: crd.ProvidersConfig{
+				Deployment: crd.DeploymentConfig{ConfigFormat: "yaml"},
+			},
+		},
+	}
+	d = &apps.Deployment{}
+	assert.NoError(t, initDeployment(app, env, d, nn, deployment))
+	assert.Equal(t, "/cdapp/cdappconfig.yaml", acgConfig(d))
+}
+
+func TestInitDeploymentProgressDeadline(t *testing.T) {
+	app := &crd.ClowdApp{ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "test"}}
+	deployment := &crd.Deployment{
+		Name:    "api",
+		PodSpec: crd.PodSpec{Image: "quay.io/cloudservices/api:abc123"},
+	}
+	nn := types.NamespacedName{Name: "app-api", Namespace: "test"}
+
+	d := &apps.Deployment{}
+	assert.NoError(t, initDeployment(app, &crd.ClowdEnvironment{}, d, nn, deployment))
+	assert.Equal(t, int32(600), *d.Spec.ProgressDeadlineSeconds)
+
+	deadline := int32(120)
+	deployment.ProgressDeadlineSeconds = &deadline
+	d = &apps.Deployment{}
+	assert.NoError(t, initDeployment(app, &crd.ClowdEnvironment{}, d, nn, deployment))
+	assert.Equal(t, int32(120), *d.Spec.ProgressDeadlineSeconds)
+}
+
+func TestInitDeploymentDrainDelay(t *testing.T) {
+	app := &crd.ClowdApp{ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "test"}}
+	deployment := &crd.Deployment{
+		Name:    "api",
+		PodSpec: crd.PodSpec{Image: "quay.io/cloudservices/api:abc123"},
+	}
+	nn := types.NamespacedName{Name: "app-api", Namespace: "test"}
+
+	// Images may not provide sleep, so there is no delay unless one is set
+	d := &apps.Deployment{}
+	assert.NoError(t, initDeployment(app, &crd.ClowdEnvironment{}, d, nn, deployment))
+	assert.Nil(t, d.Spec.Template.Spec.Containers[0].Lifecycle)
+
+	delay := int32(15)
+	deployment.DrainDelaySeconds = &delay
+	d = &apps.Deployment{}
+	assert.NoError(t, initDeployment(app, &crd.ClowdEnvironment{}, d, nn, deployment))
+	assert.Equal(t, []string{"sleep", "15"}, d.Spec.Template.Spec.Containers[0].Lifecycle.PreStop.Exec.Command)
+
+	delay = 0
+	d = &apps.Deployment{}
+	assert.NoError(t, initDeployment(app, &crd.ClowdEnvironment{}, d, nn, deployment))
+	assert.Nil(t, d.Spec.Template.Spec.Containers[0].Lifecycle)
+}
+
+func TestInitDeploymentPodAntiAffinity(t *testing.T) {
+	app := &crd.ClowdApp{ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "test"}}
+	deployment := &crd.Deployment{
+		Name:    "api",
+		PodSpec: crd.PodSpec{Image: "quay.io/cloudservices/api:abc123"},
+	}
+	nn := types.NamespacedName{Name: "app-api", Namespace: "test"}
+
+	d := &apps.Deployment{}
+	assert.NoError(t, initDeployment(app, &crd.ClowdEnvironment{}, d, nn, deployment))
+	antiAffinity := d.Spec.Template.Spec.Affinity.PodAntiAffinity
+	assert.Empty(t, antiAffinity.RequiredDuringSchedulingIgnoredDuringExecution)
+	assert.Len(t, antiAffinity.PreferredDuringSchedulingIgnoredDuringExecution, 2)
+	assert.Equal(t, d.Spec.Template.Labels, antiAffinity.PreferredDuringSchedulingIgnoredDuringExecution[1].PodAffinityTerm.LabelSelector.MatchLabels)
+
+	deployment.PodAntiAffinity = crd.PodAntiAffinityRequired
+	d = &apps.Deployment{}
+	assert.NoError(t, initDeployment(app, &crd.ClowdEnvironment{}, d, nn, deployment))
+	antiAffinity = d.Spec.Template.Spec.Affinity.PodAntiAffinity
+	assert.Len(t, antiAffinity.RequiredDuringSchedulingIgnoredDuringExecution, 1)
+	assert.Equal(t, "kubernetes.io/hostname", antiAffinity.RequiredDuringSchedulingIgnoredDuringExecution[0].TopologyKey)
+	assert.Equal(t, "app-api", antiAffinity.RequiredDuringSchedulingIgnoredDuringExecution[0].LabelSelector.MatchLabels["pod"])
+	assert.Len(t, antiAffinity.PreferredDuringSchedulingIgnoredDuringExecution, 1)
+
+	deployment.PodAntiAffinity = crd.PodAntiAffinityDisabled
+	d = &apps.Deployment{}
+	assert.NoError(t, initDeployment(app, &crd.ClowdEnvironment{}, d, nn, deployment))
+	assert.Nil(t, d.Spec.Template.Spec.Affinity)
+
+	// Any other affinity of the pods is kept
+	nodeAffinity := &core.NodeAffinity{RequiredDuringSchedulingIgnoredDuringExecution: &core.NodeSelector{}}
+	d.Spec.Template.Spec.Affinity = &core.Affinity{NodeAffinity: nodeAffinity, PodAntiAffinity: antiAffinity}
+	assert.NoError(t, initDeployment(app, &crd.ClowdEnvironment{}, d, nn, deployment))
+	assert.Equal(t, &core.Affinity{NodeAffinity: nodeAffinity}, d.Spec.Template.Spec.Affinity)
+}
+
+func TestInitDaemonSet(t *testing.T) {
+	app := &crd.ClowdApp{ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "test"}}
+	deployment := &crd.Deployment{
+		Name:    "agent",
+		Kind:    "DaemonSet",
+		PodSpec: crd.PodSpec{Image: "quay.io/cloudservices/agent:abc123"},
+	}
+	nn := types.NamespacedName{Name: "app-agent", Namespace: "test"}
+
+	ds := &apps.DaemonSet{}
+	assert.NoError(t, initDaemonSet(app, &crd.ClowdEnvironment{}, ds, nn, deployment))
+
+	assert.Equal(t, "DaemonSet", ds.Kind)
+	assert.Equal(t, "app-agent", ds.Spec.Selector.MatchLabels["pod"])
+	assert.Equal(t, ds.Spec.Selector.MatchLabels, ds.Spec.Template.Labels)
+	assert.Equal(t, apps.RollingUpdateDaemonSetStrategyType, ds.Spec.UpdateStrategy.Type)
+
+	c := ds.Spec.Template.Spec.Containers[0]
+	assert.Equal(t, "app-agent", c.Name)
+	assert.Contains(t, c.VolumeMounts, core.VolumeMount{Name: "config-secret", MountPath: "/cdapp/"})
+
+	vols := ds.Spec.Template.Spec.Volumes
+	assert.Len(t, vols, 1)
+	assert.Equal(t, "app", vols[0].VolumeSource.Secret.SecretName)
+}
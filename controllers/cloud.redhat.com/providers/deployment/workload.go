@@ -0,0 +1,59 @@
+package deployment
+
+import (
+	crd "github.com/RedHatInsights/clowder/apis/cloud.redhat.com/v1alpha1"
+	rc "github.com/RedHatInsights/rhc-osdk-utils/resourceCache"
+	apps "k8s.io/api/apps/v1"
+	core "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Workload is the object a ClowdApp deployment is run as, either a Deployment or a DaemonSet,
+// as held in the resource cache. Providers that only alter the pod should use it rather than
+// fetching the Deployment directly.
+type Workload struct {
+	// Object is the Deployment or DaemonSet itself.
+	Object client.Object
+
+	// Template points at the pod template of Object.
+	Template *core.PodTemplateSpec
+
+	ident rc.ResourceIdent
+}
+
+// GetWorkload fetches the workload of the given deployment from the cache.
+func GetWorkload(cache *rc.ObjectCache, app *crd.ClowdApp, deployment *crd.Deployment) (*Workload, error) {
+	nn := app.GetDeploymentNamespacedName(deployment)
+
+	if deployment.IsDaemonSet() {
+		ds := &apps.DaemonSet{}
+		if err := cache.Get(CoreDaemonSet, ds, nn); err != nil {
+			return nil, err
+		}
+		return &Workload{Object: ds, Template: &ds.Spec.Template, ident: CoreDaemonSet}, nil
+	}
+
+	d := &apps.Deployment{}
+	if err := cache.Get(CoreDeployment, d, nn); err != nil {
+		return nil, err
+	}
+	return &Workload{Object: d, Template: &d.Spec.Template, ident: CoreDeployment}, nil
+}
+
+// Update writes the workload back to the cache.
+func (w *Workload) Update(cache *rc.ObjectCache) error {
+	return cache.Update(w.ident, w.Object)
+}
+
+// GetWorkloads fetches the workloads of all of the app's deployments from the cache.
+func GetWorkloads(cache *rc.ObjectCache, app *crd.ClowdApp) ([]*Workload, error) {
+	workloads := []*Workload{}
+	for i := range app.Spec.Deployments {
+		w, err := GetWorkload(cache, app, &app.Spec.Deployments[i])
+		if err != nil {
+			return nil, err
+		}
+		workloads = append(workloads, w)
+	}
+	return workloads, nil
+}
@@ -80,18 +80,18 @@ func (ff *localFeatureFlagsProvider) EnvProvide() error {
 
 	dbCfg := config.DatabaseConfig{}
 
-	password, err := utils.RandPassword(16, provutils.RCharSet)
+	password, err := provutils.GeneratePassword(ff.Env)
 	if err != nil {
 		return errors.Wrap("password generate failed", err)
 	}
 
-	pgPassword, err := utils.RandPassword(16, provutils.RCharSet)
+	pgPassword, err := provutils.GeneratePassword(ff.Env)
 	if err != nil {
 		return errors.Wrap("pgPassword generate failed", err)
 	}
 
-	username := utils.RandString(16)
-	hostname := fmt.Sprintf("%v.%v.svc", nn.Name, nn.Namespace)
+	username := provutils.GenerateUsername(ff.Env)
+	hostname := ff.Env.GetServiceHostname(nn.Name, nn.Namespace)
 	passwordEncode := url.QueryEscape(password)
 	connectionURL := fmt.Sprintf("postgres://%s:%s@%s/%s", username, passwordEncode, hostname, "unleash")
 
@@ -132,7 +132,7 @@ func (ff *localFeatureFlagsProvider) EnvProvide() error {
 		},
 	}
 
-	provutils.MakeLocalDB(dd, nn, ff.Env, labels, &dbCfg, "quay.io/cloudservices/postgresql-rds:12-9ee2984", ff.Env.Spec.Providers.FeatureFlags.PVC, "unleash", &res)
+	provutils.MakeLocalDB(dd, nn, ff.Env, ff.Env, labels, &dbCfg, "quay.io/cloudservices/postgresql-rds:12-9ee2984", ff.Env.Spec.Providers.FeatureFlags.PVC, "unleash", &res, provutils.RHELDBEnvVarNames)
 
 	if err = ff.Cache.Update(LocalFFDBDeployment, dd); err != nil {
 		return err
@@ -143,7 +143,7 @@ func (ff *localFeatureFlagsProvider) EnvProvide() error {
 		return err
 	}
 
-	provutils.MakeLocalDBService(s, nn, ff.Env, labels)
+	provutils.MakeLocalDBService(s, nn, ff.Env, labels, nil)
 
 	if err = ff.Cache.Update(LocalFFDBService, s); err != nil {
 		return err
@@ -169,7 +169,7 @@ func (ff *localFeatureFlagsProvider) EnvProvide() error {
 // namespaced name passed in must be the actual name of the db resources
 func (ff *localFeatureFlagsProvider) Provide(_ *crd.ClowdApp) error {
 	ff.Config.FeatureFlags = &config.FeatureFlagsConfig{
-		Hostname: fmt.Sprintf("%s-featureflags.%s.svc", ff.Env.Name, ff.Env.Status.TargetNamespace),
+		Hostname: ff.Env.GetServiceHostname(fmt.Sprintf("%s-featureflags", ff.Env.Name), ff.Env.Status.TargetNamespace),
 		Port:     4242,
 		Scheme:   config.FeatureFlagsConfigSchemeHttp,
 	}
@@ -266,6 +266,8 @@ func makeLocalFeatureFlags(o obj.ClowdObject, objMap providers.ObjectMap, _ bool
 		},
 	}
 
+	provutils.ApplyImageSettings(o.(*crd.ClowdEnvironment), &c)
+
 	dd.Spec.Template.Spec.Containers = []core.Container{c}
 	dd.Spec.Template.SetLabels(labels)
 
@@ -23,7 +23,7 @@ func (e *elasticache) EnvProvide() error {
 func (e *elasticache) Provide(app *crd.ClowdApp) error {
 	secretName := "in-memory-db"
 
-	if !app.Spec.InMemoryDB {
+	if !app.RequestsInMemoryDB() {
 		return nil
 	}
 
@@ -70,7 +70,10 @@ func (e *elasticache) Provide(app *crd.ClowdApp) error {
 		return &missingDeps
 	}
 
-	e.Config.InMemoryDb = &creds
+	if app.Spec.InMemoryDB {
+		e.Config.InMemoryDb = &creds
+	}
+	e.Config.InMemoryDbs = namedStores(app, creds)
 
 	return nil
 }
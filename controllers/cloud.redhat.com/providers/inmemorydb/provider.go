@@ -3,6 +3,8 @@ package inmemorydb
 import (
 	"fmt"
 
+	crd "github.com/RedHatInsights/clowder/apis/cloud.redhat.com/v1alpha1"
+	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/config"
 	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/errors"
 	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers"
 )
@@ -28,6 +30,22 @@ func GetInMemoryDB(c *providers.Provider) (providers.ClowderProvider, error) {
 	}
 }
 
+// namedStores returns the configuration of each of the app's named in-memory stores, which
+// share the server given by creds and each use their own logical database.
+func namedStores(app *crd.ClowdApp, creds config.InMemoryDBConfig) []config.InMemoryDBConfig {
+	var stores []config.InMemoryDBConfig
+	for _, store := range app.Spec.InMemoryDBs {
+		name := store.Name
+		index := int(store.DBIndex)
+
+		storeCreds := creds
+		storeCreds.Name = &name
+		storeCreds.DbIndex = &index
+		stores = append(stores, storeCreds)
+	}
+	return stores
+}
+
 func init() {
 	providers.ProvidersRegistration.Register(GetInMemoryDB, 5, ProvName)
 }
@@ -7,6 +7,7 @@ import (
 	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/config"
 	obj "github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/object"
 	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers"
+	provutils "github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers/utils"
 
 	apps "k8s.io/api/apps/v1"
 	core "k8s.io/api/core/v1"
@@ -45,12 +46,12 @@ func (r *localRedis) EnvProvide() error {
 }
 
 func (r *localRedis) Provide(app *crd.ClowdApp) error {
-	if !app.Spec.InMemoryDB {
+	if !app.RequestsInMemoryDB() {
 		return nil
 	}
 	creds := config.InMemoryDBConfig{}
 
-	creds.Hostname = fmt.Sprintf("%v-redis.%v.svc", app.Name, app.Namespace)
+	creds.Hostname = r.Env.GetServiceHostname(fmt.Sprintf("%v-redis", app.Name), app.Namespace)
 	creds.Port = 6379
 
 	nn := providers.GetNamespacedName(app, "redis")
@@ -74,14 +75,28 @@ func (r *localRedis) Provide(app *crd.ClowdApp) error {
 		return err
 	}
 
-	r.Config.InMemoryDb = &creds
+	if app.Spec.InMemoryDB {
+		r.Config.InMemoryDb = &creds
+	}
+	r.Config.InMemoryDbs = namedStores(app, creds)
 
 	objList := []rc.ResourceIdent{
 		RedisDeployment,
 		RedisService,
 	}
 
-	return providers.CachedMakeComponent(r.Provider.Cache, objList, app, "redis", makeLocalRedis, false, r.Env.IsNodePort())
+	if err := providers.CachedMakeComponent(r.Provider.Cache, objList, app, "redis", makeLocalRedis, false, r.Env.IsNodePort()); err != nil {
+		return err
+	}
+
+	dd := &apps.Deployment{}
+	if err := r.Provider.Cache.Get(RedisDeployment, dd, nn); err != nil {
+		return err
+	}
+
+	provutils.ApplyImageSettings(r.Env, &dd.Spec.Template.Spec.Containers[0])
+
+	return r.Provider.Cache.Update(RedisDeployment, dd)
 }
 
 func makeLocalRedis(o obj.ClowdObject, objMap providers.ObjectMap, _ bool, nodePort bool) {
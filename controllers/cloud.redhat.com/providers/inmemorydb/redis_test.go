@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	crd "github.com/RedHatInsights/clowder/apis/cloud.redhat.com/v1alpha1"
+	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/config"
 	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers"
 	"github.com/stretchr/testify/assert"
 	apps "k8s.io/api/apps/v1"
@@ -43,3 +44,23 @@ func TestLocalRedis(t *testing.T) {
 	assert.Len(t, svc.Spec.Ports, 1, "number of ports specified is wrong")
 	assert.Equal(t, int32(6379), svc.Spec.Ports[0].Port, "port number is incorrect")
 }
+
+func TestNamedStores(t *testing.T) {
+	app := &crd.ClowdApp{Spec: crd.ClowdAppSpec{InMemoryDBs: []crd.InMemoryDBSpec{
+		{Name: "cache", DBIndex: 1},
+		{Name: "sessions", DBIndex: 2},
+	}}}
+	creds := config.InMemoryDBConfig{Hostname: "puptoo-redis.test.svc", Port: 6379}
+
+	stores := namedStores(app, creds)
+	assert.Len(t, stores, 2)
+	assert.Equal(t, "cache", *stores[0].Name)
+	assert.Equal(t, 1, *stores[0].DbIndex)
+	assert.Equal(t, "sessions", *stores[1].Name)
+	assert.Equal(t, 2, *stores[1].DbIndex)
+	assert.Equal(t, "puptoo-redis.test.svc", stores[1].Hostname)
+	assert.Equal(t, 6379, stores[1].Port)
+	assert.Nil(t, creds.Name)
+
+	assert.Nil(t, namedStores(&crd.ClowdApp{}, creds))
+}
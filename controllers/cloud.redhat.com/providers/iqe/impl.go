@@ -14,6 +14,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/config"
+	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers"
 	deployProvider "github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers/deployment"
 	provutils "github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers/utils"
 
@@ -53,7 +54,7 @@ func createIqeContainer(j *batchv1.Job, nn types.NamespacedName, cji *crd.ClowdJ
 		{Name: "ENV_FOR_DYNACONF", Value: cji.Spec.Testing.Iqe.DynaconfEnvName},
 		{Name: "NAMESPACE", Value: nn.Namespace},
 		{Name: "CLOWDER_ENABLED", Value: "true"},
-		{Name: "ACG_CONFIG", Value: "/cdapp/cdappconfig.json"},
+		{Name: "ACG_CONFIG", Value: provutils.ConfigFilePath(env)},
 		{Name: "IQE_PLUGINS", Value: iqePlugins},
 		{Name: "IQE_MARKER_EXPRESSION", Value: cji.Spec.Testing.Iqe.Marker},
 		{Name: "IQE_FILTER_EXPRESSION", Value: cji.Spec.Testing.Iqe.Filter},
@@ -97,6 +98,7 @@ func createIqeContainer(j *batchv1.Job, nn types.NamespacedName, cji *crd.ClowdJ
 		TerminationMessagePath:   "/dev/termination-log",
 		TerminationMessagePolicy: core.TerminationMessageReadFile,
 	}
+	provutils.ApplyImageSettings(env, &c)
 
 	return &c
 }
@@ -128,6 +130,7 @@ func createSeleniumContainer(j *batchv1.Job, cji *crd.ClowdJobInvocation, env *c
 		TerminationMessagePath:   "/dev/termination-log",
 		TerminationMessagePolicy: core.TerminationMessageReadFile,
 	}
+	provutils.ApplyImageSettings(env, &c)
 
 	// attach /dev/shm volume
 	j.Spec.Template.Spec.Volumes = append(j.Spec.Template.Spec.Volumes, core.Volume{
@@ -188,7 +191,7 @@ func attachConfigVolumes(ctx context.Context, c *core.Container, cache *rc.Objec
 			Name: "config-secret",
 			VolumeSource: core.VolumeSource{
 				Secret: &core.SecretVolumeSource{
-					DefaultMode: utils.Int32Ptr(420),
+					DefaultMode: provutils.ConfigSecretMode(env),
 					SecretName:  cji.Spec.AppName,
 				},
 			},
@@ -394,6 +397,7 @@ func addIqeSecretToCache(ctx context.Context, cache *rc.ObjectCache, cji *crd.Cl
 	cdEnv := make(map[string][]byte)
 	cdEnv["cdenvconfig.json"] = envData
 	iqeSecret.Data = cdEnv
+	providers.SetSecretLabels(iqeSecret, cji)
 	if err := cache.Update(IqeSecret, iqeSecret); err != nil {
 		logger.Error(err, "Failed to check for iqe secret")
 		return err
@@ -412,7 +416,12 @@ func fetchConfig(ctx context.Context, name types.NamespacedName, logger logr.Log
 		return cfg, err
 	}
 
-	if err := json.Unmarshal(secretConfig.Data["cdappconfig.json"], &cfg); err != nil {
+	data, ok := secretConfig.Data[config.FileName(config.FormatYAML)]
+	if !ok {
+		data = secretConfig.Data[config.FileName(config.FormatJSON)]
+	}
+
+	if err := cfg.Unmarshal(data); err != nil {
 		logger.Error(err, "Could not unmarshall json for cdappconfig")
 		// r.Recorder.Eventf(&secretConfig, "Warning", "UnmarshallError", "app config [%s] not unmarshalled", name)
 		return cfg, err
@@ -43,7 +43,7 @@ func CreateJobResource(cji *crd.ClowdJobInvocation, env *crd.ClowdEnvironment, a
 	}
 
 	envvar := pod.Env
-	envvar = append(envvar, core.EnvVar{Name: "ACG_CONFIG", Value: "/cdapp/cdappconfig.json"})
+	envvar = append(envvar, core.EnvVar{Name: "ACG_CONFIG", Value: provutils.ConfigFilePath(env)})
 
 	var livenessProbe core.Probe
 	var readinessProbe core.Probe
@@ -60,13 +60,14 @@ func CreateJobResource(cji *crd.ClowdJobInvocation, env *crd.ClowdEnvironment, a
 	}
 
 	c := core.Container{
-		Name:         nn.Name,
-		Image:        pod.Image,
-		Command:      pod.Command,
-		Args:         pod.Args,
-		Env:          envvar,
-		Resources:    deployProvider.ProcessResources(&pod, env),
-		VolumeMounts: pod.VolumeMounts,
+		Name:          nn.Name,
+		Image:         pod.Image,
+		Command:       pod.Command,
+		Args:          pod.Args,
+		Env:           envvar,
+		Resources:     deployProvider.ProcessResources(&pod, env),
+		VolumeMounts:  pod.VolumeMounts,
+		VolumeDevices: pod.VolumeDevices,
 		Ports: []core.ContainerPort{{
 			Name:          "metrics",
 			ContainerPort: env.Spec.Providers.Metrics.Port,
@@ -102,6 +103,7 @@ func CreateJobResource(cji *crd.ClowdJobInvocation, env *crd.ClowdEnvironment, a
 			c.ImagePullPolicy = core.PullIfNotPresent
 		}
 	}
+	provutils.ApplyImageSettings(env, &c)
 
 	if (core.Probe{}) != livenessProbe {
 		c.LivenessProbe = &livenessProbe
@@ -110,7 +112,8 @@ func CreateJobResource(cji *crd.ClowdJobInvocation, env *crd.ClowdEnvironment, a
 		c.ReadinessProbe = &readinessProbe
 	}
 
-	j.Spec.Template.Spec.ServiceAccountName = app.GetClowdSAName()
+	j.Spec.Template.Spec.ServiceAccountName = app.GetJobSAName(job)
+	provutils.SetJobPullSecrets(env, job, &j.Spec.Template.Spec)
 
 	c.VolumeMounts = append(c.VolumeMounts, core.VolumeMount{
 		Name:      "config-secret",
@@ -119,7 +122,7 @@ func CreateJobResource(cji *crd.ClowdJobInvocation, env *crd.ClowdEnvironment, a
 
 	j.Spec.Template.Spec.Containers = []core.Container{c}
 
-	ics, err := deployProvider.ProcessInitContainers(nn, &c, pod.InitContainers)
+	ics, err := deployProvider.ProcessInitContainers(env, nn, &c, pod.InitContainers)
 
 	if err != nil {
 		return err
@@ -132,10 +135,12 @@ func CreateJobResource(cji *crd.ClowdJobInvocation, env *crd.ClowdEnvironment, a
 		Name: "config-secret",
 		VolumeSource: core.VolumeSource{
 			Secret: &core.SecretVolumeSource{
-				SecretName: cji.Spec.AppName,
+				DefaultMode: provutils.ConfigSecretMode(env),
+				SecretName:  cji.Spec.AppName,
 			},
 		},
 	})
+	provutils.AddTrustedCAVolume(env, cji.Spec.AppName, &j.Spec.Template.Spec)
 
 	if env.Spec.Providers.Web.TLS.Enabled {
 		provutils.AddCertVolume(&j.Spec.Template.Spec, nn.Name)
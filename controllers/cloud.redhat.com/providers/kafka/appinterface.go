@@ -76,7 +76,7 @@ func (a *appInterface) Provide(app *crd.ClowdApp) error {
 	}
 
 	brokerConfig := config.BrokerConfig{
-		Hostname: fmt.Sprintf("%v-kafka-bootstrap.%v.svc", nn.Name, nn.Namespace),
+		Hostname: a.Env.GetServiceHostname(fmt.Sprintf("%v-kafka-bootstrap", nn.Name), nn.Namespace),
 		Port:     utils.IntPtr(9092),
 	}
 
@@ -0,0 +1,29 @@
+package kafka
+
+import (
+	crd "github.com/RedHatInsights/clowder/apis/cloud.redhat.com/v1alpha1"
+)
+
+// DefaultCompressionType is the compression.type of topics that neither the apps requesting them
+// nor their tier set one for, keeping the codec the producer used as Kafka does.
+const DefaultCompressionType = "producer"
+
+// GetCompressionType returns the compression.type a topic is created with, given the requests of
+// every app for it. Apps setting different compression types get the first in alphabetical order.
+func GetCompressionType(env *crd.ClowdEnvironment, topics []crd.KafkaTopicSpec) string {
+	values := []string{}
+	for _, topic := range topics {
+		topic = withTierDefaults(env, topic)
+		if topic.CompressionType != "" {
+			values = append(values, topic.CompressionType)
+		} else if value, ok := topic.Config["compression.type"]; ok {
+			values = append(values, value)
+		}
+	}
+
+	if len(values) == 0 {
+		return DefaultCompressionType
+	}
+	compression, _ := firstValue(values)
+	return compression
+}
@@ -0,0 +1,38 @@
+package kafka
+
+import (
+	"encoding/json"
+	"testing"
+
+	crd "github.com/RedHatInsights/clowder/apis/cloud.redhat.com/v1alpha1"
+	strimzi "github.com/RedHatInsights/strimzi-client-go/apis/kafka.strimzi.io/v1beta2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProcessTopicValuesCompressionType(t *testing.T) {
+	topic := crd.KafkaTopicSpec{TopicName: "clicks", CompressionType: "zstd"}
+	appList := &crd.ClowdAppList{Items: []crd.ClowdApp{{
+		Spec: crd.ClowdAppSpec{KafkaTopics: []crd.KafkaTopicSpec{topic}},
+	}}}
+
+	k := &strimzi.KafkaTopic{Spec: &strimzi.KafkaTopicSpec{}}
+	assert.NoError(t, processTopicValues(k, tieredEnv(), appList, topic))
+
+	config := map[string]interface{}{}
+	assert.NoError(t, json.Unmarshal(k.Spec.Config.Raw, &config))
+	assert.Equal(t, "zstd", config["compression.type"])
+}
+
+func TestGetCompressionType(t *testing.T) {
+	env := tieredEnv()
+
+	assert.Equal(t, "producer", GetCompressionType(env, []crd.KafkaTopicSpec{{TopicName: "events"}}))
+	assert.Equal(t, "zstd", GetCompressionType(env, []crd.KafkaTopicSpec{{TopicName: "clicks", Tier: "analytics"}}))
+	assert.Equal(t, "gzip", GetCompressionType(env, []crd.KafkaTopicSpec{
+		{TopicName: "clicks", Tier: "analytics", Config: map[string]string{"compression.type": "gzip"}},
+	}))
+	assert.Equal(t, "lz4", GetCompressionType(env, []crd.KafkaTopicSpec{
+		{TopicName: "clicks", Tier: "analytics", CompressionType: "snappy"},
+		{TopicName: "clicks", CompressionType: "lz4"},
+	}))
+}
@@ -215,6 +215,7 @@ func applySecretToConnectNamespace(
 	// it would be best for the ClowdApp to own this, but since cross-namespace OwnerReferences
 	// are not permitted, make this owned by the ClowdEnvironment
 	secret.SetOwnerReferences([]metav1.OwnerReference{s.GetEnv().MakeOwnerReference()})
+	prov.SetSecretLabels(secret, s.GetEnv())
 
 	return s.GetCache().Update(resourceIdent, secret)
 }
@@ -0,0 +1,77 @@
+package kafka
+
+import (
+	"context"
+
+	crd "github.com/RedHatInsights/clowder/apis/cloud.redhat.com/v1alpha1"
+	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/errors"
+	strimzi "github.com/RedHatInsights/strimzi-client-go/apis/kafka.strimzi.io/v1beta2"
+	"github.com/go-logr/logr"
+
+	k8serr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// FinalizeAppTopics deletes the KafkaTopics created for a ClowdApp that is being deleted, when
+// the app sets retainTopics to false. Topics that another app in the environment also requests
+// are kept. Only operator mode manages KafkaTopic resources, so other modes are left alone.
+func FinalizeAppTopics(ctx context.Context, c client.Client, log logr.Logger, env *crd.ClowdEnvironment, app *crd.ClowdApp) error {
+	if len(app.Spec.KafkaTopics) == 0 || env.Spec.Providers.Kafka.Mode != "operator" {
+		return nil
+	}
+
+	if app.RetainsTopics() {
+		retained := []string{}
+		for _, topic := range app.Spec.KafkaTopics {
+			retained = append(retained, getTopicName(topic, *env, app.Namespace))
+		}
+		log.Info("Retaining Kafka topics of deleted ClowdApp", "retained", retained)
+		return nil
+	}
+
+	apps, err := env.GetAppsInEnv(ctx, c)
+	if err != nil {
+		return errors.Wrap("could not list apps to finalize topics", err)
+	}
+
+	deleted, retained := appTopicsToDelete(app, env, apps)
+
+	for _, topicName := range deleted {
+		topic := &strimzi.KafkaTopic{ObjectMeta: metav1.ObjectMeta{
+			Name:      topicName,
+			Namespace: getKafkaNamespace(env),
+		}}
+		if err := c.Delete(ctx, topic); err != nil && !k8serr.IsNotFound(err) {
+			return errors.Wrap("could not delete topic "+topicName, err)
+		}
+	}
+
+	log.Info("Finalized Kafka topics of deleted ClowdApp", "deleted", deleted, "retained", retained)
+	return nil
+}
+
+// appTopicsToDelete splits the topics of the app into those that can be deleted, and those that
+// are retained because another of the apps still requests them.
+func appTopicsToDelete(app *crd.ClowdApp, env *crd.ClowdEnvironment, apps *crd.ClowdAppList) ([]string, []string) {
+	inUse := map[string]bool{}
+	for _, other := range apps.Items {
+		if other.Name == app.Name && other.Namespace == app.Namespace {
+			continue
+		}
+		for _, topic := range other.Spec.KafkaTopics {
+			inUse[getTopicName(topic, *env, other.Namespace)] = true
+		}
+	}
+
+	deleted, retained := []string{}, []string{}
+	for _, topic := range app.Spec.KafkaTopics {
+		topicName := getTopicName(topic, *env, app.Namespace)
+		if inUse[topicName] {
+			retained = append(retained, topicName)
+		} else {
+			deleted = append(deleted, topicName)
+		}
+	}
+	return deleted, retained
+}
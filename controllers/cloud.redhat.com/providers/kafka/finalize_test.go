@@ -0,0 +1,96 @@
+package kafka
+
+import (
+	"context"
+	"testing"
+
+	crd "github.com/RedHatInsights/clowder/apis/cloud.redhat.com/v1alpha1"
+	strimzi "github.com/RedHatInsights/strimzi-client-go/apis/kafka.strimzi.io/v1beta2"
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	k8serr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func topicApp(name string, namespace string, topics ...string) crd.ClowdApp {
+	app := crd.ClowdApp{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
+	for _, topic := range topics {
+		app.Spec.KafkaTopics = append(app.Spec.KafkaTopics, crd.KafkaTopicSpec{TopicName: topic})
+	}
+	return app
+}
+
+func TestAppTopicsToDelete(t *testing.T) {
+	env := &crd.ClowdEnvironment{ObjectMeta: metav1.ObjectMeta{Name: "env"}}
+	app := topicApp("puptoo", "test", "ingress", "shared")
+	apps := &crd.ClowdAppList{Items: []crd.ClowdApp{
+		app,
+		topicApp("inventory", "test", "shared"),
+	}}
+
+	deleted, retained := appTopicsToDelete(&app, env, apps)
+	assert.Equal(t, []string{"ingress"}, deleted)
+	assert.Equal(t, []string{"shared"}, retained)
+}
+
+func TestFinalizeAppTopicsRetainsByDefault(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	assert.NoError(t, strimzi.AddToScheme(scheme))
+
+	env := &crd.ClowdEnvironment{ObjectMeta: metav1.ObjectMeta{Name: "env"}}
+	env.Spec.Providers.Kafka.Mode = "operator"
+	env.Spec.Providers.Kafka.Cluster.Namespace = "kafka"
+
+	topic := &strimzi.KafkaTopic{ObjectMeta: metav1.ObjectMeta{Name: "ingress", Namespace: "kafka"}}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(topic).Build()
+
+	app := topicApp("puptoo", "test", "ingress")
+	assert.True(t, app.RetainsTopics())
+	assert.NoError(t, FinalizeAppTopics(ctx, c, logr.Discard(), env, &app))
+	assert.NoError(t, c.Get(ctx, types.NamespacedName{Name: "ingress", Namespace: "kafka"}, &strimzi.KafkaTopic{}))
+
+	retain := false
+	app.Spec.RetainTopics = &retain
+	assert.False(t, app.RetainsTopics())
+
+	// Outside of operator mode Clowder does not manage the topics
+	env.Spec.Providers.Kafka.Mode = "app-interface"
+	assert.NoError(t, FinalizeAppTopics(ctx, c, logr.Discard(), env, &app))
+	assert.NoError(t, c.Get(ctx, types.NamespacedName{Name: "ingress", Namespace: "kafka"}, &strimzi.KafkaTopic{}))
+}
+
+func TestFinalizeAppTopicsDeletesUnshared(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	assert.NoError(t, strimzi.AddToScheme(scheme))
+	assert.NoError(t, crd.AddToScheme(scheme))
+
+	env := &crd.ClowdEnvironment{ObjectMeta: metav1.ObjectMeta{Name: "env"}}
+	env.Spec.Providers.Kafka.Mode = "operator"
+	env.Spec.Providers.Kafka.Cluster.Namespace = "kafka"
+
+	app := topicApp("puptoo", "test", "ingress", "shared")
+	retain := false
+	app.Spec.RetainTopics = &retain
+	other := topicApp("inventory", "test", "shared")
+
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&strimzi.KafkaTopic{ObjectMeta: metav1.ObjectMeta{Name: "ingress", Namespace: "kafka"}},
+		&strimzi.KafkaTopic{ObjectMeta: metav1.ObjectMeta{Name: "shared", Namespace: "kafka"}},
+		app.DeepCopy(),
+		&other,
+	).Build()
+
+	assert.NoError(t, FinalizeAppTopics(ctx, c, logr.Discard(), env, &app))
+
+	err := c.Get(ctx, types.NamespacedName{Name: "ingress", Namespace: "kafka"}, &strimzi.KafkaTopic{})
+	assert.True(t, k8serr.IsNotFound(err), "topic only used by the deleted app was not deleted")
+	assert.NoError(t, c.Get(ctx, types.NamespacedName{Name: "shared", Namespace: "kafka"}, &strimzi.KafkaTopic{}))
+
+	// A topic that is already gone is not an error
+	assert.NoError(t, FinalizeAppTopics(ctx, c, logr.Discard(), env, &app))
+}
@@ -324,6 +324,7 @@ func (mep *managedEphemProvider) createConnectSecret() error {
 	secret.SetName(nn.Name)
 	secret.SetNamespace(nn.Namespace)
 	secret.SetLabels(providers.Labels{"env": mep.Env.Name})
+	providers.SetSecretLabels(secret, mep.Env)
 
 	return mep.Cache.Update(EphemKafkaConnectSecret, secret)
 }
@@ -390,7 +391,17 @@ func (mep *managedEphemProvider) processTopics(app *crd.ClowdApp, httpClient HTT
 	}
 
 	for _, topic := range app.Spec.KafkaTopics {
+		if err := validateTopicReplicas(mep.Env, topic); err != nil {
+			return err
+		}
+		if err := validateTopicTier(mep.Env, topic); err != nil {
+			return err
+		}
+
 		topicName := ephemGetTopicName(topic, *mep.Env)
+		if err := validateTopicName(topic, topicName); err != nil {
+			return err
+		}
 
 		err := mep.ephemProcessTopicValues(mep.Env, appList, topic, topicName, httpClient, adminHostname)
 
@@ -421,6 +432,7 @@ func (mep *managedEphemProvider) getAppTopicTopology(appList *crd.ClowdAppList,
 					// Only consider a topic that matches the name
 					continue
 				}
+				itopic = withTierDefaults(mep.Env, itopic)
 				replicaValList = append(replicaValList, strconv.Itoa(int(itopic.Replicas)))
 				partitionValList = append(partitionValList, strconv.Itoa(int(itopic.Partitions)))
 				for key := range itopic.Config {
@@ -429,6 +441,12 @@ func (mep *managedEphemProvider) getAppTopicTopology(appList *crd.ClowdAppList,
 					}
 					keys[key] = append(keys[key], itopic.Config[key])
 				}
+				if itopic.MinInSyncReplicas > 0 {
+					keys["min.insync.replicas"] = append(keys["min.insync.replicas"], strconv.Itoa(int(itopic.MinInSyncReplicas)))
+				}
+				if itopic.CompressionType != "" {
+					keys["compression.type"] = append(keys["compression.type"], itopic.CompressionType)
+				}
 			}
 		}
 	}
@@ -500,6 +518,12 @@ func (mep *managedEphemProvider) getTopicSettings(appList *crd.ClowdAppList, top
 		replicas = int(env.Spec.Providers.Kafka.Cluster.Replicas)
 	}
 
+	for i := range topicConfig {
+		if topicConfig[i].Key == "min.insync.replicas" {
+			topicConfig[i].Value = capMinInSyncReplicas(topicConfig[i].Value, replicas)
+		}
+	}
+
 	partitions, err := mep.getMaxFromList(partitionValList, PartitionNumFloor, PartitionNumCeiling)
 	if err != nil {
 		return settings, err
@@ -532,17 +556,13 @@ func (mep *managedEphemProvider) createTopicOnKafka(newTopicName string, setting
 		return err
 	}
 
-	r := strings.NewReader(string(buf))
-
-	resp, err := httpClient.Post(fmt.Sprintf("%s/api/v1/topics", adminHostname), "application/json", r)
-	if err != nil {
+	exists, err := mep.createTopicWithRetries(newTopicName, buf, httpClient, adminHostname)
+	if err != nil || !exists {
 		return err
 	}
 
-	resp.Body.Close()
-
-	return mep.handleKafkaHTTPError(resp, "bad error status code creating")
-
+	// The topic was created since it was looked up, bring its settings in line instead
+	return mep.updateTopicOnKafka(newTopicName, settings, httpClient, adminHostname)
 }
 
 func (mep *managedEphemProvider) updateTopicOnKafka(newTopicName string, settings Settings, httpClient HTTPClient, adminHostname string) error {
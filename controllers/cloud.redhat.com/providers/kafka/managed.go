@@ -46,12 +46,12 @@ func (k *managedKafkaProvider) Provide(app *crd.ClowdApp) error {
 		return err
 	}
 
-	k.Config.Kafka = k.getKafkaConfig(broker, app)
+	k.Config.Kafka, err = k.getKafkaConfig(broker, app)
 
-	return nil
+	return err
 }
 
-func (k *managedKafkaProvider) appendTopic(topic crd.KafkaTopicSpec, kafkaConfig *config.KafkaConfig) {
+func (k *managedKafkaProvider) appendTopic(topic crd.KafkaTopicSpec, kafkaConfig *config.KafkaConfig) error {
 
 	topicName := topic.TopicName
 
@@ -59,6 +59,10 @@ func (k *managedKafkaProvider) appendTopic(topic crd.KafkaTopicSpec, kafkaConfig
 		topicName = fmt.Sprintf("%s%s", k.Env.Spec.Providers.Kafka.ManagedPrefix, topicName)
 	}
 
+	if err := validateTopicName(topic, topicName); err != nil {
+		return err
+	}
+
 	kafkaConfig.Topics = append(
 		kafkaConfig.Topics,
 		config.TopicConfig{
@@ -66,6 +70,8 @@ func (k *managedKafkaProvider) appendTopic(topic crd.KafkaTopicSpec, kafkaConfig
 			RequestedName: topic.TopicName,
 		},
 	)
+
+	return nil
 }
 
 func (k *managedKafkaProvider) destructureSecret(secret *core.Secret) (int, string, string, string, string, string, error) {
@@ -114,16 +120,18 @@ func (k *managedKafkaProvider) getBrokerConfig(secret *core.Secret) (config.Brok
 	return broker, nil
 }
 
-func (k *managedKafkaProvider) getKafkaConfig(broker config.BrokerConfig, app *crd.ClowdApp) *config.KafkaConfig {
+func (k *managedKafkaProvider) getKafkaConfig(broker config.BrokerConfig, app *crd.ClowdApp) (*config.KafkaConfig, error) {
 	kafkaConfig := &config.KafkaConfig{}
 	kafkaConfig.Brokers = []config.BrokerConfig{broker}
 	kafkaConfig.Topics = []config.TopicConfig{}
 
 	for _, topic := range app.Spec.KafkaTopics {
-		k.appendTopic(topic, kafkaConfig)
+		if err := k.appendTopic(topic, kafkaConfig); err != nil {
+			return nil, err
+		}
 	}
 
-	return kafkaConfig
+	return kafkaConfig, nil
 
 }
 
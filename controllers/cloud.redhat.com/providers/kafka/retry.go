@@ -0,0 +1,181 @@
+package kafka
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	crd "github.com/RedHatInsights/clowder/apis/cloud.redhat.com/v1alpha1"
+	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/errors"
+)
+
+// DefaultTopicCreationRetries is how many times the creation of a topic is retried when the
+// environment does not set it.
+const DefaultTopicCreationRetries = 3
+
+// DefaultTopicCreationBackoffMs is how long to wait before the first retry of a topic creation
+// when the environment does not set it.
+const DefaultTopicCreationBackoffMs = 500
+
+// topicAttempts is how the creation of a topic has gone since it last succeeded: how many
+// attempts failed, why the last one did, and when the next one is due.
+type topicAttempts struct {
+	failures int
+	lastErr  error
+	retryAt  time.Time
+}
+
+// topicCreationLimiter keeps the failed attempts at creating each topic across reconciles, so
+// that the backoff between them carries on from one reconcile to the next instead of starting
+// over, and a retry that is not due yet is left to a requeue instead of being slept for.
+type topicCreationLimiter struct {
+	mu       sync.Mutex
+	attempts map[string]*topicAttempts
+}
+
+func newTopicCreationLimiter() *topicCreationLimiter {
+	return &topicCreationLimiter{attempts: map[string]*topicAttempts{}}
+}
+
+var topicLimiter = newTopicCreationLimiter()
+
+// pending returns the failed attempts at creating a topic and how long is left before the next
+// one is due, which is zero when it may be attempted now.
+func (l *topicCreationLimiter) pending(topic string, now time.Time) (topicAttempts, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	attempts, ok := l.attempts[topic]
+	if !ok {
+		return topicAttempts{}, 0
+	}
+	if wait := attempts.retryAt.Sub(now); wait > 0 {
+		return *attempts, wait
+	}
+	return *attempts, 0
+}
+
+// fail counts a failed attempt at creating a topic and returns how many have failed along with
+// the backoff before the next one, which doubles with every failure.
+func (l *topicCreationLimiter) fail(topic string, backoff time.Duration, cause error, now time.Time) (int, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	attempts, ok := l.attempts[topic]
+	if !ok {
+		attempts = &topicAttempts{}
+		l.attempts[topic] = attempts
+	}
+	attempts.failures++
+	attempts.lastErr = cause
+	backoff <<= attempts.failures - 1
+	attempts.retryAt = now.Add(backoff)
+	return attempts.failures, backoff
+}
+
+// reset forgets the attempts at creating a topic once it stops failing, or its retries run out.
+func (l *topicCreationLimiter) reset(topic string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.attempts, topic)
+}
+
+type topicCreationOutcome int
+
+const (
+	topicCreated topicCreationOutcome = iota
+	topicExists
+	topicCreationTransient
+	topicCreationPermanent
+)
+
+func getTopicCreationRetries(env *crd.ClowdEnvironment) int {
+	if retries := env.Spec.Providers.Kafka.TopicCreation.Retries; retries != nil {
+		return int(*retries)
+	}
+	return DefaultTopicCreationRetries
+}
+
+func getTopicCreationBackoff(env *crd.ClowdEnvironment) time.Duration {
+	backoffMs := env.Spec.Providers.Kafka.TopicCreation.BackoffMs
+	if backoffMs == 0 {
+		backoffMs = DefaultTopicCreationBackoffMs
+	}
+	return time.Duration(backoffMs) * time.Millisecond
+}
+
+// classifyTopicCreation sorts the status code the cluster answered a topic creation with into
+// whether the topic was created, already existed, or failed. Failures are transient when the
+// cluster was overloaded or unavailable, and permanent when it rejected the topic itself.
+func classifyTopicCreation(statusCode int) topicCreationOutcome {
+	switch {
+	case statusCode == http.StatusOK || statusCode == http.StatusCreated:
+		return topicCreated
+	case statusCode == http.StatusConflict:
+		return topicExists
+	case statusCode == http.StatusRequestTimeout || statusCode == http.StatusTooManyRequests || statusCode >= 500:
+		return topicCreationTransient
+	default:
+		return topicCreationPermanent
+	}
+}
+
+// postTopic makes a single attempt at creating a topic, returning how it went along with the
+// cause of a failure.
+func postTopic(httpClient HTTPClient, adminHostname string, payload []byte) (topicCreationOutcome, error) {
+	resp, err := httpClient.Post(fmt.Sprintf("%s/api/v1/topics", adminHostname), "application/json", bytes.NewReader(payload))
+	if err != nil {
+		// The request did not make it to the cluster, or its answer did not make it back
+		return topicCreationTransient, err
+	}
+	defer resp.Body.Close()
+
+	outcome := classifyTopicCreation(resp.StatusCode)
+	if outcome == topicCreated || outcome == topicExists {
+		return outcome, nil
+	}
+	body, _ := io.ReadAll(resp.Body)
+	return outcome, fmt.Errorf("bad error status code creating %d - %s", resp.StatusCode, body)
+}
+
+// createTopicWithRetries makes an attempt at creating a topic, or none when its last one failed
+// and the backoff since has not passed yet. A transient failure is counted against the retries
+// the environment allows across reconciles, and asks for the app to be requeued once the backoff,
+// doubling with every failure, has passed. It returns true if the topic turned out to exist
+// already, which happens when another app sharing it created it first.
+func (mep *managedEphemProvider) createTopicWithRetries(newTopicName string, payload []byte, httpClient HTTPClient, adminHostname string) (bool, error) {
+	now := time.Now()
+
+	attempts, wait := topicLimiter.pending(newTopicName, now)
+	if wait > 0 {
+		return false, &errors.KafkaTopicCreationFailed{Topic: newTopicName, Attempts: attempts.failures, Transient: true, RetryAfter: wait, Cause: attempts.lastErr}
+	}
+
+	outcome, err := postTopic(httpClient, adminHostname, payload)
+	switch outcome {
+	case topicCreated:
+		topicLimiter.reset(newTopicName)
+		return false, nil
+	case topicExists:
+		topicLimiter.reset(newTopicName)
+		return true, nil
+	case topicCreationPermanent:
+		topicLimiter.reset(newTopicName)
+		return false, &errors.KafkaTopicCreationFailed{Topic: newTopicName, Attempts: attempts.failures + 1, Cause: err}
+	}
+
+	failures, backoff := topicLimiter.fail(newTopicName, getTopicCreationBackoff(mep.Env), err, now)
+	if failures > getTopicCreationRetries(mep.Env) {
+		// The retries are used up, the next round of them starts with the reconcile that follows
+		// the failure
+		topicLimiter.reset(newTopicName)
+		return false, &errors.KafkaTopicCreationFailed{Topic: newTopicName, Attempts: failures, Transient: true, Cause: err}
+	}
+
+	mep.Log.Info("retrying topic creation", "topic", newTopicName, "attempt", failures, "backoff", backoff.String(), "error", err.Error())
+	return false, &errors.KafkaTopicCreationFailed{Topic: newTopicName, Attempts: failures, Transient: true, RetryAfter: backoff, Cause: err}
+}
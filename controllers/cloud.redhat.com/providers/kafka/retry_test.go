@@ -0,0 +1,174 @@
+package kafka
+
+import (
+	"context"
+	errlib "errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	crd "github.com/RedHatInsights/clowder/apis/cloud.redhat.com/v1alpha1"
+	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/errors"
+	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers"
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+)
+
+// sequenceClient answers each topic creation with the next of its status codes, a code of zero
+// standing for a request that never made it to the cluster.
+type sequenceClient struct {
+	codes []int
+	posts int
+}
+
+func (c *sequenceClient) Do(_ *http.Request) (*http.Response, error) {
+	return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(""))}, nil
+}
+
+func (c *sequenceClient) Get(_ string) (*http.Response, error) {
+	return &http.Response{StatusCode: http.StatusNotFound, Body: io.NopCloser(strings.NewReader(""))}, nil
+}
+
+func (c *sequenceClient) Post(_, _ string, _ io.Reader) (*http.Response, error) {
+	code := c.codes[c.posts]
+	c.posts++
+	if code == 0 {
+		return nil, fmt.Errorf("connection refused")
+	}
+	return &http.Response{StatusCode: code, Body: io.NopCloser(strings.NewReader("status"))}, nil
+}
+
+func retryProvider(retries int32) *managedEphemProvider {
+	env := &crd.ClowdEnvironment{}
+	env.Spec.Providers.Kafka.TopicCreation = crd.KafkaTopicCreationConfig{Retries: &retries, BackoffMs: 1}
+	return &managedEphemProvider{Provider: providers.Provider{Ctx: context.Background(), Env: env, Log: logr.Discard()}}
+}
+
+func TestClassifyTopicCreation(t *testing.T) {
+	assert.Equal(t, topicCreated, classifyTopicCreation(http.StatusCreated))
+	assert.Equal(t, topicExists, classifyTopicCreation(http.StatusConflict))
+	assert.Equal(t, topicCreationTransient, classifyTopicCreation(http.StatusTooManyRequests))
+	assert.Equal(t, topicCreationTransient, classifyTopicCreation(http.StatusServiceUnavailable))
+	assert.Equal(t, topicCreationPermanent, classifyTopicCreation(http.StatusBadRequest))
+}
+
+func TestTopicCreationDefaults(t *testing.T) {
+	env := &crd.ClowdEnvironment{}
+	assert.Equal(t, DefaultTopicCreationRetries, getTopicCreationRetries(env))
+	assert.Equal(t, "500ms", getTopicCreationBackoff(env).String())
+}
+
+// expireBackoff lets the next attempt at creating a topic through straight away, as if its
+// backoff had passed.
+func expireBackoff(topic string) {
+	topicLimiter.mu.Lock()
+	defer topicLimiter.mu.Unlock()
+
+	if attempts, ok := topicLimiter.attempts[topic]; ok {
+		attempts.retryAt = time.Time{}
+	}
+}
+
+// createUntilSettled creates a topic the way the requeues of an app would, letting the backoff
+// pass before every attempt, until the creation no longer asks for a requeue.
+func createUntilSettled(prov *managedEphemProvider, topic string, client HTTPClient) (bool, error) {
+	for {
+		exists, err := prov.createTopicWithRetries(topic, []byte("{}"), client, "admin")
+		var topicErr *errors.KafkaTopicCreationFailed
+		if !errlib.As(err, &topicErr) || topicErr.RetryAfter == 0 {
+			return exists, err
+		}
+		expireBackoff(topic)
+	}
+}
+
+func TestCreateTopicWithRetries(t *testing.T) {
+	tests := []struct {
+		name      string
+		codes     []int
+		exists    bool
+		posts     int
+		transient bool
+		fails     bool
+	}{
+		{"created", []int{201}, false, 1, false, false},
+		{"already exists", []int{409}, true, 1, false, false},
+		{"retried until created", []int{503, 0, 201}, false, 3, false, false},
+		{"retries used up", []int{503, 503, 503}, false, 3, true, true},
+		{"rejected straight away", []int{400}, false, 1, false, true},
+		{"rejected after a retry", []int{502, 422}, false, 2, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			topicLimiter.reset("env-topic")
+			client := &sequenceClient{codes: tt.codes}
+			exists, err := createUntilSettled(retryProvider(2), "env-topic", client)
+
+			assert.Equal(t, tt.exists, exists)
+			assert.Equal(t, tt.posts, client.posts)
+			if !tt.fails {
+				assert.NoError(t, err)
+				return
+			}
+			var topicErr *errors.KafkaTopicCreationFailed
+			assert.True(t, errlib.As(err, &topicErr))
+			assert.Equal(t, tt.transient, topicErr.Transient)
+			assert.Equal(t, tt.posts, topicErr.Attempts)
+		})
+	}
+}
+
+func TestCreateTopicMakesOneAttemptPerReconcile(t *testing.T) {
+	topicLimiter.reset("env-topic")
+	prov := retryProvider(10)
+	prov.Env.Spec.Providers.Kafka.TopicCreation.BackoffMs = 60000
+	client := &sequenceClient{codes: []int{503, 503, 201}}
+
+	// The retry is left to a requeue rather than slept for
+	_, err := prov.createTopicWithRetries("env-topic", []byte("{}"), client, "admin")
+	var topicErr *errors.KafkaTopicCreationFailed
+	assert.True(t, errlib.As(err, &topicErr))
+	assert.True(t, topicErr.Transient)
+	assert.Equal(t, time.Minute, topicErr.RetryAfter)
+	assert.Equal(t, 1, client.posts)
+
+	// A reconcile before the backoff has passed makes no attempt
+	_, err = prov.createTopicWithRetries("env-topic", []byte("{}"), client, "admin")
+	assert.True(t, errlib.As(err, &topicErr))
+	assert.Equal(t, 1, topicErr.Attempts)
+	assert.Greater(t, topicErr.RetryAfter, time.Duration(0))
+	assert.LessOrEqual(t, topicErr.RetryAfter, time.Minute)
+	assert.Equal(t, 1, client.posts)
+
+	// The backoff carries on doubling from one reconcile to the next
+	expireBackoff("env-topic")
+	_, err = prov.createTopicWithRetries("env-topic", []byte("{}"), client, "admin")
+	assert.True(t, errlib.As(err, &topicErr))
+	assert.Equal(t, 2, topicErr.Attempts)
+	assert.Equal(t, 2*time.Minute, topicErr.RetryAfter)
+	assert.Equal(t, 2, client.posts)
+
+	expireBackoff("env-topic")
+	_, err = prov.createTopicWithRetries("env-topic", []byte("{}"), client, "admin")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, client.posts)
+
+	// The attempts are forgotten once the topic is created
+	_, wait := topicLimiter.pending("env-topic", time.Now())
+	assert.Zero(t, wait)
+}
+
+func TestCreateTopicWithoutRetries(t *testing.T) {
+	topicLimiter.reset("env-topic")
+	client := &sequenceClient{codes: []int{503}}
+	_, err := retryProvider(0).createTopicWithRetries("env-topic", []byte("{}"), client, "admin")
+
+	var topicErr *errors.KafkaTopicCreationFailed
+	assert.True(t, errlib.As(err, &topicErr))
+	assert.True(t, topicErr.Transient)
+	assert.Zero(t, topicErr.RetryAfter)
+	assert.Equal(t, 1, client.posts)
+}
@@ -141,6 +141,125 @@ var conversionMap = map[string]func([]string) (string, error){
 	"retention.bytes":       utils.IntMax,
 	"min.compaction.lag.ms": utils.IntMax,
 	"cleanup.policy":        utils.ListMerge,
+	"min.insync.replicas":   utils.IntMax,
+	"compression.type":      firstValue,
+}
+
+// capMinInSyncReplicas keeps a topic's min.insync.replicas within its final
+// replica count, which may have been lowered to fit the cluster. Otherwise
+// acks=all producers could never get a write acknowledged.
+func capMinInSyncReplicas(value string, replicas int) string {
+	minISR, err := strconv.Atoi(value)
+	if err != nil || minISR <= replicas {
+		return value
+	}
+	return strconv.Itoa(replicas)
+}
+
+// getBrokerCount returns the number of brokers in the environment's kafka cluster.
+func getBrokerCount(env *crd.ClowdEnvironment) int32 {
+	if env.Spec.Providers.Kafka.Cluster.Replicas < int32(1) {
+		return 1
+	}
+	return env.Spec.Providers.Kafka.Cluster.Replicas
+}
+
+// enforceTopicReplicas returns whether topics that do not fit the cluster are
+// rejected, which they are unless the environment turns it off.
+func enforceTopicReplicas(env *crd.ClowdEnvironment) bool {
+	if env.Spec.Providers.Kafka.EnforceTopicReplicas == nil {
+		return true
+	}
+	return *env.Spec.Providers.Kafka.EnforceTopicReplicas
+}
+
+// validateTopicReplicas rejects a topic requesting more replicas, or a higher
+// min.insync.replicas, than the cluster has brokers, unless the environment
+// has turned enforcement off. The values are then lowered to fit later on, as
+// are the default replicas of topics that do not request any.
+func validateTopicReplicas(env *crd.ClowdEnvironment, topic crd.KafkaTopicSpec) error {
+	if !enforceTopicReplicas(env) {
+		return nil
+	}
+
+	brokers := getBrokerCount(env)
+
+	if topic.Replicas > brokers {
+		return errors.NewClowderError(fmt.Sprintf(
+			"topic '%s' requests %d replicas but the kafka cluster has %d brokers",
+			topic.TopicName, topic.Replicas, brokers,
+		))
+	}
+
+	minISR := topic.MinInSyncReplicas
+	if value, ok := topic.Config["min.insync.replicas"]; ok {
+		configMinISR, err := strconv.Atoi(value)
+		if err != nil {
+			return errors.NewClowderError(fmt.Sprintf(
+				"topic '%s' has an invalid min.insync.replicas of '%s'", topic.TopicName, value,
+			))
+		}
+		if int32(configMinISR) > minISR {
+			minISR = int32(configMinISR)
+		}
+	}
+
+	if minISR > brokers {
+		return errors.NewClowderError(fmt.Sprintf(
+			"topic '%s' requests a min.insync.replicas of %d but the kafka cluster has %d brokers",
+			topic.TopicName, minISR, brokers,
+		))
+	}
+
+	return nil
+}
+
+const defaultRetentionHours = 24
+
+func getRetentionHours(env *crd.ClowdEnvironment) int32 {
+	if env.Spec.Providers.Kafka.Cluster.RetentionHours > 0 {
+		return env.Spec.Providers.Kafka.Cluster.RetentionHours
+	}
+	return defaultRetentionHours
+}
+
+// getBrokerConfig builds the broker config for the Kafka resource. Options set
+// in the environment's cluster config replace the defaults, but a broker-wide
+// log retention is always applied unless one is given explicitly so that local
+// brokers don't fill their volumes.
+func getBrokerConfig(env *crd.ClowdEnvironment, replicas int32) (apiextensions.JSON, error) {
+	var kafConfig apiextensions.JSON
+
+	config := map[string]interface{}{
+		"offsets.topic.replication.factor": replicas,
+	}
+
+	if env.Spec.Providers.Kafka.Cluster.Config != nil && len(*env.Spec.Providers.Kafka.Cluster.Config) != 0 {
+		config = map[string]interface{}{}
+		for k, v := range *env.Spec.Providers.Kafka.Cluster.Config {
+			config[k] = v
+		}
+	}
+
+	hasRetention := false
+	for _, key := range []string{"log.retention.ms", "log.retention.minutes", "log.retention.hours"} {
+		if _, ok := config[key]; ok {
+			hasRetention = true
+			break
+		}
+	}
+	if !hasRetention {
+		config["log.retention.hours"] = getRetentionHours(env)
+	}
+
+	jsonData, err := json.Marshal(config)
+	if err != nil {
+		return kafConfig, err
+	}
+	if err := kafConfig.UnmarshalJSON(jsonData); err != nil {
+		return kafConfig, fmt.Errorf("could not unmarshal kConfig: %w", err)
+	}
+	return kafConfig, nil
 }
 
 func (s *strimziProvider) configureKafkaCluster() error {
@@ -187,16 +306,14 @@ func (s *strimziProvider) configureKafkaCluster() error {
 	deleteClaim := s.Env.Spec.Providers.Kafka.Cluster.DeleteClaim
 
 	// default values for config/requests/limits in Strimzi resource specs
-	var kafConfig, kafRequests, kafLimits, zLimits, zRequests apiextensions.JSON
+	var kafRequests, kafLimits, zLimits, zRequests apiextensions.JSON
 	var entityUserLimits, entityUserRequests apiextensions.JSON
 	var entityTopicLimits, entityTopicRequests apiextensions.JSON
 	var entityTLSLimits, entityTLSRequests apiextensions.JSON
 
-	err = kafConfig.UnmarshalJSON([]byte(fmt.Sprintf(`{
-		"offsets.topic.replication.factor": %s
-	}`, strconv.Itoa(int(replicas)))))
+	kafConfig, err := getBrokerConfig(s.Env, replicas)
 	if err != nil {
-		return fmt.Errorf("could not unmarshal kConfig: %w", err)
+		return err
 	}
 
 	err = kafRequests.UnmarshalJSON([]byte(`{
@@ -326,18 +443,6 @@ func (s *strimziProvider) configureKafkaCluster() error {
 		},
 	}
 
-	if s.Env.Spec.Providers.Kafka.Cluster.Config != nil && len(*s.Env.Spec.Providers.Kafka.Cluster.Config) != 0 {
-		jsonData, err := json.Marshal(s.Env.Spec.Providers.Kafka.Cluster.Config)
-		if err != nil {
-			return err
-		}
-		err = kafConfig.UnmarshalJSON(jsonData)
-		if err != nil {
-			return fmt.Errorf("could not unmarshall json data: %w", err)
-		}
-		k.Spec.Kafka.Config = &kafConfig
-	}
-
 	k.Spec.Kafka.JvmOptions = &s.Env.Spec.Providers.Kafka.Cluster.JVMOptions
 
 	metricsConfig := strimzi.KafkaSpecKafkaMetricsConfig{
@@ -929,9 +1034,20 @@ func (s *strimziProvider) processTopics(app *crd.ClowdApp, c *config.KafkaConfig
 	}
 
 	for _, topic := range app.Spec.KafkaTopics {
+		if err := validateTopicReplicas(s.Env, topic); err != nil {
+			return err
+		}
+		if err := validateTopicTier(s.Env, topic); err != nil {
+			return err
+		}
+
 		k := &strimzi.KafkaTopic{}
 
 		topicName := getTopicName(topic, *s.Env, app.Namespace)
+		if err := validateTopicName(topic, topicName); err != nil {
+			return err
+		}
+
 		knn := types.NamespacedName{
 			Namespace: getKafkaNamespace(s.Env),
 			Name:      topicName,
@@ -977,6 +1093,18 @@ func (s *strimziProvider) processTopics(app *crd.ClowdApp, c *config.KafkaConfig
 	return nil
 }
 
+// validateTopicName rejects a topic whose name in the kafka cluster, once the
+// environment has added to it, breaks Kafka's topic naming rules.
+func validateTopicName(topic crd.KafkaTopicSpec, topicName string) error {
+	if msgs := crd.KafkaTopicNameErrors(topicName); len(msgs) > 0 {
+		return errors.NewClowderError(fmt.Sprintf(
+			"topic '%s' is named '%s' in the kafka cluster, which %s",
+			topic.TopicName, topicName, strings.Join(msgs, " and "),
+		))
+	}
+	return nil
+}
+
 func getTopicName(topic crd.KafkaTopicSpec, env crd.ClowdEnvironment, namespace string) string {
 	if clowderconfig.LoadedConfig.Features.UseComplexStrimziTopicNames {
 		return fmt.Sprintf("%s-%s-%s", topic.TopicName, env.Name, namespace)
@@ -1002,6 +1130,7 @@ func processTopicValues(
 					// Only consider a topic that matches the name
 					continue
 				}
+				itopic = withTierDefaults(env, itopic)
 				replicaValList = append(replicaValList, strconv.Itoa(int(itopic.Replicas)))
 				partitionValList = append(partitionValList, strconv.Itoa(int(itopic.Partitions)))
 				for key := range itopic.Config {
@@ -1010,38 +1139,16 @@ func processTopicValues(
 					}
 					keys[key] = append(keys[key], itopic.Config[key])
 				}
+				if itopic.MinInSyncReplicas > 0 {
+					keys["min.insync.replicas"] = append(keys["min.insync.replicas"], strconv.Itoa(int(itopic.MinInSyncReplicas)))
+				}
+				if itopic.CompressionType != "" {
+					keys["compression.type"] = append(keys["compression.type"], itopic.CompressionType)
+				}
 			}
 		}
 	}
 
-	jsonData := "{"
-
-	for key, valList := range keys {
-		f, ok := conversionMap[key]
-		if ok {
-			out, _ := f(valList)
-			jsonData = fmt.Sprintf("%s\"%s\":\"%s\",", jsonData, key, out)
-		} else {
-			return errors.NewClowderError(fmt.Sprintf("no conversion type for %s", key))
-		}
-	}
-
-	if len(jsonData) > 1 {
-		jsonData = jsonData[0 : len(jsonData)-1]
-	}
-	jsonData += "}"
-
-	var config apiextensions.JSON
-
-	err := config.UnmarshalJSON([]byte(jsonData))
-
-	if err != nil {
-		return err
-
-	}
-
-	k.Spec.Config = &config
-
 	if len(replicaValList) > 0 {
 		maxReplicas, err := utils.IntMax(replicaValList)
 		if err != nil {
@@ -1080,5 +1187,36 @@ func processTopicValues(
 		k.Spec.Replicas = &env.Spec.Providers.Kafka.Cluster.Replicas
 	}
 
+	jsonData := "{"
+
+	for key, valList := range keys {
+		f, ok := conversionMap[key]
+		if ok {
+			out, _ := f(valList)
+			if key == "min.insync.replicas" {
+				out = capMinInSyncReplicas(out, int(*k.Spec.Replicas))
+			}
+			jsonData = fmt.Sprintf("%s\"%s\":\"%s\",", jsonData, key, out)
+		} else {
+			return errors.NewClowderError(fmt.Sprintf("no conversion type for %s", key))
+		}
+	}
+
+	if len(jsonData) > 1 {
+		jsonData = jsonData[0 : len(jsonData)-1]
+	}
+	jsonData += "}"
+
+	var config apiextensions.JSON
+
+	err := config.UnmarshalJSON([]byte(jsonData))
+
+	if err != nil {
+		return err
+
+	}
+
+	k.Spec.Config = &config
+
 	return nil
 }
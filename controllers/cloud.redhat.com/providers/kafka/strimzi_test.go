@@ -0,0 +1,130 @@
+package kafka
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	crd "github.com/RedHatInsights/clowder/apis/cloud.redhat.com/v1alpha1"
+	"github.com/RedHatInsights/rhc-osdk-utils/utils"
+	strimzi "github.com/RedHatInsights/strimzi-client-go/apis/kafka.strimzi.io/v1beta2"
+	"github.com/stretchr/testify/assert"
+)
+
+func brokerConfigFor(t *testing.T, cluster crd.KafkaClusterConfig) map[string]interface{} {
+	env := &crd.ClowdEnvironment{
+		Spec: crd.ClowdEnvironmentSpec{
+			Providers: crd.ProvidersConfig{
+				Kafka: crd.KafkaConfig{
+					Mode:    "operator",
+					Cluster: cluster,
+				},
+			},
+		},
+	}
+	kafConfig, err := getBrokerConfig(env, 3)
+	assert.NoError(t, err)
+
+	config := map[string]interface{}{}
+	assert.NoError(t, json.Unmarshal(kafConfig.Raw, &config))
+	return config
+}
+
+func TestBrokerConfigDefaultRetention(t *testing.T) {
+	config := brokerConfigFor(t, crd.KafkaClusterConfig{})
+	assert.Equal(t, float64(3), config["offsets.topic.replication.factor"])
+	assert.Equal(t, float64(defaultRetentionHours), config["log.retention.hours"])
+}
+
+func TestBrokerConfigRetentionHours(t *testing.T) {
+	config := brokerConfigFor(t, crd.KafkaClusterConfig{RetentionHours: 6})
+	assert.Equal(t, float64(6), config["log.retention.hours"])
+}
+
+func TestBrokerConfigExplicitRetention(t *testing.T) {
+	config := brokerConfigFor(t, crd.KafkaClusterConfig{
+		RetentionHours: 6,
+		Config:         &map[string]string{"log.retention.ms": "3600000"},
+	})
+	assert.Equal(t, "3600000", config["log.retention.ms"])
+	assert.NotContains(t, config, "log.retention.hours")
+}
+
+func TestProcessTopicValuesMinInSyncReplicas(t *testing.T) {
+	topic := crd.KafkaTopicSpec{TopicName: "events", Replicas: 3, MinInSyncReplicas: 2}
+	appList := &crd.ClowdAppList{Items: []crd.ClowdApp{{
+		Spec: crd.ClowdAppSpec{KafkaTopics: []crd.KafkaTopicSpec{topic}},
+	}}}
+
+	topicConfigFor := func(clusterReplicas int32) (int32, map[string]interface{}) {
+		env := &crd.ClowdEnvironment{Spec: crd.ClowdEnvironmentSpec{Providers: crd.ProvidersConfig{
+			Kafka: crd.KafkaConfig{Cluster: crd.KafkaClusterConfig{Replicas: clusterReplicas}},
+		}}}
+		k := &strimzi.KafkaTopic{Spec: &strimzi.KafkaTopicSpec{}}
+		assert.NoError(t, processTopicValues(k, env, appList, topic))
+
+		config := map[string]interface{}{}
+		assert.NoError(t, json.Unmarshal(k.Spec.Config.Raw, &config))
+		return *k.Spec.Replicas, config
+	}
+
+	replicas, config := topicConfigFor(3)
+	assert.Equal(t, int32(3), replicas)
+	assert.Equal(t, "2", config["min.insync.replicas"])
+
+	// A single broker cluster lowers the replicas, and the minimum with them
+	replicas, config = topicConfigFor(1)
+	assert.Equal(t, int32(1), replicas)
+	assert.Equal(t, "1", config["min.insync.replicas"])
+}
+
+func TestValidateTopicReplicas(t *testing.T) {
+	envWith := func(enforce *bool, brokers int32) *crd.ClowdEnvironment {
+		return &crd.ClowdEnvironment{Spec: crd.ClowdEnvironmentSpec{Providers: crd.ProvidersConfig{
+			Kafka: crd.KafkaConfig{
+				EnforceTopicReplicas: enforce,
+				Cluster:              crd.KafkaClusterConfig{Replicas: brokers},
+			},
+		}}}
+	}
+	enforced := utils.TruePtr()
+
+	topic := crd.KafkaTopicSpec{TopicName: "events", Replicas: 3, MinInSyncReplicas: 2}
+
+	// Replicas are enforced by default, topics are only lowered to fit the
+	// cluster when the environment turns that off
+	assert.Error(t, validateTopicReplicas(envWith(nil, 1), topic))
+	assert.NoError(t, validateTopicReplicas(envWith(utils.FalsePtr(), 1), topic))
+	assert.NoError(t, validateTopicReplicas(envWith(enforced, 3), topic))
+
+	// Topics without replicas get the default lowered to fit
+	assert.NoError(t, validateTopicReplicas(envWith(nil, 1), crd.KafkaTopicSpec{TopicName: "events"}))
+
+	// A minInSyncReplicas the cluster cannot meet is rejected by default too
+	err := validateTopicReplicas(envWith(nil, 1), crd.KafkaTopicSpec{TopicName: "events", MinInSyncReplicas: 2})
+	assert.ErrorContains(t, err, "topic 'events' requests a min.insync.replicas of 2 but the kafka cluster has 1 brokers")
+
+	err = validateTopicReplicas(envWith(enforced, 2), topic)
+	assert.ErrorContains(t, err, "topic 'events' requests 3 replicas but the kafka cluster has 2 brokers")
+
+	// An unset cluster size counts as a single broker
+	topic = crd.KafkaTopicSpec{TopicName: "events", Config: map[string]string{"min.insync.replicas": "2"}}
+	err = validateTopicReplicas(envWith(enforced, 0), topic)
+	assert.ErrorContains(t, err, "topic 'events' requests a min.insync.replicas of 2 but the kafka cluster has 1 brokers")
+
+	topic.Config["min.insync.replicas"] = "two"
+	assert.Error(t, validateTopicReplicas(envWith(enforced, 3), topic))
+}
+
+func TestValidateTopicName(t *testing.T) {
+	topic := crd.KafkaTopicSpec{TopicName: "events"}
+	assert.NoError(t, validateTopicName(topic, "events-env-namespace"))
+
+	// The environment's additions can push a valid topic over Kafka's limit
+	err := validateTopicName(topic, "events-"+strings.Repeat("e", 245))
+	assert.ErrorContains(t, err, "topic 'events' is named 'events-eeee")
+	assert.ErrorContains(t, err, "which must be no more than 249 characters")
+
+	err = validateTopicName(topic, "env/events")
+	assert.ErrorContains(t, err, "which must only contain alphanumerics, '.', '_' and '-'")
+}
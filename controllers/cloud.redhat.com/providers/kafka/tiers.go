@@ -0,0 +1,79 @@
+package kafka
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	crd "github.com/RedHatInsights/clowder/apis/cloud.redhat.com/v1alpha1"
+	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/errors"
+)
+
+// DefaultTopicTier is the tier of topics that do not request one.
+const DefaultTopicTier = "standard"
+
+func getTopicTier(topic crd.KafkaTopicSpec) string {
+	if topic.Tier == "" {
+		return DefaultTopicTier
+	}
+	return topic.Tier
+}
+
+// validateTopicTier rejects a topic requesting a tier that the environment does not define. The
+// standard tier is always available, as it has no defaults of its own.
+func validateTopicTier(env *crd.ClowdEnvironment, topic crd.KafkaTopicSpec) error {
+	tier := getTopicTier(topic)
+	if _, ok := env.Spec.Providers.Kafka.TopicTiers[tier]; ok || tier == DefaultTopicTier {
+		return nil
+	}
+	return errors.NewClowderError(fmt.Sprintf(
+		"topic '%s' requests tier '%s', which the environment does not define", topic.TopicName, tier,
+	))
+}
+
+// withTierDefaults returns the topic with the defaults of its tier filled in wherever the topic
+// does not set the partitions or config itself.
+func withTierDefaults(env *crd.ClowdEnvironment, topic crd.KafkaTopicSpec) crd.KafkaTopicSpec {
+	tier, ok := env.Spec.Providers.Kafka.TopicTiers[getTopicTier(topic)]
+	if !ok {
+		return topic
+	}
+
+	if topic.Partitions == 0 {
+		topic.Partitions = tier.Partitions
+	}
+
+	defaults := map[string]string{}
+	if tier.RetentionMs != 0 {
+		defaults["retention.ms"] = strconv.FormatInt(tier.RetentionMs, 10)
+	}
+	if tier.CompressionType != "" && topic.CompressionType == "" {
+		defaults["compression.type"] = tier.CompressionType
+	}
+	if len(defaults) == 0 {
+		return topic
+	}
+
+	config := map[string]string{}
+	for key, value := range defaults {
+		if _, ok := topic.Config[key]; !ok {
+			config[key] = value
+		}
+	}
+	for key, value := range topic.Config {
+		config[key] = value
+	}
+	topic.Config = config
+	return topic
+}
+
+// firstValue settles an option that apps sharing a topic may set differently and that cannot be
+// merged, by taking the first value in alphabetical order.
+func firstValue(values []string) (string, error) {
+	if len(values) == 0 {
+		return "", errors.NewClowderError("no values given")
+	}
+	sorted := append([]string{}, values...)
+	sort.Strings(sorted)
+	return sorted[0], nil
+}
@@ -0,0 +1,70 @@
+package kafka
+
+import (
+	"encoding/json"
+	"testing"
+
+	crd "github.com/RedHatInsights/clowder/apis/cloud.redhat.com/v1alpha1"
+	strimzi "github.com/RedHatInsights/strimzi-client-go/apis/kafka.strimzi.io/v1beta2"
+	"github.com/stretchr/testify/assert"
+)
+
+func tieredEnv() *crd.ClowdEnvironment {
+	return &crd.ClowdEnvironment{Spec: crd.ClowdEnvironmentSpec{Providers: crd.ProvidersConfig{
+		Kafka: crd.KafkaConfig{
+			Cluster: crd.KafkaClusterConfig{Replicas: 3},
+			TopicTiers: map[string]crd.KafkaTopicTier{
+				"standard":  {Partitions: 3},
+				"analytics": {Partitions: 48, RetentionMs: 604800000, CompressionType: "zstd"},
+			},
+		},
+	}}}
+}
+
+func TestValidateTopicTier(t *testing.T) {
+	env := tieredEnv()
+	assert.NoError(t, validateTopicTier(env, crd.KafkaTopicSpec{TopicName: "events"}))
+	assert.NoError(t, validateTopicTier(env, crd.KafkaTopicSpec{TopicName: "events", Tier: "analytics"}))
+	assert.Error(t, validateTopicTier(env, crd.KafkaTopicSpec{TopicName: "events", Tier: "bulk"}))
+
+	// The standard tier needs no configuration
+	env.Spec.Providers.Kafka.TopicTiers = nil
+	assert.NoError(t, validateTopicTier(env, crd.KafkaTopicSpec{TopicName: "events", Tier: "standard"}))
+}
+
+func TestProcessTopicValuesTiers(t *testing.T) {
+	topicValuesFor := func(topics ...crd.KafkaTopicSpec) (int32, map[string]interface{}) {
+		appList := &crd.ClowdAppList{}
+		for _, topic := range topics {
+			appList.Items = append(appList.Items, crd.ClowdApp{
+				Spec: crd.ClowdAppSpec{KafkaTopics: []crd.KafkaTopicSpec{topic}},
+			})
+		}
+		k := &strimzi.KafkaTopic{Spec: &strimzi.KafkaTopicSpec{}}
+		assert.NoError(t, processTopicValues(k, tieredEnv(), appList, topics[0]))
+
+		config := map[string]interface{}{}
+		assert.NoError(t, json.Unmarshal(k.Spec.Config.Raw, &config))
+		return *k.Spec.Partitions, config
+	}
+
+	partitions, config := topicValuesFor(crd.KafkaTopicSpec{TopicName: "events"})
+	assert.Equal(t, int32(3), partitions)
+	assert.Empty(t, config)
+
+	partitions, config = topicValuesFor(crd.KafkaTopicSpec{TopicName: "clicks", Tier: "analytics"})
+	assert.Equal(t, int32(48), partitions)
+	assert.Equal(t, "604800000", config["retention.ms"])
+	assert.Equal(t, "zstd", config["compression.type"])
+
+	// The topic's own settings take precedence over its tier's
+	partitions, config = topicValuesFor(crd.KafkaTopicSpec{
+		TopicName:  "clicks",
+		Tier:       "analytics",
+		Partitions: 12,
+		Config:     map[string]string{"compression.type": "lz4"},
+	})
+	assert.Equal(t, int32(12), partitions)
+	assert.Equal(t, "604800000", config["retention.ms"])
+	assert.Equal(t, "lz4", config["compression.type"])
+}
@@ -2,14 +2,15 @@ package metrics
 
 import (
 	"fmt"
+	"strconv"
 
 	crd "github.com/RedHatInsights/clowder/apis/cloud.redhat.com/v1alpha1"
 	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/config"
+	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/errors"
 	deployProvider "github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers/deployment"
 	webProvider "github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers/web"
 
 	prom "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
-	apps "k8s.io/api/apps/v1"
 	core "k8s.io/api/core/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
@@ -19,7 +20,28 @@ import (
 	"github.com/RedHatInsights/rhc-osdk-utils/utils"
 )
 
-func makeMetrics(cache *rc.ObjectCache, deployment *crd.Deployment, app *crd.ClowdApp, port int32) error {
+// getMetricsPort returns the metrics port for the app, falling back to the environment default.
+func getMetricsPort(env *crd.ClowdEnvironment, app *crd.ClowdApp) int32 {
+	if app.Spec.MetricsPort != 0 {
+		return app.Spec.MetricsPort
+	}
+	return env.Spec.Providers.Metrics.Port
+}
+
+func validateMetricsPort(env *crd.ClowdEnvironment, port int32) error {
+	webPorts := []int32{env.Spec.Providers.Web.Port, env.Spec.Providers.Web.PrivatePort}
+	if env.Spec.Providers.Web.TLS.Enabled {
+		webPorts = append(webPorts, env.Spec.Providers.Web.TLS.Port, env.Spec.Providers.Web.TLS.PrivatePort)
+	}
+	for _, webPort := range webPorts {
+		if webPort != 0 && webPort == port {
+			return errors.NewClowderError(fmt.Sprintf("metrics port %d conflicts with a web service port", port))
+		}
+	}
+	return nil
+}
+
+func makeMetrics(cache *rc.ObjectCache, deployment *crd.Deployment, app *crd.ClowdApp, port int32, path string) error {
 
 	s := &core.Service{}
 
@@ -27,9 +49,8 @@ func makeMetrics(cache *rc.ObjectCache, deployment *crd.Deployment, app *crd.Clo
 		return err
 	}
 
-	d := &apps.Deployment{}
-
-	if err := cache.Get(deployProvider.CoreDeployment, d, app.GetDeploymentNamespacedName(deployment)); err != nil {
+	w, err := deployProvider.GetWorkload(cache, app, deployment)
+	if err != nil {
 		return err
 	}
 
@@ -44,7 +65,7 @@ func makeMetrics(cache *rc.ObjectCache, deployment *crd.Deployment, app *crd.Clo
 
 	s.Spec.Ports = append(s.Spec.Ports, metricsPort)
 
-	d.Spec.Template.Spec.Containers[0].Ports = append(d.Spec.Template.Spec.Containers[0].Ports,
+	w.Template.Spec.Containers[0].Ports = append(w.Template.Spec.Containers[0].Ports,
 		core.ContainerPort{
 			Name:          "metrics",
 			ContainerPort: port,
@@ -52,20 +73,31 @@ func makeMetrics(cache *rc.ObjectCache, deployment *crd.Deployment, app *crd.Clo
 		},
 	)
 
+	utils.UpdateAnnotations(w.Template, map[string]string{
+		"prometheus.io/scrape": "true",
+		"prometheus.io/port":   strconv.Itoa(int(port)),
+		"prometheus.io/path":   path,
+	})
+
 	if err := cache.Update(webProvider.CoreService, s); err != nil {
 		return err
 	}
 
-	return cache.Update(deployProvider.CoreDeployment, d)
+	return w.Update(cache)
 }
 
 func createMetricsOnDeployments(cache *rc.ObjectCache, env *crd.ClowdEnvironment, app *crd.ClowdApp, c *config.AppConfig) error {
-	c.MetricsPort = int(env.Spec.Providers.Metrics.Port)
+	port := getMetricsPort(env, app)
+	if err := validateMetricsPort(env, port); err != nil {
+		return err
+	}
+
+	c.MetricsPort = int(port)
 	c.MetricsPath = env.Spec.Providers.Metrics.Path
 
 	for _, deployment := range app.Spec.Deployments {
 		innerDeployment := deployment
-		if err := makeMetrics(cache, &innerDeployment, app, env.Spec.Providers.Metrics.Port); err != nil {
+		if err := makeMetrics(cache, &innerDeployment, app, port, env.Spec.Providers.Metrics.Path); err != nil {
 			return err
 		}
 	}
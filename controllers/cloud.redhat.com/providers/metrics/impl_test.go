@@ -0,0 +1,54 @@
+package metrics
+
+import (
+	"testing"
+
+	crd "github.com/RedHatInsights/clowder/apis/cloud.redhat.com/v1alpha1"
+	"github.com/stretchr/testify/assert"
+)
+
+func getMetricsEnv() *crd.ClowdEnvironment {
+	return &crd.ClowdEnvironment{
+		Spec: crd.ClowdEnvironmentSpec{
+			Providers: crd.ProvidersConfig{
+				Web: crd.WebConfig{
+					Port:        8000,
+					PrivatePort: 10000,
+				},
+				Metrics: crd.MetricsConfig{
+					Port: 9000,
+					Path: "/metrics",
+				},
+			},
+		},
+	}
+}
+
+func TestMetricsPortDefaultsToEnv(t *testing.T) {
+	env := getMetricsEnv()
+	app := &crd.ClowdApp{}
+
+	assert.Equal(t, int32(9000), getMetricsPort(env, app))
+}
+
+func TestMetricsPortOverride(t *testing.T) {
+	env := getMetricsEnv()
+	app := &crd.ClowdApp{Spec: crd.ClowdAppSpec{MetricsPort: 9500}}
+
+	assert.Equal(t, int32(9500), getMetricsPort(env, app))
+}
+
+func TestMetricsPortConflict(t *testing.T) {
+	env := getMetricsEnv()
+
+	assert.NoError(t, validateMetricsPort(env, 9000))
+	assert.Error(t, validateMetricsPort(env, 8000))
+	assert.Error(t, validateMetricsPort(env, 10000))
+
+	// The TLS ports only clash while the TLS sidecar is enabled
+	env.Spec.Providers.Web.TLS = crd.TLS{Port: 8800, PrivatePort: 10800}
+	assert.NoError(t, validateMetricsPort(env, 8800))
+	env.Spec.Providers.Web.TLS.Enabled = true
+	assert.Error(t, validateMetricsPort(env, 8800))
+	assert.Error(t, validateMetricsPort(env, 10800))
+}
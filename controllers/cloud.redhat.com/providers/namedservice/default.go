@@ -0,0 +1,103 @@
+package namedservice
+
+import (
+	"fmt"
+
+	crd "github.com/RedHatInsights/clowder/apis/cloud.redhat.com/v1alpha1"
+	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/errors"
+	p "github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers"
+	webProvider "github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers/web"
+	core "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	rc "github.com/RedHatInsights/rhc-osdk-utils/resourceCache"
+	"github.com/RedHatInsights/rhc-osdk-utils/utils"
+)
+
+// NamedService is an additional service of a deployment, exposing some of its ports.
+var NamedService = rc.NewMultiResourceIdent(ProvName, "named_service", &core.Service{})
+
+type namedServiceProvider struct {
+	p.Provider
+}
+
+// NewNamedServiceProvider returns a new provider for the additional services of deployments.
+func NewNamedServiceProvider(p *p.Provider) (p.ClowderProvider, error) {
+	p.Cache.AddPossibleGVKFromIdent(NamedService)
+	return &namedServiceProvider{Provider: *p}, nil
+}
+
+func (ns *namedServiceProvider) EnvProvide() error {
+	return nil
+}
+
+func (ns *namedServiceProvider) Provide(app *crd.ClowdApp) error {
+	for i := range app.Spec.Deployments {
+		deployment := &app.Spec.Deployments[i]
+		if len(deployment.Services) == 0 {
+			continue
+		}
+
+		main := &core.Service{}
+		if err := ns.Cache.Get(webProvider.CoreService, main, app.GetDeploymentNamespacedName(deployment)); err != nil {
+			return err
+		}
+
+		for _, svc := range deployment.Services {
+			if err := makeNamedService(ns.Cache, app, deployment, main, svc, ns.Env.IsNodePort()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// makeNamedService creates a service exposing the ports of the deployment's main service that
+// svc names.
+func makeNamedService(cache *rc.ObjectCache, app *crd.ClowdApp, deployment *crd.Deployment, main *core.Service, svc crd.DeploymentService, nodePort bool) error {
+	ports := []core.ServicePort{}
+	for _, name := range svc.Ports {
+		port, ok := getPort(main, name)
+		if !ok {
+			return errors.NewClowderError(fmt.Sprintf("deployment %s has no %s port for service %s", deployment.Name, name, svc.Name))
+		}
+		// Node ports are allocated per service, so the main service's can't be reused
+		port.NodePort = 0
+		ports = append(ports, port)
+	}
+
+	selector := map[string]string{"pod": main.Spec.Selector["pod"]}
+	if len(svc.Selector) > 0 {
+		selector = map[string]string{}
+		for k, v := range svc.Selector {
+			selector[k] = v
+		}
+	}
+	// Only ever select pods of the app
+	selector["app"] = app.GetLabels()["app"]
+
+	nn := types.NamespacedName{Name: svc.Name, Namespace: app.Namespace}
+	s := &core.Service{}
+	if err := cache.Create(NamedService, nn, s); err != nil {
+		return err
+	}
+
+	// Never take over a Service that something other than the app created
+	if s.ResourceVersion != "" && !metav1.IsControlledBy(s, app) {
+		return errors.NewClowderError(fmt.Sprintf("service %s of deployment %s already exists and is not owned by the app", svc.Name, deployment.Name))
+	}
+
+	utils.MakeService(s, nn, selector, ports, app, nodePort)
+
+	return cache.Update(NamedService, s)
+}
+
+func getPort(s *core.Service, name string) (core.ServicePort, bool) {
+	for _, port := range s.Spec.Ports {
+		if port.Name == name {
+			return port, true
+		}
+	}
+	return core.ServicePort{}, false
+}
@@ -0,0 +1,81 @@
+package namedservice
+
+import (
+	"context"
+	"testing"
+
+	crd "github.com/RedHatInsights/clowder/apis/cloud.redhat.com/v1alpha1"
+	rc "github.com/RedHatInsights/rhc-osdk-utils/resourceCache"
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	core "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestMakeNamedService(t *testing.T) {
+	ctx := context.Background()
+	log := logr.Discard()
+	cache := rc.NewObjectCache(ctx, fake.NewClientBuilder().Build(), &log, rc.NewCacheConfig(nil, nil, nil))
+	cache.AddPossibleGVKFromIdent(NamedService)
+
+	app := &crd.ClowdApp{ObjectMeta: metav1.ObjectMeta{Name: "puptoo", Namespace: "test"}}
+	deployment := &crd.Deployment{Name: "api"}
+	main := &core.Service{Spec: core.ServiceSpec{
+		Selector: map[string]string{"pod": "puptoo-api"},
+		Ports: []core.ServicePort{
+			{Name: "public", Port: 8000, NodePort: 30000},
+			{Name: "private", Port: 10000},
+			{Name: "metrics", Port: 9000},
+		},
+	}}
+
+	err := makeNamedService(&cache, app, deployment, main, crd.DeploymentService{Name: "puptoo-metrics", Ports: []string{"metrics"}}, false)
+	assert.NoError(t, err)
+	err = makeNamedService(&cache, app, deployment, main, crd.DeploymentService{
+		Name:     "puptoo-public",
+		Ports:    []string{"public"},
+		Selector: map[string]string{"version": "v2"},
+	}, false)
+	assert.NoError(t, err)
+
+	s := &core.Service{}
+	assert.NoError(t, cache.Get(NamedService, s, types.NamespacedName{Name: "puptoo-metrics", Namespace: "test"}))
+	assert.Equal(t, map[string]string{"pod": "puptoo-api", "app": "puptoo"}, s.Spec.Selector)
+	assert.Len(t, s.Spec.Ports, 1)
+	assert.Equal(t, int32(9000), s.Spec.Ports[0].Port)
+
+	assert.NoError(t, cache.Get(NamedService, s, types.NamespacedName{Name: "puptoo-public", Namespace: "test"}))
+	assert.Equal(t, map[string]string{"version": "v2", "app": "puptoo"}, s.Spec.Selector)
+	assert.Equal(t, int32(0), s.Spec.Ports[0].NodePort)
+
+	err = makeNamedService(&cache, app, deployment, main, crd.DeploymentService{Name: "puptoo-tls", Ports: []string{"tls"}}, false)
+	assert.ErrorContains(t, err, "deployment api has no tls port for service puptoo-tls")
+}
+
+func TestMakeNamedServiceRefusesUnownedService(t *testing.T) {
+	ctx := context.Background()
+	log := logr.Discard()
+
+	app := &crd.ClowdApp{ObjectMeta: metav1.ObjectMeta{Name: "puptoo", Namespace: "test", UID: types.UID("puptoo-uid")}}
+	other := &core.Service{ObjectMeta: metav1.ObjectMeta{Name: "inventory", Namespace: "test"}}
+	owned := &core.Service{ObjectMeta: metav1.ObjectMeta{
+		Name:            "puptoo-metrics",
+		Namespace:       "test",
+		OwnerReferences: []metav1.OwnerReference{app.MakeOwnerReference()},
+	}}
+	c := fake.NewClientBuilder().WithObjects(other, owned).Build()
+	cache := rc.NewObjectCache(ctx, c, &log, rc.NewCacheConfig(nil, nil, nil))
+	cache.AddPossibleGVKFromIdent(NamedService)
+
+	deployment := &crd.Deployment{Name: "api"}
+	main := &core.Service{Spec: core.ServiceSpec{Ports: []core.ServicePort{{Name: "metrics", Port: 9000}}}}
+
+	err := makeNamedService(&cache, app, deployment, main, crd.DeploymentService{Name: "inventory", Ports: []string{"metrics"}}, false)
+	assert.ErrorContains(t, err, "service inventory of deployment api already exists and is not owned by the app")
+
+	// The app's own services are updated as usual
+	err = makeNamedService(&cache, app, deployment, main, crd.DeploymentService{Name: "puptoo-metrics", Ports: []string{"metrics"}}, false)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,18 @@
+package namedservice
+
+import (
+	p "github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers"
+)
+
+// ProvName sets the provider name identifier
+var ProvName = "namedservice"
+
+// GetNamedService returns the correct named service provider.
+func GetNamedService(c *p.Provider) (p.ClowderProvider, error) {
+	return NewNamedServiceProvider(c)
+}
+
+func init() {
+	// Run after the web and metrics providers have added their ports to the deployments' services
+	p.ProvidersRegistration.Register(GetNamedService, 3, ProvName)
+}
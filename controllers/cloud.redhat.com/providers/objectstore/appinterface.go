@@ -44,12 +44,24 @@ func (a *appInterfaceObjectstoreProvider) Provide(app *crd.ClowdApp) error {
 		return err
 	}
 
+	err = setClientSettings(&a.Provider, objStoreConfig, false, true)
+
+	if err != nil {
+		return err
+	}
+
 	err = resolveBucketDeps(app.Spec.ObjectStore, objStoreConfig)
 
 	if err != nil {
 		return err
 	}
 
+	err = resolveScopes(app.Spec.ObjectStoreScopes, secrets.Items, objStoreConfig)
+
+	if err != nil {
+		return err
+	}
+
 	a.Config.ObjectStore = objStoreConfig
 	return nil
 }
@@ -83,6 +95,61 @@ func resolveBucketDeps(requestedBuckets []string, c *config.ObjectStoreConfig) e
 	return nil
 }
 
+// resolveScopes finds the credentials for each requested scope in the secrets
+// annotated with the scope's name. The credentials themselves are provisioned
+// by app-interface with a policy matching the scope.
+func resolveScopes(requestedScopes []crd.ObjectStoreScope, secrets []core.Secret, c *config.ObjectStoreConfig) error {
+	if len(requestedScopes) == 0 {
+		return nil
+	}
+
+	found := map[string]*core.Secret{}
+
+	extractFn := func(secret *core.Secret, scope string) {
+		found[scope] = secret
+	}
+
+	keys := []string{"aws_access_key_id", "aws_secret_access_key"}
+	providers.ExtractSecretDataAnno(secrets, extractFn, "clowder/objectstore-scope", keys...)
+
+	bucketNames := map[string]string{}
+	for _, bucket := range c.Buckets {
+		bucketNames[bucket.RequestedName] = bucket.Name
+	}
+
+	scopes := []config.ObjectStoreScope{}
+	missing := []string{}
+
+	for _, requestedScope := range requestedScopes {
+		secret, ok := found[requestedScope.Name]
+		if !ok {
+			missing = append(missing, requestedScope.Name)
+			continue
+		}
+
+		buckets := []string{}
+		for _, bucket := range requestedScope.Buckets {
+			buckets = append(buckets, bucketNames[bucket])
+		}
+
+		scopes = append(scopes, config.ObjectStoreScope{
+			Name:      requestedScope.Name,
+			Buckets:   buckets,
+			ReadOnly:  requestedScope.ReadOnly,
+			AccessKey: providers.StrPtr(string(secret.Data["aws_access_key_id"])),
+			SecretKey: providers.StrPtr(string(secret.Data["aws_secret_access_key"])),
+		})
+	}
+
+	if len(missing) > 0 {
+		scopeStr := strings.Join(missing, ", ")
+		return errors.NewClowderError("Missing object store scopes from app-interface: " + scopeStr)
+	}
+
+	c.Scopes = scopes
+	return nil
+}
+
 func genObjStoreConfig(secrets []core.Secret) (*config.ObjectStoreConfig, error) {
 	buckets := []config.ObjectStoreBucket{}
 	objectStoreConfig := config.ObjectStoreConfig{Port: 443}
@@ -3,10 +3,12 @@ package objectstore
 import (
 	"testing"
 
+	crd "github.com/RedHatInsights/clowder/apis/cloud.redhat.com/v1alpha1"
 	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/config"
 	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers"
 	"github.com/stretchr/testify/assert"
 	core "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/RedHatInsights/rhc-osdk-utils/utils"
 )
@@ -81,3 +83,48 @@ func TestAppInterfaceObjectStore(t *testing.T) {
 
 	assert.Equal(t, &expected, c)
 }
+
+func TestAppInterfaceObjectStoreScopes(t *testing.T) {
+	secrets := []core.Secret{{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{"clowder/objectstore-scope": "lake"},
+		},
+		Data: map[string][]byte{
+			"aws_access_key_id":     []byte("lakeKey"),
+			"aws_secret_access_key": []byte("lakeSecret"),
+		},
+	}}
+
+	c := &config.ObjectStoreConfig{
+		Buckets: []config.ObjectStoreBucket{{
+			Name:          "data-lake-prod",
+			RequestedName: "data-lake",
+		}},
+	}
+
+	t.Run("resolved", func(t *testing.T) {
+		err := resolveScopes([]crd.ObjectStoreScope{{
+			Name:     "lake",
+			Buckets:  []string{"data-lake"},
+			ReadOnly: true,
+		}}, secrets, c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []config.ObjectStoreScope{{
+			Name:      "lake",
+			Buckets:   []string{"data-lake-prod"},
+			ReadOnly:  true,
+			AccessKey: utils.StringPtr("lakeKey"),
+			SecretKey: utils.StringPtr("lakeSecret"),
+		}}, c.Scopes)
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		err := resolveScopes([]crd.ObjectStoreScope{{
+			Name:    "writer",
+			Buckets: []string{"data-lake"},
+		}}, secrets, c)
+
+		assert.ErrorContains(t, err, "writer")
+	})
+}
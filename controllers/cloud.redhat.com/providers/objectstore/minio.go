@@ -2,8 +2,10 @@ package objectstore
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"strconv"
+	"time"
 
 	crd "github.com/RedHatInsights/clowder/apis/cloud.redhat.com/v1alpha1"
 	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/config"
@@ -39,6 +41,18 @@ var MinioSecret = rc.NewSingleResourceIdent(ProvName, "minio_db_secret", &core.S
 // MinioNetworkPolicy is the resource ident for the KafkaNetworkPolicy
 var MinioNetworkPolicy = rc.NewSingleResourceIdent(ProvName, "minio_network_policy", &networking.NetworkPolicy{})
 
+// MinioScopeSecret is the resource ident for the secret holding an app's scoped
+// object store credentials.
+var MinioScopeSecret = rc.NewSingleResourceIdent(ProvName, "minio_scope_secret", &core.Secret{})
+
+// scopeCredentialsDuration is how long the credentials minted for a scope are
+// valid for.
+const scopeCredentialsDuration = 7 * 24 * time.Hour
+
+// scopeCredentialsRenewWindow is how long before expiry a scope's credentials
+// are replaced.
+const scopeCredentialsRenewWindow = 24 * time.Hour
+
 // minio is an object store provider that deploys and configures MinIO
 type minioProvider struct {
 	providers.Provider
@@ -53,12 +67,13 @@ func NewMinIO(p *providers.Provider) (providers.ClowderProvider, error) {
 		MinioPVC,
 		MinioSecret,
 		MinioNetworkPolicy,
+		MinioScopeSecret,
 	)
 
 	nn := providers.GetNamespacedName(p.Env, "minio")
 
 	dataInit := func() map[string]string {
-		return createDefaultMinioSecMap(nn.Name, nn.Namespace)
+		return createDefaultMinioSecMap(p.Env, nn.Name, nn.Namespace)
 	}
 	// MakeOrGetSecret will set data if it already exists
 	secMap, err := providers.MakeOrGetSecret(p.Env, p.Cache, MinioSecret, nn, dataInit)
@@ -68,6 +83,12 @@ func NewMinIO(p *providers.Provider) (providers.ClowderProvider, error) {
 		return nil, raisedErr
 	}
 
+	if err := updateMinioHostname(p.Cache, p.Env, nn, *secMap); err != nil {
+		raisedErr := errors.Wrap("Couldn't update secret hostname", err)
+		raisedErr.Requeue = true
+		return nil, raisedErr
+	}
+
 	mp, err := createMinioProvider(p, *secMap, &minioHandler{})
 
 	if err != nil {
@@ -122,10 +143,13 @@ func (m *minioProvider) Provide(app *crd.ClowdApp) error {
 		Port:      int(port),
 		AccessKey: utils.StringPtr(string(secret.Data["accessKey"])),
 		SecretKey: utils.StringPtr(string(secret.Data["secretKey"])),
-		Tls:       false,
 		Buckets:   []config.ObjectStoreBucket{},
 	}
 
+	if err := setClientSettings(&m.Provider, m.Config.ObjectStore, true, false); err != nil {
+		return err
+	}
+
 	for _, bucket := range app.Spec.ObjectStore {
 		found, err := m.BucketHandler.Exists(m.Ctx, bucket)
 
@@ -156,7 +180,163 @@ func (m *minioProvider) Provide(app *crd.ClowdApp) error {
 		m.Config.ObjectStore.Buckets = append(m.Config.ObjectStore.Buckets, newBucket)
 	}
 
-	return nil
+	if len(app.Spec.ObjectStoreScopes) == 0 {
+		return nil
+	}
+
+	return m.provideScopes(app)
+}
+
+// provideScopes mints credentials for each of the app's object store scopes,
+// limited by an inline policy to the scope's buckets. Credentials are kept in
+// a secret owned by the app and reused until the scope changes or they near
+// expiry, so that the app config does not churn on every reconcile.
+func (m *minioProvider) provideScopes(app *crd.ClowdApp) error {
+	nn := types.NamespacedName{
+		Name:      fmt.Sprintf("%s-objectstore-scopes", app.Name),
+		Namespace: app.Namespace,
+	}
+
+	secret := &core.Secret{}
+	if err := m.Cache.Create(MinioScopeSecret, nn, secret); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	data := map[string][]byte{}
+
+	for _, scope := range app.Spec.ObjectStoreScopes {
+		policy, err := scopePolicy(scope)
+		if err != nil {
+			return errors.Wrap(fmt.Sprintf("scope %q -- failed to build policy", scope.Name), err)
+		}
+
+		creds, ok := storedScopeCredentials(secret.Data, scope.Name, policy, now)
+		if !ok {
+			value, err := m.BucketHandler.AssumeRole(m.Ctx, policy, scopeCredentialsDuration)
+			if err != nil {
+				newErr := errors.Wrap(fmt.Sprintf("scope %q -- failed to create credentials", scope.Name), err)
+				newErr.Requeue = true
+				return newErr
+			}
+			creds = scopeCredentials{
+				AccessKey:    value.AccessKeyID,
+				SecretKey:    value.SecretAccessKey,
+				SessionToken: value.SessionToken,
+				Expiry:       now.Add(scopeCredentialsDuration),
+			}
+		}
+		creds.store(data, scope.Name, policy)
+		m.RequeueAfter(creds.renewAfter(now))
+
+		scopeConfig := config.ObjectStoreScope{
+			Name:      scope.Name,
+			Buckets:   scope.Buckets,
+			ReadOnly:  scope.ReadOnly,
+			AccessKey: utils.StringPtr(creds.AccessKey),
+			SecretKey: utils.StringPtr(creds.SecretKey),
+		}
+		if creds.SessionToken != "" {
+			scopeConfig.SessionToken = utils.StringPtr(creds.SessionToken)
+		}
+
+		m.Config.ObjectStore.Scopes = append(m.Config.ObjectStore.Scopes, scopeConfig)
+	}
+
+	secret.Name = nn.Name
+	secret.Namespace = nn.Namespace
+	secret.ObjectMeta.OwnerReferences = []metav1.OwnerReference{app.MakeOwnerReference()}
+	secret.Type = core.SecretTypeOpaque
+	secret.Data = data
+	providers.SetSecretLabels(secret, app)
+
+	return m.Cache.Update(MinioScopeSecret, secret)
+}
+
+// scopeCredentials are the credentials minted for a single scope.
+type scopeCredentials struct {
+	AccessKey    string
+	SecretKey    string
+	SessionToken string
+	Expiry       time.Time
+}
+
+// renewAfter returns how long from now the credentials should be replaced.
+func (c scopeCredentials) renewAfter(now time.Time) time.Duration {
+	return c.Expiry.Add(-scopeCredentialsRenewWindow).Sub(now)
+}
+
+func (c scopeCredentials) store(data map[string][]byte, scope string, policy string) {
+	data[scope+".accessKey"] = []byte(c.AccessKey)
+	data[scope+".secretKey"] = []byte(c.SecretKey)
+	data[scope+".sessionToken"] = []byte(c.SessionToken)
+	data[scope+".expiry"] = []byte(c.Expiry.UTC().Format(time.RFC3339))
+	data[scope+".policy"] = []byte(policy)
+}
+
+// storedScopeCredentials returns the credentials previously stored for a scope,
+// provided they were minted for the same policy and are not close to expiry.
+func storedScopeCredentials(data map[string][]byte, scope string, policy string, now time.Time) (scopeCredentials, bool) {
+	creds := scopeCredentials{}
+
+	if string(data[scope+".policy"]) != policy {
+		return creds, false
+	}
+
+	expiry, err := time.Parse(time.RFC3339, string(data[scope+".expiry"]))
+	if err != nil || now.Add(scopeCredentialsRenewWindow).After(expiry) {
+		return creds, false
+	}
+
+	creds.AccessKey = string(data[scope+".accessKey"])
+	creds.SecretKey = string(data[scope+".secretKey"])
+	creds.SessionToken = string(data[scope+".sessionToken"])
+	creds.Expiry = expiry
+
+	if creds.AccessKey == "" || creds.SecretKey == "" {
+		return creds, false
+	}
+
+	return creds, true
+}
+
+type policyStatement struct {
+	Effect   string   `json:"Effect"`
+	Action   []string `json:"Action"`
+	Resource []string `json:"Resource"`
+}
+
+type policyDocument struct {
+	Version   string            `json:"Version"`
+	Statement []policyStatement `json:"Statement"`
+}
+
+// scopePolicy builds the inline policy that limits a scope's credentials to
+// its buckets.
+func scopePolicy(scope crd.ObjectStoreScope) (string, error) {
+	actions := []string{"s3:*"}
+	if scope.ReadOnly {
+		actions = []string{"s3:GetBucketLocation", "s3:ListBucket", "s3:GetObject"}
+	}
+
+	resources := []string{}
+	for _, bucket := range scope.Buckets {
+		resources = append(resources, fmt.Sprintf("arn:aws:s3:::%s", bucket), fmt.Sprintf("arn:aws:s3:::%s/*", bucket))
+	}
+
+	policy, err := json.Marshal(policyDocument{
+		Version: "2012-10-17",
+		Statement: []policyStatement{{
+			Effect:   "Allow",
+			Action:   actions,
+			Resource: resources,
+		}},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return string(policy), nil
 }
 
 const bucketCheckErrorMsg = "failed to check if bucket exists"
@@ -173,11 +353,15 @@ type bucketHandler interface {
 	Exists(ctx context.Context, bucketName string) (bool, error)
 	Make(ctx context.Context, bucketName string) error
 	CreateClient(hostname string, port int, accessKey *string, secretKey *string) error
+	AssumeRole(ctx context.Context, policy string, duration time.Duration) (credentials.Value, error)
 }
 
 // minioHandler will implement the above interface using minio-go
 type minioHandler struct {
-	Client *minio.Client
+	Client    *minio.Client
+	endpoint  string
+	accessKey string
+	secretKey string
 }
 
 func (h *minioHandler) Exists(ctx context.Context, bucketName string) (bool, error) {
@@ -203,10 +387,28 @@ func (h *minioHandler) CreateClient(
 	}
 
 	h.Client = cl
+	h.endpoint = endpoint
+	h.accessKey = *accessKey
+	h.secretKey = *secretKey
 
 	return nil
 }
 
+func (h *minioHandler) AssumeRole(_ context.Context, policy string, duration time.Duration) (credentials.Value, error) {
+	creds, err := credentials.NewSTSAssumeRole(fmt.Sprintf("http://%s", h.endpoint), credentials.STSAssumeRoleOptions{
+		AccessKey:       h.accessKey,
+		SecretKey:       h.secretKey,
+		Policy:          policy,
+		DurationSeconds: int(duration.Seconds()),
+	})
+
+	if err != nil {
+		return credentials.Value{}, errors.Wrap("Failed to create STS client", err)
+	}
+
+	return creds.Get()
+}
+
 func createMinioProvider(
 	p *providers.Provider, secMap map[string]string, handler bucketHandler,
 ) (*minioProvider, error) {
@@ -232,15 +434,41 @@ func createMinioProvider(
 	return mp, nil
 }
 
-func createDefaultMinioSecMap(name string, namespace string) map[string]string {
+func createDefaultMinioSecMap(env *crd.ClowdEnvironment, name string, namespace string) map[string]string {
 	return map[string]string{
 		"accessKey": utils.RandString(12),
 		"secretKey": utils.RandString(12),
-		"hostname":  fmt.Sprintf("%v.%v.svc", name, namespace),
+		"hostname":  env.GetServiceHostname(name, namespace),
 		"port":      strconv.Itoa(int(9000)),
 	}
 }
 
+// updateMinioHostname rewrites the hostname in the minio secret, given as secMap, when it no longer
+// matches the service hostname of the environment, e.g. after its DNS suffix has changed.
+func updateMinioHostname(cache *rc.ObjectCache, env *crd.ClowdEnvironment, nn types.NamespacedName, secMap map[string]string) error {
+	hostname := env.GetServiceHostname(nn.Name, nn.Namespace)
+	if secMap["hostname"] == hostname {
+		return nil
+	}
+
+	secret := &core.Secret{}
+	if err := cache.Get(MinioSecret, secret, nn); err != nil {
+		return err
+	}
+
+	if secret.StringData != nil {
+		secret.StringData["hostname"] = hostname
+	} else {
+		if secret.Data == nil {
+			secret.Data = map[string][]byte{}
+		}
+		secret.Data["hostname"] = []byte(hostname)
+	}
+	secMap["hostname"] = hostname
+
+	return cache.Update(MinioSecret, secret)
+}
+
 func createNetworkPolicy(p *providers.Provider) error {
 	clowderNs, err := provutils.GetClowderNamespace()
 
@@ -393,6 +621,8 @@ func makeLocalMinIO(o obj.ClowdObject, objMap providers.ObjectMap, usePVC bool,
 		ImagePullPolicy:          core.PullIfNotPresent,
 	}
 
+	provutils.ApplyImageSettings(o.(*crd.ClowdEnvironment), &c)
+
 	dd.Spec.Template.Spec.Containers = []core.Container{c}
 	dd.Spec.Template.SetLabels(labels)
 
@@ -3,17 +3,22 @@ package objectstore
 import (
 	"context"
 	"testing"
+	"time"
 
 	crd "github.com/RedHatInsights/clowder/apis/cloud.redhat.com/v1alpha1"
 	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/config"
 	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/errors"
 	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers"
+	rc "github.com/RedHatInsights/rhc-osdk-utils/resourceCache"
+	"github.com/go-logr/logr"
+	"github.com/minio/minio-go/v7/pkg/credentials"
 	"github.com/stretchr/testify/assert"
 	core "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/meta"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
 type mockBucket struct {
@@ -31,6 +36,7 @@ type mockBucketHandler struct {
 	wantCreateClientError bool
 	ExistsCalls           []string
 	MakeCalls             []string
+	AssumeRoleCalls       []string
 	MockBuckets           []mockBucket
 }
 
@@ -79,6 +85,12 @@ func (c *mockBucketHandler) CreateClient(
 	return nil
 }
 
+func (c *mockBucketHandler) AssumeRole(_ context.Context, policy string, _ time.Duration) (credentials.Value, error) {
+	// track the calls to this mock func
+	c.AssumeRoleCalls = append(c.AssumeRoleCalls, policy)
+	return credentials.Value{AccessKeyID: "scoped", SecretAccessKey: "scopedSecret"}, nil
+}
+
 func getTestProvider(t *testing.T) providers.Provider {
 	t.Helper()
 	return providers.Provider{
@@ -410,3 +422,104 @@ func TestMinio(t *testing.T) {
 		assert.Contains(mp.Config.ObjectStore.Buckets, wantBucketConfig)
 	})
 }
+
+func TestMinioScopes(t *testing.T) {
+	assert := assert.New(t)
+
+	t.Run("scopePolicyFullAccess", func(t *testing.T) {
+		policy, err := scopePolicy(crd.ObjectStoreScope{Name: "writer", Buckets: []string{"own"}})
+		assert.NoError(err)
+		assert.JSONEq(`{
+			"Version": "2012-10-17",
+			"Statement": [{
+				"Effect": "Allow",
+				"Action": ["s3:*"],
+				"Resource": ["arn:aws:s3:::own", "arn:aws:s3:::own/*"]
+			}]
+		}`, policy)
+	})
+
+	t.Run("scopePolicyReadOnly", func(t *testing.T) {
+		policy, err := scopePolicy(crd.ObjectStoreScope{Name: "lake", Buckets: []string{"a", "b"}, ReadOnly: true})
+		assert.NoError(err)
+		assert.JSONEq(`{
+			"Version": "2012-10-17",
+			"Statement": [{
+				"Effect": "Allow",
+				"Action": ["s3:GetBucketLocation", "s3:ListBucket", "s3:GetObject"],
+				"Resource": ["arn:aws:s3:::a", "arn:aws:s3:::a/*", "arn:aws:s3:::b", "arn:aws:s3:::b/*"]
+			}]
+		}`, policy)
+	})
+
+	now := time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC)
+	stored := scopeCredentials{
+		AccessKey: "key",
+		SecretKey: "secret",
+		Expiry:    now.Add(scopeCredentialsDuration),
+	}
+
+	t.Run("storedCredentialsReused", func(t *testing.T) {
+		data := map[string][]byte{}
+		stored.store(data, "lake", "policy")
+
+		creds, ok := storedScopeCredentials(data, "lake", "policy", now.Add(time.Hour))
+		assert.True(ok)
+		assert.Equal(stored, creds)
+	})
+
+	t.Run("storedCredentialsPolicyChanged", func(t *testing.T) {
+		data := map[string][]byte{}
+		stored.store(data, "lake", "policy")
+
+		_, ok := storedScopeCredentials(data, "lake", "otherPolicy", now)
+		assert.False(ok)
+	})
+
+	t.Run("storedCredentialsNearExpiry", func(t *testing.T) {
+		data := map[string][]byte{}
+		stored.store(data, "lake", "policy")
+
+		_, ok := storedScopeCredentials(data, "lake", "policy", now.Add(scopeCredentialsDuration-time.Hour))
+		assert.False(ok)
+	})
+
+	t.Run("credentialsRenewAfter", func(t *testing.T) {
+		assert.Equal(scopeCredentialsDuration-scopeCredentialsRenewWindow, stored.renewAfter(now))
+	})
+
+	t.Run("storedCredentialsMissing", func(t *testing.T) {
+		_, ok := storedScopeCredentials(map[string][]byte{}, "lake", "policy", now)
+		assert.False(ok)
+	})
+}
+
+func TestUpdateMinioHostname(t *testing.T) {
+	ctx := context.Background()
+	log := logr.Discard()
+	cache := rc.NewObjectCache(ctx, fake.NewClientBuilder().Build(), &log, rc.NewCacheConfig(nil, nil, nil))
+	cache.AddPossibleGVKFromIdent(MinioSecret)
+
+	env := &crd.ClowdEnvironment{ObjectMeta: v1.ObjectMeta{Name: "env"}}
+	env.Status.TargetNamespace = "env-ns"
+	nn := providers.GetNamespacedName(env, "minio")
+
+	secret := &core.Secret{}
+	assert.NoError(t, cache.Create(MinioSecret, nn, secret))
+	secret.Name, secret.Namespace = nn.Name, nn.Namespace
+	secret.Data = map[string][]byte{"hostname": []byte("env-minio.env-ns.svc"), "port": []byte("9000")}
+	assert.NoError(t, cache.Update(MinioSecret, secret))
+
+	// The secret made with the old DNS suffix follows the environment's new one
+	env.Spec.ServiceConfig.DNSSuffix = "svc.cluster.local"
+	secMap := map[string]string{"hostname": "env-minio.env-ns.svc", "port": "9000"}
+	assert.NoError(t, updateMinioHostname(&cache, env, nn, secMap))
+	assert.Equal(t, "env-minio.env-ns.svc.cluster.local", secMap["hostname"])
+
+	updated := &core.Secret{}
+	assert.NoError(t, cache.Get(MinioSecret, updated, nn))
+	assert.Equal(t, "env-minio.env-ns.svc.cluster.local", string(updated.Data["hostname"]))
+	assert.Equal(t, "9000", string(updated.Data["port"]))
+
+	assert.NoError(t, updateMinioHostname(&cache, env, nn, secMap), "an up to date hostname is left alone")
+}
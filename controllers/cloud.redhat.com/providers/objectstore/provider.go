@@ -3,8 +3,10 @@ package objectstore
 import (
 	"fmt"
 
+	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/config"
 	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/errors"
 	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers"
+	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers/trustedca"
 )
 
 var DefaultImageObjectStoreMinio = "quay.io/cloudservices/minio:RELEASE.2020-11-19T23-48-16Z-amd64"
@@ -28,6 +30,34 @@ func GetObjectStore(c *providers.Provider) (providers.ClowderProvider, error) {
 	}
 }
 
+// setClientSettings tells clients how to address and connect to the object store, as the
+// environment sets it, or else as the store of the provider's mode expects: MinIO is addressed by
+// path without TLS, and S3 by virtual-hosted bucket names over TLS.
+func setClientSettings(p *providers.Provider, c *config.ObjectStoreConfig, forcePathStyle bool, tls bool) error {
+	objectStore := p.Env.Spec.Providers.ObjectStore
+
+	c.ForcePathStyle = forcePathStyle
+	if objectStore.ForcePathStyle != nil {
+		c.ForcePathStyle = *objectStore.ForcePathStyle
+	}
+
+	c.Tls = tls
+	if objectStore.TLSEnabled != nil {
+		c.Tls = *objectStore.TLSEnabled
+	}
+
+	if objectStore.CABundle == nil {
+		return nil
+	}
+
+	caCert, err := trustedca.ReadBundle(p.Ctx, p.Client, objectStore.CABundle)
+	if err != nil {
+		return err
+	}
+	c.CaCert = &caCert
+	return nil
+}
+
 func init() {
 	providers.ProvidersRegistration.Register(GetObjectStore, 5, ProvName)
 }
@@ -0,0 +1,71 @@
+package objectstore
+
+import (
+	"context"
+	"testing"
+
+	crd "github.com/RedHatInsights/clowder/apis/cloud.redhat.com/v1alpha1"
+	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/config"
+	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers"
+	"github.com/RedHatInsights/rhc-osdk-utils/utils"
+	"github.com/stretchr/testify/assert"
+	core "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestSetClientSettingsPathStyle(t *testing.T) {
+	p := &providers.Provider{Env: &crd.ClowdEnvironment{}}
+
+	// MinIO is addressed by path, without TLS
+	c := &config.ObjectStoreConfig{}
+	assert.NoError(t, setClientSettings(p, c, true, false))
+	assert.True(t, c.ForcePathStyle)
+	assert.False(t, c.Tls)
+	assert.Nil(t, c.CaCert)
+
+	// An S3 compatible store behind app-interface can ask for path style too
+	p.Env.Spec.Providers.ObjectStore.ForcePathStyle = utils.TruePtr()
+	c = &config.ObjectStoreConfig{}
+	assert.NoError(t, setClientSettings(p, c, false, true))
+	assert.True(t, c.ForcePathStyle)
+	assert.True(t, c.Tls)
+}
+
+func TestSetClientSettingsVirtualHostedStyle(t *testing.T) {
+	p := &providers.Provider{Env: &crd.ClowdEnvironment{}}
+
+	// S3 is addressed by virtual-hosted bucket names, over TLS
+	c := &config.ObjectStoreConfig{}
+	assert.NoError(t, setClientSettings(p, c, false, true))
+	assert.False(t, c.ForcePathStyle)
+	assert.True(t, c.Tls)
+
+	// MinIO served over TLS by its hostname
+	p.Env.Spec.Providers.ObjectStore.ForcePathStyle = utils.FalsePtr()
+	p.Env.Spec.Providers.ObjectStore.TLSEnabled = utils.TruePtr()
+	c = &config.ObjectStoreConfig{}
+	assert.NoError(t, setClientSettings(p, c, true, false))
+	assert.False(t, c.ForcePathStyle)
+	assert.True(t, c.Tls)
+}
+
+func TestSetClientSettingsCABundle(t *testing.T) {
+	cm := &core.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "store-ca", Namespace: "certs"},
+		Data:       map[string]string{"ca.crt": "-----BEGIN CERTIFICATE-----"},
+	}
+	p := &providers.Provider{
+		Ctx:    context.Background(),
+		Client: fake.NewClientBuilder().WithObjects(cm).Build(),
+		Env:    &crd.ClowdEnvironment{},
+	}
+	p.Env.Spec.Providers.ObjectStore.CABundle = &crd.TrustedCABundleConfig{Name: "store-ca", Namespace: "certs", Key: "ca.crt"}
+
+	c := &config.ObjectStoreConfig{}
+	assert.NoError(t, setClientSettings(p, c, false, true))
+	assert.Equal(t, "-----BEGIN CERTIFICATE-----", *c.CaCert)
+
+	p.Env.Spec.Providers.ObjectStore.CABundle.Key = "missing.crt"
+	assert.Error(t, setClientSettings(p, &config.ObjectStoreConfig{}, false, true))
+}
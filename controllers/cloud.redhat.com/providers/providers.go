@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"time"
 
 	crd "github.com/RedHatInsights/clowder/apis/cloud.redhat.com/v1alpha1"
 	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/config"
@@ -84,6 +85,54 @@ type Provider struct {
 	Log       logr.Logger
 	Config    *config.AppConfig
 	HashCache *hashcache.HashCache
+	Requeue   *Requeue
+}
+
+// Requeue collects the requeue delays suggested by providers during a reconcile.
+type Requeue struct {
+	After time.Duration
+}
+
+// Suggest records a delay after which the reconciled object should be reconciled again, keeping
+// the shortest delay suggested so far. Delays that are not positive are ignored.
+func (r *Requeue) Suggest(after time.Duration) {
+	if after <= 0 {
+		return
+	}
+	if r.After == 0 || after < r.After {
+		r.After = after
+	}
+}
+
+// RequeueAfter asks for the object being reconciled to be reconciled again after the given delay,
+// e.g. while something the provider manages is still converging. If no provider asks, the
+// controller's usual resync behaviour applies.
+func (prov *Provider) RequeueAfter(after time.Duration) {
+	if prov.Requeue != nil {
+		prov.Requeue.Suggest(after)
+	}
+}
+
+// RunWithTimeout runs fn against a copy of the provider whose context is cancelled once timeout
+// has passed, so that calls honouring the context give up instead of blocking the reconcile. An
+// error returned after the deadline passed is reported as a ProviderTimeout. A timeout that is not
+// positive runs fn without a deadline.
+func RunWithTimeout(prov *Provider, name string, timeout time.Duration, fn func(*Provider) error) error {
+	if timeout <= 0 {
+		return fn(prov)
+	}
+
+	ctx, cancel := context.WithTimeout(prov.Ctx, timeout)
+	defer cancel()
+
+	timed := *prov
+	timed.Ctx = ctx
+
+	err := fn(&timed)
+	if err != nil && ctx.Err() == context.DeadlineExceeded {
+		return &errors.ProviderTimeout{Provider: name, Timeout: timeout, Cause: err}
+	}
+	return err
 }
 
 func (prov *Provider) GetClient() client.Client {
@@ -266,6 +315,32 @@ func ExtractSecretDataAnno(secrets []core.Secret, fn ExtractFnAnno, annoKey stri
 	}
 }
 
+// ManagedSecretLabel is set on every Secret Clowder generates, so that secret scanners and rotation
+// tooling can tell them apart from Secrets managed by other means.
+const ManagedSecretLabel = "clowder-managed-secret"
+
+// SecretOwnerAnnotation names the Clowder object a generated Secret belongs to, as Kind/name.
+const SecretOwnerAnnotation = "clowder/secret-owner"
+
+// SetSecretLabels marks a Secret generated by Clowder as managed and owned by the given object,
+// keeping any labels and annotations it already has.
+func SetSecretLabels(secret *core.Secret, owner obj.LabeledClowdObject) {
+	labels := secret.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	labels[ManagedSecretLabel] = "true"
+	secret.SetLabels(labels)
+
+	annotations := secret.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	ref := owner.MakeOwnerReference()
+	annotations[SecretOwnerAnnotation] = fmt.Sprintf("%s/%s", ref.Kind, ref.Name)
+	secret.SetAnnotations(annotations)
+}
+
 // MakeOrGetSecret tries to get the secret described by nn, if it exists it populates a map with the
 // key/value pairs from the secret. If it doesn't exist the dataInit function is run and the
 // resulting data is returned, as well as the secret being created.
@@ -292,6 +367,8 @@ func MakeOrGetSecret(obj obj.ClowdObject, cache *rc.ObjectCache, resourceIdent r
 		}
 	}
 
+	SetSecretLabels(secret, obj)
+
 	if err := cache.Update(resourceIdent, secret); err != nil {
 		return nil, err
 	}
@@ -0,0 +1,86 @@
+package providers
+
+import (
+	"context"
+	errlib "errors"
+	"testing"
+	"time"
+
+	crd "github.com/RedHatInsights/clowder/apis/cloud.redhat.com/v1alpha1"
+	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/errors"
+	"github.com/stretchr/testify/assert"
+	core "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestRunWithTimeout(t *testing.T) {
+	prov := &Provider{Ctx: context.Background()}
+
+	// A slow provider is cancelled once the deadline passes
+	err := RunWithTimeout(prov, "slow", 10*time.Millisecond, func(p *Provider) error {
+		<-p.Ctx.Done()
+		return p.Ctx.Err()
+	})
+	var timeoutErr *errors.ProviderTimeout
+	assert.True(t, errlib.As(err, &timeoutErr))
+	assert.Equal(t, "slow", timeoutErr.Provider)
+	assert.Equal(t, 10*time.Millisecond, timeoutErr.Timeout)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// A provider finishing in time is left alone
+	err = RunWithTimeout(prov, "fast", time.Minute, func(p *Provider) error {
+		return nil
+	})
+	assert.NoError(t, err)
+
+	// Errors returned before the deadline are not reported as timeouts
+	failure := errlib.New("failed")
+	err = RunWithTimeout(prov, "failing", time.Minute, func(p *Provider) error {
+		return failure
+	})
+	assert.Equal(t, failure, err)
+
+	// The caller's provider keeps its own context
+	assert.Equal(t, context.Background(), prov.Ctx)
+
+	// Without a timeout the provider runs without a deadline
+	err = RunWithTimeout(prov, "unbounded", 0, func(p *Provider) error {
+		_, hasDeadline := p.Ctx.Deadline()
+		assert.False(t, hasDeadline)
+		return nil
+	})
+	assert.NoError(t, err)
+}
+
+func TestSetSecretLabels(t *testing.T) {
+	app := &crd.ClowdApp{
+		TypeMeta:   metav1.TypeMeta{Kind: "ClowdApp"},
+		ObjectMeta: metav1.ObjectMeta{Name: "puptoo", Namespace: "test"},
+	}
+	secret := &core.Secret{
+		ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "puptoo"}},
+	}
+
+	SetSecretLabels(secret, app)
+
+	assert.Equal(t, map[string]string{"app": "puptoo", ManagedSecretLabel: "true"}, secret.Labels)
+	assert.Equal(t, "ClowdApp/puptoo", secret.Annotations[SecretOwnerAnnotation])
+}
+
+func TestRequeueAfter(t *testing.T) {
+	requeue := &Requeue{}
+	prov := &Provider{Requeue: requeue}
+
+	// The shortest delay asked for wins
+	prov.RequeueAfter(time.Hour)
+	prov.RequeueAfter(5 * time.Minute)
+	prov.RequeueAfter(30 * time.Minute)
+	assert.Equal(t, 5*time.Minute, requeue.After)
+
+	// Delays that are not positive are ignored
+	prov.RequeueAfter(0)
+	assert.Equal(t, 5*time.Minute, requeue.After)
+
+	// Providers run without a requeue collector can still ask
+	(&Provider{}).RequeueAfter(time.Minute)
+}
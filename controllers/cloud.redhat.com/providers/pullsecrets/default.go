@@ -7,8 +7,12 @@ import (
 	crd "github.com/RedHatInsights/clowder/apis/cloud.redhat.com/v1alpha1"
 	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/object"
 	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers"
+	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers/database"
+	deployProvider "github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers/deployment"
 	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers/serviceaccount"
+	provutils "github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers/utils"
 
+	apps "k8s.io/api/apps/v1"
 	core "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
 
@@ -75,6 +79,7 @@ func (ps *pullsecretProvider) Provide(app *crd.ClowdApp) error {
 		return err
 	}
 
+	saPullSecrets := map[string][]core.LocalObjectReference{}
 	for _, sa := range saList.Items {
 		innerSA := sa
 		addAllSecrets(secList, &innerSA)
@@ -82,6 +87,11 @@ func (ps *pullsecretProvider) Provide(app *crd.ClowdApp) error {
 		if err := ps.Cache.Update(serviceaccount.CoreDeploymentServiceAccount, &innerSA); err != nil {
 			return err
 		}
+		saPullSecrets[innerSA.Name] = innerSA.ImagePullSecrets
+	}
+
+	if err := addDeploymentPullSecrets(&ps.Provider, app, saPullSecrets); err != nil {
+		return err
 	}
 
 	sa := &core.ServiceAccount{}
@@ -91,6 +101,10 @@ func (ps *pullsecretProvider) Provide(app *crd.ClowdApp) error {
 
 	addAllSecrets(secList, sa)
 
+	if err := addDatabasePullSecrets(&ps.Provider, app, sa.ImagePullSecrets); err != nil {
+		return err
+	}
+
 	return ps.Cache.Update(serviceaccount.CoreAppServiceAccount, sa)
 }
 
@@ -108,7 +122,7 @@ func copyPullSecrets(prov *providers.Provider, namespace string, obj object.Clow
 			return nil, err
 		}
 
-		secName := fmt.Sprintf("%s-%s-clowder-copy", prov.Env.Name, pullSecretName.Name)
+		secName := provutils.EnvPullSecretName(prov.Env, pullSecretName.Name)
 		secList = append(secList, secName)
 
 		newPullSecObj := &core.Secret{}
@@ -134,6 +148,7 @@ func copyPullSecrets(prov *providers.Provider, namespace string, obj object.Clow
 
 		newPullSecObj.Name = newSecNN.Name
 		newPullSecObj.Namespace = newSecNN.Namespace
+		providers.SetSecretLabels(newPullSecObj, prov.Env)
 
 		if err := prov.Cache.Update(CoreEnvPullSecrets, newPullSecObj); err != nil {
 			return nil, err
@@ -161,3 +176,66 @@ func addAllSecrets(secList []string, sa *core.ServiceAccount) {
 
 	sa.ImagePullSecrets = newSecrets
 }
+
+// addDeploymentPullSecrets sets the pod level pull secrets for any deployment
+// that requests its own. Pods that specify imagePullSecrets no longer inherit
+// those of their service account, so the service account's secrets are merged
+// in first to keep the environment defaults.
+func addDeploymentPullSecrets(prov *providers.Provider, app *crd.ClowdApp, saPullSecrets map[string][]core.LocalObjectReference) error {
+	for _, dep := range app.Spec.Deployments {
+		if len(dep.ImagePullSecrets) == 0 {
+			continue
+		}
+
+		innerDeployment := dep
+		w, err := deployProvider.GetWorkload(prov.Cache, app, &innerDeployment)
+		if err != nil {
+			return err
+		}
+
+		w.Template.Spec.ImagePullSecrets = mergePullSecrets(
+			saPullSecrets[w.Template.Spec.ServiceAccountName],
+			dep.ImagePullSecrets,
+		)
+
+		if err := w.Update(prov.Cache); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addDatabasePullSecrets sets the pod level pull secrets of the app's local
+// database if the app requests its own, merged with those of the app's
+// service account that the database runs as.
+func addDatabasePullSecrets(prov *providers.Provider, app *crd.ClowdApp, saPullSecrets []core.LocalObjectReference) error {
+	if len(app.Spec.Database.ImagePullSecrets) == 0 || !database.HasLocalDB(prov.Env, app) {
+		return nil
+	}
+
+	dd := &apps.Deployment{}
+	if err := prov.Cache.Get(database.LocalDBDeployment, dd); err != nil {
+		return err
+	}
+
+	dd.Spec.Template.Spec.ImagePullSecrets = mergePullSecrets(saPullSecrets, app.Spec.Database.ImagePullSecrets)
+
+	return prov.Cache.Update(database.LocalDBDeployment, dd)
+}
+
+func mergePullSecrets(lists ...[]core.LocalObjectReference) []core.LocalObjectReference {
+	merged := []core.LocalObjectReference{}
+	seen := map[string]bool{}
+
+	for _, list := range lists {
+		for _, secret := range list {
+			if secret.Name == "" || seen[secret.Name] {
+				continue
+			}
+			seen[secret.Name] = true
+			merged = append(merged, secret)
+		}
+	}
+
+	return merged
+}
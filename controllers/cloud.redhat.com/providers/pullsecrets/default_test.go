@@ -0,0 +1,75 @@
+package pullsecrets
+
+import (
+	"context"
+	"testing"
+
+	crd "github.com/RedHatInsights/clowder/apis/cloud.redhat.com/v1alpha1"
+	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers"
+	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers/database"
+	rc "github.com/RedHatInsights/rhc-osdk-utils/resourceCache"
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	apps "k8s.io/api/apps/v1"
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestMergePullSecrets(t *testing.T) {
+	env := []core.LocalObjectReference{
+		{Name: "app-dockercfg-abcde"},
+		{Name: "env-quay-clowder-copy"},
+	}
+	app := []core.LocalObjectReference{
+		{Name: "my-registry"},
+		{Name: "env-quay-clowder-copy"},
+		{Name: ""},
+	}
+
+	assert.Equal(t, []core.LocalObjectReference{
+		{Name: "app-dockercfg-abcde"},
+		{Name: "env-quay-clowder-copy"},
+		{Name: "my-registry"},
+	}, mergePullSecrets(env, app))
+}
+
+func TestAddDatabasePullSecrets(t *testing.T) {
+	ctx := context.Background()
+	log := logr.Discard()
+	cache := rc.NewObjectCache(ctx, fake.NewClientBuilder().Build(), &log, rc.NewCacheConfig(nil, nil, nil))
+	env := &crd.ClowdEnvironment{}
+	env.Spec.Providers.Database.Mode = "local"
+	prov := &providers.Provider{Ctx: ctx, Cache: &cache, Env: env}
+
+	app := &crd.ClowdApp{Spec: crd.ClowdAppSpec{Database: crd.DatabaseSpec{
+		Name:             "inventory",
+		ImagePullSecrets: []core.LocalObjectReference{{Name: "db-registry"}},
+	}}}
+	saPullSecrets := []core.LocalObjectReference{{Name: "env-quay-clowder-copy"}}
+
+	// Apps without a local database are left alone
+	shared := app.DeepCopy()
+	shared.Spec.Database.SharedDBAppName = "host"
+	assert.NoError(t, addDatabasePullSecrets(prov, shared, saPullSecrets))
+	override := app.DeepCopy()
+	override.Spec.Database.ModeOverride = "app-interface"
+	assert.NoError(t, addDatabasePullSecrets(prov, override, saPullSecrets))
+
+	// A local database missing from the cache is an error rather than skipped
+	assert.Error(t, addDatabasePullSecrets(prov, app, saPullSecrets))
+
+	nn := types.NamespacedName{Name: "inventory-db", Namespace: "test"}
+	dd := &apps.Deployment{}
+	assert.NoError(t, cache.Create(database.LocalDBDeployment, nn, dd))
+	dd.Name, dd.Namespace = nn.Name, nn.Namespace
+	assert.NoError(t, cache.Update(database.LocalDBDeployment, dd))
+	assert.NoError(t, addDatabasePullSecrets(prov, app, saPullSecrets))
+
+	dd = &apps.Deployment{}
+	assert.NoError(t, cache.Get(database.LocalDBDeployment, dd))
+	assert.Equal(t, []core.LocalObjectReference{
+		{Name: "env-quay-clowder-copy"},
+		{Name: "db-registry"},
+	}, dd.Spec.Template.Spec.ImagePullSecrets)
+}
@@ -0,0 +1,233 @@
+package rollout
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	crd "github.com/RedHatInsights/clowder/apis/cloud.redhat.com/v1alpha1"
+	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/errors"
+	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/featuregates"
+	p "github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers"
+	deployProvider "github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers/deployment"
+	provutils "github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers/utils"
+
+	apps "k8s.io/api/apps/v1"
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	k8serr "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/RedHatInsights/rhc-osdk-utils/utils"
+)
+
+// PausedAnnotation marks a deployment whose rollout Clowder paused, listing the deployments it
+// waits on. Deployments paused by anyone else are left alone.
+const PausedAnnotation = "clowder/rollout-paused"
+
+// rolloutPollInterval is how soon an app with a paused deployment is reconciled again, should
+// an update of the deployments it waits on be missed.
+const rolloutPollInterval = 30 * time.Second
+
+type rolloutProvider struct {
+	p.Provider
+}
+
+// NewRolloutProvider returns a new provider that rolls out an app's deployments in the order of
+// their dependencies.
+func NewRolloutProvider(p *p.Provider) (p.ClowderProvider, error) {
+	return &rolloutProvider{Provider: *p}, nil
+}
+
+func (r *rolloutProvider) EnvProvide() error {
+	return nil
+}
+
+// Provide pauses the rollout of each deployment that depends on a deployment which is not yet
+// running the spec applied to it, on all of its pods, and resumes it once none are. While the
+// DeploymentRolloutOrder gate is disabled nothing is held, so every deployment Clowder paused is
+// resumed.
+func (r *rolloutProvider) Provide(app *crd.ClowdApp) error {
+	held := map[string][]string{}
+	if featuregates.Gates.Enabled(featuregates.DeploymentRolloutOrder) {
+		var err error
+		if held, err = r.getHeldDeployments(app); err != nil {
+			return err
+		}
+	}
+
+	for i := range app.Spec.Deployments {
+		deployment := &app.Spec.Deployments[i]
+		if deployment.IsDaemonSet() {
+			continue
+		}
+
+		w, err := deployProvider.GetWorkload(r.Cache, app, deployment)
+		if err != nil {
+			return err
+		}
+		d := w.Object.(*apps.Deployment)
+
+		waitingOn, isHeld := held[deployment.Name]
+		_, wasHeld := d.GetAnnotations()[PausedAnnotation]
+		switch {
+		case isHeld:
+			d.Spec.Paused = true
+			utils.UpdateAnnotations(d, map[string]string{PausedAnnotation: strings.Join(waitingOn, ",")})
+			provutils.DebugLog(r.Log, "pausing rollout until dependencies are ready", "deployment", deployment.Name, "waitingOn", waitingOn)
+			r.RequeueAfter(rolloutPollInterval)
+		case wasHeld:
+			d.Spec.Paused = false
+			delete(d.Annotations, PausedAnnotation)
+		default:
+			continue
+		}
+
+		if err := w.Update(r.Cache); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// getHeldDeployments returns the deployments whose rollout waits on others, along with the
+// deployments each waits on.
+func (r *rolloutProvider) getHeldDeployments(app *crd.ClowdApp) (map[string][]string, error) {
+	held := map[string][]string{}
+	if !app.HasDeploymentOrder() {
+		return held, nil
+	}
+
+	order, err := app.GetDeploymentRolloutOrder()
+	if err != nil {
+		return nil, errors.Wrap("order deployments", err)
+	}
+
+	deployments := map[string]*crd.Deployment{}
+	for _, deployment := range order {
+		deployments[deployment.Name] = deployment
+	}
+
+	for _, deployment := range order {
+		waitingOn := []string{}
+		for _, dep := range deployment.DependsOn {
+			ready := false
+			if _, depHeld := held[dep]; !depHeld {
+				if ready, err = r.isRolledOut(app, deployments[dep]); err != nil {
+					return nil, err
+				}
+			}
+			if !ready {
+				waitingOn = append(waitingOn, dep)
+			}
+		}
+		if len(waitingOn) > 0 && !deployment.IsDaemonSet() {
+			held[deployment.Name] = waitingOn
+		}
+	}
+	return held, nil
+}
+
+// isRolledOut reports whether the deployment runs the spec this reconcile applies to it.
+func (r *rolloutProvider) isRolledOut(app *crd.ClowdApp, deployment *crd.Deployment) (bool, error) {
+	desired, err := deployProvider.GetWorkload(r.Cache, app, deployment)
+	if err != nil {
+		return false, err
+	}
+
+	live, template, err := getLiveWorkload(r.Ctx, r.Client, app, deployment)
+	if err != nil || live == nil {
+		return false, err
+	}
+
+	// Fields the API server defaults are missing from the desired template, so only the fields
+	// it sets are compared
+	if !equality.Semantic.DeepDerivative(*desired.Template, *template) {
+		return false, nil
+	}
+
+	return isLiveRolledOut(live), nil
+}
+
+// getLiveWorkload fetches the deployment's workload and its pod template from the cluster, the
+// workload is nil if it does not exist yet.
+func getLiveWorkload(ctx context.Context, c client.Client, app *crd.ClowdApp, deployment *crd.Deployment) (client.Object, *core.PodTemplateSpec, error) {
+	nn := app.GetDeploymentNamespacedName(deployment)
+
+	var live client.Object
+	var template *core.PodTemplateSpec
+	if deployment.IsDaemonSet() {
+		ds := &apps.DaemonSet{}
+		live, template = ds, &ds.Spec.Template
+	} else {
+		d := &apps.Deployment{}
+		live, template = d, &d.Spec.Template
+	}
+
+	if err := c.Get(ctx, nn, live); err != nil {
+		if k8serr.IsNotFound(err) {
+			return nil, nil, nil
+		}
+		return nil, nil, errors.Wrap("get workload", err)
+	}
+	return live, template, nil
+}
+
+// isLiveRolledOut reports whether all of the workload's pods run its current spec and are
+// available.
+func isLiveRolledOut(live client.Object) bool {
+	switch w := live.(type) {
+	case *apps.Deployment:
+		replicas := int32(1)
+		if w.Spec.Replicas != nil {
+			replicas = *w.Spec.Replicas
+		}
+		return !w.Spec.Paused &&
+			w.Status.ObservedGeneration >= w.Generation &&
+			w.Status.Replicas == replicas &&
+			w.Status.UpdatedReplicas == replicas &&
+			w.Status.AvailableReplicas == replicas
+	case *apps.DaemonSet:
+		return w.Status.ObservedGeneration >= w.Generation &&
+			w.Status.UpdatedNumberScheduled == w.Status.DesiredNumberScheduled &&
+			w.Status.NumberAvailable == w.Status.DesiredNumberScheduled
+	}
+	return false
+}
+
+// GetRolloutStatus reports the rollout progress of each of the app's deployments, as observed
+// in the cluster. Nothing is reported unless some of the deployments depend on others.
+func GetRolloutStatus(ctx context.Context, c client.Client, app *crd.ClowdApp) ([]crd.DeploymentRolloutStatus, error) {
+	if !app.HasDeploymentOrder() {
+		return nil, nil
+	}
+
+	rolledOut := map[string]bool{}
+	for i := range app.Spec.Deployments {
+		deployment := &app.Spec.Deployments[i]
+		live, _, err := getLiveWorkload(ctx, c, app, deployment)
+		if err != nil {
+			return nil, err
+		}
+		rolledOut[deployment.Name] = live != nil && isLiveRolledOut(live)
+	}
+
+	statuses := []crd.DeploymentRolloutStatus{}
+	for _, deployment := range app.Spec.Deployments {
+		status := crd.DeploymentRolloutStatus{Name: deployment.Name, Phase: crd.RolloutReady}
+		if !rolledOut[deployment.Name] {
+			for _, dep := range deployment.DependsOn {
+				if !rolledOut[dep] {
+					status.WaitingOn = append(status.WaitingOn, dep)
+				}
+			}
+			status.Phase = crd.RolloutRollingOut
+			if len(status.WaitingOn) > 0 {
+				status.Phase = crd.RolloutWaiting
+			}
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
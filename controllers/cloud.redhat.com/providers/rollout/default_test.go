@@ -0,0 +1,148 @@
+package rollout
+
+import (
+	"context"
+	"testing"
+
+	crd "github.com/RedHatInsights/clowder/apis/cloud.redhat.com/v1alpha1"
+	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/featuregates"
+	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers"
+	deployProvider "github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers/deployment"
+	rc "github.com/RedHatInsights/rhc-osdk-utils/resourceCache"
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	apps "k8s.io/api/apps/v1"
+	core "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func getOrderedApp() *crd.ClowdApp {
+	return &crd.ClowdApp{
+		ObjectMeta: metav1.ObjectMeta{Name: "puptoo", Namespace: "test"},
+		Spec: crd.ClowdAppSpec{Deployments: []crd.Deployment{
+			{Name: "migrate"},
+			{Name: "api", DependsOn: []string{"migrate"}},
+		}},
+	}
+}
+
+func makeLiveDeployment(name string, image string, rolledOut bool) *apps.Deployment {
+	replicas := int32(1)
+	d := &apps.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test", Generation: 1},
+		Spec:       apps.DeploymentSpec{Replicas: &replicas},
+	}
+	d.Spec.Template.Spec.Containers = []core.Container{{Name: name, Image: image}}
+	if rolledOut {
+		d.Status = apps.DeploymentStatus{ObservedGeneration: 1, Replicas: 1, UpdatedReplicas: 1, AvailableReplicas: 1}
+	}
+	return d
+}
+
+// enableRolloutOrder turns the DeploymentRolloutOrder gate on for the rest of the test.
+func enableRolloutOrder(t *testing.T) {
+	assert.NoError(t, featuregates.Gates.Set("DeploymentRolloutOrder=true"))
+	t.Cleanup(func() {
+		assert.NoError(t, featuregates.Gates.Set("DeploymentRolloutOrder=false"))
+	})
+}
+
+// provideRollout builds the desired deployments of the app in a fresh cache and runs the
+// provider, returning the desired api deployment.
+func provideRollout(t *testing.T, c client.Client, app *crd.ClowdApp, image string) *apps.Deployment {
+	ctx := context.Background()
+	log := logr.Discard()
+	cache := rc.NewObjectCache(ctx, c, &log, rc.NewCacheConfig(nil, nil, nil))
+	cache.AddPossibleGVKFromIdent(deployProvider.CoreDeployment)
+
+	for i := range app.Spec.Deployments {
+		nn := app.GetDeploymentNamespacedName(&app.Spec.Deployments[i])
+		d := &apps.Deployment{}
+		assert.NoError(t, cache.Create(deployProvider.CoreDeployment, nn, d))
+		d.Name, d.Namespace = nn.Name, nn.Namespace
+		d.Spec.Template.Spec.Containers = []core.Container{{Name: nn.Name, Image: image}}
+		assert.NoError(t, cache.Update(deployProvider.CoreDeployment, d))
+	}
+
+	prov, err := NewRolloutProvider(&providers.Provider{Ctx: ctx, Client: c, Cache: &cache, Log: log})
+	assert.NoError(t, err)
+	assert.NoError(t, prov.Provide(app))
+
+	d := &apps.Deployment{}
+	assert.NoError(t, cache.Get(deployProvider.CoreDeployment, d, app.GetDeploymentNamespacedName(&app.Spec.Deployments[1])))
+	return d
+}
+
+func TestProvideHoldsDependents(t *testing.T) {
+	enableRolloutOrder(t)
+	app := getOrderedApp()
+
+	// Nothing has rolled out yet
+	api := provideRollout(t, fake.NewClientBuilder().Build(), app, "v1")
+	assert.True(t, api.Spec.Paused)
+	assert.Equal(t, "migrate", api.Annotations[PausedAnnotation])
+
+	// The dependency runs the desired spec, so the dependent is resumed
+	pausedAPI := makeLiveDeployment("puptoo-api", "v1", false)
+	pausedAPI.Spec.Paused = true
+	pausedAPI.Annotations = map[string]string{PausedAnnotation: "migrate"}
+	c := fake.NewClientBuilder().WithObjects(makeLiveDeployment("puptoo-migrate", "v1", true), pausedAPI).Build()
+	api = provideRollout(t, c, app, "v1")
+	assert.False(t, api.Spec.Paused)
+	assert.NotContains(t, api.Annotations, PausedAnnotation)
+
+	// A change to the dependency holds the dependent back again
+	api = provideRollout(t, c, app, "v2")
+	assert.True(t, api.Spec.Paused)
+}
+
+func TestProvideLeavesOthersPaused(t *testing.T) {
+	enableRolloutOrder(t)
+	app := getOrderedApp()
+	app.Spec.Deployments[1].DependsOn = nil
+
+	pausedAPI := makeLiveDeployment("puptoo-api", "v1", false)
+	pausedAPI.Spec.Paused = true
+	api := provideRollout(t, fake.NewClientBuilder().WithObjects(pausedAPI).Build(), app, "v1")
+	assert.True(t, api.Spec.Paused)
+}
+
+func TestProvideResumesWhenGateDisabled(t *testing.T) {
+	app := getOrderedApp()
+
+	pausedAPI := makeLiveDeployment("puptoo-api", "v1", false)
+	pausedAPI.Spec.Paused = true
+	pausedAPI.Annotations = map[string]string{PausedAnnotation: "migrate"}
+	api := provideRollout(t, fake.NewClientBuilder().WithObjects(pausedAPI).Build(), app, "v1")
+	assert.False(t, api.Spec.Paused)
+	assert.NotContains(t, api.Annotations, PausedAnnotation)
+}
+
+func TestGetRolloutStatus(t *testing.T) {
+	ctx := context.Background()
+	app := getOrderedApp()
+
+	c := fake.NewClientBuilder().WithObjects(makeLiveDeployment("puptoo-migrate", "v1", false)).Build()
+	statuses, err := GetRolloutStatus(ctx, c, app)
+	assert.NoError(t, err)
+	assert.Equal(t, []crd.DeploymentRolloutStatus{
+		{Name: "migrate", Phase: crd.RolloutRollingOut},
+		{Name: "api", Phase: crd.RolloutWaiting, WaitingOn: []string{"migrate"}},
+	}, statuses)
+
+	c = fake.NewClientBuilder().WithObjects(
+		makeLiveDeployment("puptoo-migrate", "v1", true),
+		makeLiveDeployment("puptoo-api", "v1", false),
+	).Build()
+	statuses, err = GetRolloutStatus(ctx, c, app)
+	assert.NoError(t, err)
+	assert.Equal(t, crd.RolloutReady, statuses[0].Phase)
+	assert.Equal(t, crd.RolloutRollingOut, statuses[1].Phase)
+
+	app.Spec.Deployments[1].DependsOn = nil
+	statuses, err = GetRolloutStatus(ctx, c, app)
+	assert.NoError(t, err)
+	assert.Nil(t, statuses)
+}
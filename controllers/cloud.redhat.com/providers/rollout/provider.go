@@ -0,0 +1,19 @@
+package rollout
+
+import (
+	p "github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers"
+)
+
+// ProvName sets the provider name identifier
+var ProvName = "rollout"
+
+// GetRollout returns the correct rollout provider.
+func GetRollout(c *p.Provider) (p.ClowderProvider, error) {
+	return NewRolloutProvider(c)
+}
+
+func init() {
+	// Run after every provider that changes the deployments, so that a dependency whose
+	// spec changes in this reconcile is seen as not yet rolled out
+	p.ProvidersRegistration.Register(GetRollout, 100, ProvName)
+}
@@ -29,6 +29,12 @@ var CoreDeploymentServiceAccount = rc.NewMultiResourceIdent(ProvName, "core_depl
 // CoreAppServiceAccount is the serviceaccount for the apps.
 var CoreAppServiceAccount = rc.NewSingleResourceIdent(ProvName, "core_app_service_account", &core.ServiceAccount{})
 
+// CoreAppRole is the role holding the RBAC rules of the app.
+var CoreAppRole = rc.NewSingleResourceIdent(ProvName, "core_app_role", &rbac.Role{})
+
+// CoreAppRoleBinding is the rolebinding for the role of the app.
+var CoreAppRoleBinding = rc.NewSingleResourceIdent(ProvName, "core_app_role_binding", &rbac.RoleBinding{})
+
 // CoreEnvServiceAccount is the serviceaccount for the env.
 var CoreEnvServiceAccount = rc.NewSingleResourceIdent(ProvName, "core_env_service_account", &core.ServiceAccount{})
 
@@ -47,6 +53,8 @@ func NewServiceAccountProvider(p *providers.Provider) (providers.ClowderProvider
 		CoreDeploymentRoleBinding,
 		CoreDeploymentServiceAccount,
 		CoreAppServiceAccount,
+		CoreAppRole,
+		CoreAppRoleBinding,
 		CoreEnvServiceAccount,
 		IQEServiceAccount,
 		IQERoleBinding,
@@ -114,11 +122,11 @@ func (sa *serviceaccountProvider) Provide(app *crd.ClowdApp) error {
 	}
 
 	for _, dep := range app.Spec.Deployments {
-		d := &apps.Deployment{}
 		innerDeployment := dep
 		nn := app.GetDeploymentNamespacedName(&innerDeployment)
 
-		if err := sa.Cache.Get(deployment.CoreDeployment, d, nn); err != nil {
+		w, err := deployment.GetWorkload(sa.Cache, app, &innerDeployment)
+		if err != nil {
 			return err
 		}
 
@@ -128,8 +136,8 @@ func (sa *serviceaccountProvider) Provide(app *crd.ClowdApp) error {
 			return err
 		}
 
-		d.Spec.Template.Spec.ServiceAccountName = nn.Name
-		if err := sa.Cache.Update(deployment.CoreDeployment, d); err != nil {
+		w.Template.Spec.ServiceAccountName = nn.Name
+		if err := w.Update(sa.Cache); err != nil {
 			return err
 		}
 
@@ -139,7 +147,7 @@ func (sa *serviceaccountProvider) Provide(app *crd.ClowdApp) error {
 
 	}
 
-	return nil
+	return CreateAppRole(sa.Cache, CoreAppRole, CoreAppRoleBinding, app)
 }
 
 func createIQEServiceAccounts(p *providers.Provider, app *crd.ClowdApp) error {
@@ -1,6 +1,8 @@
 package serviceaccount
 
 import (
+	"fmt"
+
 	crd "github.com/RedHatInsights/clowder/apis/cloud.redhat.com/v1alpha1"
 
 	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/errors"
@@ -77,3 +79,58 @@ func CreateRoleBinding(cache *rc.ObjectCache, ident rc.ResourceIdent, nn types.N
 
 	return cache.Update(ident, rb)
 }
+
+// CreateAppRole creates a Role holding the app's RBAC rules in its namespace, and binds it to the
+// app's service account and those of each of its deployments. Nothing is created for an app
+// without rules.
+func CreateAppRole(cache *rc.ObjectCache, roleIdent rc.ResourceIdent, bindingIdent rc.ResourceIdent, app *crd.ClowdApp) error {
+	if len(app.Spec.RBACRules) == 0 {
+		return nil
+	}
+
+	nn := types.NamespacedName{
+		Name:      fmt.Sprintf("%s-rbac", app.Name),
+		Namespace: app.Namespace,
+	}
+	labeler := utils.GetCustomLabeler(nil, nn, app)
+
+	role := &rbac.Role{}
+	if err := cache.Create(roleIdent, nn, role); err != nil {
+		return err
+	}
+
+	labeler(role)
+	role.Rules = app.Spec.RBACRules
+
+	if err := cache.Update(roleIdent, role); err != nil {
+		return err
+	}
+
+	rb := &rbac.RoleBinding{}
+	if err := cache.Create(bindingIdent, nn, rb); err != nil {
+		return err
+	}
+
+	labeler(rb)
+
+	rb.Subjects = []rbac.Subject{{
+		Kind:      "ServiceAccount",
+		Name:      app.GetClowdSAName(),
+		Namespace: app.Namespace,
+	}}
+	for _, dep := range app.Spec.Deployments {
+		innerDeployment := dep
+		rb.Subjects = append(rb.Subjects, rbac.Subject{
+			Kind:      "ServiceAccount",
+			Name:      app.GetDeploymentNamespacedName(&innerDeployment).Name,
+			Namespace: app.Namespace,
+		})
+	}
+	rb.RoleRef = rbac.RoleRef{
+		APIGroup: "rbac.authorization.k8s.io",
+		Kind:     "Role",
+		Name:     nn.Name,
+	}
+
+	return cache.Update(bindingIdent, rb)
+}
@@ -0,0 +1,52 @@
+package serviceaccount
+
+import (
+	"context"
+	"testing"
+
+	crd "github.com/RedHatInsights/clowder/apis/cloud.redhat.com/v1alpha1"
+	rc "github.com/RedHatInsights/rhc-osdk-utils/resourceCache"
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	rbac "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestCreateAppRole(t *testing.T) {
+	ctx := context.Background()
+	log := logr.Discard()
+	c := fake.NewClientBuilder().Build()
+	cache := rc.NewObjectCache(ctx, c, &log, rc.NewCacheConfig(nil, nil, nil))
+	cache.AddPossibleGVKFromIdent(CoreAppRole, CoreAppRoleBinding)
+
+	app := &crd.ClowdApp{
+		ObjectMeta: metav1.ObjectMeta{Name: "puptoo", Namespace: "test"},
+		Spec:       crd.ClowdAppSpec{Deployments: []crd.Deployment{{Name: "processor"}}},
+	}
+
+	// Without rules no role is created
+	assert.NoError(t, CreateAppRole(&cache, CoreAppRole, CoreAppRoleBinding, app))
+	assert.Error(t, cache.Get(CoreAppRole, &rbac.Role{}))
+
+	app.Spec.RBACRules = []rbac.PolicyRule{{
+		APIGroups: []string{""},
+		Resources: []string{"configmaps"},
+		Verbs:     []string{"get", "watch"},
+	}}
+	assert.NoError(t, CreateAppRole(&cache, CoreAppRole, CoreAppRoleBinding, app))
+
+	role := &rbac.Role{}
+	assert.NoError(t, cache.Get(CoreAppRole, role))
+	assert.Equal(t, "puptoo-rbac", role.Name)
+	assert.Equal(t, "test", role.Namespace)
+	assert.Equal(t, app.Spec.RBACRules, role.Rules)
+
+	rb := &rbac.RoleBinding{}
+	assert.NoError(t, cache.Get(CoreAppRoleBinding, rb))
+	assert.Equal(t, rbac.RoleRef{APIGroup: "rbac.authorization.k8s.io", Kind: "Role", Name: "puptoo-rbac"}, rb.RoleRef)
+	assert.Equal(t, []rbac.Subject{
+		{Kind: "ServiceAccount", Name: "puptoo-app", Namespace: "test"},
+		{Kind: "ServiceAccount", Name: "puptoo-processor", Namespace: "test"},
+	}, rb.Subjects)
+}
@@ -6,7 +6,6 @@ import (
 	crd "github.com/RedHatInsights/clowder/apis/cloud.redhat.com/v1alpha1"
 	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers"
 	deployProvider "github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers/deployment"
-	apps "k8s.io/api/apps/v1"
 
 	"github.com/RedHatInsights/rhc-osdk-utils/utils"
 )
@@ -24,25 +23,24 @@ func (ch *servicemeshProvider) EnvProvide() error {
 	return nil
 }
 
-func (ch *servicemeshProvider) Provide(_ *crd.ClowdApp) error {
+func (ch *servicemeshProvider) Provide(app *crd.ClowdApp) error {
 	if ch.Env.Spec.Providers.ServiceMesh.Mode != "enabled" {
 		return nil
 	}
 
-	dList := apps.DeploymentList{}
-	if err := ch.Cache.List(deployProvider.CoreDeployment, &dList); err != nil {
+	workloads, err := deployProvider.GetWorkloads(ch.Cache, app)
+	if err != nil {
 		return err
 	}
 
-	for _, deployment := range dList.Items {
-		innerDeployment := deployment
+	for _, w := range workloads {
 		annotations := map[string]string{
 			"sidecar.istio.io/inject":                       "true",
 			"traffic.sidecar.istio.io/excludeOutboundPorts": "443,9093,5432,10000",
 		}
-		utils.UpdateAnnotations(&innerDeployment.Spec.Template, annotations)
+		utils.UpdateAnnotations(w.Template, annotations)
 
-		err := ch.Cache.Update(deployProvider.CoreDeployment, &innerDeployment)
+		err := w.Update(ch.Cache)
 		if err != nil {
 			return fmt.Errorf("could not update annotations: %w", err)
 		}
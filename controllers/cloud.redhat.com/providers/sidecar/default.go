@@ -2,22 +2,30 @@ package sidecar
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 
 	crd "github.com/RedHatInsights/clowder/apis/cloud.redhat.com/v1alpha1"
 	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers"
 	cronjobProvider "github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers/cronjob"
 	deployProvider "github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers/deployment"
+	provutils "github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers/utils"
 
-	apps "k8s.io/api/apps/v1"
 	batch "k8s.io/api/batch/v1"
 	core "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/RedHatInsights/rhc-osdk-utils/utils"
 )
 
 type sidecarProvider struct {
 	providers.Provider
 }
 
+// SidecarVolumesAnnotation lists the shared sidecar volumes of a pod template, so that the
+// sidecars the pod mutator injects into its pods mount them as well.
+const SidecarVolumesAnnotation = "clowder/sidecar-volumes"
+
 func NewSidecarProvider(p *providers.Provider) (providers.ClowderProvider, error) {
 	return &sidecarProvider{Provider: *p}, nil
 }
@@ -29,27 +37,16 @@ func (sc *sidecarProvider) EnvProvide() error {
 func (sc *sidecarProvider) Provide(app *crd.ClowdApp) error {
 	for _, deployment := range app.Spec.Deployments {
 		innerDeployment := deployment
-		d := &apps.Deployment{}
-
-		if err := sc.Cache.Get(deployProvider.CoreDeployment, d, app.GetDeploymentNamespacedName(&innerDeployment)); err != nil {
+		w, err := deployProvider.GetWorkload(sc.Cache, app, &innerDeployment)
+		if err != nil {
 			return err
 		}
 
-		for _, sidecar := range innerDeployment.PodSpec.Sidecars {
-			switch sidecar.Name {
-			case "token-refresher":
-				if sidecar.Enabled && sc.Env.Spec.Providers.Sidecars.TokenRefresher.Enabled {
-					cont := getTokenRefresher(app.Name)
-					if cont != nil {
-						d.Spec.Template.Spec.Containers = append(d.Spec.Template.Spec.Containers, *cont)
-					}
-				}
-			default:
-				return fmt.Errorf("%s is not a valid sidecar name", sidecar.Name)
-			}
+		if err := sc.injectSidecars(app, &innerDeployment.PodSpec, w.Template); err != nil {
+			return err
 		}
 
-		if err := sc.Cache.Update(deployProvider.CoreDeployment, d); err != nil {
+		if err := w.Update(sc.Cache); err != nil {
 			return err
 		}
 	}
@@ -65,18 +62,8 @@ func (sc *sidecarProvider) Provide(app *crd.ClowdApp) error {
 			return err
 		}
 
-		for _, sidecar := range innerCronJob.PodSpec.Sidecars {
-			switch sidecar.Name {
-			case "token-refresher":
-				if sidecar.Enabled && sc.Env.Spec.Providers.Sidecars.TokenRefresher.Enabled {
-					cont := getTokenRefresher(app.Name)
-					if cont != nil {
-						cj.Spec.JobTemplate.Spec.Template.Spec.Containers = append(cj.Spec.JobTemplate.Spec.Template.Spec.Containers, *cont)
-					}
-				}
-			default:
-				return fmt.Errorf("%s is not a valid sidecar name", sidecar.Name)
-			}
+		if err := sc.injectSidecars(app, &innerCronJob.PodSpec, &cj.Spec.JobTemplate.Spec.Template); err != nil {
+			return err
 		}
 
 		if err := sc.Cache.Update(cronjobProvider.CoreCronJob, cj); err != nil {
@@ -87,7 +74,97 @@ func (sc *sidecarProvider) Provide(app *crd.ClowdApp) error {
 	return nil
 }
 
-func getTokenRefresher(appName string) *core.Container {
+// injectSidecars appends the enabled sidecars requested by the pod to the given pod template,
+// along with any volumes they share with the pod's container.
+func (sc *sidecarProvider) injectSidecars(app *crd.ClowdApp, pod *crd.PodSpec, template *core.PodTemplateSpec) error {
+	spec := &template.Spec
+	var sidecars []core.Container
+
+	for _, sidecar := range pod.Sidecars {
+		switch sidecar.Name {
+		case "token-refresher":
+			if sidecar.Enabled && sc.Env.Spec.Providers.Sidecars.TokenRefresher.Enabled {
+				cont := getTokenRefresher(sc.Env, app.Name)
+				if cont != nil {
+					setTerminationDelay(spec, cont, sidecar.TerminationDelaySeconds)
+					sidecars = append(sidecars, *cont)
+				}
+			}
+		default:
+			return fmt.Errorf("%s is not a valid sidecar name", sidecar.Name)
+		}
+	}
+
+	spec.Containers = append(spec.Containers, sidecars...)
+	addSidecarVolumes(template, pod.SidecarVolumes)
+
+	return nil
+}
+
+// defaultTerminationGracePeriod is the grace period kubernetes gives a pod that doesn't set one.
+const defaultTerminationGracePeriod = 30
+
+// setTerminationDelay delays the termination of a sidecar with a preStop sleep, so that the
+// pod's container is sent SIGTERM first. The pod's grace period is raised so a delayed sidecar
+// still gets the default grace period to shut down once the sleep ends.
+func setTerminationDelay(spec *core.PodSpec, cont *core.Container, delay int32) {
+	if delay <= 0 {
+		return
+	}
+
+	cont.Lifecycle = &core.Lifecycle{
+		PreStop: &core.LifecycleHandler{
+			Exec: &core.ExecAction{
+				Command: []string{"sleep", strconv.Itoa(int(delay))},
+			},
+		},
+	}
+
+	grace := int64(defaultTerminationGracePeriod)
+	if spec.TerminationGracePeriodSeconds != nil {
+		grace = *spec.TerminationGracePeriodSeconds
+	}
+	if needed := int64(delay) + defaultTerminationGracePeriod; grace < needed {
+		spec.TerminationGracePeriodSeconds = &needed
+	}
+}
+
+// addSidecarVolumes adds an emptyDir for each shared volume and mounts it in the pod's container
+// and in every sidecar of the template, whether injected by this provider or by another, such as
+// the envoy proxy or the tracing collector. The volumes are listed on the template for the auth
+// sidecar, which the pod mutator injects later. Nothing is shared if the pod has no sidecars.
+func addSidecarVolumes(template *core.PodTemplateSpec, volumes []crd.SidecarVolume) {
+	spec := &template.Spec
+	authSidecar := template.GetAnnotations()["clowder/authsidecar-enabled"] == "true"
+	if len(volumes) == 0 || len(spec.Containers) == 0 || (len(spec.Containers) == 1 && !authSidecar) {
+		return
+	}
+
+	names := make([]string, 0, len(volumes))
+	for _, vol := range volumes {
+		spec.Volumes = append(spec.Volumes, core.Volume{
+			Name: vol.Name,
+			VolumeSource: core.VolumeSource{
+				EmptyDir: &core.EmptyDirVolumeSource{},
+			},
+		})
+
+		mount := core.VolumeMount{
+			Name:      vol.Name,
+			MountPath: vol.MountPath,
+		}
+		for i := range spec.Containers {
+			spec.Containers[i].VolumeMounts = append(spec.Containers[i].VolumeMounts, mount)
+		}
+		names = append(names, vol.Name)
+	}
+
+	if authSidecar {
+		utils.UpdateAnnotations(template, map[string]string{SidecarVolumesAnnotation: strings.Join(names, ",")})
+	}
+}
+
+func getTokenRefresher(env *crd.ClowdEnvironment, appName string) *core.Container {
 	cont := core.Container{}
 
 	cont.Name = "token-refresher"
@@ -160,5 +237,7 @@ func getTokenRefresher(appName string) *core.Container {
 		},
 	}
 
+	provutils.ApplyImageSettings(env, &cont)
+
 	return &cont
 }
@@ -0,0 +1,61 @@
+package sidecar
+
+import (
+	"testing"
+
+	crd "github.com/RedHatInsights/clowder/apis/cloud.redhat.com/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	core "k8s.io/api/core/v1"
+)
+
+func TestAddSidecarVolumes(t *testing.T) {
+	volumes := []crd.SidecarVolume{{Name: "sockets", MountPath: "/var/run/sockets"}}
+	mount := core.VolumeMount{Name: "sockets", MountPath: "/var/run/sockets"}
+
+	template := &core.PodTemplateSpec{Spec: core.PodSpec{Containers: []core.Container{{Name: "app"}}}}
+	addSidecarVolumes(template, volumes)
+	assert.Empty(t, template.Spec.Volumes, "volumes should not be added without sidecars")
+	assert.Empty(t, template.Spec.Containers[0].VolumeMounts)
+
+	// Every sidecar gets the volumes, not only those injected by this provider
+	template.Spec.Containers = append(template.Spec.Containers, core.Container{Name: "otel-collector"}, core.Container{Name: "token-refresher"})
+	addSidecarVolumes(template, volumes)
+
+	assert.Equal(t, []core.Volume{{
+		Name: "sockets",
+		VolumeSource: core.VolumeSource{
+			EmptyDir: &core.EmptyDirVolumeSource{},
+		},
+	}}, template.Spec.Volumes)
+	for _, cont := range template.Spec.Containers {
+		assert.Equal(t, []core.VolumeMount{mount}, cont.VolumeMounts, cont.Name)
+	}
+	assert.NotContains(t, template.Annotations, SidecarVolumesAnnotation)
+
+	// The auth sidecar is injected by the pod mutator, which is told which volumes to mount
+	template = &core.PodTemplateSpec{Spec: core.PodSpec{Containers: []core.Container{{Name: "app"}}}}
+	template.Annotations = map[string]string{"clowder/authsidecar-enabled": "true"}
+	addSidecarVolumes(template, volumes)
+	assert.Equal(t, []core.VolumeMount{mount}, template.Spec.Containers[0].VolumeMounts)
+	assert.Equal(t, "sockets", template.Annotations[SidecarVolumesAnnotation])
+}
+
+func TestSetTerminationDelay(t *testing.T) {
+	spec := &core.PodSpec{}
+	cont := &core.Container{Name: "token-refresher"}
+
+	// Sidecars are not ordered by default
+	setTerminationDelay(spec, cont, 0)
+	assert.Nil(t, cont.Lifecycle)
+	assert.Nil(t, spec.TerminationGracePeriodSeconds)
+
+	setTerminationDelay(spec, cont, 10)
+	assert.Equal(t, []string{"sleep", "10"}, cont.Lifecycle.PreStop.Exec.Command)
+	assert.Equal(t, int64(40), *spec.TerminationGracePeriodSeconds)
+
+	// A longer grace period is left alone
+	grace := int64(120)
+	spec = &core.PodSpec{TerminationGracePeriodSeconds: &grace}
+	setTerminationDelay(spec, &core.Container{}, 10)
+	assert.Equal(t, int64(120), *spec.TerminationGracePeriodSeconds)
+}
@@ -0,0 +1,110 @@
+package tracing
+
+import (
+	"strconv"
+
+	crd "github.com/RedHatInsights/clowder/apis/cloud.redhat.com/v1alpha1"
+	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/config"
+	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers"
+	deployProvider "github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers/deployment"
+	provutils "github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers/utils"
+
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// localCollectorEndpoint is where apps reach the collector sidecar in their own pod.
+const localCollectorEndpoint = "http://localhost:4317"
+
+// collectorFlushSeconds is how long the collector keeps running after the app's container is sent
+// SIGTERM, so that the spans the app flushes as it shuts down are still received.
+const collectorFlushSeconds = 5
+
+type localTracingProvider struct {
+	providers.Provider
+}
+
+// NewLocalTracing returns a new local tracing provider object, which runs a collector
+// sidecar in the pods of each of the app's deployments. Jobs are left without one, as a sidecar
+// that never exits would keep their pods from completing.
+func NewLocalTracing(p *providers.Provider) (providers.ClowderProvider, error) {
+	return &localTracingProvider{Provider: *p}, nil
+}
+
+func (t *localTracingProvider) EnvProvide() error {
+	return nil
+}
+
+func (t *localTracingProvider) Provide(app *crd.ClowdApp) error {
+	for _, deployment := range app.Spec.Deployments {
+		innerDeployment := deployment
+		w, err := deployProvider.GetWorkload(t.Cache, app, &innerDeployment)
+		if err != nil {
+			return err
+		}
+
+		collector := getCollector(t.Env)
+		setCollectorDelay(&collector, deployProvider.DrainDelaySeconds(&innerDeployment))
+		w.Template.Spec.Containers = append(w.Template.Spec.Containers, collector)
+
+		if err := w.Update(t.Cache); err != nil {
+			return err
+		}
+	}
+
+	serviceName := app.Name
+	t.Config.Tracing = &config.TracingConfig{
+		Endpoint:    localCollectorEndpoint,
+		ServiceName: &serviceName,
+	}
+
+	return nil
+}
+
+// setCollectorDelay delays the termination of the collector with a preStop sleep, so that it
+// outlives the drain delay of the deployment's container. Nothing is set if the drain delay is
+// disabled, and the containers are then stopped together.
+func setCollectorDelay(cont *core.Container, drainDelay int32) {
+	if drainDelay <= 0 {
+		return
+	}
+
+	cont.Lifecycle = &core.Lifecycle{
+		PreStop: &core.LifecycleHandler{
+			Exec: &core.ExecAction{
+				Command: []string{"sleep", strconv.Itoa(int(drainDelay + collectorFlushSeconds))},
+			},
+		},
+	}
+}
+
+// getCollector returns the OpenTelemetry collector sidecar, which receives OTLP traces from
+// the app's container over localhost.
+func getCollector(env *crd.ClowdEnvironment) core.Container {
+	image := DefaultImageOTelCollector
+	if env.Spec.Providers.Tracing.Image != "" {
+		image = env.Spec.Providers.Tracing.Image
+	}
+
+	cont := core.Container{
+		Name:                     "otel-collector",
+		Image:                    image,
+		TerminationMessagePath:   "/dev/termination-log",
+		TerminationMessagePolicy: core.TerminationMessageReadFile,
+		ImagePullPolicy:          core.PullIfNotPresent,
+		Resources: core.ResourceRequirements{
+			Limits: core.ResourceList{
+				"cpu":    resource.MustParse("200m"),
+				"memory": resource.MustParse("256Mi"),
+			},
+			Requests: core.ResourceList{
+				"cpu":    resource.MustParse("50m"),
+				"memory": resource.MustParse("64Mi"),
+			},
+		},
+	}
+
+	provutils.ApplyImageSettings(env, &cont)
+
+	return cont
+}
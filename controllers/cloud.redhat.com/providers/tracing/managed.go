@@ -0,0 +1,39 @@
+package tracing
+
+import (
+	crd "github.com/RedHatInsights/clowder/apis/cloud.redhat.com/v1alpha1"
+	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/config"
+	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/errors"
+	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers"
+)
+
+type managedTracingProvider struct {
+	providers.Provider
+}
+
+// NewManagedTracing returns a new managed tracing provider object, pointing apps at the
+// environment's shared collector.
+func NewManagedTracing(p *providers.Provider) (providers.ClowderProvider, error) {
+	return &managedTracingProvider{Provider: *p}, nil
+}
+
+func (t *managedTracingProvider) EnvProvide() error {
+	if t.Env.Spec.Providers.Tracing.Endpoint == "" {
+		return errors.NewClowderError("tracing endpoint must be set in managed mode")
+	}
+	return nil
+}
+
+func (t *managedTracingProvider) Provide(app *crd.ClowdApp) error {
+	if t.Env.Spec.Providers.Tracing.Endpoint == "" {
+		return errors.NewClowderError("tracing endpoint must be set in managed mode")
+	}
+
+	serviceName := app.Name
+	t.Config.Tracing = &config.TracingConfig{
+		Endpoint:    t.Env.Spec.Providers.Tracing.Endpoint,
+		ServiceName: &serviceName,
+	}
+
+	return nil
+}
@@ -0,0 +1,23 @@
+package tracing
+
+import (
+	crd "github.com/RedHatInsights/clowder/apis/cloud.redhat.com/v1alpha1"
+	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers"
+)
+
+type noneTracingProvider struct {
+	providers.Provider
+}
+
+// NewNoneTracing returns a new none tracing provider object.
+func NewNoneTracing(p *providers.Provider) (providers.ClowderProvider, error) {
+	return &noneTracingProvider{Provider: *p}, nil
+}
+
+func (t *noneTracingProvider) EnvProvide() error {
+	return nil
+}
+
+func (t *noneTracingProvider) Provide(_ *crd.ClowdApp) error {
+	return nil
+}
@@ -0,0 +1,35 @@
+package tracing
+
+import (
+	"fmt"
+
+	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/errors"
+	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers"
+)
+
+var DefaultImageOTelCollector = "docker.io/otel/opentelemetry-collector:0.88.0"
+
+// ProvName is the name/ident of the provider
+var ProvName = "tracing"
+
+// GetTracing returns the correct tracing provider based on the environment.
+func GetTracing(c *providers.Provider) (providers.ClowderProvider, error) {
+	tracingMode := c.Env.Spec.Providers.Tracing.Mode
+	switch tracingMode {
+	case "local":
+		return NewLocalTracing(c)
+	case "managed":
+		return NewManagedTracing(c)
+	case "none", "":
+		return NewNoneTracing(c)
+	default:
+		errStr := fmt.Sprintf("No matching tracing mode for %s", tracingMode)
+		return nil, errors.NewClowderError(errStr)
+	}
+}
+
+func init() {
+	// The collector is added ahead of the sidecar provider, which mounts the shared sidecar
+	// volumes in it
+	providers.ProvidersRegistration.Register(GetTracing, 97, ProvName)
+}
@@ -0,0 +1,99 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	crd "github.com/RedHatInsights/clowder/apis/cloud.redhat.com/v1alpha1"
+	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/config"
+	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers"
+	cronjobProvider "github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers/cronjob"
+	deployProvider "github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers/deployment"
+	rc "github.com/RedHatInsights/rhc-osdk-utils/resourceCache"
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	apps "k8s.io/api/apps/v1"
+	batch "k8s.io/api/batch/v1"
+	core "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestGetCollector(t *testing.T) {
+	env := &crd.ClowdEnvironment{}
+	cont := getCollector(env)
+	assert.Equal(t, "otel-collector", cont.Name)
+	assert.Equal(t, DefaultImageOTelCollector, cont.Image)
+
+	env.Spec.Providers.Tracing.Image = "quay.io/example/otel-collector:latest"
+	cont = getCollector(env)
+	assert.Equal(t, "quay.io/example/otel-collector:latest", cont.Image)
+}
+
+func TestManagedTracingRequiresEndpoint(t *testing.T) {
+	env := &crd.ClowdEnvironment{}
+	env.Spec.Providers.Tracing.Mode = "managed"
+
+	prov, err := GetTracing(&providers.Provider{Env: env})
+	assert.NoError(t, err)
+	assert.ErrorContains(t, prov.EnvProvide(), "tracing endpoint must be set")
+
+	env.Spec.Providers.Tracing.Endpoint = "http://otel-collector.observability.svc:4317"
+	assert.NoError(t, prov.EnvProvide())
+}
+
+func TestLocalTracingProvide(t *testing.T) {
+	ctx := context.Background()
+	log := logr.Discard()
+	cache := rc.NewObjectCache(ctx, fake.NewClientBuilder().Build(), &log, rc.NewCacheConfig(nil, nil, nil))
+
+	delay := int32(10)
+	app := &crd.ClowdApp{
+		ObjectMeta: metav1.ObjectMeta{Name: "puptoo", Namespace: "test"},
+		Spec: crd.ClowdAppSpec{
+			Deployments: []crd.Deployment{{Name: "api", DrainDelaySeconds: &delay}},
+			Jobs:        []crd.Job{{Name: "cleanup", Schedule: "*/5 * * * *"}},
+		},
+	}
+
+	dnn := app.GetDeploymentNamespacedName(&app.Spec.Deployments[0])
+	d := &apps.Deployment{}
+	assert.NoError(t, cache.Create(deployProvider.CoreDeployment, dnn, d))
+	d.Name, d.Namespace = dnn.Name, dnn.Namespace
+	d.Spec.Template.Spec.Containers = []core.Container{{Name: dnn.Name}}
+	assert.NoError(t, cache.Update(deployProvider.CoreDeployment, d))
+
+	cjnn := app.GetCronJobNamespacedName(&app.Spec.Jobs[0])
+	cj := &batch.CronJob{}
+	assert.NoError(t, cache.Create(cronjobProvider.CoreCronJob, cjnn, cj))
+	cj.Name, cj.Namespace = cjnn.Name, cjnn.Namespace
+	cj.Spec.JobTemplate.Spec.Template.Spec.Containers = []core.Container{{Name: cjnn.Name}}
+	assert.NoError(t, cache.Update(cronjobProvider.CoreCronJob, cj))
+
+	env := &crd.ClowdEnvironment{}
+	env.Spec.Providers.Tracing.Mode = "local"
+	cfg := &config.AppConfig{}
+	prov, err := NewLocalTracing(&providers.Provider{Ctx: ctx, Cache: &cache, Env: env, Config: cfg, Log: log})
+	assert.NoError(t, err)
+	assert.NoError(t, prov.Provide(app))
+	assert.Equal(t, localCollectorEndpoint, cfg.Tracing.Endpoint)
+
+	// The collector outlives the drain delay of the app's container
+	d = &apps.Deployment{}
+	assert.NoError(t, cache.Get(deployProvider.CoreDeployment, d, dnn))
+	containers := d.Spec.Template.Spec.Containers
+	assert.Len(t, containers, 2)
+	assert.Equal(t, "otel-collector", containers[1].Name)
+	assert.Equal(t, []string{"sleep", "15"}, containers[1].Lifecycle.PreStop.Exec.Command)
+
+	// Jobs are left alone, as the collector would keep them from completing
+	cj = &batch.CronJob{}
+	assert.NoError(t, cache.Get(cronjobProvider.CoreCronJob, cj, cjnn))
+	assert.Len(t, cj.Spec.JobTemplate.Spec.Template.Spec.Containers, 1)
+}
+
+func TestSetCollectorDelay(t *testing.T) {
+	cont := &core.Container{}
+	setCollectorDelay(cont, 0)
+	assert.Nil(t, cont.Lifecycle)
+}
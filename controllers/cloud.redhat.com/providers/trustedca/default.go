@@ -0,0 +1,106 @@
+package trustedca
+
+import (
+	"context"
+	"fmt"
+
+	crd "github.com/RedHatInsights/clowder/apis/cloud.redhat.com/v1alpha1"
+	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/errors"
+	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers"
+	provutils "github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers/utils"
+
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	rc "github.com/RedHatInsights/rhc-osdk-utils/resourceCache"
+	"github.com/RedHatInsights/rhc-osdk-utils/utils"
+)
+
+// CoreTrustedCABundle is the copy of the environment's trusted CA bundle made for the app.
+var CoreTrustedCABundle = rc.NewSingleResourceIdent(ProvName, "core_trusted_ca_bundle", &core.ConfigMap{})
+
+// defaultBundleKey is the key the bundle is read from when the environment does not set one.
+const defaultBundleKey = "ca-bundle.crt"
+
+type trustedCAProvider struct {
+	providers.Provider
+}
+
+// NewTrustedCAProvider returns a new provider copying the environment's trusted CA bundle into
+// each app's namespace, where the app's containers mount it.
+func NewTrustedCAProvider(p *providers.Provider) (providers.ClowderProvider, error) {
+	p.Cache.AddPossibleGVKFromIdent(
+		CoreTrustedCABundle,
+	)
+	return &trustedCAProvider{Provider: *p}, nil
+}
+
+func (tc *trustedCAProvider) EnvProvide() error {
+	return nil
+}
+
+func (tc *trustedCAProvider) Provide(app *crd.ClowdApp) error {
+	bundle := tc.Env.Spec.Providers.Deployment.TrustedCABundle
+	if bundle == nil {
+		return nil
+	}
+
+	data, err := ReadBundle(tc.Ctx, tc.Client, bundle)
+	if err != nil {
+		return err
+	}
+
+	nn := types.NamespacedName{
+		Name:      provutils.TrustedCAConfigMapName(app.Name),
+		Namespace: app.Namespace,
+	}
+
+	cm := &core.ConfigMap{}
+	if err := tc.Cache.Create(CoreTrustedCABundle, nn, cm); err != nil {
+		return err
+	}
+
+	app.SetObjectMeta(cm, crd.Name(nn.Name))
+	cm.Data = map[string]string{provutils.TrustedCAFile: data}
+
+	if err := tc.Cache.Update(CoreTrustedCABundle, cm); err != nil {
+		return err
+	}
+
+	tc.Config.TrustedCAPath = utils.StringPtr(fmt.Sprintf("%s/%s", provutils.TrustedCAMountPath, provutils.TrustedCAFile))
+	return nil
+}
+
+// ReadBundle returns the PEM encoded bundle held under the key of the ConfigMap or Secret the
+// environment refers to.
+func ReadBundle(ctx context.Context, c client.Client, bundle *crd.TrustedCABundleConfig) (string, error) {
+	key := bundle.Key
+	if key == "" {
+		key = defaultBundleKey
+	}
+	nn := types.NamespacedName{Name: bundle.Name, Namespace: bundle.Namespace}
+
+	var data string
+	var found bool
+	if bundle.Kind == "Secret" {
+		secret := &core.Secret{}
+		if err := c.Get(ctx, nn, secret); err != nil {
+			return "", errors.Wrap(fmt.Sprintf("could not get trusted CA bundle secret %s", nn), err)
+		}
+		var raw []byte
+		raw, found = secret.Data[key]
+		data = string(raw)
+	} else {
+		cm := &core.ConfigMap{}
+		if err := c.Get(ctx, nn, cm); err != nil {
+			return "", errors.Wrap(fmt.Sprintf("could not get trusted CA bundle configmap %s", nn), err)
+		}
+		data, found = cm.Data[key]
+	}
+
+	if !found || data == "" {
+		return "", errors.NewClowderError(fmt.Sprintf("trusted CA bundle %s has no key %s", nn, key))
+	}
+	return data, nil
+}
@@ -0,0 +1,82 @@
+package trustedca
+
+import (
+	"context"
+	"testing"
+
+	crd "github.com/RedHatInsights/clowder/apis/cloud.redhat.com/v1alpha1"
+	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/config"
+	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers"
+	provutils "github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers/utils"
+	rc "github.com/RedHatInsights/rhc-osdk-utils/resourceCache"
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	core "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const testBundle = "-----BEGIN CERTIFICATE-----\nMIIB\n-----END CERTIFICATE-----\n"
+
+func TestProvideCopiesBundle(t *testing.T) {
+	ctx := context.Background()
+	log := logr.Discard()
+	c := fake.NewClientBuilder().WithObjects(&core.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "corp-ca", Namespace: "certs"},
+		Data:       map[string][]byte{"root.pem": []byte(testBundle)},
+	}).Build()
+	cache := rc.NewObjectCache(ctx, c, &log, rc.NewCacheConfig(nil, nil, nil))
+
+	env := &crd.ClowdEnvironment{}
+	app := &crd.ClowdApp{ObjectMeta: metav1.ObjectMeta{Name: "puptoo", Namespace: "test"}}
+	appConfig := &config.AppConfig{}
+	prov := &providers.Provider{Ctx: ctx, Client: c, Cache: &cache, Env: env, Config: appConfig}
+
+	// Without a bundle nothing is copied or configured
+	tc, err := NewTrustedCAProvider(prov)
+	assert.NoError(t, err)
+	assert.NoError(t, tc.Provide(app))
+	assert.Nil(t, appConfig.TrustedCAPath)
+
+	env.Spec.Providers.Deployment.TrustedCABundle = &crd.TrustedCABundleConfig{
+		Kind:      "Secret",
+		Name:      "corp-ca",
+		Namespace: "certs",
+		Key:       "root.pem",
+	}
+	assert.NoError(t, tc.Provide(app))
+	assert.Equal(t, "/cdapp/trusted-ca/ca-bundle.crt", *appConfig.TrustedCAPath)
+
+	cm := &core.ConfigMap{}
+	assert.NoError(t, cache.Get(CoreTrustedCABundle, cm))
+	assert.Equal(t, "puptoo-trusted-ca", cm.Name)
+	assert.Equal(t, "test", cm.Namespace)
+	assert.Equal(t, map[string]string{"ca-bundle.crt": testBundle}, cm.Data)
+}
+
+func TestReadBundleMissingKey(t *testing.T) {
+	c := fake.NewClientBuilder().WithObjects(&core.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "corp-ca", Namespace: "certs"},
+		Data:       map[string]string{"other.crt": testBundle},
+	}).Build()
+
+	_, err := ReadBundle(context.Background(), c, &crd.TrustedCABundleConfig{Name: "corp-ca", Namespace: "certs"})
+	assert.ErrorContains(t, err, "trusted CA bundle certs/corp-ca has no key ca-bundle.crt")
+}
+
+func TestAddTrustedCAVolume(t *testing.T) {
+	env := &crd.ClowdEnvironment{}
+	spec := &core.PodSpec{
+		Containers:     []core.Container{{Name: "puptoo-processor"}},
+		InitContainers: []core.Container{{Name: "puptoo-processor-init"}},
+	}
+
+	provutils.AddTrustedCAVolume(env, "puptoo", spec)
+	assert.Empty(t, spec.Volumes)
+
+	env.Spec.Providers.Deployment.TrustedCABundle = &crd.TrustedCABundleConfig{Name: "corp-ca", Namespace: "certs"}
+	provutils.AddTrustedCAVolume(env, "puptoo", spec)
+	assert.Equal(t, "puptoo-trusted-ca", spec.Volumes[0].ConfigMap.Name)
+	assert.Equal(t, "/cdapp/trusted-ca", spec.Containers[0].VolumeMounts[0].MountPath)
+	assert.Equal(t, "/cdapp/trusted-ca", spec.InitContainers[0].VolumeMounts[0].MountPath)
+}
@@ -0,0 +1,17 @@
+package trustedca
+
+import (
+	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers"
+)
+
+// ProvName sets the provider name identifier
+var ProvName = "trustedca"
+
+// GetTrustedCA returns the correct trusted CA provider.
+func GetTrustedCA(c *providers.Provider) (providers.ClowderProvider, error) {
+	return NewTrustedCAProvider(c)
+}
+
+func init() {
+	providers.ProvidersRegistration.Register(GetTrustedCA, 5, ProvName)
+}
@@ -1,12 +1,17 @@
 package providers
 
 import (
+	"context"
+	"crypto/rand"
 	"fmt"
+	"math/big"
 	"os"
+	"strings"
 
 	crd "github.com/RedHatInsights/clowder/apis/cloud.redhat.com/v1alpha1"
 	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/clowderconfig"
 	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/config"
+	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/errors"
 	obj "github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/object"
 	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers"
 	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers/sizing"
@@ -18,6 +23,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/RedHatInsights/rhc-osdk-utils/utils"
 )
@@ -26,10 +32,107 @@ var DefaultImageCaddySideCar = "quay.io/cloudservices/crc-caddy-plugin:1c4882e"
 var DefaultImageMBOP = "quay.io/cloudservices/mbop:bb071db"
 var DefaultImageMocktitlements = "quay.io/cloudservices/mocktitlements:e24820c"
 var DefaultKeyCloakVersion = "15.0.2"
+
 var DefaultImageKeyCloak = fmt.Sprintf("quay.io/keycloak/keycloak:%s", DefaultKeyCloakVersion)
 
-// MakeLocalDB populates the given deployment object with the local DB struct.
-func MakeLocalDB(dd *apps.Deployment, nn types.NamespacedName, baseResource obj.ClowdObject, extraLabels *map[string]string, cfg *config.DatabaseConfig, image string, usePVC bool, dbName string, res *core.ResourceRequirements) {
+// DefaultProgressDeadlineSeconds is how long a rollout of a deployment Clowder creates may take
+// before it is reported as failed.
+const DefaultProgressDeadlineSeconds int32 = 600
+
+// localDBDataDir is where the local DB's volume is mounted.
+const localDBDataDir = "/var/lib/pgsql/data"
+
+// RHELDBEnvVarNames are the credential variables read by the RHEL postgres image.
+var RHELDBEnvVarNames = crd.DatabaseEnvVarNames{
+	Preset:        "rhel",
+	User:          "POSTGRESQL_USER",
+	Password:      "POSTGRESQL_PASSWORD",
+	Database:      "POSTGRESQL_DATABASE",
+	AdminUser:     "POSTGRESQL_MASTER_USER",
+	AdminPassword: "POSTGRESQL_MASTER_PASSWORD",
+}
+
+// UpstreamDBEnvVarNames are the credential variables read by the upstream postgres image, which
+// creates the user as its superuser and has no separate admin user.
+var UpstreamDBEnvVarNames = crd.DatabaseEnvVarNames{
+	Preset:   "upstream",
+	User:     "POSTGRES_USER",
+	Password: "POSTGRES_PASSWORD",
+	Database: "POSTGRES_DB",
+}
+
+// GetDBEnvVarNames returns the credential variables of the environment's local DB image, being
+// its preset with any names the environment sets replacing those of the preset.
+func GetDBEnvVarNames(env *crd.ClowdEnvironment) crd.DatabaseEnvVarNames {
+	custom := env.Spec.Providers.Database.EnvVarNames
+
+	names := RHELDBEnvVarNames
+	if custom.Preset == "upstream" {
+		names = UpstreamDBEnvVarNames
+	}
+
+	for _, override := range []struct {
+		name  *string
+		value string
+	}{
+		{&names.User, custom.User},
+		{&names.Password, custom.Password},
+		{&names.Database, custom.Database},
+		{&names.AdminUser, custom.AdminUser},
+		{&names.AdminPassword, custom.AdminPassword},
+	} {
+		if override.value != "" {
+			*override.name = override.value
+		}
+	}
+
+	return names
+}
+
+// MakeLocalDBProbeHandler returns a probe handler running the given query as the DB user.
+func MakeLocalDBProbeHandler(names crd.DatabaseEnvVarNames, query string) core.ProbeHandler {
+	return core.ProbeHandler{
+		Exec: &core.ExecAction{
+			Command: []string{
+				"psql",
+				"-U",
+				fmt.Sprintf("$(%s)", names.User),
+				"-d",
+				fmt.Sprintf("$(%s)", names.Database),
+				"-c",
+				query,
+			},
+		},
+	}
+}
+
+// makeLocalDBEnvVars hands the DB credentials to the DB container under the given names. Admin
+// credentials are left out if the image has no names for them.
+func makeLocalDBEnvVars(names crd.DatabaseEnvVarNames, cfg *config.DatabaseConfig, dbName string) []core.EnvVar {
+	envVars := []core.EnvVar{
+		{Name: names.User, Value: cfg.Username},
+		{Name: names.Password, Value: cfg.Password},
+	}
+	if names.Preset == "rhel" {
+		envVars = append(envVars, core.EnvVar{Name: "PGPASSWORD", Value: cfg.AdminPassword}) // Legacy for old db images can likely be removed soon
+	}
+	if names.AdminUser != "" {
+		envVars = append(envVars, core.EnvVar{Name: names.AdminUser, Value: cfg.AdminUsername})
+	}
+	if names.AdminPassword != "" {
+		envVars = append(envVars, core.EnvVar{Name: names.AdminPassword, Value: cfg.AdminPassword})
+	}
+	envVars = append(envVars, core.EnvVar{Name: names.Database, Value: dbName})
+	if names.Preset == "upstream" {
+		// The upstream image keeps its data elsewhere, and needs a subdirectory of the volume
+		envVars = append(envVars, core.EnvVar{Name: "PGDATA", Value: localDBDataDir + "/pgdata"})
+	}
+	return envVars
+}
+
+// MakeLocalDB populates the given deployment object with the local DB struct. The credentials are
+// handed to the DB container under the given variable names.
+func MakeLocalDB(dd *apps.Deployment, nn types.NamespacedName, baseResource obj.ClowdObject, env *crd.ClowdEnvironment, extraLabels *map[string]string, cfg *config.DatabaseConfig, image string, usePVC bool, dbName string, res *core.ResourceRequirements, names crd.DatabaseEnvVarNames) {
 	labels := baseResource.GetLabels()
 	labels["service"] = "db"
 
@@ -67,6 +170,7 @@ func MakeLocalDB(dd *apps.Deployment, nn types.NamespacedName, baseResource obj.
 	}
 
 	dd.Spec.Replicas = utils.Int32Ptr(1)
+	dd.Spec.ProgressDeadlineSeconds = utils.Int32Ptr(int(DefaultProgressDeadlineSeconds))
 	dd.Spec.Selector = &metav1.LabelSelector{MatchLabels: labels}
 	dd.Spec.Template.Spec.Volumes = []core.Volume{
 		{
@@ -77,33 +181,14 @@ func MakeLocalDB(dd *apps.Deployment, nn types.NamespacedName, baseResource obj.
 
 	dd.Spec.Template.ObjectMeta.Labels = labels
 
-	envVars := []core.EnvVar{
-		{Name: "POSTGRESQL_USER", Value: cfg.Username},
-		{Name: "POSTGRESQL_PASSWORD", Value: cfg.Password},
-		{Name: "PGPASSWORD", Value: cfg.AdminPassword}, // Legacy for old db images can likely be removed soon
-		{Name: "POSTGRESQL_MASTER_USER", Value: cfg.AdminUsername},
-		{Name: "POSTGRESQL_MASTER_PASSWORD", Value: cfg.AdminPassword},
-		{Name: "POSTGRESQL_DATABASE", Value: dbName},
-	}
+	envVars := makeLocalDBEnvVars(names, cfg, dbName)
 	ports := []core.ContainerPort{{
 		Name:          "database",
 		ContainerPort: 5432,
 		Protocol:      core.ProtocolTCP,
 	}}
 
-	probeHandler := core.ProbeHandler{
-		Exec: &core.ExecAction{
-			Command: []string{
-				"psql",
-				"-U",
-				"$(POSTGRESQL_USER)",
-				"-d",
-				"$(POSTGRESQL_DATABASE)",
-				"-c",
-				"SELECT 1",
-			},
-		},
-	}
+	probeHandler := MakeLocalDBProbeHandler(names, "SELECT 1")
 
 	livenessProbe := core.Probe{
 		ProbeHandler:        probeHandler,
@@ -138,18 +223,21 @@ func MakeLocalDB(dd *apps.Deployment, nn types.NamespacedName, baseResource obj.
 		Resources:      requestResource,
 		VolumeMounts: []core.VolumeMount{{
 			Name:      nn.Name,
-			MountPath: "/var/lib/pgsql/data",
+			MountPath: localDBDataDir,
 		}},
 		TerminationMessagePath:   "/dev/termination-log",
 		TerminationMessagePolicy: core.TerminationMessageReadFile,
 		ImagePullPolicy:          core.PullIfNotPresent,
 	}
 
+	ApplyImageSettings(env, &c)
+
 	dd.Spec.Template.Spec.Containers = []core.Container{c}
 }
 
-// MakeLocalDBService populates the given service object with the local DB struct.
-func MakeLocalDBService(s *core.Service, nn types.NamespacedName, baseResource obj.ClowdObject, extraLabels *map[string]string) {
+// MakeLocalDBService populates the given service object with the local DB struct. Any annotations
+// passed in are applied to the service, e.g. cloud-provider load balancer hints.
+func MakeLocalDBService(s *core.Service, nn types.NamespacedName, baseResource obj.ClowdObject, extraLabels *map[string]string, annotations map[string]string) {
 	servicePorts := []core.ServicePort{{
 		Name:       "database",
 		Port:       5432,
@@ -161,11 +249,92 @@ func MakeLocalDBService(s *core.Service, nn types.NamespacedName, baseResource o
 		labels[k] = v
 	}
 	utils.MakeService(s, nn, labels, servicePorts, baseResource, false)
+	utils.UpdateAnnotations(s, annotations)
 }
 
-// MakeLocalDBPVC populates the given PVC object with the local DB struct.
+// defaultDBSessionAffinityTimeout is the timeout the API server gives a ClientIP affinity
+// without one.
+const defaultDBSessionAffinityTimeout = int32(10800)
+
+// SetDBSessionAffinity sets the session affinity of a database service, which is None unless the
+// environment asks for ClientIP. The defaults the API server would fill in are set explicitly, so
+// that the service is not rewritten on every reconcile.
+func SetDBSessionAffinity(s *core.Service, affinity *crd.DatabaseSessionAffinity) {
+	s.Spec.SessionAffinity = core.ServiceAffinityNone
+	s.Spec.SessionAffinityConfig = nil
+
+	if affinity == nil || affinity.Type != core.ServiceAffinityClientIP {
+		return
+	}
+
+	timeout := defaultDBSessionAffinityTimeout
+	if affinity.TimeoutSeconds != nil {
+		timeout = *affinity.TimeoutSeconds
+	}
+	s.Spec.SessionAffinity = core.ServiceAffinityClientIP
+	s.Spec.SessionAffinityConfig = &core.SessionAffinityConfig{
+		ClientIP: &core.ClientIPConfig{TimeoutSeconds: &timeout},
+	}
+}
+
+// MakeLocalDBPVC populates the given PVC object with the local DB struct. The databases mount
+// their volume, so the claim always asks for a Filesystem volume.
 func MakeLocalDBPVC(pvc *core.PersistentVolumeClaim, nn types.NamespacedName, baseResource obj.ClowdObject, capacity string) {
 	utils.MakePVC(pvc, nn, providers.Labels{"service": "db", "app": baseResource.GetClowdName()}, capacity, baseResource)
+	volumeMode := core.PersistentVolumeFilesystem
+	pvc.Spec.VolumeMode = &volumeMode
+}
+
+// MirrorImage returns the given image with its registry replaced by the environment's registry
+// mirror. Images without a registry, which would be pulled from Docker Hub, are prefixed with
+// the mirror. If no mirror is set, or the image is already pulled from the mirror, the image is
+// returned unchanged.
+func MirrorImage(env *crd.ClowdEnvironment, image string) string {
+	mirror := strings.TrimSuffix(env.Spec.Providers.Deployment.RegistryMirror, "/")
+	if mirror == "" || image == "" || strings.HasPrefix(image, mirror+"/") {
+		return image
+	}
+
+	parts := strings.SplitN(image, "/", 2)
+	if len(parts) == 2 && (strings.ContainsAny(parts[0], ".:") || parts[0] == "localhost") {
+		return mirror + "/" + parts[1]
+	}
+	return mirror + "/" + image
+}
+
+// ApplyImageSettings applies the environment's registry mirror and, if set, its image pull
+// policy to the given container. It should be called once the container's image and default
+// pull policy have been set.
+func ApplyImageSettings(env *crd.ClowdEnvironment, c *core.Container) {
+	c.Image = MirrorImage(env, c.Image)
+	if env.Spec.Providers.Deployment.ImagePullPolicy != "" {
+		c.ImagePullPolicy = env.Spec.Providers.Deployment.ImagePullPolicy
+	}
+}
+
+// ConfigSecretMode returns the file mode to mount the app config secret with, as set in the
+// environment, defaulting to 0644.
+func ConfigSecretMode(env *crd.ClowdEnvironment) *int32 {
+	if env.Spec.Providers.Deployment.ConfigSecretMode != nil {
+		mode := *env.Spec.Providers.Deployment.ConfigSecretMode
+		return &mode
+	}
+	return utils.Int32Ptr(0644)
+}
+
+// ConfigFormat returns the format the app config is presented in, as set in the environment,
+// defaulting to JSON.
+func ConfigFormat(env *crd.ClowdEnvironment) string {
+	if env.Spec.Providers.Deployment.ConfigFormat != "" {
+		return env.Spec.Providers.Deployment.ConfigFormat
+	}
+	return config.FormatJSON
+}
+
+// ConfigFilePath returns the path the app config file is mounted at in app containers, which the
+// ACG_CONFIG variable points at.
+func ConfigFilePath(env *crd.ClowdEnvironment) string {
+	return "/cdapp/" + config.FileName(ConfigFormat(env))
 }
 
 // GetCaddyImage returns the caddy image to use in a given environment
@@ -242,7 +411,70 @@ var KubeLinterAnnotations = map[string]string{
 	"ignore-check.kube-linter.io/no-readiness-probe": "probes not required on Job pods",
 }
 
-const RCharSet = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789"
+const RCharSet = crd.DefaultCredentialCharset
+
+// GeneratePassword returns a random password following the environment's
+// credential policy, with at least one character of each required class.
+func GeneratePassword(env *crd.ClowdEnvironment) (string, error) {
+	policy := env.Spec.CredentialPolicy
+	if err := policy.Validate(); err != nil {
+		return "", errors.Wrap("invalid credential policy", err)
+	}
+
+	charset := policy.GetCharset()
+	password, err := utils.RandPassword(policy.GetLength(), charset)
+	if err != nil {
+		return "", err
+	}
+	if policy == nil || len(policy.RequiredClasses) == 0 {
+		return password, nil
+	}
+
+	// Overwrite a distinct random position for each required class, so that
+	// the password stays uniformly random apart from the enforced classes.
+	b := []byte(password)
+	positions, err := randPerm(len(b))
+	if err != nil {
+		return "", err
+	}
+	for i, class := range policy.RequiredClasses {
+		classChars := ""
+		for _, r := range charset {
+			if class.Matches(r) {
+				classChars += string(r)
+			}
+		}
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(classChars))))
+		if err != nil {
+			return "", err
+		}
+		b[positions[i]] = classChars[n.Int64()]
+	}
+
+	return string(b), nil
+}
+
+// GenerateUsername returns a random alphanumeric username of the length set
+// by the environment's credential policy.
+func GenerateUsername(env *crd.ClowdEnvironment) string {
+	return utils.RandString(env.Spec.CredentialPolicy.GetLength())
+}
+
+// randPerm returns a cryptographically random permutation of [0, n).
+func randPerm(n int) ([]int, error) {
+	perm := make([]int, n)
+	for i := range perm {
+		perm[i] = i
+	}
+	for i := n - 1; i > 0; i-- {
+		j, err := rand.Int(rand.Reader, big.NewInt(int64(i+1)))
+		if err != nil {
+			return nil, err
+		}
+		perm[i], perm[j.Int64()] = perm[j.Int64()], perm[i]
+	}
+	return perm, nil
+}
 
 func AddCertVolume(d *v1.PodSpec, dnn string) {
 	d.Volumes = append(d.Volumes, v1.Volume{
@@ -277,3 +509,97 @@ func AddCertVolume(d *v1.PodSpec, dnn string) {
 		d.InitContainers[i].VolumeMounts = vms
 	}
 }
+
+// TrustedCAMountPath is where the environment's trusted CA bundle is mounted in app containers,
+// under the file name TrustedCAFile.
+const TrustedCAMountPath = "/cdapp/trusted-ca"
+
+// TrustedCAFile is the file name of the trusted CA bundle in TrustedCAMountPath.
+const TrustedCAFile = "ca-bundle.crt"
+
+// TrustedCAConfigMapName returns the name of the copy of the environment's trusted CA bundle
+// made in the app's namespace.
+func TrustedCAConfigMapName(appName string) string {
+	return fmt.Sprintf("%s-trusted-ca", appName)
+}
+
+// AddTrustedCAVolume mounts the environment's trusted CA bundle, as copied for the named app, into
+// the containers and init containers of the pod. Nothing is mounted if the environment has no
+// bundle, so it should be called before any sidecars are injected.
+func AddTrustedCAVolume(env *crd.ClowdEnvironment, appName string, d *v1.PodSpec) {
+	if env.Spec.Providers.Deployment.TrustedCABundle == nil {
+		return
+	}
+
+	d.Volumes = append(d.Volumes, v1.Volume{
+		Name: "trusted-ca",
+		VolumeSource: v1.VolumeSource{
+			ConfigMap: &v1.ConfigMapVolumeSource{
+				LocalObjectReference: v1.LocalObjectReference{
+					Name: TrustedCAConfigMapName(appName),
+				},
+				DefaultMode: utils.Int32Ptr(420),
+			},
+		},
+	})
+
+	mount := v1.VolumeMount{
+		Name:      "trusted-ca",
+		ReadOnly:  true,
+		MountPath: TrustedCAMountPath,
+	}
+	for i := range d.Containers {
+		d.Containers[i].VolumeMounts = append(d.Containers[i].VolumeMounts, mount)
+	}
+	for i := range d.InitContainers {
+		d.InitContainers[i].VolumeMounts = append(d.InitContainers[i].VolumeMounts, mount)
+	}
+}
+
+// EnvPullSecretName returns the name of the copy Clowder makes of one of the environment's pull
+// secrets in each namespace it manages.
+func EnvPullSecretName(env *crd.ClowdEnvironment, name string) string {
+	return fmt.Sprintf("%s-%s-clowder-copy", env.Name, name)
+}
+
+// SetJobPullSecrets hands the environment's pull secrets to the pods of a job that runs as its own
+// ServiceAccount, as Clowder only adds them to the ServiceAccounts it manages.
+func SetJobPullSecrets(env *crd.ClowdEnvironment, job *crd.Job, spec *core.PodSpec) {
+	if job.ServiceAccountName == "" {
+		return
+	}
+
+	seen := map[string]bool{}
+	for _, secret := range spec.ImagePullSecrets {
+		seen[secret.Name] = true
+	}
+	for _, pullSecret := range env.Spec.Providers.PullSecrets {
+		name := EnvPullSecretName(env, pullSecret.Name)
+		if !seen[name] {
+			seen[name] = true
+			spec.ImagePullSecrets = append(spec.ImagePullSecrets, core.LocalObjectReference{Name: name})
+		}
+	}
+}
+
+// CheckJobServiceAccount ensures the ServiceAccount a job was told to run as exists, so that a
+// missing one is reported instead of the job's pods silently failing to be created. Jobs using the
+// app's own ServiceAccount are not checked, as Clowder creates it.
+func CheckJobServiceAccount(ctx context.Context, pClient client.Client, app *crd.ClowdApp, job *crd.Job) error {
+	if job.ServiceAccountName == "" {
+		return nil
+	}
+
+	nn := types.NamespacedName{
+		Name:      job.ServiceAccountName,
+		Namespace: app.Namespace,
+	}
+
+	if err := pClient.Get(ctx, nn, &core.ServiceAccount{}); err != nil {
+		cerr := errors.Wrap(fmt.Sprintf("service account [%s] for job [%s] could not be found", nn.Name, job.Name), err)
+		cerr.Requeue = true
+		return cerr
+	}
+
+	return nil
+}
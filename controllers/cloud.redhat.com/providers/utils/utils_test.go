@@ -0,0 +1,84 @@
+package providers
+
+import (
+	"strings"
+	"testing"
+
+	crd "github.com/RedHatInsights/clowder/apis/cloud.redhat.com/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	core "k8s.io/api/core/v1"
+)
+
+func TestMirrorImage(t *testing.T) {
+	env := &crd.ClowdEnvironment{}
+	assert.Equal(t, "quay.io/cloudservices/app:abc", MirrorImage(env, "quay.io/cloudservices/app:abc"))
+
+	env.Spec.Providers.Deployment.RegistryMirror = "mirror.example.com/quay"
+
+	tests := map[string]string{
+		"quay.io/cloudservices/app:abc": "mirror.example.com/quay/cloudservices/app:abc",
+		"localhost/app:abc":             "mirror.example.com/quay/app:abc",
+		"registry:5000/app:abc":         "mirror.example.com/quay/app:abc",
+		"redis:6":                       "mirror.example.com/quay/redis:6",
+		"library/redis:6":               "mirror.example.com/quay/library/redis:6",
+		"":                              "",
+	}
+	for image, expected := range tests {
+		assert.Equal(t, expected, MirrorImage(env, image), image)
+		// Mirroring an image that is already mirrored leaves it as it is
+		assert.Equal(t, expected, MirrorImage(env, MirrorImage(env, image)), image)
+	}
+}
+
+func TestGeneratePassword(t *testing.T) {
+	env := &crd.ClowdEnvironment{}
+	password, err := GeneratePassword(env)
+	assert.NoError(t, err)
+	assert.Len(t, password, 16)
+	assert.Len(t, GenerateUsername(env), 16)
+
+	env.Spec.CredentialPolicy = &crd.CredentialPolicy{
+		Length:  24,
+		Charset: crd.DefaultCredentialCharset + "!@#",
+		RequiredClasses: []crd.CredentialClass{
+			crd.CredentialClassUpper,
+			crd.CredentialClassLower,
+			crd.CredentialClassDigit,
+			crd.CredentialClassSymbol,
+		},
+	}
+	for i := 0; i < 50; i++ {
+		password, err := GeneratePassword(env)
+		assert.NoError(t, err)
+		assert.Len(t, password, 24)
+		for _, class := range env.Spec.CredentialPolicy.RequiredClasses {
+			assert.True(t, strings.IndexFunc(password, class.Matches) >= 0, "%s missing %s", password, class)
+		}
+	}
+	assert.Len(t, GenerateUsername(env), 24)
+
+	env.Spec.CredentialPolicy.Charset = "abcdefghij"
+	_, err = GeneratePassword(env)
+	assert.ErrorContains(t, err, `required class "upper" has no characters in the charset`)
+
+	env.Spec.CredentialPolicy.Charset = crd.DefaultCredentialCharset + "$"
+	_, err = GeneratePassword(env)
+	assert.ErrorContains(t, err, `charset may not contain '$'`)
+}
+
+func TestSetJobPullSecrets(t *testing.T) {
+	env := &crd.ClowdEnvironment{}
+	env.Name = "env"
+	env.Spec.Providers.PullSecrets = []crd.NamespacedName{{Name: "quay", Namespace: "secrets"}}
+
+	// Jobs running as the app's ServiceAccount get the pull secrets from it
+	spec := &core.PodSpec{}
+	SetJobPullSecrets(env, &crd.Job{Name: "cleanup"}, spec)
+	assert.Empty(t, spec.ImagePullSecrets)
+
+	job := &crd.Job{Name: "cleanup", ServiceAccountName: "custom"}
+	spec.ImagePullSecrets = []core.LocalObjectReference{{Name: "own"}}
+	SetJobPullSecrets(env, job, spec)
+	SetJobPullSecrets(env, job, spec)
+	assert.Equal(t, []core.LocalObjectReference{{Name: "own"}, {Name: "env-quay-clowder-copy"}}, spec.ImagePullSecrets)
+}
@@ -0,0 +1,142 @@
+package waitfordeps
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	crd "github.com/RedHatInsights/clowder/apis/cloud.redhat.com/v1alpha1"
+	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/clowderconfig"
+	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/config"
+	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers"
+	deployProvider "github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers/deployment"
+	provutils "github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers/utils"
+
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// InitContainerName is the name given to the init container that waits on dependencies
+const InitContainerName = "wait-for-dependencies"
+
+type waitForDepsProvider struct {
+	providers.Provider
+}
+
+// NewWaitForDepsProvider returns a new wait-for-dependencies provider.
+func NewWaitForDepsProvider(p *providers.Provider) (providers.ClowderProvider, error) {
+	return &waitForDepsProvider{Provider: *p}, nil
+}
+
+func (wd *waitForDepsProvider) EnvProvide() error {
+	return nil
+}
+
+func (wd *waitForDepsProvider) Provide(app *crd.ClowdApp) error {
+	if !app.Spec.WaitForDependencies {
+		return nil
+	}
+
+	targets := getTargets(app, wd.Config)
+	if len(targets) == 0 {
+		return nil
+	}
+
+	cont := makeInitContainer(targets)
+	provutils.ApplyImageSettings(wd.Env, &cont)
+
+	for _, deployment := range app.Spec.Deployments {
+		innerDeployment := deployment
+		w, err := deployProvider.GetWorkload(wd.Cache, app, &innerDeployment)
+		if err != nil {
+			return err
+		}
+
+		// The wait must happen before any of the app's own init containers run
+		w.Template.Spec.InitContainers = append([]core.Container{cont}, w.Template.Spec.InitContainers...)
+
+		if err := w.Update(wd.Cache); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// getTargets returns the sorted list of host:port pairs that must be reachable, derived from the
+// endpoints of the app's hard dependencies and its database configurations.
+func getTargets(app *crd.ClowdApp, c *config.AppConfig) []string {
+	deps := map[string]bool{}
+	for _, dep := range app.Spec.Dependencies {
+		deps[dep] = true
+	}
+
+	found := map[string]bool{}
+	for _, endpoint := range c.Endpoints {
+		if !deps[endpoint.App] || endpoint.Port == 0 {
+			continue
+		}
+		found[fmt.Sprintf("%s:%d", endpoint.Hostname, endpoint.Port)] = true
+	}
+
+	if c.Database != nil && c.Database.Hostname != "" {
+		found[fmt.Sprintf("%s:%d", c.Database.Hostname, c.Database.Port)] = true
+	}
+	for _, db := range c.Databases {
+		if db.Hostname != "" {
+			found[fmt.Sprintf("%s:%d", db.Hostname, db.Port)] = true
+		}
+	}
+
+	targets := []string{}
+	for target := range found {
+		targets = append(targets, target)
+	}
+	sort.Strings(targets)
+
+	return targets
+}
+
+func getImage() string {
+	if clowderconfig.LoadedConfig.Images.WaitForDependencies != "" {
+		return clowderconfig.LoadedConfig.Images.WaitForDependencies
+	}
+	return DefaultImageWaitForDependencies
+}
+
+func makeInitContainer(targets []string) core.Container {
+	script := `for target in $WAIT_FOR_TARGETS; do
+  host=${target%:*}
+  port=${target##*:}
+  until (echo > /dev/tcp/$host/$port) >/dev/null 2>&1; do
+    echo "waiting for $host:$port"
+    sleep 2
+  done
+  echo "$host:$port is reachable"
+done`
+
+	cont := core.Container{}
+
+	cont.Name = InitContainerName
+	cont.Image = getImage()
+	cont.Command = []string{"/bin/bash", "-c", script}
+	cont.Env = []core.EnvVar{{
+		Name:  "WAIT_FOR_TARGETS",
+		Value: strings.Join(targets, " "),
+	}}
+	cont.TerminationMessagePath = "/dev/termination-log"
+	cont.TerminationMessagePolicy = core.TerminationMessageReadFile
+	cont.ImagePullPolicy = core.PullIfNotPresent
+	cont.Resources = core.ResourceRequirements{
+		Limits: core.ResourceList{
+			"cpu":    resource.MustParse("50m"),
+			"memory": resource.MustParse("64Mi"),
+		},
+		Requests: core.ResourceList{
+			"cpu":    resource.MustParse("10m"),
+			"memory": resource.MustParse("32Mi"),
+		},
+	}
+
+	return cont
+}
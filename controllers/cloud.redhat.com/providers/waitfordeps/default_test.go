@@ -0,0 +1,58 @@
+package waitfordeps
+
+import (
+	"testing"
+
+	crd "github.com/RedHatInsights/clowder/apis/cloud.redhat.com/v1alpha1"
+	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetTargets(t *testing.T) {
+	app := &crd.ClowdApp{
+		Spec: crd.ClowdAppSpec{
+			Dependencies:         []string{"app-b"},
+			OptionalDependencies: []string{"app-c"},
+		},
+	}
+
+	c := &config.AppConfig{
+		Endpoints: []config.DependencyEndpoint{
+			{App: "app-b", Name: "api", Hostname: "app-b-api.test.svc", Port: 8000},
+			{App: "app-b", Name: "worker", Hostname: "app-b-worker.test.svc", Port: 0},
+			{App: "app-c", Name: "api", Hostname: "app-c-api.test.svc", Port: 8000},
+		},
+		Database: &config.DatabaseConfig{
+			Hostname: "app-a-db.test.svc",
+			Port:     5432,
+		},
+	}
+
+	targets := getTargets(app, c)
+	assert.Equal(t, []string{"app-a-db.test.svc:5432", "app-b-api.test.svc:8000"}, targets)
+
+	// Every named database is waited on, the primary one once
+	c.Databases = map[string]config.DatabaseConfig{
+		"primary":   {Hostname: "app-a-db.test.svc", Port: 5432},
+		"reporting": {Hostname: "reporting-db.test.svc", Port: 5432},
+		"external":  {Hostname: "", Port: 5432},
+	}
+	targets = getTargets(app, c)
+	assert.Equal(t, []string{"app-a-db.test.svc:5432", "app-b-api.test.svc:8000", "reporting-db.test.svc:5432"}, targets)
+}
+
+func TestGetTargetsNoDependencies(t *testing.T) {
+	app := &crd.ClowdApp{}
+	c := &config.AppConfig{}
+
+	assert.Empty(t, getTargets(app, c))
+}
+
+func TestMakeInitContainer(t *testing.T) {
+	cont := makeInitContainer([]string{"app-a-db.test.svc:5432", "app-b-api.test.svc:8000"})
+
+	assert.Equal(t, InitContainerName, cont.Name)
+	assert.Equal(t, DefaultImageWaitForDependencies, cont.Image)
+	assert.Equal(t, "WAIT_FOR_TARGETS", cont.Env[0].Name)
+	assert.Equal(t, "app-a-db.test.svc:5432 app-b-api.test.svc:8000", cont.Env[0].Value)
+}
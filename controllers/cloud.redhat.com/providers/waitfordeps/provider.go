@@ -0,0 +1,20 @@
+package waitfordeps
+
+import (
+	p "github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers"
+)
+
+// ProvName sets the provider name identifier
+var ProvName = "waitfordeps"
+
+// DefaultImageWaitForDependencies defines the default image used for the wait-for-dependencies init container
+var DefaultImageWaitForDependencies = "registry.access.redhat.com/ubi8/ubi-minimal:8.7-1085"
+
+// GetWaitForDeps returns the correct wait-for-dependencies provider.
+func GetWaitForDeps(c *p.Provider) (p.ClowderProvider, error) {
+	return NewWaitForDepsProvider(c)
+}
+
+func init() {
+	p.ProvidersRegistration.Register(GetWaitForDeps, 96, ProvName)
+}
@@ -41,9 +41,8 @@ func makeService(cache *rc.ObjectCache, deployment *crd.Deployment, app *crd.Clo
 		return err
 	}
 
-	d := &apps.Deployment{}
-
-	if err := cache.Get(deployProvider.CoreDeployment, d, app.GetDeploymentNamespacedName(deployment)); err != nil {
+	w, err := deployProvider.GetWorkload(cache, app, deployment)
+	if err != nil {
 		return err
 	}
 
@@ -157,20 +156,20 @@ func makeService(cache *rc.ObjectCache, deployment *crd.Deployment, app *crd.Clo
 			if err := generateEnvoyConfigMap(cache, nn, app, pub, priv, pubPort, privPort); err != nil {
 				return err
 			}
-			populateSideCar(d, nn.Name, env.Spec.Providers.Web.TLS.Port, env.Spec.Providers.Web.TLS.PrivatePort, pub, priv)
+			populateSideCar(env, w.Template, nn.Name, env.Spec.Providers.Web.TLS.Port, env.Spec.Providers.Web.TLS.PrivatePort, pub, priv)
 			setServiceTLSAnnotations(s, nn.Name)
 		}
 	}
 
 	utils.MakeService(s, nn, map[string]string{"pod": nn.Name}, servicePorts, app, env.IsNodePort())
 
-	d.Spec.Template.Spec.Containers[0].Ports = containerPorts
+	w.Template.Spec.Containers[0].Ports = containerPorts
 
 	if err := cache.Update(CoreService, s); err != nil {
 		return err
 	}
 
-	return cache.Update(deployProvider.CoreDeployment, d)
+	return w.Update(cache)
 }
 
 func generateEnvoyConfigMap(cache *rc.ObjectCache, nn types.NamespacedName, app *crd.ClowdApp, pub bool, priv bool, pubPort uint32, privPort uint32) error {
@@ -200,7 +199,7 @@ func generateEnvoyConfigMap(cache *rc.ObjectCache, nn types.NamespacedName, app
 	return cache.Update(CoreEnvoyConfigMap, cm)
 }
 
-func populateSideCar(d *apps.Deployment, name string, port int32, privatePort int32, pub bool, priv bool) {
+func populateSideCar(env *crd.ClowdEnvironment, template *core.PodTemplateSpec, name string, port int32, privatePort int32, pub bool, priv bool) {
 	ports := []core.ContainerPort{}
 	if pub {
 		ports = append(ports, core.ContainerPort{
@@ -242,6 +241,8 @@ func populateSideCar(d *apps.Deployment, name string, port int32, privatePort in
 		},
 		Ports: ports,
 	}
+	provutils.ApplyImageSettings(env, &container)
+
 	envoyTLSVol := core.Volume{
 		Name: "envoy-tls",
 		VolumeSource: core.VolumeSource{
@@ -255,13 +256,13 @@ func populateSideCar(d *apps.Deployment, name string, port int32, privatePort in
 		VolumeSource: core.VolumeSource{
 			ConfigMap: &core.ConfigMapVolumeSource{
 				LocalObjectReference: core.LocalObjectReference{
-					Name: envoyConfigName(d.Name),
+					Name: envoyConfigName(name),
 				},
 			},
 		},
 	}
-	d.Spec.Template.Spec.Containers = append(d.Spec.Template.Spec.Containers, container)
-	d.Spec.Template.Spec.Volumes = append(d.Spec.Template.Spec.Volumes, envoyConfigVol, envoyTLSVol)
+	template.Spec.Containers = append(template.Spec.Containers, container)
+	template.Spec.Volumes = append(template.Spec.Volumes, envoyConfigVol, envoyTLSVol)
 }
 
 func setServiceTLSAnnotations(s *core.Service, name string) {
@@ -304,6 +305,7 @@ func makeKeycloakImportSecretRealm(cache *rc.ObjectCache, o obj.ClowdObject, pas
 	userImportDataString = strings.Replace(userImportDataString, "########PASSWORD########", password, 1)
 
 	userData.StringData["redhat-external-realm.json"] = string(userImportDataString)
+	providers.SetSecretLabels(userData, o)
 
 	return cache.Update(WebKeycloakImportSecret, userData)
 }
@@ -430,6 +432,8 @@ func makeKeycloak(o obj.ClowdObject, objMap providers.ObjectMap, _ bool, nodePor
 		},
 	}
 
+	provutils.ApplyImageSettings(env, &c)
+
 	dd.Spec.Template.Spec.Volumes = []core.Volume{
 		{
 			Name: "realm-import",
@@ -476,10 +480,12 @@ func makeBOP(o obj.ClowdObject, objMap providers.ObjectMap, _ bool, nodePort boo
 
 	dd.Spec.Template.ObjectMeta.Labels = labels
 
+	env := o.(*crd.ClowdEnvironment)
+
 	envVars := []core.EnvVar{
 		{
 			Name:  "KEYCLOAK_SERVER",
-			Value: fmt.Sprintf("http://%s-keycloak.%s.svc:8080", o.GetClowdName(), o.GetClowdNamespace()),
+			Value: fmt.Sprintf("http://%s:8080", env.GetServiceHostname(fmt.Sprintf("%s-keycloak", o.GetClowdName()), o.GetClowdNamespace())),
 		},
 		{
 			Name: "KEYCLOAK_USERNAME",
@@ -544,7 +550,6 @@ func makeBOP(o obj.ClowdObject, objMap providers.ObjectMap, _ bool, nodePort boo
 		TimeoutSeconds:      2,
 	}
 
-	env := o.(*crd.ClowdEnvironment)
 	image := provutils.GetMockBOPImage(env)
 
 	c := core.Container{
@@ -569,6 +574,8 @@ func makeBOP(o obj.ClowdObject, objMap providers.ObjectMap, _ bool, nodePort boo
 		ImagePullPolicy:          core.PullIfNotPresent,
 	}
 
+	provutils.ApplyImageSettings(env, &c)
+
 	dd.Spec.Template.Spec.Containers = []core.Container{c}
 	dd.Spec.Template.SetLabels(labels)
 
@@ -607,7 +614,7 @@ func makeMocktitlements(o obj.ClowdObject, objMap providers.ObjectMap, _ bool, n
 	dd.Spec.Template.ObjectMeta.Labels = labels
 
 	env := o.(*crd.ClowdEnvironment)
-	caddyImage := provutils.GetCaddyImage(env)
+	caddyImage := provutils.MirrorImage(env, provutils.GetCaddyImage(env))
 
 	annotations := map[string]string{
 		"clowder/authsidecar-image":   caddyImage,
@@ -621,7 +628,7 @@ func makeMocktitlements(o obj.ClowdObject, objMap providers.ObjectMap, _ bool, n
 	envVars := []core.EnvVar{
 		{
 			Name:  "KEYCLOAK_SERVER",
-			Value: fmt.Sprintf("http://%s-keycloak.%s.svc:8080", o.GetClowdName(), o.GetClowdNamespace()),
+			Value: fmt.Sprintf("http://%s:8080", env.GetServiceHostname(fmt.Sprintf("%s-keycloak", o.GetClowdName()), o.GetClowdNamespace())),
 		},
 		{
 			Name: "KEYCLOAK_USERNAME",
@@ -711,6 +718,8 @@ func makeMocktitlements(o obj.ClowdObject, objMap providers.ObjectMap, _ bool, n
 		ImagePullPolicy:          core.PullIfNotPresent,
 	}
 
+	provutils.ApplyImageSettings(env, &c)
+
 	dd.Spec.Template.Spec.Containers = []core.Container{c}
 	dd.Spec.Template.SetLabels(labels)
 
@@ -96,12 +96,12 @@ func (web *localWebProvider) EnvProvide() error {
 
 	username := utils.RandString(8)
 
-	password, err := utils.RandPassword(16, provutils.RCharSet)
+	password, err := provutils.GeneratePassword(web.Env)
 	if err != nil {
 		return errors.Wrap("couldn't generate password", err)
 	}
 
-	defaultPassword, err := utils.RandPassword(16, provutils.RCharSet)
+	defaultPassword, err := provutils.GeneratePassword(web.Env)
 	if err != nil {
 		return errors.Wrap("couldn't generate defaultPassword", err)
 	}
@@ -113,7 +113,7 @@ func (web *localWebProvider) EnvProvide() error {
 			"defaultUsername": "jdoe",
 			"defaultPassword": defaultPassword,
 			"version":         provutils.GetKeycloakVersion(web.Env),
-			"bopurl":          fmt.Sprintf("http://%s-%s.%s.svc:8090", web.Env.GetClowdName(), "mbop", web.Env.GetClowdNamespace()),
+			"bopurl":          fmt.Sprintf("http://%s:8090", web.Env.GetServiceHostname(fmt.Sprintf("%s-mbop", web.Env.GetClowdName()), web.Env.GetClowdNamespace())),
 		}
 	}
 
@@ -192,6 +192,8 @@ func (web *localWebProvider) Provide(app *crd.ClowdApp) error {
 		return err
 	}
 
+	web.Config.ExternalHostname = externalHostname(web.Env, app)
+
 	for _, deployment := range app.Spec.Deployments {
 		innerDeployment := deployment
 		if err := makeService(web.Cache, &innerDeployment, app, web.Env); err != nil {
@@ -216,10 +218,11 @@ func (web *localWebProvider) Provide(app *crd.ClowdApp) error {
 		sec.Namespace = nn.Namespace
 		sec.ObjectMeta.OwnerReferences = []metav1.OwnerReference{web.Env.MakeOwnerReference()}
 		sec.Type = core.SecretTypeOpaque
+		providers.SetSecretLabels(sec, web.Env)
 
 		sec.StringData = map[string]string{
-			"bopurl":      fmt.Sprintf("http://%s-%s.%s.svc:8090", web.Env.GetClowdName(), "mbop", web.Env.GetClowdNamespace()),
-			"keycloakurl": fmt.Sprintf("http://%s-%s.%s.svc:8080", web.Env.GetClowdName(), "keycloak", web.Env.GetClowdNamespace()),
+			"bopurl":      fmt.Sprintf("http://%s:8090", web.Env.GetServiceHostname(fmt.Sprintf("%s-mbop", web.Env.GetClowdName()), web.Env.GetClowdNamespace())),
+			"keycloakurl": fmt.Sprintf("http://%s:8080", web.Env.GetServiceHostname(fmt.Sprintf("%s-keycloak", web.Env.GetClowdName()), web.Env.GetClowdNamespace())),
 			"whitelist":   strings.Join(deployment.WebServices.Public.WhitelistPaths, ","),
 		}
 
@@ -232,23 +235,23 @@ func (web *localWebProvider) Provide(app *crd.ClowdApp) error {
 		h.Write([]byte(jsonData))
 		hash := fmt.Sprintf("%x", h.Sum(nil))
 
-		d := &apps.Deployment{}
 		dnn := app.GetDeploymentNamespacedName(&innerDeployment)
-		if err := web.Cache.Get(provDeploy.CoreDeployment, d, dnn); err != nil {
+		w, err := provDeploy.GetWorkload(web.Cache, app, &innerDeployment)
+		if err != nil {
 			return err
 		}
 
 		if web.Env.Spec.Providers.Web.TLS.Enabled {
-			provutils.AddCertVolume(&d.Spec.Template.Spec, dnn.Name)
+			provutils.AddCertVolume(&w.Template.Spec, dnn.Name)
 		}
 
 		annotations := map[string]string{
 			"clowder/authsidecar-confighash": hash,
 		}
 
-		utils.UpdateAnnotations(&d.Spec.Template, annotations)
+		utils.UpdateAnnotations(w.Template, annotations)
 
-		if err := web.Cache.Update(provDeploy.CoreDeployment, d); err != nil {
+		if err := w.Update(web.Cache); err != nil {
 			return err
 		}
 
@@ -302,6 +305,7 @@ func makeMocktitlementsSecret(p *providers.Provider) error {
 	sec.Namespace = nn.Namespace
 	sec.ObjectMeta.OwnerReferences = []metav1.OwnerReference{p.Env.MakeOwnerReference()}
 	sec.Type = core.SecretTypeOpaque
+	providers.SetSecretLabels(sec, p.Env)
 
 	envSec := &core.Secret{}
 	envSecnn := providers.GetNamespacedName(p.Env, "keycloak")
@@ -311,7 +315,7 @@ func makeMocktitlementsSecret(p *providers.Provider) error {
 
 	sec.StringData = map[string]string{
 		"bopurl":      string(envSec.Data["bopurl"]),
-		"keycloakurl": fmt.Sprintf("http://%s-%s.%s.svc:8080", p.Env.GetClowdName(), "keycloak", p.Env.GetClowdNamespace()),
+		"keycloakurl": fmt.Sprintf("http://%s:8080", p.Env.GetServiceHostname(fmt.Sprintf("%s-keycloak", p.Env.GetClowdName()), p.Env.GetClowdNamespace())),
 		"whitelist":   "",
 	}
 
@@ -447,9 +451,29 @@ func makeAuthIngress(p *providers.Provider) error {
 	return p.Cache.Update(WebKeycloakIngress, netobj)
 }
 
+// isExposed reports whether the deployment is served through the environment's
+// ingress.
+func isExposed(deployment *crd.Deployment) bool {
+	return deployment.WebServices.Public.Enabled || bool(deployment.Web)
+}
+
+// externalHostname returns the hostname the app is routed through, it is nil when none of the
+// app's deployments are exposed or the environment has no hostname yet.
+func externalHostname(env *crd.ClowdEnvironment, app *crd.ClowdApp) *string {
+	if env.Status.Hostname == "" {
+		return nil
+	}
+	for i := range app.Spec.Deployments {
+		if isExposed(&app.Spec.Deployments[i]) {
+			return utils.StringPtr(env.Status.Hostname)
+		}
+	}
+	return nil
+}
+
 func (web *localWebProvider) createIngress(app *crd.ClowdApp, deployment *crd.Deployment) error {
 
-	if !deployment.WebServices.Public.Enabled && !bool(deployment.Web) {
+	if !isExposed(deployment) {
 		return nil
 	}
 
@@ -0,0 +1,53 @@
+package web
+
+import (
+	"testing"
+
+	crd "github.com/RedHatInsights/clowder/apis/cloud.redhat.com/v1alpha1"
+	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers"
+	"github.com/stretchr/testify/assert"
+	apps "k8s.io/api/apps/v1"
+	core "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestExternalHostname(t *testing.T) {
+	env := &crd.ClowdEnvironment{}
+	app := &crd.ClowdApp{Spec: crd.ClowdAppSpec{Deployments: []crd.Deployment{{Name: "worker"}}}}
+
+	// Apps that are not exposed get no hostname
+	env.Status.Hostname = "env-myenv.apps.example.com"
+	assert.Nil(t, externalHostname(env, app))
+
+	app.Spec.Deployments = append(app.Spec.Deployments, crd.Deployment{Name: "api"})
+	app.Spec.Deployments[1].WebServices.Public.Enabled = true
+	assert.Equal(t, "env-myenv.apps.example.com", *externalHostname(env, app))
+
+	// Nor do apps in an environment that has no hostname
+	env.Status.Hostname = ""
+	assert.Nil(t, externalHostname(env, app))
+}
+
+func TestKeycloakServer(t *testing.T) {
+	env := &crd.ClowdEnvironment{ObjectMeta: metav1.ObjectMeta{Name: "myenv", Labels: map[string]string{}}}
+	env.Status.TargetNamespace = "myenv-ns"
+	env.Spec.ServiceConfig.DNSSuffix = "svc.cluster.local"
+
+	keycloakServer := func(dd *apps.Deployment) string {
+		for _, envVar := range dd.Spec.Template.Spec.Containers[0].Env {
+			if envVar.Name == "KEYCLOAK_SERVER" {
+				return envVar.Value
+			}
+		}
+		return ""
+	}
+
+	// The mocks reach keycloak through the environment's DNS suffix
+	bop := &apps.Deployment{}
+	makeBOP(env, providers.ObjectMap{WebBOPDeployment: bop, WebBOPService: &core.Service{}}, false, false)
+	assert.Equal(t, "http://myenv-keycloak.myenv-ns.svc.cluster.local:8080", keycloakServer(bop))
+
+	mocktitlements := &apps.Deployment{}
+	makeMocktitlements(env, providers.ObjectMap{WebMocktitlementsDeployment: mocktitlements, WebMocktitlementsService: &core.Service{}}, false, false)
+	assert.Equal(t, "http://myenv-keycloak.myenv-ns.svc.cluster.local:8080", keycloakServer(mocktitlements))
+}
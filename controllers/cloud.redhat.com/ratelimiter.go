@@ -0,0 +1,59 @@
+package controllers
+
+import (
+	"fmt"
+	"time"
+
+	"golang.org/x/time/rate"
+	"k8s.io/client-go/util/workqueue"
+)
+
+// RateLimiterOptions configures how the controllers' work queues back off requeued reconciles.
+// Each failed reconcile of an object doubles its delay, from BaseDelay up to MaxDelay, until a
+// reconcile of it succeeds. When BucketSize is set, requeues of all objects are also limited to
+// BucketQPS on average, with bursts of up to BucketSize.
+type RateLimiterOptions struct {
+	BaseDelay  time.Duration
+	MaxDelay   time.Duration
+	BucketQPS  float64
+	BucketSize int
+}
+
+// RateLimiter holds the rate limiter options of the controllers, set from the operator's
+// --rate-limiter-* flags.
+var RateLimiter = RateLimiterOptions{
+	BaseDelay:  500 * time.Millisecond,
+	MaxDelay:   60 * time.Second,
+	BucketQPS:  10,
+	BucketSize: 0,
+}
+
+// Validate returns an error if the options cannot make a rate limiter.
+func (o RateLimiterOptions) Validate() error {
+	if o.BaseDelay <= 0 {
+		return fmt.Errorf("rate limiter base delay must be positive, got %s", o.BaseDelay)
+	}
+	if o.MaxDelay < o.BaseDelay {
+		return fmt.Errorf("rate limiter max delay %s is less than its base delay %s", o.MaxDelay, o.BaseDelay)
+	}
+	if o.BucketSize < 0 {
+		return fmt.Errorf("rate limiter bucket size must not be negative, got %d", o.BucketSize)
+	}
+	if o.BucketSize > 0 && o.BucketQPS <= 0 {
+		return fmt.Errorf("rate limiter bucket qps must be positive, got %v", o.BucketQPS)
+	}
+	return nil
+}
+
+// newRateLimiter returns a rate limiter for a controller's work queue. Every controller needs its
+// own, as the limiter tracks the failures of the objects in its queue.
+func (o RateLimiterOptions) newRateLimiter() workqueue.RateLimiter {
+	itemLimiter := workqueue.NewItemExponentialFailureRateLimiter(o.BaseDelay, o.MaxDelay)
+	if o.BucketSize == 0 {
+		return itemLimiter
+	}
+	return workqueue.NewMaxOfRateLimiter(
+		itemLimiter,
+		&workqueue.BucketRateLimiter{Limiter: rate.NewLimiter(rate.Limit(o.BucketQPS), o.BucketSize)},
+	)
+}
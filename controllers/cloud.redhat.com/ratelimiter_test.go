@@ -0,0 +1,38 @@
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimiterOptionsValidate(t *testing.T) {
+	assert.NoError(t, RateLimiter.Validate())
+
+	for _, opts := range []RateLimiterOptions{
+		{BaseDelay: 0, MaxDelay: time.Second},
+		{BaseDelay: time.Second, MaxDelay: time.Millisecond},
+		{BaseDelay: time.Second, MaxDelay: time.Minute, BucketSize: -1},
+		{BaseDelay: time.Second, MaxDelay: time.Minute, BucketSize: 10, BucketQPS: 0},
+	} {
+		assert.Error(t, opts.Validate(), "%+v", opts)
+	}
+}
+
+func TestNewRateLimiter(t *testing.T) {
+	limiter := RateLimiterOptions{BaseDelay: time.Second, MaxDelay: 3 * time.Second}.newRateLimiter()
+
+	assert.Equal(t, time.Second, limiter.When("app"))
+	assert.Equal(t, 2*time.Second, limiter.When("app"))
+	assert.Equal(t, 3*time.Second, limiter.When("app"))
+	assert.Equal(t, time.Second, limiter.When("other-app"))
+
+	limiter.Forget("app")
+	assert.Equal(t, time.Second, limiter.When("app"))
+
+	// Once the bucket is drained, requeues of any object wait for it to refill
+	limiter = RateLimiterOptions{BaseDelay: time.Millisecond, MaxDelay: time.Second, BucketQPS: 1, BucketSize: 1}.newRateLimiter()
+	assert.Equal(t, time.Millisecond, limiter.When("app"))
+	assert.Greater(t, limiter.When("other-app"), 900*time.Millisecond)
+}
@@ -0,0 +1,146 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	crd "github.com/RedHatInsights/clowder/apis/cloud.redhat.com/v1alpha1"
+	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/errors"
+	promapi "github.com/prometheus/client_golang/api"
+	promv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// recommendationInterval is how often an app's resource recommendation is recomputed.
+const recommendationInterval = time.Hour
+
+// recommendationHeadroom is added on top of the observed usage when suggesting requests.
+const recommendationHeadroom = 1.2
+
+// recommendationTimeout bounds the time spent querying Prometheus for an app's recommendation, so
+// that a slow or unreachable Prometheus does not hold up the reconcile.
+const recommendationTimeout = 10 * time.Second
+
+const cpuUsageQuery = `max(quantile_over_time(0.95, rate(container_cpu_usage_seconds_total{namespace="%s",container="%s"}[5m])[1d:5m]))`
+
+const memoryUsageQuery = `max(max_over_time(container_memory_working_set_bytes{namespace="%s",container="%s"}[1d]))`
+
+// promQuerier is the part of the Prometheus API used to read container usage.
+type promQuerier interface {
+	Query(ctx context.Context, query string, ts time.Time, opts ...promv1.Option) (model.Value, promv1.Warnings, error)
+}
+
+// newPromQuerier returns a Prometheus API client for the given hostname or URL, defaulting to
+// plain HTTP on the Prometheus port when only a hostname is given.
+func newPromQuerier(hostname string) (promQuerier, error) {
+	address := hostname
+	if !strings.Contains(address, "://") {
+		address = fmt.Sprintf("http://%s:9090", address)
+	}
+
+	client, err := promapi.NewClient(promapi.Config{Address: address})
+	if err != nil {
+		return nil, errors.Wrap("could not create prometheus client", err)
+	}
+	return promv1.NewAPI(client), nil
+}
+
+// queryScalar runs an instant query and returns its single value, or false if Prometheus
+// has no data for it or did not answer in time.
+func queryScalar(ctx context.Context, prom promQuerier, query string, now time.Time) (float64, bool, error) {
+	value, _, err := prom.Query(ctx, query, now)
+	if ctx.Err() == context.DeadlineExceeded {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, errors.Wrap("prometheus query failed", err)
+	}
+
+	vector, ok := value.(model.Vector)
+	if !ok || len(vector) == 0 {
+		return 0, false, nil
+	}
+
+	sample := float64(vector[0].Value)
+	if math.IsNaN(sample) || math.IsInf(sample, 0) {
+		return 0, false, nil
+	}
+	return sample, true, nil
+}
+
+// suggestResources returns the observed usage of a container as a resource list, along with
+// the requests and limits suggested for it.
+func suggestResources(cpuCores float64, memoryBytes float64) (core.ResourceList, core.ResourceList, core.ResourceList) {
+	mebibyte := float64(1024 * 1024)
+
+	observed := core.ResourceList{
+		core.ResourceCPU:    *resource.NewMilliQuantity(int64(math.Ceil(cpuCores*1000)), resource.DecimalSI),
+		core.ResourceMemory: *resource.NewQuantity(int64(math.Ceil(memoryBytes/mebibyte))*int64(mebibyte), resource.BinarySI),
+	}
+
+	cpuRequest := int64(math.Ceil(cpuCores * 1000 * recommendationHeadroom))
+	if cpuRequest < 1 {
+		cpuRequest = 1
+	}
+	memoryRequest := int64(math.Ceil(memoryBytes*recommendationHeadroom/mebibyte)) * int64(mebibyte)
+	if memoryRequest < int64(mebibyte) {
+		memoryRequest = int64(mebibyte)
+	}
+
+	requests := core.ResourceList{
+		core.ResourceCPU:    *resource.NewMilliQuantity(cpuRequest, resource.DecimalSI),
+		core.ResourceMemory: *resource.NewQuantity(memoryRequest, resource.BinarySI),
+	}
+	limits := core.ResourceList{
+		core.ResourceCPU:    *resource.NewMilliQuantity(cpuRequest*2, resource.DecimalSI),
+		core.ResourceMemory: *resource.NewQuantity(memoryRequest*2, resource.BinarySI),
+	}
+
+	return observed, requests, limits
+}
+
+// getResourceRecommendation reads the usage of each of the app's deployments from Prometheus
+// and suggests resources for them. Deployments without usage data, or whose usage was not read
+// within the recommendationTimeout, are left out.
+func getResourceRecommendation(ctx context.Context, prom promQuerier, app *crd.ClowdApp, now time.Time) (*crd.ResourceRecommendation, error) {
+	ctx, cancel := context.WithTimeout(ctx, recommendationTimeout)
+	defer cancel()
+
+	recommendation := &crd.ResourceRecommendation{
+		LastUpdated: metav1.NewTime(now),
+		Deployments: []crd.DeploymentResourceRecommendation{},
+	}
+
+	for i := range app.Spec.Deployments {
+		deployment := &app.Spec.Deployments[i]
+		container := app.GetDeploymentNamespacedName(deployment).Name
+
+		cpuCores, cpuFound, err := queryScalar(ctx, prom, fmt.Sprintf(cpuUsageQuery, app.Namespace, container), now)
+		if err != nil {
+			return nil, err
+		}
+		memoryBytes, memoryFound, err := queryScalar(ctx, prom, fmt.Sprintf(memoryUsageQuery, app.Namespace, container), now)
+		if err != nil {
+			return nil, err
+		}
+		if !cpuFound || !memoryFound {
+			continue
+		}
+
+		observed, requests, limits := suggestResources(cpuCores, memoryBytes)
+		recommendation.Deployments = append(recommendation.Deployments, crd.DeploymentResourceRecommendation{
+			Name:     deployment.Name,
+			Observed: observed,
+			Requests: requests,
+			Limits:   limits,
+		})
+	}
+
+	return recommendation, nil
+}
@@ -0,0 +1,97 @@
+package controllers
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	crd "github.com/RedHatInsights/clowder/apis/cloud.redhat.com/v1alpha1"
+	promv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// fakePromQuerier answers queries mentioning a container with that container's usage.
+type fakePromQuerier struct {
+	cpu    map[string]float64
+	memory map[string]float64
+	hang   bool
+}
+
+func (f *fakePromQuerier) Query(ctx context.Context, query string, _ time.Time, _ ...promv1.Option) (model.Value, promv1.Warnings, error) {
+	if f.hang {
+		<-ctx.Done()
+		return nil, nil, ctx.Err()
+	}
+	usage := f.memory
+	if strings.Contains(query, "container_cpu_usage_seconds_total") {
+		usage = f.cpu
+	}
+	for container, value := range usage {
+		if strings.Contains(query, `container="`+container+`"`) {
+			return model.Vector{&model.Sample{Value: model.SampleValue(value)}}, nil, nil
+		}
+	}
+	return model.Vector{}, nil, nil
+}
+
+func TestSuggestResources(t *testing.T) {
+	observed, requests, limits := suggestResources(0.25, 200*1024*1024)
+
+	assert.True(t, resource.MustParse("250m").Equal(observed[core.ResourceCPU]))
+	assert.True(t, resource.MustParse("200Mi").Equal(observed[core.ResourceMemory]))
+	assert.True(t, resource.MustParse("300m").Equal(requests[core.ResourceCPU]))
+	assert.True(t, resource.MustParse("240Mi").Equal(requests[core.ResourceMemory]))
+	assert.True(t, resource.MustParse("600m").Equal(limits[core.ResourceCPU]))
+	assert.True(t, resource.MustParse("480Mi").Equal(limits[core.ResourceMemory]))
+
+	// Idle containers still get a minimal request
+	_, requests, _ = suggestResources(0, 0)
+	assert.True(t, resource.MustParse("1m").Equal(requests[core.ResourceCPU]))
+	assert.True(t, resource.MustParse("1Mi").Equal(requests[core.ResourceMemory]))
+}
+
+func TestGetResourceRecommendation(t *testing.T) {
+	app := &crd.ClowdApp{
+		ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "test"},
+		Spec: crd.ClowdAppSpec{Deployments: []crd.Deployment{
+			{Name: "api"},
+			{Name: "worker"},
+		}},
+	}
+	prom := &fakePromQuerier{
+		cpu:    map[string]float64{"app-api": 0.5},
+		memory: map[string]float64{"app-api": 512 * 1024 * 1024},
+	}
+
+	now := time.Unix(1700000000, 0)
+	recommendation, err := getResourceRecommendation(context.Background(), prom, app, now)
+	assert.NoError(t, err)
+	assert.Equal(t, now, recommendation.LastUpdated.Time)
+
+	// The worker has no usage data, so only the api gets a recommendation
+	assert.Len(t, recommendation.Deployments, 1)
+	api := recommendation.Deployments[0]
+	assert.Equal(t, "api", api.Name)
+	assert.True(t, resource.MustParse("600m").Equal(api.Requests[core.ResourceCPU]))
+	assert.True(t, resource.MustParse("615Mi").Equal(api.Requests[core.ResourceMemory]))
+}
+
+func TestGetResourceRecommendationTimeout(t *testing.T) {
+	app := &crd.ClowdApp{
+		ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "test"},
+		Spec:       crd.ClowdAppSpec{Deployments: []crd.Deployment{{Name: "api"}}},
+	}
+	prom := &fakePromQuerier{hang: true}
+
+	// A Prometheus that doesn't answer in time gives no recommendation rather than an error
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	recommendation, err := getResourceRecommendation(ctx, prom, app, time.Now())
+	assert.NoError(t, err)
+	assert.Empty(t, recommendation.Deployments)
+}
@@ -0,0 +1,89 @@
+package controllers
+
+import "sync"
+
+// appRetries tracks the consecutive failed reconciles of a single app, for a
+// given generation of the app and of its environment.
+type appRetries struct {
+	failures      int
+	limit         int32
+	appGeneration int64
+	envGeneration int64
+}
+
+// retryLimiter gives up on apps that keep failing to reconcile. Where the
+// circuit breaker holds an app back for a cooldown and then tries it again,
+// an app that has used up the retries its environment allows is marked Failed
+// and not retried until the app or its environment changes generation.
+type retryLimiter struct {
+	mu      sync.Mutex
+	retries map[string]*appRetries
+}
+
+func newRetryLimiter() *retryLimiter {
+	return &retryLimiter{retries: map[string]*appRetries{}}
+}
+
+var appRetryLimiter = newRetryLimiter()
+
+// isExhausted reports whether the app has used up its retries. The failures
+// counted are forgotten if the app or its environment changed since.
+func (rl *retryLimiter) isExhausted(ident string, appGeneration int64, envGeneration int64) bool {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	r, ok := rl.retries[ident]
+	if !ok {
+		return false
+	}
+
+	if r.appGeneration != appGeneration || r.envGeneration != envGeneration {
+		delete(rl.retries, ident)
+		return false
+	}
+
+	return r.limit > 0 && r.failures >= int(r.limit)
+}
+
+// failed reports whether the app at the given generation has been marked
+// Failed, and after how many reconciles.
+func (rl *retryLimiter) failed(ident string, appGeneration int64) (bool, int) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	r, ok := rl.retries[ident]
+	if !ok || r.appGeneration != appGeneration || r.limit <= 0 || r.failures < int(r.limit) {
+		return false, 0
+	}
+	return true, r.failures
+}
+
+// recordFailure counts a failed reconcile of the app and reports whether that
+// used up its retries. A limit of zero retries indefinitely.
+func (rl *retryLimiter) recordFailure(ident string, appGeneration int64, envGeneration int64, limit int32) bool {
+	if limit <= 0 {
+		return false
+	}
+
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	r, ok := rl.retries[ident]
+	if !ok || r.appGeneration != appGeneration || r.envGeneration != envGeneration {
+		r = &appRetries{appGeneration: appGeneration, envGeneration: envGeneration}
+		rl.retries[ident] = r
+	}
+
+	r.limit = limit
+	r.failures++
+	return r.failures >= int(limit)
+}
+
+// reset forgets the failures of the app, after it reconciles successfully or
+// is deleted.
+func (rl *retryLimiter) reset(ident string) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	delete(rl.retries, ident)
+}
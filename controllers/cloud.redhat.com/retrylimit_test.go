@@ -0,0 +1,42 @@
+package controllers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryLimiter(t *testing.T) {
+	rl := newRetryLimiter()
+
+	assert.False(t, rl.recordFailure("ns/app", 1, 1, 3))
+	assert.False(t, rl.recordFailure("ns/app", 1, 1, 3))
+	assert.False(t, rl.isExhausted("ns/app", 1, 1))
+
+	assert.True(t, rl.recordFailure("ns/app", 1, 1, 3))
+	assert.True(t, rl.isExhausted("ns/app", 1, 1))
+	failed, failures := rl.failed("ns/app", 1)
+	assert.True(t, failed)
+	assert.Equal(t, 3, failures)
+
+	// A change to the environment gives the app its retries back
+	assert.False(t, rl.isExhausted("ns/app", 1, 2))
+	failed, _ = rl.failed("ns/app", 1)
+	assert.False(t, failed)
+
+	// As does a change to the app
+	assert.True(t, rl.recordFailure("ns/app", 1, 2, 1))
+	assert.False(t, rl.isExhausted("ns/app", 2, 2))
+	failed, _ = rl.failed("ns/app", 2)
+	assert.False(t, failed)
+
+	assert.True(t, rl.recordFailure("ns/app", 2, 2, 1))
+	rl.reset("ns/app")
+	assert.False(t, rl.isExhausted("ns/app", 2, 2))
+
+	// Without a limit the app is retried indefinitely
+	for i := 0; i < 10; i++ {
+		assert.False(t, rl.recordFailure("ns/other", 1, 1, 0))
+	}
+	assert.False(t, rl.isExhausted("ns/other", 1, 1))
+}
@@ -22,7 +22,6 @@ import (
 	core "k8s.io/api/core/v1"
 	"k8s.io/client-go/rest"
 	ctrl "sigs.k8s.io/controller-runtime"
-	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
@@ -72,6 +71,10 @@ func init() {
 //go:embed version.txt
 var Version string
 
+// DefaultEnvName is the ClowdEnvironment given to ClowdApps that do not set envName, set from the
+// operator's --default-env-name flag. ClowdApps must name their environment when it is empty.
+var DefaultEnvName string
+
 func printConfig() error {
 	setupLog.Info("Loaded config", "config", clowderconfig.LoadedConfig)
 	return nil
@@ -87,6 +90,21 @@ func Run(signalHandler context.Context, metricsAddr string, probeAddr string, en
 
 	clowderVersion.With(prometheus.Labels{"version": Version}).Inc()
 
+	if DefaultEnvName != "" {
+		setupLog.Info("ClowdApps without an envName will use the default environment", "env", DefaultEnvName)
+	}
+
+	if err := RateLimiter.Validate(); err != nil {
+		setupLog.Error(err, "invalid rate limiter options")
+		os.Exit(1)
+	}
+
+	auditLog, err = openAuditLog()
+	if err != nil {
+		setupLog.Error(err, "unable to open audit log")
+		os.Exit(1)
+	}
+
 	mgr, err := ctrl.NewManager(config, ctrl.Options{
 		Scheme:                 Scheme,
 		MetricsBindAddress:     metricsAddr,
@@ -110,12 +128,7 @@ func Run(signalHandler context.Context, metricsAddr string, probeAddr string, en
 		os.Exit(1)
 	}
 
-	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
-		setupLog.Error(err, "unable to set up health check")
-		os.Exit(1)
-	}
-	if err := mgr.AddReadyzCheck("readyz", healthz.Ping); err != nil {
-		setupLog.Error(err, "unable to set up ready check")
+	if err := addHealthChecks(mgr); err != nil {
 		os.Exit(1)
 	}
 
@@ -166,6 +179,10 @@ func setupWebhooks(mgr manager.Manager, enableWebHooks bool) error {
 			setupLog.Error(err, "unable to create webhook", "webhook", "Captain")
 			return err
 		}
+		if err := (&crd.ClowdEnvironment{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "ClowdEnvironment")
+			return err
+		}
 		mgr.GetWebhookServer().Register(
 			"/mutate-pod",
 			&webhook.Admission{
@@ -2,6 +2,7 @@ package controllers
 
 import (
 	"context"
+	errlib "errors"
 	"fmt"
 	"reflect"
 	"sort"
@@ -11,11 +12,17 @@ import (
 	crd "github.com/RedHatInsights/clowder/apis/cloud.redhat.com/v1alpha1"
 	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/clowderconfig"
 	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/errors"
+	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/featuregates"
 	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/object"
+	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers"
+	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers/database"
+	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/providers/rollout"
 	strimzi "github.com/RedHatInsights/strimzi-client-go/apis/kafka.strimzi.io/v1beta2"
 	apps "k8s.io/api/apps/v1"
+	batch "k8s.io/api/batch/v1"
 	core "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
+	k8serr "k8s.io/apimachinery/pkg/api/errors"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/wait"
@@ -39,6 +46,16 @@ func deploymentStatusChecker(deployment apps.Deployment) bool {
 	return false
 }
 
+func daemonSetStatusChecker(daemonSet apps.DaemonSet) bool {
+	if daemonSet.Generation > daemonSet.Status.ObservedGeneration {
+		// The status on this resource needs to update
+		return false
+	}
+
+	return daemonSet.Status.UpdatedNumberScheduled == daemonSet.Status.DesiredNumberScheduled &&
+		daemonSet.Status.NumberAvailable == daemonSet.Status.DesiredNumberScheduled
+}
+
 func kafkaStatusChecker(kafka strimzi.Kafka) bool {
 	// nil checks needed since these are all pointers in strimzi-client-go
 	if kafka.Status == nil {
@@ -106,6 +123,7 @@ func countDeployments(ctx context.Context, pClient client.Client, o object.Clowd
 	var msg = ""
 
 	deployments := []apps.Deployment{}
+	daemonSets := []apps.DaemonSet{}
 	for _, namespace := range namespaces {
 		opts := []client.ListOption{
 			client.InNamespace(namespace),
@@ -116,6 +134,13 @@ func countDeployments(ctx context.Context, pClient client.Client, o object.Clowd
 			return 0, 0, "", err
 		}
 		deployments = append(deployments, tmpDeployments.Items...)
+
+		tmpDaemonSets := apps.DaemonSetList{}
+		err = pClient.List(ctx, &tmpDaemonSets, opts...)
+		if err != nil {
+			return 0, 0, "", err
+		}
+		daemonSets = append(daemonSets, tmpDaemonSets.Items...)
 	}
 
 	// filter for resources owned by the ClowdObject and check their status
@@ -133,6 +158,21 @@ func countDeployments(ctx context.Context, pClient client.Client, o object.Clowd
 		}
 	}
 
+	// DaemonSets are run for ClowdApp deployments too, so count them alongside
+	for _, daemonSet := range daemonSets {
+		for _, owner := range daemonSet.GetOwnerReferences() {
+			if owner.UID == o.GetUID() {
+				managedDeployments++
+				if ok := daemonSetStatusChecker(daemonSet); ok {
+					readyDeployments++
+				} else {
+					brokenDeployments = append(brokenDeployments, fmt.Sprintf("%s/%s", daemonSet.Name, daemonSet.Namespace))
+				}
+				break
+			}
+		}
+	}
+
 	if len(brokenDeployments) > 0 {
 		sort.Strings(brokenDeployments)
 		msg = fmt.Sprintf("broken deployments: [%s]", strings.Join(brokenDeployments, ", "))
@@ -368,9 +408,279 @@ func SetAppResourceStatus(ctx context.Context, client client.Client, o *crd.Clow
 	status.ManagedDeployments = stats.ManagedDeployments
 	status.ReadyDeployments = stats.ReadyDeployments
 
+	// Without the rollout provider nothing holds the deployments back, so there is no progress to report
+	o.Status.DeploymentRollouts = nil
+	if featuregates.Gates.Enabled(featuregates.DeploymentRolloutOrder) {
+		rollouts, err := rollout.GetRolloutStatus(ctx, client, o)
+		if err != nil {
+			return errors.Wrap("get rollouts: ", err)
+		}
+		o.Status.DeploymentRollouts = rollouts
+	}
+
 	return nil
 }
 
+// GetAppPendingMaintenance returns the changes to the ClowdApp's local database that are waiting
+// for its maintenance window, it is empty when there are none.
+func GetAppPendingMaintenance(ctx context.Context, pClient client.Client, o *crd.ClowdApp) (string, error) {
+	if o.Spec.Database.MaintenanceWindow == nil {
+		return "", nil
+	}
+
+	d := &apps.Deployment{}
+	if err := pClient.Get(ctx, providers.GetNamespacedName(o, "db"), d); err != nil {
+		if k8serr.IsNotFound(err) {
+			return "", nil
+		}
+		return "", errors.Wrap("get db deployment: ", err)
+	}
+
+	return d.GetAnnotations()[database.PendingMaintenanceAnnotation], nil
+}
+
+var databaseDrainMessages = map[string]string{
+	database.DrainScalingDown: "scaling deployments down before the database is upgraded",
+	database.DrainUpgrading:   "upgrading the database, deployments are scaled back up once it is ready",
+}
+
+// GetAppDatabaseDrain returns the step of the drain of the ClowdApp's deployments for an upgrade of
+// its local database, or of the database it shares through sharedDbAppName, it is empty when the
+// app is not being drained.
+func GetAppDatabaseDrain(ctx context.Context, pClient client.Client, o *crd.ClowdApp) (string, error) {
+	if o.Spec.Database.Name == "" && o.Spec.Database.SharedDBAppName == "" {
+		return "", nil
+	}
+
+	nn := providers.GetNamespacedName(o, "db")
+	if o.Spec.Database.SharedDBAppName != "" {
+		refApp, err := crd.GetAppForDBInSameEnv(ctx, pClient, o)
+		if err != nil {
+			// The database provider already fails the reconcile of an app whose shared db is missing
+			return "", nil
+		}
+		nn = providers.GetNamespacedName(refApp, "db")
+	}
+
+	d := &apps.Deployment{}
+	if err := pClient.Get(ctx, nn, d); err != nil {
+		if k8serr.IsNotFound(err) {
+			return "", nil
+		}
+		return "", errors.Wrap("get db deployment: ", err)
+	}
+
+	return d.GetAnnotations()[database.DrainAnnotation], nil
+}
+
+// GetAppFailedDatabaseMigrations returns a message describing each of the jobs running the
+// migrations of the ClowdApp's local database that has failed, the message is empty when there are
+// none.
+func GetAppFailedDatabaseMigrations(ctx context.Context, pClient client.Client, o *crd.ClowdApp) (string, error) {
+	if o.Spec.Database.Migrations == nil {
+		return "", nil
+	}
+
+	jobs := &batch.JobList{}
+	opts := []client.ListOption{
+		client.MatchingLabels{o.GetPrimaryLabel(): o.GetClowdName(), "service": "db-migrations"},
+		client.InNamespace(o.Namespace),
+	}
+
+	if err := pClient.List(ctx, jobs, opts...); err != nil {
+		return "", errors.Wrap("list db migration jobs: ", err)
+	}
+
+	var msgs []string
+	for _, job := range jobs.Items {
+		for _, condition := range job.Status.Conditions {
+			if condition.Type == batch.JobFailed && condition.Status == core.ConditionTrue {
+				msgs = append(msgs, fmt.Sprintf("job [%s] failed: %s", job.Name, condition.Message))
+			}
+		}
+	}
+
+	sort.Strings(msgs)
+
+	return strings.Join(msgs, "; "), nil
+}
+
+// GetAppMaintenanceMode returns a message naming the jobs running the migrations of the ClowdApp's
+// local database, or that failed them, while maintenanceMode is set in its config, the message is
+// empty when the app is not in maintenance mode.
+func GetAppMaintenanceMode(ctx context.Context, pClient client.Client, o *crd.ClowdApp) (string, error) {
+	if o.Spec.Database.Migrations == nil || o.GetAnnotations()[database.MaintenanceModeAnnotation] != "true" {
+		return "", nil
+	}
+
+	jobs := &batch.JobList{}
+	opts := []client.ListOption{
+		client.MatchingLabels{o.GetPrimaryLabel(): o.GetClowdName(), "service": "db-migrations"},
+		client.InNamespace(o.Namespace),
+	}
+
+	if err := pClient.List(ctx, jobs, opts...); err != nil {
+		return "", errors.Wrap("list db migration jobs: ", err)
+	}
+
+	var running, failed []string
+	for i := range jobs.Items {
+		switch job := &jobs.Items[i]; {
+		case database.MigrationJobRunning(job):
+			running = append(running, job.Name)
+		case !database.MigrationJobSucceeded(job):
+			failed = append(failed, job.Name)
+		}
+	}
+
+	var msgs []string
+	if len(running) != 0 {
+		sort.Strings(running)
+		msgs = append(msgs, fmt.Sprintf("maintenanceMode is set while job [%s] runs the database migrations", strings.Join(running, ", ")))
+	}
+	if len(failed) != 0 {
+		sort.Strings(failed)
+		msgs = append(msgs, fmt.Sprintf("maintenanceMode is kept set until the database migrations succeed, job [%s] failed", strings.Join(failed, ", ")))
+	}
+
+	return strings.Join(msgs, "; "), nil
+}
+
+// GetAppVolumeZoneMismatch returns a message describing each of the ClowdApp's local database pods
+// that cannot be scheduled because no node in its volume's zone fits, the message is empty when
+// there are none.
+func GetAppVolumeZoneMismatch(ctx context.Context, pClient client.Client, o *crd.ClowdApp) (string, error) {
+	if o.Spec.Database.Name == "" {
+		return "", nil
+	}
+
+	pods := &core.PodList{}
+	opts := []client.ListOption{
+		client.MatchingLabels{o.GetPrimaryLabel(): o.GetClowdName(), "service": "db"},
+		client.InNamespace(o.Namespace),
+	}
+
+	if err := pClient.List(ctx, pods, opts...); err != nil {
+		return "", errors.Wrap("list db pods: ", err)
+	}
+
+	var msgs []string
+	for _, pod := range pods.Items {
+		for _, condition := range pod.Status.Conditions {
+			if condition.Type == core.PodScheduled && condition.Status == core.ConditionFalse &&
+				strings.Contains(condition.Message, "volume node affinity conflict") {
+				msgs = append(msgs, fmt.Sprintf("pod [%s] cannot be scheduled in the zone of its volume", pod.Name))
+			}
+		}
+	}
+
+	sort.Strings(msgs)
+
+	return strings.Join(msgs, "; "), nil
+}
+
+// GetAppFailedRollouts returns a message describing each of the ClowdApp's deployments whose
+// rollout exceeded its progress deadline, the message is empty when there are none.
+func GetAppFailedRollouts(ctx context.Context, pClient client.Client, o *crd.ClowdApp) (string, error) {
+	deployments := &apps.DeploymentList{}
+	if err := pClient.List(ctx, deployments, client.InNamespace(o.Namespace)); err != nil {
+		return "", errors.Wrap("list deployments: ", err)
+	}
+
+	var msgs []string
+	for i := range deployments.Items {
+		deployment := &deployments.Items[i]
+		if !v1.IsControlledBy(deployment, o) {
+			continue
+		}
+		for _, condition := range deployment.Status.Conditions {
+			if condition.Type == apps.DeploymentProgressing && condition.Status == core.ConditionFalse &&
+				condition.Reason == "ProgressDeadlineExceeded" {
+				msgs = append(msgs, fmt.Sprintf("deployment [%s] exceeded its progress deadline: %s", deployment.Name, condition.Message))
+			}
+		}
+	}
+
+	sort.Strings(msgs)
+
+	return strings.Join(msgs, "; "), nil
+}
+
+// GetAppCrashLoopingContainers returns a message describing each of the ClowdApp's containers that
+// is in a CrashLoopBackOff in any of its pods, along with the most restarts among those pods. The
+// message is empty when there are none.
+func GetAppCrashLoopingContainers(ctx context.Context, pClient client.Client, o *crd.ClowdApp) (string, error) {
+	pods := &core.PodList{}
+	opts := []client.ListOption{
+		client.MatchingLabels{o.GetPrimaryLabel(): o.GetClowdName()},
+		client.InNamespace(o.Namespace),
+	}
+
+	if err := pClient.List(ctx, pods, opts...); err != nil {
+		return "", errors.Wrap("list pods: ", err)
+	}
+
+	podCount := map[string]int{}
+	restarts := map[string]int32{}
+	for _, pod := range pods.Items {
+		statuses := []core.ContainerStatus{}
+		statuses = append(statuses, pod.Status.InitContainerStatuses...)
+		statuses = append(statuses, pod.Status.ContainerStatuses...)
+		for _, status := range statuses {
+			if status.State.Waiting == nil || status.State.Waiting.Reason != "CrashLoopBackOff" {
+				continue
+			}
+			podCount[status.Name]++
+			if status.RestartCount > restarts[status.Name] {
+				restarts[status.Name] = status.RestartCount
+			}
+		}
+	}
+
+	var msgs []string
+	for name, count := range podCount {
+		msgs = append(msgs, fmt.Sprintf("container [%s] is crash looping in %d pod(s), last restart count %d", name, count, restarts[name]))
+	}
+
+	sort.Strings(msgs)
+
+	return strings.Join(msgs, "; "), nil
+}
+
+// GetAppUnboundVolumes returns a message describing each of the ClowdApp's PVCs that are not yet
+// bound, the message is empty when all of them are.
+func GetAppUnboundVolumes(ctx context.Context, pClient client.Client, o *crd.ClowdApp) (string, error) {
+	pvcs := &core.PersistentVolumeClaimList{}
+	opts := []client.ListOption{
+		client.MatchingLabels{o.GetPrimaryLabel(): o.GetClowdName()},
+		client.InNamespace(o.Namespace),
+	}
+
+	if err := pClient.List(ctx, pvcs, opts...); err != nil {
+		return "", errors.Wrap("list pvcs: ", err)
+	}
+
+	var msgs []string
+	for _, pvc := range pvcs.Items {
+		if pvc.Status.Phase == core.ClaimBound {
+			continue
+		}
+		phase := pvc.Status.Phase
+		if phase == "" {
+			phase = core.ClaimPending
+		}
+		storageClass := "<default>"
+		if pvc.Spec.StorageClassName != nil {
+			storageClass = *pvc.Spec.StorageClassName
+		}
+		msgs = append(msgs, fmt.Sprintf("pvc [%s] is %s (storageClass: %s)", pvc.Name, phase, storageClass))
+	}
+
+	sort.Strings(msgs)
+
+	return strings.Join(msgs, "; "), nil
+}
+
 func GetAppResourceFigures(ctx context.Context, client client.Client, o *crd.ClowdApp) (crd.AppResourceStatus, string, error) {
 
 	var totalManagedDeployments int32
@@ -411,6 +721,79 @@ func GetEnvResourceStatus(ctx context.Context, client client.Client, o *crd.Clow
 	return false, msg, nil
 }
 
+// providerTimeoutCondition returns the ProviderTimedOut condition for a reconcile that failed with
+// the given error, or nil if no provider timed out.
+func providerTimeoutCondition(err error) *clusterv1.Condition {
+	var timeoutErr *errors.ProviderTimeout
+	if !errlib.As(err, &timeoutErr) {
+		return nil
+	}
+
+	return &clusterv1.Condition{
+		Type:               crd.ProviderTimedOut,
+		Status:             core.ConditionTrue,
+		Reason:             "ProviderDeadlineExceeded",
+		Message:            fmt.Sprintf("provider %s did not finish within %s", timeoutErr.Provider, timeoutErr.Timeout),
+		LastTransitionTime: v1.Now(),
+	}
+}
+
+// missingProvidersCondition returns the ProvidersMissing condition for a reconcile that failed
+// with the given error, or nil if the environment offers every provider the app requires.
+func missingProvidersCondition(err error) *clusterv1.Condition {
+	var missingErr *errors.MissingProviders
+	if !errlib.As(err, &missingErr) {
+		return nil
+	}
+
+	return &clusterv1.Condition{
+		Type:               crd.ProvidersMissing,
+		Status:             core.ConditionTrue,
+		Reason:             "RequiredProviderUnavailable",
+		Message:            fmt.Sprintf("environment %s does not offer: %s", missingErr.Env, strings.Join(missingErr.Providers, ", ")),
+		LastTransitionTime: v1.Now(),
+	}
+}
+
+// databaseImageMissingCondition returns the DatabaseImageMissing condition for a reconcile that
+// failed with the given error, or nil if the app's database had an image to run.
+func databaseImageMissingCondition(err error) *clusterv1.Condition {
+	var imageErr *errors.DatabaseImageMissing
+	if !errlib.As(err, &imageErr) {
+		return nil
+	}
+
+	return &clusterv1.Condition{
+		Type:               crd.DatabaseImageMissing,
+		Status:             core.ConditionTrue,
+		Reason:             "DatabaseImageNotSet",
+		Message:            fmt.Sprintf("no image is set for PostgreSQL %d; set spec.providers.db.images[\"%d\"] of ClowdEnvironment %s, or spec.database.version to a supported version", imageErr.Version, imageErr.Version, imageErr.Env),
+		LastTransitionTime: v1.Now(),
+	}
+}
+
+// kafkaTopicCreationCondition returns the KafkaTopicCreationFailed condition for a reconcile that
+// failed with the given error, or nil if the app's topics were created.
+func kafkaTopicCreationCondition(err error) *clusterv1.Condition {
+	var topicErr *errors.KafkaTopicCreationFailed
+	if !errlib.As(err, &topicErr) {
+		return nil
+	}
+
+	condition := &clusterv1.Condition{
+		Type:               crd.KafkaTopicCreationFailed,
+		Status:             core.ConditionTrue,
+		Reason:             "TopicRejected",
+		Message:            fmt.Sprintf("the Kafka cluster rejected topic %s: %s", topicErr.Topic, topicErr.Cause),
+		LastTransitionTime: v1.Now(),
+	}
+	if topicErr.Transient {
+		condition.Reason = "ClusterUnavailable"
+		condition.Message = fmt.Sprintf("the Kafka cluster was unavailable on %d attempts to create topic %s, retrying on the next reconcile: %s", topicErr.Attempts, topicErr.Topic, topicErr.Cause)
+	}
+	return condition
+}
+
 func SetClowdEnvConditions(ctx context.Context, client client.Client, o *crd.ClowdEnvironment, state clusterv1.ConditionType, oldStatus *crd.ClowdEnvironmentStatus, err error) error {
 	conditions := []clusterv1.Condition{}
 
@@ -431,6 +814,13 @@ func SetClowdEnvConditions(ctx context.Context, client client.Client, o *crd.Clo
 		conditions = append(conditions, *condition)
 	}
 
+	// The ProviderTimedOut condition is only present while reconciles fail on a slow provider
+	if timeoutCondition := providerTimeoutCondition(err); timeoutCondition != nil {
+		conditions = append(conditions, *timeoutCondition)
+	} else {
+		cond.Delete(o, crd.ProviderTimedOut)
+	}
+
 	deploymentStatus, msg, err := GetEnvResourceStatus(ctx, client, o)
 	if err != nil {
 		return err
@@ -453,6 +843,20 @@ func SetClowdEnvConditions(ctx context.Context, client client.Client, o *crd.Clo
 
 	conditions = append(conditions, *condition)
 
+	// The Ready condition gates ClowdApp reconciliation, it is only set once the providers have
+	// run successfully and the shared resources they created are ready.
+	readyCondition := &clusterv1.Condition{}
+	readyCondition.Type = crd.EnvironmentReady
+	readyCondition.Status = core.ConditionFalse
+	readyCondition.Message = "Shared environment resources are not yet ready"
+	if state == crd.ReconciliationSuccessful && deploymentStatus {
+		readyCondition.Status = core.ConditionTrue
+		readyCondition.Message = "Shared environment resources ready"
+	}
+	readyCondition.LastTransitionTime = v1.Now()
+
+	conditions = append(conditions, *readyCondition)
+
 	for _, condition := range conditions {
 		innerCondition := condition
 		cond.Set(o, &innerCondition)
@@ -488,6 +892,34 @@ func SetClowdAppConditions(ctx context.Context, client client.Client, o *crd.Clo
 		conditions = append(conditions, *condition)
 	}
 
+	// The ProviderTimedOut condition is only present while reconciles fail on a slow provider
+	if timeoutCondition := providerTimeoutCondition(err); timeoutCondition != nil {
+		conditions = append(conditions, *timeoutCondition)
+	} else {
+		cond.Delete(o, crd.ProviderTimedOut)
+	}
+
+	// The ProvidersMissing condition is only present while the environment lacks a required provider
+	if missingCondition := missingProvidersCondition(err); missingCondition != nil {
+		conditions = append(conditions, *missingCondition)
+	} else {
+		cond.Delete(o, crd.ProvidersMissing)
+	}
+
+	// The DatabaseImageMissing condition is only present while the app's database has no image
+	if imageCondition := databaseImageMissingCondition(err); imageCondition != nil {
+		conditions = append(conditions, *imageCondition)
+	} else {
+		cond.Delete(o, crd.DatabaseImageMissing)
+	}
+
+	// The KafkaTopicCreationFailed condition is only present while a topic of the app cannot be created
+	if topicCondition := kafkaTopicCreationCondition(err); topicCondition != nil {
+		conditions = append(conditions, *topicCondition)
+	} else {
+		cond.Delete(o, crd.KafkaTopicCreationFailed)
+	}
+
 	deploymentStatus, err := GetAppResourceStatus(ctx, client, o)
 	if err != nil {
 		return err
@@ -510,6 +942,177 @@ func SetClowdAppConditions(ctx context.Context, client client.Client, o *crd.Clo
 
 	conditions = append(conditions, *condition)
 
+	unboundVolumes, err := GetAppUnboundVolumes(ctx, client, o)
+	if err != nil {
+		return err
+	}
+
+	// The VolumeUnbound condition is only present while a PVC is waiting to bind
+	if unboundVolumes != "" {
+		volumeCondition := &clusterv1.Condition{}
+		volumeCondition.Type = crd.VolumeUnbound
+		volumeCondition.Status = core.ConditionTrue
+		volumeCondition.Reason = "PersistentVolumeClaimNotBound"
+		volumeCondition.Message = unboundVolumes
+		volumeCondition.LastTransitionTime = v1.Now()
+		conditions = append(conditions, *volumeCondition)
+	} else {
+		cond.Delete(o, crd.VolumeUnbound)
+	}
+
+	zoneMismatch, err := GetAppVolumeZoneMismatch(ctx, client, o)
+	if err != nil {
+		return err
+	}
+
+	// The VolumeZoneMismatch condition is only present while a database pod cannot reach its volume
+	if zoneMismatch != "" {
+		zoneCondition := &clusterv1.Condition{}
+		zoneCondition.Type = crd.VolumeZoneMismatch
+		zoneCondition.Status = core.ConditionTrue
+		zoneCondition.Reason = "VolumeNodeAffinityConflict"
+		zoneCondition.Message = zoneMismatch
+		zoneCondition.LastTransitionTime = v1.Now()
+		conditions = append(conditions, *zoneCondition)
+	} else {
+		cond.Delete(o, crd.VolumeZoneMismatch)
+	}
+
+	failedRollouts, err := GetAppFailedRollouts(ctx, client, o)
+	if err != nil {
+		return err
+	}
+
+	// The RolloutFailed condition is only present while a deployment is stuck past its progress deadline
+	if failedRollouts != "" {
+		rolloutCondition := &clusterv1.Condition{}
+		rolloutCondition.Type = crd.RolloutFailed
+		rolloutCondition.Status = core.ConditionTrue
+		rolloutCondition.Reason = "ProgressDeadlineExceeded"
+		rolloutCondition.Message = failedRollouts
+		rolloutCondition.LastTransitionTime = v1.Now()
+		conditions = append(conditions, *rolloutCondition)
+	} else {
+		cond.Delete(o, crd.RolloutFailed)
+	}
+
+	crashLooping, err := GetAppCrashLoopingContainers(ctx, client, o)
+	if err != nil {
+		return err
+	}
+
+	// The CrashLooping condition is only present while containers are restarting in a CrashLoopBackOff
+	if crashLooping != "" {
+		crashCondition := &clusterv1.Condition{}
+		crashCondition.Type = crd.CrashLooping
+		crashCondition.Status = core.ConditionTrue
+		crashCondition.Reason = "CrashLoopBackOff"
+		crashCondition.Message = crashLooping
+		crashCondition.LastTransitionTime = v1.Now()
+		conditions = append(conditions, *crashCondition)
+	} else {
+		cond.Delete(o, crd.CrashLooping)
+	}
+
+	// The CircuitOpen condition is only present while reconciles are held back after repeated failures
+	if open, remaining := appCircuitBreaker.isOpen(o.GetIdent(), o.Generation); open {
+		circuitCondition := &clusterv1.Condition{}
+		circuitCondition.Type = crd.CircuitOpen
+		circuitCondition.Status = core.ConditionTrue
+		circuitCondition.Reason = "RepeatedReconciliationFailures"
+		until := time.Now().Add(remaining).UTC().Format(time.RFC3339)
+		circuitCondition.Message = fmt.Sprintf("reconciles held back until %s or until the spec changes", until)
+		circuitCondition.LastTransitionTime = v1.Now()
+		conditions = append(conditions, *circuitCondition)
+	} else {
+		cond.Delete(o, crd.CircuitOpen)
+	}
+
+	// The Failed condition is only present while the app is no longer retried after using up its retries
+	if failed, failures := appRetryLimiter.failed(o.GetIdent(), o.Generation); failed {
+		failedCondition := &clusterv1.Condition{}
+		failedCondition.Type = crd.Failed
+		failedCondition.Status = core.ConditionTrue
+		failedCondition.Reason = "RetriesExhausted"
+		failedCondition.Message = fmt.Sprintf("reconcile failed %d times in a row, not retried until the app or its environment changes", failures)
+		failedCondition.LastTransitionTime = v1.Now()
+		conditions = append(conditions, *failedCondition)
+	} else {
+		cond.Delete(o, crd.Failed)
+	}
+
+	pendingMaintenance, err := GetAppPendingMaintenance(ctx, client, o)
+	if err != nil {
+		return err
+	}
+
+	// The PendingMaintenance condition is only present while database changes are deferred
+	if pendingMaintenance != "" {
+		maintenanceCondition := &clusterv1.Condition{}
+		maintenanceCondition.Type = crd.PendingMaintenance
+		maintenanceCondition.Status = core.ConditionTrue
+		maintenanceCondition.Reason = "OutsideMaintenanceWindow"
+		maintenanceCondition.Message = fmt.Sprintf("database changes deferred until the maintenance window: %s", pendingMaintenance)
+		maintenanceCondition.LastTransitionTime = v1.Now()
+		conditions = append(conditions, *maintenanceCondition)
+	} else {
+		cond.Delete(o, crd.PendingMaintenance)
+	}
+
+	drainStep, err := GetAppDatabaseDrain(ctx, client, o)
+	if err != nil {
+		return err
+	}
+
+	// The DatabaseDraining condition is only present while the app is scaled down for a database upgrade
+	if drainStep != "" {
+		drainCondition := &clusterv1.Condition{}
+		drainCondition.Type = crd.DatabaseDraining
+		drainCondition.Status = core.ConditionTrue
+		drainCondition.Reason = "DatabaseUpgrade"
+		drainCondition.Message = databaseDrainMessages[drainStep]
+		drainCondition.LastTransitionTime = v1.Now()
+		conditions = append(conditions, *drainCondition)
+	} else {
+		cond.Delete(o, crd.DatabaseDraining)
+	}
+
+	failedMigrations, err := GetAppFailedDatabaseMigrations(ctx, client, o)
+	if err != nil {
+		return err
+	}
+
+	// The DatabaseMigrationFailed condition is only present while the latest migrations have failed
+	if failedMigrations != "" {
+		migrationCondition := &clusterv1.Condition{}
+		migrationCondition.Type = crd.DatabaseMigrationFailed
+		migrationCondition.Status = core.ConditionTrue
+		migrationCondition.Reason = "MigrationJobFailed"
+		migrationCondition.Message = failedMigrations
+		migrationCondition.LastTransitionTime = v1.Now()
+		conditions = append(conditions, *migrationCondition)
+	} else {
+		cond.Delete(o, crd.DatabaseMigrationFailed)
+	}
+
+	maintenanceMode, err := GetAppMaintenanceMode(ctx, client, o)
+	if err != nil {
+		return err
+	}
+
+	// The MaintenanceMode condition is only present while the app is told to hold off its writes
+	if maintenanceMode != "" {
+		maintenanceCondition := &clusterv1.Condition{}
+		maintenanceCondition.Type = crd.MaintenanceMode
+		maintenanceCondition.Status = core.ConditionTrue
+		maintenanceCondition.Reason = "MigrationsPending"
+		maintenanceCondition.Message = maintenanceMode
+		maintenanceCondition.LastTransitionTime = v1.Now()
+		conditions = append(conditions, *maintenanceCondition)
+	} else {
+		cond.Delete(o, crd.MaintenanceMode)
+	}
+
 	for _, condition := range conditions {
 		innerCondition := condition
 		cond.Set(o, &innerCondition)
@@ -0,0 +1,135 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	crd "github.com/RedHatInsights/clowder/apis/cloud.redhat.com/v1alpha1"
+	"github.com/RedHatInsights/rhc-osdk-utils/utils"
+	"github.com/stretchr/testify/assert"
+	apps "k8s.io/api/apps/v1"
+	core "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	cond "sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func statusApp() *crd.ClowdApp {
+	app := &crd.ClowdApp{
+		ObjectMeta: metav1.ObjectMeta{Name: "puptoo", Namespace: "test", UID: types.UID("puptoo-uid")},
+		Spec:       crd.ClowdAppSpec{EnvName: "env"},
+	}
+	app.Spec.Database.Name = "puptoo"
+	return app
+}
+
+func ownedByApp(app *crd.ClowdApp) []metav1.OwnerReference {
+	return []metav1.OwnerReference{{
+		APIVersion: "cloud.redhat.com/v1alpha1",
+		Kind:       "ClowdApp",
+		Name:       app.Name,
+		UID:        app.UID,
+		Controller: utils.TruePtr(),
+	}}
+}
+
+// setAppConditions sets the conditions of the app as a successful reconcile would, with the given
+// objects in the cluster alongside the app and its environment.
+func setAppConditions(t *testing.T, app *crd.ClowdApp, objs ...client.Object) {
+	env := &crd.ClowdEnvironment{ObjectMeta: metav1.ObjectMeta{Name: "env"}}
+	env.Status.TargetNamespace = "test"
+
+	stored := app.DeepCopy()
+	stored.ResourceVersion = ""
+	objs = append(objs, env, stored)
+	c := fake.NewClientBuilder().WithScheme(Scheme).WithObjects(objs...).Build()
+	app.ResourceVersion = stored.ResourceVersion
+
+	oldStatus := app.Status.DeepCopy()
+	err := SetClowdAppConditions(context.Background(), c, app, crd.ReconciliationSuccessful, oldStatus, nil)
+	assert.NoError(t, err)
+}
+
+func TestAppConditionsSetAndCleared(t *testing.T) {
+	app := statusApp()
+
+	pvc := &core.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "puptoo-db", Namespace: "test", Labels: map[string]string{"app": "puptoo"}},
+		Status:     core.PersistentVolumeClaimStatus{Phase: core.ClaimPending},
+	}
+
+	dbPod := &core.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "puptoo-db-1", Namespace: "test", Labels: map[string]string{"app": "puptoo", "service": "db"}},
+		Status: core.PodStatus{Conditions: []core.PodCondition{{
+			Type:    core.PodScheduled,
+			Status:  core.ConditionFalse,
+			Message: "0/3 nodes are available: 3 node(s) had volume node affinity conflict",
+		}}},
+	}
+
+	stuck := &apps.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "puptoo-processor", Namespace: "test", OwnerReferences: ownedByApp(app)},
+		Status: apps.DeploymentStatus{Conditions: []apps.DeploymentCondition{{
+			Type:    apps.DeploymentProgressing,
+			Status:  core.ConditionFalse,
+			Reason:  "ProgressDeadlineExceeded",
+			Message: "ReplicaSet has timed out progressing",
+		}}},
+	}
+
+	crashing := &core.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "puptoo-processor-1", Namespace: "test", Labels: map[string]string{"app": "puptoo"}},
+		Status: core.PodStatus{ContainerStatuses: []core.ContainerStatus{{
+			Name:         "puptoo-processor",
+			RestartCount: 4,
+			State:        core.ContainerState{Waiting: &core.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+		}}},
+	}
+
+	tests := []struct {
+		name      string
+		obj       client.Object
+		condition clusterv1.ConditionType
+		reason    string
+		message   string
+	}{
+		{"unbound volume", pvc, crd.VolumeUnbound, "PersistentVolumeClaimNotBound", "pvc [puptoo-db] is Pending (storageClass: <default>)"},
+		{"volume zone mismatch", dbPod, crd.VolumeZoneMismatch, "VolumeNodeAffinityConflict", "pod [puptoo-db-1] cannot be scheduled in the zone of its volume"},
+		{"failed rollout", stuck, crd.RolloutFailed, "ProgressDeadlineExceeded", "deployment [puptoo-processor] exceeded its progress deadline: ReplicaSet has timed out progressing"},
+		{"crash looping", crashing, crd.CrashLooping, "CrashLoopBackOff", "container [puptoo-processor] is crash looping in 1 pod(s), last restart count 4"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setAppConditions(t, app, tt.obj.DeepCopyObject().(client.Object))
+			assert.True(t, cond.IsTrue(app, tt.condition))
+			assert.Equal(t, tt.reason, cond.GetReason(app, tt.condition))
+			assert.Equal(t, tt.message, cond.GetMessage(app, tt.condition))
+
+			// Once the cause is gone, so is the condition
+			setAppConditions(t, app)
+			assert.Nil(t, cond.Get(app, tt.condition))
+		})
+	}
+}
+
+func TestAppConditionsDaemonSets(t *testing.T) {
+	app := statusApp()
+
+	daemonSet := &apps.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "puptoo-agent", Namespace: "test", OwnerReferences: ownedByApp(app)},
+		Status:     apps.DaemonSetStatus{DesiredNumberScheduled: 3, UpdatedNumberScheduled: 3, NumberAvailable: 1},
+	}
+
+	setAppConditions(t, app, daemonSet.DeepCopy())
+	assert.True(t, cond.IsFalse(app, crd.DeploymentsReady))
+	assert.False(t, app.Status.Ready)
+
+	daemonSet.Status.NumberAvailable = 3
+	setAppConditions(t, app, daemonSet.DeepCopy())
+	assert.True(t, cond.IsTrue(app, crd.DeploymentsReady))
+	assert.True(t, app.Status.Ready)
+}
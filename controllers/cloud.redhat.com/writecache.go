@@ -0,0 +1,185 @@
+package controllers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	crd "github.com/RedHatInsights/clowder/apis/cloud.redhat.com/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// writtenObject is the content an app's reconcile last wrote to an object,
+// along with the resourceVersion that write produced.
+type writtenObject struct {
+	resourceVersion string
+	hash            string
+}
+
+// appWrites holds the objects written by an app's reconciles, for as long as
+// neither the app nor its environment change generation.
+type appWrites struct {
+	appGeneration int64
+	envGeneration int64
+	objects       map[string]writtenObject
+}
+
+// writeCache remembers the objects each app last wrote, so that an update
+// which would send exactly the same content to an object nobody has touched
+// since can be skipped. The resource cache already skips objects that are
+// unchanged from what was read back from the cluster; this also catches the
+// objects the API server normalizes on write, which would otherwise be
+// rewritten on every reconcile.
+type writeCache struct {
+	mu   sync.Mutex
+	apps map[string]*appWrites
+}
+
+func newWriteCache() *writeCache {
+	return &writeCache{apps: map[string]*appWrites{}}
+}
+
+var appWriteCache = newWriteCache()
+
+// start begins a reconcile of the app, dropping everything known about it if
+// the app or its environment changed generation since the last one.
+func (wc *writeCache) start(ident string, appGeneration int64, envGeneration int64) {
+	wc.mu.Lock()
+	defer wc.mu.Unlock()
+
+	w, ok := wc.apps[ident]
+	if !ok || w.appGeneration != appGeneration || w.envGeneration != envGeneration {
+		wc.apps[ident] = &appWrites{
+			appGeneration: appGeneration,
+			envGeneration: envGeneration,
+			objects:       map[string]writtenObject{},
+		}
+	}
+}
+
+// unchanged reports whether the app last wrote content with the given hash to
+// the object, and the object is still at the resourceVersion that write left.
+func (wc *writeCache) unchanged(ident string, key string, resourceVersion string, hash string) bool {
+	wc.mu.Lock()
+	defer wc.mu.Unlock()
+
+	w, ok := wc.apps[ident]
+	if !ok {
+		return false
+	}
+	written, ok := w.objects[key]
+	return ok && resourceVersion != "" && written.resourceVersion == resourceVersion && written.hash == hash
+}
+
+func (wc *writeCache) record(ident string, key string, resourceVersion string, hash string) {
+	wc.mu.Lock()
+	defer wc.mu.Unlock()
+
+	if w, ok := wc.apps[ident]; ok {
+		w.objects[key] = writtenObject{resourceVersion: resourceVersion, hash: hash}
+	}
+}
+
+func (wc *writeCache) forget(ident string, key string) {
+	wc.mu.Lock()
+	defer wc.mu.Unlock()
+
+	if w, ok := wc.apps[ident]; ok {
+		delete(w.objects, key)
+	}
+}
+
+// reset forgets everything the app wrote, when it is deleted.
+func (wc *writeCache) reset(ident string) {
+	wc.mu.Lock()
+	defer wc.mu.Unlock()
+
+	delete(wc.apps, ident)
+}
+
+// writeCacheClient skips updates of objects that the app's last write left
+// unchanged, recording every create and update made through it.
+type writeCacheClient struct {
+	client.Client
+	cache *writeCache
+	ident string
+}
+
+// newWriteCacheClient wraps the client for a reconcile of the app in the given
+// environment.
+func newWriteCacheClient(c client.Client, cache *writeCache, app *crd.ClowdApp, env *crd.ClowdEnvironment) client.Client {
+	cache.start(app.GetIdent(), app.Generation, env.Generation)
+	return &writeCacheClient{Client: c, cache: cache, ident: app.GetIdent()}
+}
+
+// fingerprint returns the key the object is cached under and a hash of its
+// content, leaving out the fields the API server maintains on every write.
+func (w *writeCacheClient) fingerprint(obj client.Object) (string, string, error) {
+	gvk, err := apiutil.GVKForObject(obj, w.Scheme())
+	if err != nil {
+		return "", "", err
+	}
+
+	content := obj.DeepCopyObject().(client.Object)
+	content.SetResourceVersion("")
+	content.SetManagedFields(nil)
+
+	data, err := json.Marshal(content)
+	if err != nil {
+		return "", "", err
+	}
+	sum := sha256.Sum256(data)
+
+	key := fmt.Sprintf("%s/%s/%s", gvk.String(), obj.GetNamespace(), obj.GetName())
+	return key, hex.EncodeToString(sum[:]), nil
+}
+
+func (w *writeCacheClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	if err := w.Client.Create(ctx, obj, opts...); err != nil {
+		return err
+	}
+
+	// The created object is hashed as the server returned it, as that is what
+	// the next reconcile starts from.
+	if key, hash, err := w.fingerprint(obj); err == nil {
+		w.cache.record(w.ident, key, obj.GetResourceVersion(), hash)
+	}
+	return nil
+}
+
+func (w *writeCacheClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	key, hash, err := w.fingerprint(obj)
+	if err != nil {
+		return w.Client.Update(ctx, obj, opts...)
+	}
+
+	if w.cache.unchanged(w.ident, key, obj.GetResourceVersion(), hash) {
+		return nil
+	}
+
+	if err := w.Client.Update(ctx, obj, opts...); err != nil {
+		w.cache.forget(w.ident, key)
+		return err
+	}
+
+	w.cache.record(w.ident, key, obj.GetResourceVersion(), hash)
+	return nil
+}
+
+func (w *writeCacheClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	if key, _, err := w.fingerprint(obj); err == nil {
+		w.cache.forget(w.ident, key)
+	}
+	return w.Client.Patch(ctx, obj, patch, opts...)
+}
+
+func (w *writeCacheClient) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	if key, _, err := w.fingerprint(obj); err == nil {
+		w.cache.forget(w.ident, key)
+	}
+	return w.Client.Delete(ctx, obj, opts...)
+}
@@ -0,0 +1,114 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	crd "github.com/RedHatInsights/clowder/apis/cloud.redhat.com/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	core "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// countingClient counts the updates that reach the cluster.
+type countingClient struct {
+	client.Client
+	updates int
+}
+
+func (c *countingClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	c.updates++
+	return c.Client.Update(ctx, obj, opts...)
+}
+
+// applyConfigMap reads the ConfigMap back and writes the desired data to it,
+// the way a provider does through the resource cache.
+func applyConfigMap(ctx context.Context, t testing.TB, c client.Client, name string, data string) {
+	cm := &core.ConfigMap{}
+	err := c.Get(ctx, types.NamespacedName{Name: name, Namespace: "test"}, cm)
+	assert.NoError(t, err)
+	cm.Data = map[string]string{"key": data}
+	assert.NoError(t, c.Update(ctx, cm))
+}
+
+func TestWriteCacheClient(t *testing.T) {
+	ctx := context.Background()
+	cluster := &countingClient{Client: fake.NewClientBuilder().WithScheme(Scheme).Build()}
+	cache := newWriteCache()
+
+	app := &crd.ClowdApp{ObjectMeta: metav1.ObjectMeta{Name: "puptoo", Namespace: "test", Generation: 1}}
+	env := &crd.ClowdEnvironment{ObjectMeta: metav1.ObjectMeta{Name: "env", Generation: 1}}
+
+	c := newWriteCacheClient(cluster, cache, app, env)
+	assert.NoError(t, c.Create(ctx, &core.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "puptoo-config", Namespace: "test"}}))
+
+	applyConfigMap(ctx, t, c, "puptoo-config", "a")
+	assert.Equal(t, 1, cluster.updates)
+
+	// The same content on the next reconcile is not written again
+	c = newWriteCacheClient(cluster, cache, app, env)
+	applyConfigMap(ctx, t, c, "puptoo-config", "a")
+	assert.Equal(t, 1, cluster.updates)
+
+	// New content is
+	applyConfigMap(ctx, t, c, "puptoo-config", "b")
+	assert.Equal(t, 2, cluster.updates)
+
+	// As is the same content, once someone else changed the object
+	cm := &core.ConfigMap{}
+	assert.NoError(t, cluster.Get(ctx, types.NamespacedName{Name: "puptoo-config", Namespace: "test"}, cm))
+	cm.Labels = map[string]string{"edited": "true"}
+	assert.NoError(t, cluster.Update(ctx, cm))
+	applyConfigMap(ctx, t, c, "puptoo-config", "b")
+	assert.Equal(t, 4, cluster.updates)
+
+	applyConfigMap(ctx, t, c, "puptoo-config", "b")
+	assert.Equal(t, 4, cluster.updates)
+
+	// A change to the environment invalidates everything the app wrote
+	env.Generation = 2
+	c = newWriteCacheClient(cluster, cache, app, env)
+	applyConfigMap(ctx, t, c, "puptoo-config", "b")
+	assert.Equal(t, 5, cluster.updates)
+
+	// As does deleting the object
+	c = newWriteCacheClient(cluster, cache, app, env)
+	assert.NoError(t, c.Delete(ctx, cm))
+	_, ok := cache.apps[app.GetIdent()].objects["/v1, Kind=ConfigMap/test/puptoo-config"]
+	assert.False(t, ok)
+}
+
+// BenchmarkWriteCache reconciles an app of 100 unchanged ConfigMaps and reports
+// how many updates reach the cluster per reconcile.
+func BenchmarkWriteCache(b *testing.B) {
+	for _, cached := range []bool{false, true} {
+		b.Run(fmt.Sprintf("cached=%t", cached), func(b *testing.B) {
+			ctx := context.Background()
+			cluster := &countingClient{Client: fake.NewClientBuilder().WithScheme(Scheme).Build()}
+			cache := newWriteCache()
+			app := &crd.ClowdApp{ObjectMeta: metav1.ObjectMeta{Name: "puptoo", Namespace: "test", Generation: 1}}
+			env := &crd.ClowdEnvironment{ObjectMeta: metav1.ObjectMeta{Name: "env", Generation: 1}}
+
+			for i := 0; i < 100; i++ {
+				cm := &core.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("cm-%d", i), Namespace: "test"}}
+				assert.NoError(b, cluster.Create(ctx, cm))
+			}
+
+			b.ResetTimer()
+			for n := 0; n < b.N; n++ {
+				var c client.Client = cluster
+				if cached {
+					c = newWriteCacheClient(cluster, cache, app, env)
+				}
+				for i := 0; i < 100; i++ {
+					applyConfigMap(ctx, b, c, fmt.Sprintf("cm-%d", i), "data")
+				}
+			}
+			b.ReportMetric(float64(cluster.updates)/float64(b.N), "updates/op")
+		})
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"flag"
 	"fmt"
 	"net/http"
+	"os"
 	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
@@ -38,6 +39,7 @@ import (
 	crd "github.com/RedHatInsights/clowder/apis/cloud.redhat.com/v1alpha1"
 	controllers "github.com/RedHatInsights/clowder/controllers/cloud.redhat.com"
 	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/clowderconfig"
+	"github.com/RedHatInsights/clowder/controllers/cloud.redhat.com/featuregates"
 	"github.com/RedHatInsights/rhc-osdk-utils/logging"
 	//+kubebuilder:scaffold:imports
 )
@@ -68,6 +70,10 @@ func runAPIServer() {
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "validate" {
+		os.Exit(runValidate(os.Args[2:], os.Stdout, os.Stderr))
+	}
+
 	var metricsAddr string
 	var enableLeaderElection bool
 	var probeAddr string
@@ -76,6 +82,18 @@ func main() {
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
+	flag.StringVar(&controllers.DefaultEnvName, "default-env-name", "",
+		"The ClowdEnvironment used by ClowdApps that do not set an envName.")
+	flag.DurationVar(&controllers.RateLimiter.BaseDelay, "rate-limiter-base-delay", controllers.RateLimiter.BaseDelay,
+		"The delay before requeueing an object after its first failed reconcile, doubled on each further failure.")
+	flag.DurationVar(&controllers.RateLimiter.MaxDelay, "rate-limiter-max-delay", controllers.RateLimiter.MaxDelay,
+		"The longest delay before requeueing an object whose reconciles keep failing.")
+	flag.Float64Var(&controllers.RateLimiter.BucketQPS, "rate-limiter-bucket-qps", controllers.RateLimiter.BucketQPS,
+		"The average number of requeues per second across all objects, when rate-limiter-bucket-size is set.")
+	flag.IntVar(&controllers.RateLimiter.BucketSize, "rate-limiter-bucket-size", controllers.RateLimiter.BucketSize,
+		"The burst of requeues allowed across all objects. 0 leaves requeues of different objects unlimited.")
+	flag.Var(featuregates.Gates, "feature-gates", featuregates.Usage())
+	flag.Parse()
 
 	logger, err := logging.SetupLogging(clowderconfig.LoadedConfig.Features.DisableCloudWatchLogging)
 
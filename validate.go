@@ -0,0 +1,208 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	crd "github.com/RedHatInsights/clowder/apis/cloud.redhat.com/v1alpha1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/apimachinery/pkg/util/yaml"
+)
+
+// Exit codes returned by the validate subcommand.
+const (
+	validateOK       = 0
+	validateProblems = 1
+	validateFailed   = 2
+)
+
+type validationProblem struct {
+	Field    string `json:"field"`
+	Type     string `json:"type"`
+	BadValue string `json:"badValue,omitempty"`
+	Detail   string `json:"detail,omitempty"`
+}
+
+type validationResult struct {
+	File     string              `json:"file"`
+	Document int                 `json:"document"`
+	Kind     string              `json:"kind"`
+	Name     string              `json:"name,omitempty"`
+	Problems []validationProblem `json:"problems"`
+}
+
+type validationReport struct {
+	Valid   bool               `json:"valid"`
+	Results []validationResult `json:"results"`
+}
+
+type manifestDoc struct {
+	file  string
+	index int
+	kind  string
+	raw   []byte
+}
+
+// runValidate implements `manager validate [-env FILE] FILE...`. It lints the
+// ClowdApps and ClowdEnvironments found in the given YAML files without
+// talking to a cluster and writes a JSON report to out. Any ClowdEnvironments
+// found in the inputs are also used to check the apps that target them.
+func runValidate(args []string, out io.Writer, errOut io.Writer) int {
+	fs := flag.NewFlagSet("validate", flag.ContinueOnError)
+	fs.SetOutput(errOut)
+	envFile := fs.String("env", "", "A file containing the ClowdEnvironment the apps target.")
+	if err := fs.Parse(args); err != nil {
+		return validateFailed
+	}
+
+	files := fs.Args()
+	if *envFile != "" {
+		files = append([]string{*envFile}, files...)
+	}
+	if len(files) == 0 {
+		fmt.Fprintln(errOut, "usage: manager validate [-env FILE] FILE...")
+		return validateFailed
+	}
+
+	var docs []manifestDoc
+	for _, file := range files {
+		fileDocs, err := readManifests(file)
+		if err != nil {
+			fmt.Fprintf(errOut, "could not read %s: %s\n", file, err)
+			return validateFailed
+		}
+		docs = append(docs, fileDocs...)
+	}
+
+	report := validateManifests(docs)
+
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		fmt.Fprintf(errOut, "could not write report: %s\n", err)
+		return validateFailed
+	}
+
+	if !report.Valid {
+		return validateProblems
+	}
+	return validateOK
+}
+
+func readManifests(file string) ([]manifestDoc, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var docs []manifestDoc
+	decoder := yaml.NewYAMLOrJSONDecoder(f, 4096)
+	for index := 0; ; index++ {
+		obj := map[string]interface{}{}
+		if err := decoder.Decode(&obj); err != nil {
+			if errors.Is(err, io.EOF) {
+				return docs, nil
+			}
+			return nil, fmt.Errorf("document %d: %w", index, err)
+		}
+		if len(obj) == 0 {
+			continue
+		}
+		raw, err := json.Marshal(obj)
+		if err != nil {
+			return nil, err
+		}
+		kind, _ := obj["kind"].(string)
+		docs = append(docs, manifestDoc{file: file, index: index, kind: kind, raw: raw})
+	}
+}
+
+func decodeStrict(raw []byte, into interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(into)
+}
+
+func validateManifests(docs []manifestDoc) validationReport {
+	report := validationReport{Valid: true, Results: []validationResult{}}
+
+	envs := map[string]*crd.ClowdEnvironment{}
+	for _, doc := range docs {
+		if doc.kind != "ClowdEnvironment" {
+			continue
+		}
+		env := &crd.ClowdEnvironment{}
+		if err := decodeStrict(doc.raw, env); err != nil {
+			report.add(doc, "", decodeProblems(err))
+			continue
+		}
+		envs[env.Name] = env
+		report.add(doc, env.Name, fieldProblems(env.Validate()))
+	}
+
+	for _, doc := range docs {
+		if doc.kind != "ClowdApp" {
+			continue
+		}
+		app := &crd.ClowdApp{}
+		if err := decodeStrict(doc.raw, app); err != nil {
+			report.add(doc, "", decodeProblems(err))
+			continue
+		}
+
+		var errs field.ErrorList
+		if env, ok := envs[app.Spec.EnvName]; ok {
+			errs = app.ValidateWithEnvironment(env)
+		} else {
+			errs = app.Validate()
+			if len(envs) != 0 {
+				errs = append(errs, field.NotFound(field.NewPath("spec.EnvName"), app.Spec.EnvName))
+			}
+		}
+		report.add(doc, app.Name, fieldProblems(errs))
+	}
+
+	return report
+}
+
+func (r *validationReport) add(doc manifestDoc, name string, problems []validationProblem) {
+	if len(problems) != 0 {
+		r.Valid = false
+	}
+	r.Results = append(r.Results, validationResult{
+		File:     doc.file,
+		Document: doc.index,
+		Kind:     doc.kind,
+		Name:     name,
+		Problems: problems,
+	})
+}
+
+func decodeProblems(err error) []validationProblem {
+	return []validationProblem{{
+		Type:   "DecodeError",
+		Detail: err.Error(),
+	}}
+}
+
+func fieldProblems(errs field.ErrorList) []validationProblem {
+	problems := []validationProblem{}
+	for _, err := range errs {
+		problem := validationProblem{
+			Field:  err.Field,
+			Type:   string(err.Type),
+			Detail: err.Detail,
+		}
+		if err.BadValue != nil {
+			problem.BadValue = fmt.Sprintf("%v", err.BadValue)
+		}
+		problems = append(problems, problem)
+	}
+	return problems
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const validateEnv = `
+apiVersion: cloud.redhat.com/v1alpha1
+kind: ClowdEnvironment
+metadata:
+  name: env
+spec:
+  providers:
+    web:
+      port: 8000
+      privatePort: 10000
+    metrics:
+      port: 9000
+`
+
+const validateApp = `
+apiVersion: cloud.redhat.com/v1alpha1
+kind: ClowdApp
+metadata:
+  name: puptoo
+spec:
+  envName: env
+  deployments:
+  - name: processor
+    podSpec:
+      image: quay.io/puptoo:1
+`
+
+func TestRunValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      string
+		app      string
+		code     int
+		problems []string
+	}{
+		{"valid app", "", validateApp, validateOK, nil},
+		{"valid app and environment", validateEnv, validateApp, validateOK, nil},
+		{"metrics port colliding with the environment", validateEnv, validateApp + "  metricsPort: 8000\n", validateProblems, []string{"FieldValueDuplicate"}},
+		{"unknown environment", validateEnv, strings.Replace(validateApp, "envName: env", "envName: other", 1), validateProblems, []string{"FieldValueNotFound"}},
+		{"unknown field", "", validateApp + "  replicas: 3\n", validateProblems, []string{"DecodeError"}},
+		{"invalid resource requests", "", validateApp + "      resources:\n        limits:\n          cpu: \"1\"\n        requests:\n          cpu: \"2\"\n", validateProblems, []string{"FieldValueInvalid"}},
+		{"malformed manifest", "", "kind: [ClowdApp", validateFailed, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			args := []string{}
+			if tt.env != "" {
+				envFile := filepath.Join(dir, "env.yaml")
+				assert.NoError(t, os.WriteFile(envFile, []byte(tt.env), 0600))
+				args = append(args, "-env", envFile)
+			}
+			appFile := filepath.Join(dir, "app.yaml")
+			assert.NoError(t, os.WriteFile(appFile, []byte(tt.app), 0600))
+			args = append(args, appFile)
+
+			out, errOut := &bytes.Buffer{}, &bytes.Buffer{}
+			assert.Equal(t, tt.code, runValidate(args, out, errOut), errOut.String())
+			if tt.code == validateFailed {
+				assert.NotEmpty(t, errOut.String())
+				return
+			}
+
+			report := validationReport{}
+			assert.NoError(t, json.Unmarshal(out.Bytes(), &report))
+			assert.Equal(t, tt.code == validateOK, report.Valid)
+
+			problems := []string{}
+			for _, result := range report.Results {
+				for _, problem := range result.Problems {
+					problems = append(problems, problem.Type)
+				}
+			}
+			if tt.problems == nil {
+				assert.Empty(t, problems)
+			} else {
+				assert.Equal(t, tt.problems, problems)
+			}
+		})
+	}
+}
+
+func TestRunValidateUsage(t *testing.T) {
+	errOut := &bytes.Buffer{}
+	assert.Equal(t, validateFailed, runValidate([]string{}, &bytes.Buffer{}, errOut))
+	assert.Contains(t, errOut.String(), "usage: manager validate")
+
+	errOut.Reset()
+	assert.Equal(t, validateFailed, runValidate([]string{"missing.yaml"}, &bytes.Buffer{}, errOut))
+	assert.Contains(t, errOut.String(), "could not read missing.yaml")
+}